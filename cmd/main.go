@@ -12,17 +12,39 @@ import (
 	_ "github.com/jackc/pgx/v5/stdlib"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 
+	"filmhub/pkg/audit"
+	"filmhub/pkg/cache"
 	"filmhub/pkg/config"
+	"filmhub/pkg/contentfilter"
 	"filmhub/pkg/database"
 	"filmhub/pkg/logger"
 	jwt "filmhub/pkg/login"
+	"filmhub/pkg/mailer"
+	"filmhub/pkg/middleware"
+	"filmhub/pkg/oidc"
+	"filmhub/pkg/outbox"
+	"filmhub/pkg/pubsub"
+	"filmhub/pkg/recommend"
+	"filmhub/pkg/server"
+	"filmhub/pkg/signedtoken"
+	"filmhub/pkg/validation"
+	"filmhub/pkg/webhook"
+	"filmhub/pkg/worker"
 
 	"filmhub/internal/handler"
 	"filmhub/internal/repository"
 	"filmhub/internal/service"
 )
 
+// @title FilmHub API
+// @version 1.0
+// @description REST API платформы FilmHub: каталог фильмов, отзывы и рейтинги.
+// @BasePath /
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 func main() {
 	// Load configuration
 	cfg, err := config.Load()
@@ -34,8 +56,23 @@ func main() {
 	log := logger.New(cfg.AppEnv, cfg.SentryDSN)
 	defer logger.Sync(log)
 
-	// Init JWT secret
-	jwt.Init(cfg.JWTSecret)
+	// JWT signing keys; scheduled rotation runs until shutdown.
+	keys, err := jwt.NewKeyring(jwt.KeyringConfig{
+		Algorithm:   cfg.JWTAlgorithm,
+		Dir:         cfg.JWTKeysDir,
+		RotateEvery: cfg.JWTRotateEvery,
+		Retain:      cfg.JWTKeyRetain,
+	})
+	if err != nil {
+		log.Fatalf("JWT keyring setup error: %v", err)
+	}
+	if cfg.JWTKeysDir == "" {
+		log.Warn("JWT_KEYS_DIR is not set: signing keys live in memory and sessions end on restart")
+	}
+	jwt.Init(keys, jwt.Config{Issuer: cfg.JWTIssuer, Audience: cfg.JWTAudience, TTL: cfg.JWTTTL})
+	rotateCtx, stopRotation := context.WithCancel(context.Background())
+	defer stopRotation()
+	go keys.Run(rotateCtx, func(err error) { log.Errorf("JWT key rotation: %v", err) })
 
 	// Initialize database
 	pool, err := database.NewPostgresPool(cfg)
@@ -68,59 +105,366 @@ func main() {
 	filmRepo := repository.NewFilmRepository(pool)
 	userRepo := repository.NewUserRepository(pool)
 
+	userTokenRepo := repository.NewUserTokenRepository(pool)
+	apiKeyRepo := repository.NewAPIKeyRepository(pool)
+
+	// Email delivery and signed tokens for verification / password reset
+	mail, err := mailer.New(cfg, log)
+	if err != nil {
+		log.Fatalf("mailer setup error: %v", err)
+	}
+	signer, err := signedtoken.New(cfg.TokenSecret)
+	if err != nil {
+		log.Fatalf("token signer setup error: %v", err)
+	}
+
+	// Film reads go through an in-process cache unless it is disabled.
+	var films service.FilmRepo = filmRepo
+	var filmCache *service.CachedFilmRepo
+	if cfg.FilmCacheSize > 0 {
+		filmCache = service.NewCachedFilmRepo(filmRepo, cache.NewLRU(cfg.FilmCacheSize, cfg.FilmCacheTTL))
+		films = filmCache
+	}
+
+	// Services run multi-repository writes as one unit of work.
+	txManager := database.NewTxManager(pool, cfg.DBTxAttempts)
+
+	// Domain events are stored in the outbox with the change they describe
+	// and delivered to the configured sinks in the background.
+	outboxRepo := repository.NewOutboxRepository(pool)
+	sinks, err := outbox.NewSinks(cfg, log, pool)
+	if err != nil {
+		log.Fatalf("outbox setup error: %v", err)
+	}
+	// Admin-managed webhook subscriptions get their events through the
+	// outbox; the sender signs and retries the queued deliveries.
+	webhookRepo := repository.NewWebhookRepository(pool)
+	webhookService := service.NewWebhookService(webhookRepo).WithTransactions(txManager)
+	sinks = append(sinks, webhookService)
+	sender := webhook.NewSender(webhookRepo, webhook.Options{
+		MaxAttempts: cfg.WebhookMaxAttempts,
+		BaseBackoff: cfg.WebhookBackoff,
+		Client:      webhook.NewClient(cfg.WebhookTimeout),
+		OnError:     func(err error) { log.Warnf("webhooks: %v", err) },
+	})
+	sender.Start()
+	dispatcher := outbox.NewDispatcher(outboxRepo, sinks, outbox.Options{
+		PollInterval: cfg.OutboxPollInterval,
+		MaxAttempts:  cfg.OutboxMaxAttempts,
+		OnError:      func(err error) { log.Errorf("outbox: %v", err) },
+	})
+	dispatcher.Start()
+
+	// Review and film changes are pushed to SSE clients through the hub.
+	liveHub := pubsub.New(cfg.SSEHistory, 64)
+
+	// Replies, helpful votes and release dates of watched films notify
+	// users in the app.
+	notificationService := service.NewNotificationService(repository.NewNotificationRepository(pool))
+	watchlistService := service.NewWatchlistService(repository.NewWatchlistRepository(pool), films)
+
 	// Initialize services
-	filmService := service.NewFilmService(filmRepo)
-	authService := service.NewAuthService(userRepo)
+	filmService := service.NewFilmService(films).WithTransactions(txManager).WithEvents(outboxRepo).WithLiveUpdates(liveHub).
+		WithNotifications(notificationService)
+	authService := service.NewAuthService(userRepo).WithTransactions(txManager).WithEvents(outboxRepo)
+	accountService := service.NewAccountService(userRepo, userTokenRepo, signer, mail, cfg.AppBaseURL).WithTransactions(txManager)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, userRepo)
+
+	// Social login is optional; a provider that cannot be discovered at
+	// startup only disables it.
+	var oidcHandler *handler.OIDCHandler
+	if cfg.OIDCIssuer != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		provider, err := oidc.Discover(ctx, oidc.Config{
+			Issuer:       cfg.OIDCIssuer,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+			Scopes:       cfg.OIDCScopes,
+		}, nil)
+		cancel()
+		if err != nil {
+			log.Warnf("OIDC login disabled: %v", err)
+		} else {
+			identityRepo := repository.NewIdentityRepository(pool)
+			socialService := service.NewSocialAuthService(cfg.OIDCProviderName, provider, userRepo, identityRepo, signer).
+				WithTransactions(txManager).WithEvents(outboxRepo)
+			oidcHandler = handler.NewOIDCHandler(socialService)
+		}
+	}
+
+	// Every write operation is recorded in the append-only audit log.
+	auditService := service.NewAuditService(repository.NewAuditRepository(pool))
+	filmService.WithAudit(auditService)
+	adminService := service.NewAdminService(userRepo).WithAudit(auditService).WithTransactions(txManager)
+	trashService := service.NewTrashService(repository.NewTrashRepository(pool), cfg.TrashRetention).WithAudit(auditService).WithTransactions(txManager)
+	if cfg.TrashPurgeEvery > 0 {
+		purgeCtx, stopPurge := context.WithCancel(context.Background())
+		defer stopPurge()
+		purge := func(ctx context.Context) error {
+			_, _, err := trashService.Purge(ctx)
+			return err
+		}
+		go worker.Every(purgeCtx, cfg.TrashPurgeEvery, purge, func(err error) { log.Errorf("trash purge: %v", err) })
+	}
+
+	// Recommendations are served from film similarities recomputed in the
+	// background.
+	recommendationService := service.NewRecommendationService(repository.NewRecommendationRepository(pool),
+		recommend.Options{MinSupport: cfg.RecommendMinSupport})
+	if cfg.RecommendRefreshEvery > 0 {
+		recommendCtx, stopRecommend := context.WithCancel(context.Background())
+		defer stopRecommend()
+		go worker.Every(recommendCtx, cfg.RecommendRefreshEvery, recommendationService.Refresh, func(err error) { log.Errorf("recommendations refresh: %v", err) })
+	}
+
+	// Charts are served from a table recomputed in the background.
+	chartService := service.NewChartService(repository.NewChartRepository(pool), service.ChartOptions{
+		Size: cfg.ChartsSize, TrendingWindow: cfg.ChartsTrendingWindow, TrendingHalfLife: cfg.ChartsTrendingHalfLife,
+		TopRatedMinReviews: cfg.ChartsTopRatedMinReviews, NewReleasesWindow: cfg.ChartsNewReleasesWindow,
+	})
+	if cfg.ChartsRefreshEvery > 0 {
+		chartsCtx, stopCharts := context.WithCancel(context.Background())
+		defer stopCharts()
+		go worker.Every(chartsCtx, cfg.ChartsRefreshEvery, chartService.Refresh, func(err error) { log.Errorf("charts refresh: %v", err) })
+	}
+
+	// Similar films, and the catalog they are computed from, are cached
+	// unless the cache is disabled.
+	var similarCache cache.Cache
+	if cfg.SimilarCacheSize > 0 {
+		similarCache = cache.NewLRU(cfg.SimilarCacheSize, cfg.SimilarCacheTTL)
+	}
+	similarService := service.NewSimilarService(repository.NewSimilarRepository(pool), films, service.SimilarWeights{
+		Genres: cfg.SimilarWeightGenres, Tags: cfg.SimilarWeightTags, People: cfg.SimilarWeightPeople,
+		Ratings: cfg.SimilarWeightRatings, Text: cfg.SimilarWeightText,
+	}, similarCache)
+
+	listService := service.NewListService(repository.NewListRepository(pool), films, userRepo).WithAudit(auditService).WithTransactions(txManager)
 
 	reviewRepo := repository.NewReviewRepository(pool)
-	reviewService := service.NewReviewService(reviewRepo)
+	reviewService := service.NewReviewService(reviewRepo).WithAudit(auditService).WithTransactions(txManager).WithEvents(outboxRepo).WithLiveUpdates(liveHub).
+		WithNotifications(notificationService)
+	if filmCache != nil {
+		reviewService.WithFilmCache(filmCache)
+		trashService.WithFilmCache(filmCache)
+	}
+	if cfg.RequireVerifiedEmail {
+		reviewService.RequireVerifiedEmail(userRepo)
+	}
+	if cfg.ContentFilterEnabled {
+		filter, err := contentfilter.New(cfg)
+		if err != nil {
+			log.Fatalf("content filter setup error: %v", err)
+		}
+		reviewService.WithContentFilter(filter)
+	}
+	commentService := service.NewCommentService(repository.NewCommentRepository(pool), reviewRepo).WithAudit(auditService).
+		WithTransactions(txManager).WithNotifications(notificationService)
+	moderationService := service.NewModerationService(repository.NewModerationRepository(pool)).WithAudit(auditService).WithTransactions(txManager).
+		WithReviews(reviewRepo).WithEvents(outboxRepo).WithLiveUpdates(liveHub)
+	trashService.WithReviews(reviewRepo).WithLiveUpdates(liveHub)
+	if filmCache != nil {
+		moderationService.WithFilmCache(filmCache)
+	}
 
 	// Initialize handlers
 	filmHandler := handler.NewFilmHandler(filmService)
-	authHandler := handler.NewAuthHandler(authService)
+	authHandler := handler.NewAuthHandler(authService, accountService)
+	accountHandler := handler.NewAccountHandler(accountService)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
 	reviewHandler := handler.NewReviewHandler(reviewService)
+	commentHandler := handler.NewCommentHandler(commentService)
+	moderationHandler := handler.NewModerationHandler(moderationService)
+	adminHandler := handler.NewAdminHandler(adminService, auditService)
+	trashHandler := handler.NewTrashHandler(trashService)
+	eventsHandler := handler.NewEventsHandler(liveHub, filmService, cfg.SSEHeartbeat)
+	webhookHandler := handler.NewWebhookHandler(webhookService.WithAudit(auditService))
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	watchlistHandler := handler.NewWatchlistHandler(watchlistService)
+	recommendationHandler := handler.NewRecommendationHandler(recommendationService)
+	similarHandler := handler.NewSimilarHandler(similarService)
+	chartHandler := handler.NewChartHandler(chartService)
+	listHandler := handler.NewListHandler(listService)
 
 	// Setup router (Gin in release mode for prod.)
 	if cfg.AppEnv == "prod" {
 		gin.SetMode(gin.ReleaseMode)
 	}
+	// Route every gin binding through the project validator.
+	binding.Validator = validation.New()
 	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("trusted proxies: %v", err)
+	}
 	router.Use(gin.Recovery())
+	router.Use(middleware.RequestID())
+	router.Use(middleware.SecurityHeaders(middleware.SecurityConfig{
+		HSTS:                  cfg.TLSEnabled(),
+		ContentSecurityPolicy: cfg.ContentSecurityPolicy,
+	}))
+	router.Use(middleware.CORS(middleware.CORSConfig{
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowCredentials: cfg.CORSAllowCredentials,
+		// Pages read the ETag to send it back in If-Match on updates.
+		ExposedHeaders: []string{middleware.RequestIDHeader, "ETag"},
+	}))
+	router.Use(middleware.BodyLimit(cfg.MaxBodyBytes))
+
+	handler.RegisterSwagger(router)
+	router.GET("/.well-known/jwks.json", handler.NewJWKSHandler(keys).JWKS)
 
 	// Public routes
 	router.POST("/register", authHandler.Register)
 	router.POST("/login", authHandler.Login)
+	router.POST("/auth/verify-email/confirm", accountHandler.ConfirmEmail)
+	router.POST("/auth/password-reset", accountHandler.RequestPasswordReset)
+	router.POST("/auth/password-reset/confirm", accountHandler.ConfirmPasswordReset)
 	router.GET("/films", filmHandler.SearchFilms)
 	router.GET("/films/:id", filmHandler.GetFilm)
+	router.GET("/films/:id/revisions", filmHandler.ListRevisions)
+	router.GET("/films/:id/revisions/diff", filmHandler.DiffRevisions)
+	router.GET("/films/:id/events", eventsHandler.FilmEvents)
+	router.GET("/films/:id/similar", similarHandler.ListSimilar)
+	router.GET("/charts/:chart", chartHandler.GetChart)
+	// Listings are public; a signed-in author also sees their hidden content.
+	optionalAuth := jwt.AuthMiddleware(jwt.WithAPIKeys(apiKeyService), jwt.Optional())
+	router.GET("/films/:id/reviews", optionalAuth, reviewHandler.ListReviews)
+	router.GET("/reviews/:id", optionalAuth, reviewHandler.GetReview)
+	router.GET("/reviews/:id/comments", optionalAuth, commentHandler.ListComments)
+	router.GET("/lists", optionalAuth, listHandler.DiscoverLists)
+	router.GET("/lists/:id", optionalAuth, listHandler.GetList)
+	if oidcHandler != nil {
+		router.GET("/auth/oidc/login", oidcHandler.Login)
+		router.GET("/auth/oidc/callback", oidcHandler.Callback)
+	}
 
-	// Protected routes (require JWT)
+	// Protected routes (require a JWT or an API key)
 	auth := router.Group("/")
-	auth.Use(jwt.AuthMiddleware())
+	auth.Use(jwt.AuthMiddleware(jwt.WithAPIKeys(apiKeyService)), audit.Middleware())
 	{
+		auth.POST("/auth/verify-email", accountHandler.RequestEmailVerification)
 		auth.POST("/films", filmHandler.CreateFilm)
+		auth.POST("/films/import", filmHandler.ImportFilms)
+		auth.PUT("/films/:id", filmHandler.UpdateFilm)
+		auth.DELETE("/films/:id", filmHandler.DeleteFilm)
+		auth.POST("/films/:id/revisions/:revision/rollback", filmHandler.Rollback)
 		auth.POST("/films/:id/reviews", reviewHandler.CreateReview)
-		auth.GET("/films/:id/reviews", reviewHandler.ListReviews)
+		auth.POST("/reviews/:id/vote", reviewHandler.VoteReview)
+		auth.PUT("/reviews/:id", reviewHandler.UpdateReview)
+		auth.DELETE("/reviews/:id", reviewHandler.DeleteReview)
+		auth.POST("/reviews/:id/comments", commentHandler.CreateComment)
+		auth.PATCH("/comments/:id", commentHandler.UpdateComment)
+		auth.DELETE("/comments/:id", commentHandler.DeleteComment)
+		auth.POST("/reviews/:id/report", moderationHandler.ReportReview)
+		auth.POST("/comments/:id/report", moderationHandler.ReportComment)
+		auth.GET("/moderation/reports", moderationHandler.ListReports)
+		auth.POST("/moderation/reports/:id/hide", moderationHandler.HideReported)
+		auth.POST("/moderation/reports/:id/restore", moderationHandler.RestoreReported)
+		auth.POST("/moderation/reports/:id/dismiss", moderationHandler.DismissReport)
+		auth.GET("/moderation/log", moderationHandler.ModerationLog)
+		auth.GET("/events", eventsHandler.AllEvents)
+		auth.GET("/admin/audit", adminHandler.ListAudit)
+		auth.PUT("/admin/users/:id/role", adminHandler.SetRole)
+		auth.GET("/admin/trash", trashHandler.ListTrash)
+		auth.POST("/admin/trash/:type/:id/restore", trashHandler.RestoreTrash)
+		auth.POST("/admin/webhooks", webhookHandler.CreateWebhook)
+		auth.GET("/admin/webhooks", webhookHandler.ListWebhooks)
+		auth.DELETE("/admin/webhooks/:id", webhookHandler.DeleteWebhook)
+		auth.GET("/admin/webhooks/:id/deliveries", webhookHandler.ListWebhookDeliveries)
+		auth.POST("/admin/webhooks/:id/deliveries/:delivery/redeliver", webhookHandler.RedeliverWebhook)
+		if filmCache != nil {
+			auth.GET("/admin/cache/films", handler.NewCacheHandler(filmCache).FilmCacheStats)
+		}
+		auth.POST("/me/api-keys", apiKeyHandler.Create)
+		auth.GET("/me/api-keys", apiKeyHandler.List)
+		auth.DELETE("/me/api-keys/:id", apiKeyHandler.Revoke)
+		auth.GET("/me/notifications", notificationHandler.ListNotifications)
+		auth.POST("/me/notifications/read-all", notificationHandler.MarkAllNotificationsRead)
+		auth.POST("/me/notifications/:id/read", notificationHandler.MarkNotificationRead)
+		auth.GET("/me/notification-preferences", notificationHandler.GetNotificationPreferences)
+		auth.PUT("/me/notification-preferences", notificationHandler.UpdateNotificationPreferences)
+		auth.GET("/me/recommendations", recommendationHandler.ListRecommendations)
+		auth.GET("/me/watchlist", watchlistHandler.ListWatchlist)
+		auth.PUT("/me/watchlist/:film_id", watchlistHandler.AddToWatchlist)
+		auth.DELETE("/me/watchlist/:film_id", watchlistHandler.RemoveFromWatchlist)
+		auth.GET("/me/lists", listHandler.ListMyLists)
+		auth.POST("/lists", listHandler.CreateList)
+		auth.PUT("/lists/:id", listHandler.UpdateList)
+		auth.DELETE("/lists/:id", listHandler.DeleteList)
+		auth.POST("/lists/:id/entries", listHandler.AddListEntry)
+		auth.PATCH("/lists/:id/entries/:film_id", listHandler.UpdateListEntry)
+		auth.DELETE("/lists/:id/entries/:film_id", listHandler.RemoveListEntry)
+		auth.PUT("/lists/:id/order", listHandler.ReorderList)
+		auth.PUT("/lists/:id/collaborators/:user_id", listHandler.AddListCollaborator)
+		auth.DELETE("/lists/:id/collaborators/:user_id", listHandler.RemoveListCollaborator)
+		auth.PUT("/lists/:id/like", listHandler.LikeList)
+		auth.DELETE("/lists/:id/like", listHandler.UnlikeList)
+		if oidcHandler != nil {
+			// A key able to link a sign-in method could sign in through it and
+			// get a session beyond its scope.
+			identities := auth.Group("/me/identities", jwt.SessionOnly())
+			identities.POST("/link", oidcHandler.Link)
+			identities.GET("", oidcHandler.ListIdentities)
+			identities.DELETE("/:provider", oidcHandler.Unlink)
+		}
 	}
 
 	// Start server
 	srv := &http.Server{
-		Addr:           ":8080",
+		Addr:           cfg.HTTPAddr,
 		Handler:        router,
 		ReadTimeout:    10 * time.Second,
 		WriteTimeout:   10 * time.Second,
 		MaxHeaderBytes: 1 << 20,
 	}
+	// End SSE streams on shutdown, which otherwise waits for them.
+	srv.RegisterOnShutdown(liveHub.Close)
+
+	var certs *server.CertReloader
+	if cfg.TLSEnabled() {
+		certs, err = server.NewCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			log.Fatalf("TLS setup error: %v", err)
+		}
+		srv.TLSConfig = certs.TLSConfig()
+	}
 
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if certs != nil {
+			log.Infof("Listening on %s (TLS)", cfg.HTTPAddr)
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			log.Infof("Listening on %s", cfg.HTTPAddr)
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Server error: %v", err)
 		}
 	}()
 
-	// Graceful shutdown
+	// Graceful shutdown; SIGHUP reloads the TLS certificate.
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+wait:
+	for {
+		select {
+		case <-hup:
+			if certs == nil {
+				continue
+			}
+			if err := certs.Reload(); err != nil {
+				log.Errorf("TLS certificate reload failed: %v", err)
+			} else {
+				log.Info("TLS certificate reloaded")
+			}
+		case <-quit:
+			break wait
+		}
+	}
 	log.Info("Shutting down server...")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -129,6 +473,13 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
+	// Deliver the batch in flight; the rest waits in the outbox.
+	if err := dispatcher.Stop(ctx); err != nil {
+		log.Errorf("outbox dispatcher stop: %v", err)
+	}
+	if err := sender.Stop(ctx); err != nil {
+		log.Errorf("webhook sender stop: %v", err)
+	}
 
 	log.Info("Server exited")
 }
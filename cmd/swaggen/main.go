@@ -0,0 +1,504 @@
+// Command swaggen builds the OpenAPI 3 spec from the swag-style annotations
+// on handler functions (@Summary, @Param, @Success, @Router, ...) and from the
+// Go structs they reference. It understands the subset of swag syntax used in
+// this repository, so the spec is regenerated instead of being edited by hand.
+//
+// Usage (from the repository root):
+//
+//	go run ./cmd/swaggen -o swagger/swagger.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	root := flag.String("root", ".", "repository root")
+	dirs := flag.String("dirs", "cmd,internal/handler,internal/models,pkg/jwk", "comma-separated package directories to scan")
+	out := flag.String("o", "swagger/swagger.json", "output file, relative to root")
+	flag.Parse()
+
+	g := newGenerator()
+	for _, dir := range strings.Split(*dirs, ",") {
+		if err := g.parseDir(filepath.Join(*root, dir)); err != nil {
+			fail(err)
+		}
+	}
+	spec, err := g.build()
+	if err != nil {
+		fail(err)
+	}
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		fail(err)
+	}
+	if err := os.WriteFile(filepath.Join(*root, *out), append(data, '\n'), 0o644); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "swaggen:", err)
+	os.Exit(1)
+}
+
+type typeDecl struct {
+	pkg  string
+	spec *ast.StructType
+}
+
+type operation struct {
+	pkg   string
+	lines []string
+}
+
+type generator struct {
+	fset    *token.FileSet
+	types   map[string]typeDecl // "pkg.Name" -> struct
+	named   map[string]ast.Expr // "pkg.Name" -> underlying type of non-struct types
+	ops     []operation
+	general []string
+
+	schemas map[string]any
+}
+
+func newGenerator() *generator {
+	return &generator{
+		fset:    token.NewFileSet(),
+		types:   make(map[string]typeDecl),
+		named:   make(map[string]ast.Expr),
+		schemas: make(map[string]any),
+	}
+}
+
+func (g *generator) parseDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(g.fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		g.collect(f)
+	}
+	return nil
+}
+
+func (g *generator) collect(f *ast.File) {
+	pkg := f.Name.Name
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				if st, ok := ts.Type.(*ast.StructType); ok {
+					g.types[pkg+"."+ts.Name.Name] = typeDecl{pkg: pkg, spec: st}
+				} else {
+					g.named[pkg+"."+ts.Name.Name] = ts.Type
+				}
+			}
+		case *ast.FuncDecl:
+			if d.Doc == nil {
+				continue
+			}
+			lines := annotations(d.Doc)
+			if d.Name.Name == "main" && pkg == "main" {
+				g.general = lines
+				continue
+			}
+			for _, l := range lines {
+				if strings.HasPrefix(l, "@Router") {
+					g.ops = append(g.ops, operation{pkg: pkg, lines: lines})
+					break
+				}
+			}
+		}
+	}
+}
+
+func annotations(doc *ast.CommentGroup) []string {
+	var out []string
+	for _, c := range doc.List {
+		l := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+		if strings.HasPrefix(l, "@") {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// splitAnnotation returns the "@Key" and the remainder of the line.
+func splitAnnotation(l string) (string, string) {
+	key, rest, _ := strings.Cut(l, " ")
+	return key, strings.TrimSpace(rest)
+}
+
+var (
+	routeRe    = regexp.MustCompile(`^(\S+)\s+\[(\w+)]$`)
+	paramRe    = regexp.MustCompile(`^(\S+)\s+(\w+)\s+(\S+)\s+(true|false)\s*(?:"(.*)")?$`)
+	responseRe = regexp.MustCompile(`^(\d{3})\s+(?:\{(\w+)}\s+(\S+)\s*)?(?:"(.*)")?`)
+	pathVarRe  = regexp.MustCompile(`\{(\w+)}`)
+)
+
+func (g *generator) build() (map[string]any, error) {
+	spec := map[string]any{"openapi": "3.0.3"}
+	info := map[string]any{}
+	securitySchemes := map[string]any{}
+	var pendingScheme map[string]any
+	for _, l := range g.general {
+		key, rest := splitAnnotation(l)
+		switch key {
+		case "@title":
+			info["title"] = rest
+		case "@version":
+			info["version"] = rest
+		case "@description":
+			info["description"] = rest
+		case "@BasePath":
+			spec["servers"] = []any{map[string]any{"url": rest}}
+		case "@securityDefinitions.apikey":
+			pendingScheme = map[string]any{"type": "apiKey"}
+			securitySchemes[rest] = pendingScheme
+		case "@in", "@name":
+			if pendingScheme != nil {
+				pendingScheme[strings.TrimPrefix(key, "@")] = rest
+			}
+		}
+	}
+	spec["info"] = info
+
+	paths := map[string]map[string]any{}
+	for _, op := range g.ops {
+		path, method, item, err := g.operation(op)
+		if err != nil {
+			return nil, err
+		}
+		if paths[path] == nil {
+			paths[path] = map[string]any{}
+		}
+		if _, dup := paths[path][method]; dup {
+			return nil, fmt.Errorf("duplicate route %s %s", strings.ToUpper(method), path)
+		}
+		paths[path][method] = item
+	}
+	spec["paths"] = paths
+	components := map[string]any{"schemas": g.schemas}
+	if len(securitySchemes) > 0 {
+		components["securitySchemes"] = securitySchemes
+	}
+	spec["components"] = components
+	return spec, nil
+}
+
+func (g *generator) operation(op operation) (string, string, map[string]any, error) {
+	item := map[string]any{}
+	responses := map[string]any{}
+	var params []any
+	var path, method string
+	for _, l := range op.lines {
+		key, rest := splitAnnotation(l)
+		switch key {
+		case "@Summary":
+			item["summary"] = rest
+		case "@Description":
+			item["description"] = rest
+		case "@Tags":
+			item["tags"] = strings.Split(rest, ",")
+		case "@Security":
+			item["security"] = []any{map[string]any{rest: []string{}}}
+		case "@Router":
+			m := routeRe.FindStringSubmatch(rest)
+			if m == nil {
+				return "", "", nil, fmt.Errorf("malformed @Router %q", rest)
+			}
+			path, method = m[1], strings.ToLower(m[2])
+		case "@Param":
+			m := paramRe.FindStringSubmatch(rest)
+			if m == nil {
+				return "", "", nil, fmt.Errorf("malformed @Param %q", rest)
+			}
+			schema, err := g.schemaFor(op.pkg, m[3])
+			if err != nil {
+				return "", "", nil, err
+			}
+			if m[2] == "body" {
+				item["requestBody"] = map[string]any{
+					"required":    m[4] == "true",
+					"description": m[5],
+					"content":     map[string]any{"application/json": map[string]any{"schema": schema}},
+				}
+				continue
+			}
+			params = append(params, map[string]any{
+				"name":        m[1],
+				"in":          m[2],
+				"required":    m[4] == "true" || m[2] == "path",
+				"description": m[5],
+				"schema":      schema,
+			})
+		case "@Success", "@Failure":
+			m := responseRe.FindStringSubmatch(rest)
+			if m == nil {
+				return "", "", nil, fmt.Errorf("malformed %s %q", key, rest)
+			}
+			resp := map[string]any{"description": m[4]}
+			if m[4] == "" {
+				resp["description"] = defaultDescription(m[1])
+			}
+			if m[3] != "" {
+				schema, err := g.schemaFor(op.pkg, m[3])
+				if err != nil {
+					return "", "", nil, err
+				}
+				if m[2] == "array" {
+					schema = map[string]any{"type": "array", "items": schema}
+				}
+				resp["content"] = map[string]any{"application/json": map[string]any{"schema": schema}}
+			}
+			responses[m[1]] = resp
+		}
+	}
+	if path == "" {
+		return "", "", nil, fmt.Errorf("operation without @Router: %v", op.lines)
+	}
+	for _, v := range pathVarRe.FindAllStringSubmatch(path, -1) {
+		if !hasParam(params, v[1]) {
+			return "", "", nil, fmt.Errorf("%s %s: path parameter %q is not documented", method, path, v[1])
+		}
+	}
+	if len(params) > 0 {
+		item["parameters"] = params
+	}
+	item["responses"] = responses
+	return path, method, item, nil
+}
+
+func hasParam(params []any, name string) bool {
+	for _, p := range params {
+		if p.(map[string]any)["name"] == name {
+			return true
+		}
+	}
+	return false
+}
+
+func defaultDescription(code string) string {
+	n, _ := strconv.Atoi(code)
+	if n >= 200 && n < 300 {
+		return "Success"
+	}
+	return "Error"
+}
+
+// schemaFor resolves a swag type expression such as "models.Film",
+// "map[string]interface{}" or "int" into a schema.
+func (g *generator) schemaFor(pkg, typ string) (map[string]any, error) {
+	expr, err := parser.ParseExpr(typ)
+	if err != nil {
+		return nil, fmt.Errorf("parse type %q: %w", typ, err)
+	}
+	return g.exprSchema(pkg, expr)
+}
+
+func (g *generator) exprSchema(pkg string, expr ast.Expr) (map[string]any, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if s, ok := primitive(t.Name); ok {
+			return s, nil
+		}
+		if underlying, ok := g.named[pkg+"."+t.Name]; ok {
+			return g.exprSchema(pkg, underlying)
+		}
+		return g.ref(pkg + "." + t.Name)
+	case *ast.SelectorExpr:
+		name := t.X.(*ast.Ident).Name + "." + t.Sel.Name
+		if name == "time.Time" {
+			return map[string]any{"type": "string", "format": "date-time"}, nil
+		}
+		// Named non-struct types such as models.UserRole are inlined.
+		if underlying, ok := g.named[name]; ok {
+			return g.exprSchema(t.X.(*ast.Ident).Name, underlying)
+		}
+		return g.ref(name)
+	case *ast.StarExpr:
+		s, err := g.exprSchema(pkg, t.X)
+		if err != nil {
+			return nil, err
+		}
+		if _, isRef := s["$ref"]; !isRef {
+			s["nullable"] = true
+		}
+		return s, nil
+	case *ast.ArrayType:
+		items, err := g.exprSchema(pkg, t.Elt)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": items}, nil
+	case *ast.MapType:
+		values, err := g.exprSchema(pkg, t.Value)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "object", "additionalProperties": values}, nil
+	case *ast.InterfaceType:
+		return map[string]any{}, nil
+	case *ast.StructType:
+		return g.structSchema(pkg, t)
+	}
+	return nil, fmt.Errorf("unsupported type expression %T", expr)
+}
+
+func primitive(name string) (map[string]any, bool) {
+	switch name {
+	case "string":
+		return map[string]any{"type": "string"}, true
+	case "bool":
+		return map[string]any{"type": "boolean"}, true
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return map[string]any{"type": "integer"}, true
+	case "float32", "float64":
+		return map[string]any{"type": "number"}, true
+	case "any":
+		return map[string]any{}, true
+	}
+	return nil, false
+}
+
+func (g *generator) ref(name string) (map[string]any, error) {
+	ref := map[string]any{"$ref": "#/components/schemas/" + name}
+	if _, done := g.schemas[name]; done {
+		return ref, nil
+	}
+	decl, ok := g.types[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %s", name)
+	}
+	g.schemas[name] = nil // placeholder guards against recursive types
+	schema, err := g.structSchema(decl.pkg, decl.spec)
+	if err != nil {
+		return nil, err
+	}
+	g.schemas[name] = schema
+	return ref, nil
+}
+
+func (g *generator) structSchema(pkg string, st *ast.StructType) (map[string]any, error) {
+	props := map[string]any{}
+	var required []string
+	for _, field := range st.Fields.List {
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			raw, _ := strconv.Unquote(field.Tag.Value)
+			tag = reflect.StructTag(raw)
+		}
+		name, _, _ := strings.Cut(tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if len(field.Names) == 0 {
+			// Embedded struct: inline its properties.
+			embedded, err := g.exprSchema(pkg, field.Type)
+			if err != nil {
+				return nil, err
+			}
+			if r, ok := embedded["$ref"].(string); ok {
+				embedded = g.schemas[strings.TrimPrefix(r, "#/components/schemas/")].(map[string]any)
+			}
+			for k, v := range embedded["properties"].(map[string]any) {
+				props[k] = v
+			}
+			if req, ok := embedded["required"].([]string); ok {
+				required = append(required, req...)
+			}
+			continue
+		}
+		if !field.Names[0].IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Names[0].Name
+		}
+		schema, err := g.exprSchema(pkg, field.Type)
+		if err != nil {
+			return nil, err
+		}
+		if _, isRef := schema["$ref"]; !isRef {
+			if ex := tag.Get("example"); ex != "" {
+				schema["example"] = example(schema, ex)
+			}
+			if d := tag.Get("description"); d != "" {
+				schema["description"] = d
+			}
+		}
+		if isRequired(tag) {
+			required = append(required, name)
+		}
+		props[name] = schema
+	}
+	out := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		sort.Strings(required)
+		out["required"] = required
+	}
+	return out, nil
+}
+
+func isRequired(tag reflect.StructTag) bool {
+	for _, key := range []string{"validate", "binding"} {
+		for _, rule := range strings.Split(tag.Get(key), ",") {
+			if rule == "required" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// example converts a struct tag example to the schema's type. Array
+// examples are comma-separated, as in swag.
+func example(schema map[string]any, raw string) any {
+	switch schema["type"] {
+	case "array":
+		items, _ := schema["items"].(map[string]any)
+		var out []any
+		for _, item := range strings.Split(raw, ",") {
+			out = append(out, example(items, strings.TrimSpace(item)))
+		}
+		return out
+	case "integer":
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(raw, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+	return raw
+}
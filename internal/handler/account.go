@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/internal/service"
+)
+
+type AccountHandler struct {
+	service *service.AccountService
+}
+
+func NewAccountHandler(service *service.AccountService) *AccountHandler {
+	return &AccountHandler{service: service}
+}
+
+type tokenRequest struct {
+	Token string `json:"token" validate:"required" example:"eyJwIjoidmVyaWZ5X2VtYWlsIn0.c2lnbmF0dXJl" description:"Токен из письма"`
+}
+
+type passwordResetRequest struct {
+	Email string `json:"email" validate:"required,email" example:"john@example.com" description:"Email пользователя"`
+}
+
+type passwordResetConfirmRequest struct {
+	Token    string `json:"token" validate:"required" example:"eyJwIjoicmVzZXRfcGFzc3dvcmQifQ.c2lnbmF0dXJl" description:"Токен из письма"`
+	Password string `json:"password" validate:"required,min=6,max=72" example:"newpassword123" description:"Новый пароль (минимум 6 символов)"`
+}
+
+// @Summary Повторная отправка письма для подтверждения email
+// @Description Отправляет текущему пользователю ссылку для подтверждения email
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 202 "Письмо отправлено"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /auth/verify-email [post]
+func (h *AccountHandler) RequestEmailVerification(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.SendEmailVerification(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// @Summary Подтверждение email
+// @Description Подтверждает email по одноразовому токену из письма
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body tokenRequest true "Токен"
+// @Success 204 "Email подтверждён"
+// @Failure 400 {object} errorResponse "Недействительный или просроченный токен"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /auth/verify-email/confirm [post]
+func (h *AccountHandler) ConfirmEmail(c *gin.Context) {
+	var req tokenRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if err := h.service.ConfirmEmail(c.Request.Context(), req.Token); err != nil {
+		respondTokenError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Запрос на сброс пароля
+// @Description Отправляет ссылку для сброса пароля, если пользователь с таким email существует
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body passwordResetRequest true "Email"
+// @Success 202 "Запрос принят"
+// @Failure 400 {object} errorResponse "Ошибка валидации"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /auth/password-reset [post]
+func (h *AccountHandler) RequestPasswordReset(c *gin.Context) {
+	var req passwordResetRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if err := h.service.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// @Summary Установка нового пароля
+// @Description Меняет пароль по одноразовому токену из письма
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body passwordResetConfirmRequest true "Токен и новый пароль"
+// @Success 204 "Пароль изменён"
+// @Failure 400 {object} errorResponse "Ошибка валидации или недействительный токен"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /auth/password-reset/confirm [post]
+func (h *AccountHandler) ConfirmPasswordReset(c *gin.Context) {
+	var req passwordResetConfirmRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if err := h.service.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		respondTokenError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func respondTokenError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrInvalidToken) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
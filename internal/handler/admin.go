@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/internal/models"
+	"filmhub/internal/service"
+)
+
+type AdminHandler struct {
+	admin *service.AdminService
+	audit *service.AuditService
+}
+
+func NewAdminHandler(admin *service.AdminService, audit *service.AuditService) *AdminHandler {
+	return &AdminHandler{admin: admin, audit: audit}
+}
+
+// ListAudit godoc
+// @Summary Журнал аудита
+// @Description Изменения фильмов, отзывов, комментариев, ролей и решения модераторов. Доступно администраторам
+// @Tags admin
+// @Produce json
+// @Param actor_id query int false "ID пользователя, выполнившего действие"
+// @Param entity_type query string false "Тип сущности: film, review, comment, report, user, webhook"
+// @Param entity_id query int false "ID сущности"
+// @Param action query string false "Действие, например update"
+// @Param from query string false "Начало периода (RFC 3339, включительно)"
+// @Param to query string false "Конец периода (RFC 3339, не включительно)"
+// @Param page query int false "Номер страницы (с 1)"
+// @Param limit query int false "Размер страницы (1–100, по умолчанию 20)"
+// @Security BearerAuth
+// @Success 200 {object} models.AuditPage
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 500 {object} errorResponse
+// @Router /admin/audit [get]
+func (h *AdminHandler) ListAudit(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	filter := models.AuditFilter{
+		EntityType: c.Query("entity_type"),
+		Action:     c.Query("action"),
+	}
+	var err error
+	if filter.ActorID, err = queryInt(c, "actor_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid actor_id"})
+		return
+	}
+	if filter.EntityID, err = queryInt(c, "entity_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entity_id"})
+		return
+	}
+	if filter.From, err = queryTime(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from: expected RFC 3339 time"})
+		return
+	}
+	if filter.To, err = queryTime(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to: expected RFC 3339 time"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	entries, err := h.audit.List(c.Request.Context(), filter, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// SetRole godoc
+// @Summary Изменить роль пользователя
+// @Description Доступно администраторам; свою роль изменить нельзя
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param request body models.RoleRequest true "Новая роль"
+// @Security BearerAuth
+// @Success 200 {object} models.User
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 404 {object} errorResponse "Пользователь не найден"
+// @Failure 409 {object} errorResponse "Нельзя изменить свою роль"
+// @Failure 500 {object} errorResponse
+// @Router /admin/users/{id}/role [put]
+func (h *AdminHandler) SetRole(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok || !requireAdmin(c) {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	var req models.RoleRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	user, err := h.admin.SetRole(c.Request.Context(), adminID, id, req.Role)
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrOwnRole):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+// queryInt parses an optional integer query parameter; missing means 0.
+func queryInt(c *gin.Context, name string) (int, error) {
+	v := c.Query(name)
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(v)
+}
+
+// queryTime parses an optional RFC 3339 query parameter; missing means the
+// zero time.
+func queryTime(c *gin.Context, name string) (time.Time, error) {
+	v := c.Query(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
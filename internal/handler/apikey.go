@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+	"filmhub/internal/service"
+	"filmhub/pkg/login"
+)
+
+type APIKeyHandler struct {
+	service *service.APIKeyService
+}
+
+func NewAPIKeyHandler(service *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: service}
+}
+
+type apiKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=100" example:"data-export" description:"Название ключа"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=read write" example:"read,write" description:"Права: read, write"`
+	ExpiresAt *time.Time `json:"expires_at" example:"2025-01-01T00:00:00Z" description:"Срок действия (необязательно)"`
+}
+
+type apiKeyCreatedResponse struct {
+	Key    string        `json:"key" example:"fh_3f9a1c2b_q8Zp1x3mVbN0r4TgHkLw9sY2uE6cJdFa7oPiRtXyQe0" description:"Ключ; показывается только один раз"`
+	APIKey models.APIKey `json:"api_key"`
+}
+
+// @Summary Создание API-ключа
+// @Description Выпускает персональный API-ключ. Ключ передаётся в заголовке X-API-Key или как Bearer-токен
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body apiKeyRequest true "Параметры ключа"
+// @Success 201 {object} apiKeyCreatedResponse "Ключ создан"
+// @Failure 400 {object} errorResponse "Ошибка валидации"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Ключами нельзя управлять с помощью API-ключа"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /me/api-keys [post]
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	userID, ok := h.sessionUser(c)
+	if !ok {
+		return
+	}
+	var req apiKeyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	key, plain, err := h.service.Create(c.Request.Context(), userID, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidExpiry) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, apiKeyCreatedResponse{Key: plain, APIKey: *key})
+}
+
+// @Summary Список API-ключей
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.APIKey "Активные ключи"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Ключами нельзя управлять с помощью API-ключа"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /me/api-keys [get]
+func (h *APIKeyHandler) List(c *gin.Context) {
+	userID, ok := h.sessionUser(c)
+	if !ok {
+		return
+	}
+	keys, err := h.service.List(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// @Summary Отзыв API-ключа
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID ключа"
+// @Success 204 "Ключ отозван"
+// @Failure 400 {object} errorResponse "Неверный ID"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Ключами нельзя управлять с помощью API-ключа"
+// @Failure 404 {object} errorResponse "Ключ не найден"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /me/api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	userID, ok := h.sessionUser(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid api key id"})
+		return
+	}
+	if err := h.service.Revoke(c.Request.Context(), userID, id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "api key not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// sessionUser returns the current user, refusing callers authenticated with
+// an API key so a leaked key cannot mint or revoke others.
+func (h *APIKeyHandler) sessionUser(c *gin.Context) (int, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return 0, false
+	}
+	if c.GetString("auth_method") == login.MethodAPIKey {
+		c.JSON(http.StatusForbidden, gin.H{"error": "api keys cannot manage api keys"})
+		return 0, false
+	}
+	return userID, true
+}
@@ -9,22 +9,23 @@ import (
 )
 
 type AuthHandler struct {
-	service *service.AuthService
+	service  *service.AuthService
+	accounts *service.AccountService
 }
 
-func NewAuthHandler(service *service.AuthService) *AuthHandler {
-	return &AuthHandler{service: service}
+func NewAuthHandler(service *service.AuthService, accounts *service.AccountService) *AuthHandler {
+	return &AuthHandler{service: service, accounts: accounts}
 }
 
 type registerRequest struct {
-	Username string `json:"username" binding:"required" example:"john_doe" description:"Имя пользователя"`
-	Email    string `json:"email" binding:"required,email" example:"john@example.com" description:"Email пользователя"`
-	Password string `json:"password" binding:"required,min=6" example:"password123" description:"Пароль (минимум 6 символов)"`
+	Username string `json:"username" validate:"required,username" example:"john_doe" description:"Имя пользователя"`
+	Email    string `json:"email" validate:"required,email" example:"john@example.com" description:"Email пользователя"`
+	Password string `json:"password" validate:"required,min=6,max=72" example:"password123" description:"Пароль (минимум 6 символов)"`
 }
 
 type loginRequest struct {
-	Email    string `json:"email" binding:"required,email" example:"john@example.com" description:"Email пользователя"`
-	Password string `json:"password" binding:"required" example:"password123" description:"Пароль пользователя"`
+	Email    string `json:"email" validate:"required,email" example:"john@example.com" description:"Email пользователя"`
+	Password string `json:"password" validate:"required" example:"password123" description:"Пароль пользователя"`
 }
 
 type loginResponse struct {
@@ -33,19 +34,18 @@ type loginResponse struct {
 
 // Register
 // @Summary Регистрация пользователя
-// @Description Регистрирует нового пользователя в системе
+// @Description Регистрирует нового пользователя в системе и отправляет письмо для подтверждения email
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param user body registerRequest true "Данные пользователя"
-// @Success 201 {object} map[string]interface{} "Пользователь успешно зарегистрирован"
-// @Failure 400 {object} map[string]interface{} "Ошибка валидации"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Success 201 "Пользователь успешно зарегистрирован"
+// @Failure 400 {object} errorResponse "Ошибка валидации"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
 // @Router /register [post]
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req registerRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &req) {
 		return
 	}
 	user := &models.User{
@@ -57,6 +57,11 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if h.accounts != nil {
+		// Delivery failures must not fail the registration: the user can
+		// request another link via /auth/verify-email.
+		_ = h.accounts.SendEmailVerification(c.Request.Context(), user.ID)
+	}
 	c.Status(http.StatusCreated)
 }
 
@@ -67,13 +72,12 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Produce json
 // @Param credentials body loginRequest true "Данные для входа"
 // @Success 200 {object} loginResponse "Успешная авторизация"
-// @Failure 400 {object} map[string]interface{} "Ошибка валидации"
-// @Failure 401 {object} map[string]interface{} "Неверные учетные данные"
+// @Failure 400 {object} errorResponse "Ошибка валидации"
+// @Failure 401 {object} errorResponse "Неверные учетные данные"
 // @Router /login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req loginRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &req) {
 		return
 	}
 	token, err := h.service.Login(c.Request.Context(), req.Email, req.Password)
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/pkg/validation"
+)
+
+var validate = validation.New()
+
+// bindJSON decodes the request body into obj and validates it. On failure it
+// writes a 400 (or 413) with messages localized by Accept-Language and
+// returns false.
+func bindJSON(c *gin.Context, obj any) bool {
+	lang := validation.Language(c.GetHeader("Accept-Language"))
+	if err := json.NewDecoder(c.Request.Body).Decode(obj); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, errorResponse{Error: validation.Message("body_too_large", lang)})
+			return false
+		}
+		c.JSON(http.StatusBadRequest, errorResponse{Error: validation.Message("invalid_body", lang)})
+		return false
+	}
+	if err := validate.ValidateStruct(obj); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{
+			Error:  validation.Message("validation", lang),
+			Fields: validation.FieldErrors(err, lang),
+		})
+		return false
+	}
+	return true
+}
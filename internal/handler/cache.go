@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/internal/service"
+)
+
+type CacheHandler struct {
+	films *service.CachedFilmRepo
+}
+
+func NewCacheHandler(films *service.CachedFilmRepo) *CacheHandler {
+	return &CacheHandler{films: films}
+}
+
+// FilmCacheStats godoc
+// @Summary Статистика кэша фильмов
+// @Description Попадания и промахи кэша карточек фильмов и поиска с момента запуска. Доступно администраторам
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.CacheStats
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Router /admin/cache/films [get]
+func (h *CacheHandler) FilmCacheStats(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	c.JSON(http.StatusOK, h.films.Stats())
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/internal/service"
+)
+
+type ChartHandler struct {
+	service *service.ChartService
+}
+
+func NewChartHandler(s *service.ChartService) *ChartHandler {
+	return &ChartHandler{service: s}
+}
+
+// GetChart godoc
+// @Summary Чарт фильмов
+// @Description Чарты периодически пересчитываются фоновой задачей и отдаются из сохранённой таблицы. trending — по числу отзывов за скользящее окно, где недавние отзывы весят больше; top-rated — по байесовской средней оценке среди фильмов с минимальным числом отзывов; new-releases — по дате выхода среди недавно вышедших; most-watchlisted — по числу пользователей, добавивших фильм в «Буду смотреть». Для каждого фильма указано изменение места с прошлого пересчёта
+// @Tags charts
+// @Produce json
+// @Param chart path string true "Чарт: trending, top-rated, new-releases, most-watchlisted"
+// @Param limit query int false "Сколько фильмов вернуть (1–100, по умолчанию 20)"
+// @Success 200 {object} models.Chart
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /charts/{chart} [get]
+func (h *ChartHandler) GetChart(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	chart, err := h.service.Chart(c.Request.Context(), c.Param("chart"), limit)
+	if err != nil {
+		if errors.Is(err, service.ErrChartNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, chart)
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/internal/models"
+	"filmhub/internal/service"
+)
+
+type CommentHandler struct {
+	service *service.CommentService
+}
+
+func NewCommentHandler(s *service.CommentService) *CommentHandler {
+	return &CommentHandler{service: s}
+}
+
+// ListComments godoc
+// @Summary Комментарии к отзыву
+// @Description Комментарии верхнего уровня от старых к новым, у каждого — его ответы. Токен необязателен: скрытые модератором комментарии видны только их авторам
+// @Tags comments
+// @Produce json
+// @Param id path int true "ID отзыва"
+// @Param page query int false "Номер страницы (с 1)"
+// @Param limit query int false "Размер страницы (1–100, по умолчанию 20)"
+// @Success 200 {object} models.CommentPage
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse "Недействительный токен"
+// @Failure 404 {object} errorResponse "Отзыв не найден"
+// @Failure 500 {object} errorResponse
+// @Router /reviews/{id}/comments [get]
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	reviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	comments, err := h.service.ListComments(c.Request.Context(), reviewID, viewerID(c), page, limit)
+	switch {
+	case errors.Is(err, service.ErrReviewNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, comments)
+	}
+}
+
+// CreateComment godoc
+// @Summary Добавить комментарий к отзыву
+// @Description Ответить можно только на комментарий верхнего уровня того же отзыва
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID отзыва"
+// @Param comment body models.CommentRequest true "Комментарий"
+// @Security BearerAuth
+// @Success 201 {object} idResponse
+// @Failure 400 {object} errorResponse "Ошибка валидации или ответ на ответ"
+// @Failure 401 {object} errorResponse
+// @Failure 404 {object} errorResponse "Отзыв или родительский комментарий не найден"
+// @Failure 500 {object} errorResponse
+// @Router /reviews/{id}/comments [post]
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	reviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
+		return
+	}
+	var req models.CommentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	comment := models.Comment{
+		ReviewID: reviewID,
+		ParentID: req.ParentID,
+		UserID:   userID,
+		Body:     req.Body,
+	}
+	id, err := h.service.CreateComment(c.Request.Context(), &comment)
+	switch {
+	case errors.Is(err, service.ErrReviewNotFound), errors.Is(err, service.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrReplyDepth):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusCreated, gin.H{"id": id})
+	}
+}
+
+// UpdateComment godoc
+// @Summary Изменить комментарий
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID комментария"
+// @Param comment body models.CommentUpdateRequest true "Новый текст"
+// @Security BearerAuth
+// @Success 200 {object} models.Comment
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Изменять можно только свои комментарии"
+// @Failure 404 {object} errorResponse "Комментарий не найден"
+// @Failure 500 {object} errorResponse
+// @Router /comments/{id} [patch]
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment id"})
+		return
+	}
+	var req models.CommentUpdateRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	comment, err := h.service.UpdateComment(c.Request.Context(), id, userID, req.Body)
+	switch {
+	case errors.Is(err, service.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, comment)
+	}
+}
+
+// DeleteComment godoc
+// @Summary Удалить комментарий
+// @Description Автор удаляет свой комментарий, модератор или администратор — любой. Ответы удаляются вместе с комментарием
+// @Tags comments
+// @Produce json
+// @Param id path int true "ID комментария"
+// @Security BearerAuth
+// @Success 204 "Комментарий удалён"
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 404 {object} errorResponse "Комментарий не найден"
+// @Failure 500 {object} errorResponse
+// @Router /comments/{id} [delete]
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment id"})
+		return
+	}
+	err = h.service.DeleteComment(c.Request.Context(), id, userID, currentRole(c))
+	switch {
+	case errors.Is(err, service.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
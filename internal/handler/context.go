@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/internal/models"
+)
+
+// currentUserID returns the user ID stored by login.AuthMiddleware. When it
+// is missing the request is aborted with 401 and ok is false.
+func currentUserID(c *gin.Context) (int, bool) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return 0, false
+	}
+	id, ok := userID.(int)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return 0, false
+	}
+	return id, true
+}
+
+// currentRole returns the role stored by login.AuthMiddleware, or an empty
+// role for anonymous requests.
+func currentRole(c *gin.Context) models.UserRole {
+	return models.UserRole(c.GetString("role"))
+}
+
+// viewerID returns the signed-in user on routes with optional
+// authentication, or 0 for anonymous requests.
+func viewerID(c *gin.Context) int {
+	return c.GetInt("user_id")
+}
+
+// requireModerator aborts the request with 403 unless the caller is a
+// moderator or an admin.
+func requireModerator(c *gin.Context) bool {
+	if !currentRole(c).CanModerate() {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		return false
+	}
+	return true
+}
+
+// requireAdmin aborts the request with 403 unless the caller is an admin.
+func requireAdmin(c *gin.Context) bool {
+	if currentRole(c) != models.RoleAdmin {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,1449 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+	"filmhub/internal/service"
+	"filmhub/pkg/audit"
+	"filmhub/pkg/cache"
+	"filmhub/pkg/charts"
+	"filmhub/pkg/contentfilter"
+	jwtpkg "filmhub/pkg/login"
+	"filmhub/pkg/mailer"
+	"filmhub/pkg/oidc"
+	"filmhub/pkg/oidc/oidctest"
+	"filmhub/pkg/pubsub"
+	"filmhub/pkg/recommend"
+	"filmhub/pkg/signedtoken"
+	"filmhub/swagger"
+)
+
+// The contract test drives every documented operation through the real
+// handlers and checks that the status code is documented and the body
+// matches the schema in swagger/swagger.json. Objects are validated strictly:
+// a property the spec does not declare is reported as drift.
+
+type openAPISpec struct {
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components struct {
+		Schemas map[string]map[string]any `json:"schemas"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	Responses map[string]struct {
+		Content map[string]struct {
+			Schema map[string]any `json:"schema"`
+		} `json:"content"`
+	} `json:"responses"`
+}
+
+type contractFilmRepo struct{}
+
+var contractRelease = time.Date(1999, 3, 31, 0, 0, 0, 0, time.UTC)
+
+func (contractFilmRepo) CreateFilm(_ context.Context, _ *models.FilmRequest, _ int) (int, error) {
+	return 1, nil
+}
+
+func (contractFilmRepo) UpdateFilm(_ context.Context, id int, _ *models.FilmRequest, _, version int) error {
+	if id != 1 || version != 1 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (contractFilmRepo) SetGenres(_ context.Context, _ int, _ []string) error { return nil }
+
+func (contractFilmRepo) SetTags(_ context.Context, _ int, _ []string) error { return nil }
+
+func (contractFilmRepo) SetCredits(_ context.Context, _ int, _ []models.FilmCredit) error { return nil }
+
+func (contractFilmRepo) DeleteFilm(_ context.Context, _, _ int) error { return nil }
+
+func (contractFilmRepo) RollbackFilm(_ context.Context, _, _, _, _ int) error { return nil }
+
+// ListRevisions knows two revisions of film 1: the title was fixed in the
+// second one, which the film currently matches.
+func (contractFilmRepo) ListRevisions(ctx context.Context, filmID int) ([]models.FilmRevision, error) {
+	var revisions []models.FilmRevision
+	for rev := 2; rev >= 1; rev-- {
+		rv, err := contractFilmRepo{}.GetRevision(ctx, filmID, rev)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, *rv)
+	}
+	return revisions, nil
+}
+
+func (contractFilmRepo) GetRevision(_ context.Context, filmID, revision int) (*models.FilmRevision, error) {
+	if filmID != 1 || revision < 1 || revision > 2 {
+		return nil, pgx.ErrNoRows
+	}
+	author := 5
+	rv := &models.FilmRevision{FilmID: 1, Revision: revision, Title: "The Matrix", Description: "Sci-fi", ReleaseDate: contractRelease,
+		AuthorID: &author, Action: models.RevisionUpdate, CreatedAt: time.Now()}
+	if revision == 1 {
+		rv.Title, rv.Action = "Matrix", models.RevisionCreate
+	}
+	return rv, nil
+}
+
+func (contractFilmRepo) GetFilmByID(_ context.Context, id int) (*models.Film, error) {
+	if id != 1 {
+		return nil, pgx.ErrNoRows
+	}
+	return &models.Film{ID: 1, Title: "The Matrix", Description: "Sci-fi", ReleaseDate: contractRelease, Version: 1,
+		Genres: []string{"action", "sci-fi"}, Tags: []string{"cyberpunk"}}, nil
+}
+
+func (contractFilmRepo) SearchFilms(_ context.Context, _ string) ([]models.Film, error) {
+	return []models.Film{{ID: 1, Title: "The Matrix", Description: "Sci-fi", Genres: []string{}, Tags: []string{}}}, nil
+}
+
+type contractReviewRepo struct{}
+
+func (contractReviewRepo) CreateReview(_ context.Context, _ *models.Review) (int, error) {
+	return 7, nil
+}
+
+func (contractReviewRepo) HoldReview(_ context.Context, review *models.Review, _ string) (int, error) {
+	now := time.Now()
+	review.HiddenAt = &now
+	return 8, nil
+}
+
+// GetReviewByID knows review 7 by another user and review 9 by user 1, the
+// user every contract token is issued for.
+func (contractReviewRepo) GetReviewByID(_ context.Context, id int) (*models.Review, error) {
+	switch id {
+	case 7:
+		return &models.Review{ID: 7, FilmID: 1, UserID: 2, Rating: 9, Comment: "Отличный фильм!", Version: 1}, nil
+	case 9:
+		return &models.Review{ID: 9, FilmID: 1, UserID: 1, Rating: 6, Version: 1}, nil
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (contractReviewRepo) ListReviewsByFilm(_ context.Context, filmID int, _ string, _ int) ([]models.Review, error) {
+	return []models.Review{{ID: 7, FilmID: filmID, UserID: 2, Rating: 9, Comment: "Отличный фильм!", HelpfulCount: 3}}, nil
+}
+
+func (contractReviewRepo) UpdateReview(_ context.Context, review *models.Review, version int, _ string) error {
+	if version != 1 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (contractReviewRepo) RefreshFilmRating(_ context.Context, _ int) (float32, error) { return 0, nil }
+
+func (contractReviewRepo) DeleteReview(_ context.Context, _, _ int) error { return nil }
+
+func (contractReviewRepo) Vote(_ context.Context, reviewID, _, value int) (*models.ReviewVotes, error) {
+	return &models.ReviewVotes{ReviewID: reviewID, HelpfulCount: 4, MyVote: value}, nil
+}
+
+type contractUserRepo struct {
+	users map[string]*models.User
+}
+
+func (r *contractUserRepo) Create(_ context.Context, user *models.User) error {
+	user.ID = len(r.users) + 1
+	r.users[user.Email] = user
+	return nil
+}
+
+func (r *contractUserRepo) FindByEmail(_ context.Context, email string) (*models.User, error) {
+	if u, ok := r.users[email]; ok {
+		return u, nil
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (r *contractUserRepo) FindByID(_ context.Context, id int) (*models.User, error) {
+	for _, u := range r.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (r *contractUserRepo) MarkEmailVerified(ctx context.Context, id int) error {
+	u, err := r.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	u.EmailVerified = true
+	return nil
+}
+
+func (r *contractUserRepo) UpdatePassword(_ context.Context, _ int, _ string) error { return nil }
+
+func (r *contractUserRepo) UpdateRole(ctx context.Context, id int, role models.UserRole) error {
+	u, err := r.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	u.Role = role
+	return nil
+}
+
+type contractTokenRepo struct{}
+
+func (contractTokenRepo) Save(_ context.Context, _ string, _ int, _ string, _ time.Time) error {
+	return nil
+}
+
+func (contractTokenRepo) Consume(_ context.Context, _, _ string) error { return nil }
+
+type contractIdentityRepo struct {
+	identities []models.Identity
+}
+
+func (r *contractIdentityRepo) FindUserID(_ context.Context, provider, subject string) (int, error) {
+	for _, i := range r.identities {
+		if i.Provider == provider && i.Subject == subject {
+			return i.UserID, nil
+		}
+	}
+	return 0, pgx.ErrNoRows
+}
+
+func (r *contractIdentityRepo) Link(_ context.Context, identity *models.Identity) error {
+	identity.CreatedAt = time.Now()
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+func (r *contractIdentityRepo) ListByUser(_ context.Context, userID int) ([]models.Identity, error) {
+	var out []models.Identity
+	for _, i := range r.identities {
+		if i.UserID == userID {
+			out = append(out, i)
+		}
+	}
+	return out, nil
+}
+
+func (r *contractIdentityRepo) Unlink(_ context.Context, userID int, provider string) error {
+	for n, i := range r.identities {
+		if i.UserID == userID && i.Provider == provider {
+			r.identities = append(r.identities[:n], r.identities[n+1:]...)
+			return nil
+		}
+	}
+	return pgx.ErrNoRows
+}
+
+// contractCommentRepo knows comment 3 by user 1 on review 7, its reply 4 and
+// comment 5 by user 2.
+type contractCommentRepo struct{}
+
+func (contractCommentRepo) CreateComment(_ context.Context, _ *models.Comment) (int, error) {
+	return 6, nil
+}
+
+func (contractCommentRepo) GetCommentByID(_ context.Context, id int) (*models.Comment, error) {
+	parent := 3
+	switch id {
+	case 3:
+		return &models.Comment{ID: 3, ReviewID: 7, UserID: 1, Body: "Согласен"}, nil
+	case 4:
+		return &models.Comment{ID: 4, ReviewID: 7, ParentID: &parent, UserID: 2, Body: "И я"}, nil
+	case 5:
+		return &models.Comment{ID: 5, ReviewID: 7, UserID: 2, Body: "Не согласен"}, nil
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (contractCommentRepo) ListCommentsByReview(_ context.Context, reviewID, _, _, _ int) ([]models.Comment, int, error) {
+	parent := 3
+	return []models.Comment{{ID: 3, ReviewID: reviewID, UserID: 1, Body: "Согласен", CreatedAt: time.Now(),
+		Replies: []models.Comment{{ID: 4, ReviewID: reviewID, ParentID: &parent, UserID: 2, Body: "И я", CreatedAt: time.Now()}}}}, 1, nil
+}
+
+func (r contractCommentRepo) UpdateComment(ctx context.Context, id int, body string) (*models.Comment, error) {
+	comment, err := r.GetCommentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	comment.Body, comment.UpdatedAt = body, &now
+	return comment, nil
+}
+
+func (contractCommentRepo) DeleteComment(_ context.Context, _ int) error { return nil }
+
+// contractModerationRepo knows report 1 (open, on comment 5), report 2
+// (actioned, on hidden comment 6) and report 3 (dismissed, on review 7).
+// User 1 has already reported comment 5.
+type contractModerationRepo struct{}
+
+var contractUser1, contractUser2 = 1, 2
+
+var contractReports = map[int]models.Report{
+	1: {ID: 1, TargetType: models.TargetComment, TargetID: 5, ReporterID: &contractUser1, Reason: "spam", Status: models.ReportOpen},
+	2: {ID: 2, TargetType: models.TargetComment, TargetID: 6, ReporterID: &contractUser2, Reason: "abuse", Status: models.ReportActioned},
+	3: {ID: 3, TargetType: models.TargetReview, TargetID: 7, ReporterID: &contractUser2, Reason: "spoiler", Status: models.ReportDismissed},
+}
+
+func (contractModerationRepo) GetTarget(_ context.Context, targetType string, id int) (int, bool, error) {
+	switch {
+	case targetType == models.TargetReview && id == 7:
+		return 2, false, nil
+	case targetType == models.TargetComment && id == 3:
+		return 1, false, nil
+	case targetType == models.TargetComment && id == 5:
+		return 2, false, nil
+	case targetType == models.TargetComment && id == 6:
+		return 2, true, nil
+	}
+	return 0, false, pgx.ErrNoRows
+}
+
+func (contractModerationRepo) CreateReport(_ context.Context, report *models.Report) (bool, error) {
+	if report.TargetType == models.TargetComment && report.TargetID == 5 && *report.ReporterID == 1 {
+		return false, nil
+	}
+	report.ID, report.Status, report.CreatedAt = 4, models.ReportOpen, time.Now()
+	return true, nil
+}
+
+func (contractModerationRepo) GetReport(_ context.Context, id int) (*models.Report, error) {
+	report, ok := contractReports[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &report, nil
+}
+
+func (contractModerationRepo) ListReports(_ context.Context, status string, _, _ int) ([]models.Report, int, error) {
+	var out []models.Report
+	for _, id := range []int{1, 2, 3} {
+		if contractReports[id].Status == status {
+			out = append(out, contractReports[id])
+		}
+	}
+	return out, len(out), nil
+}
+
+func (contractModerationRepo) Apply(_ context.Context, action *models.ModerationAction) (*models.FilmRating, error) {
+	action.ID, action.CreatedAt = 1, time.Now()
+	return nil, nil
+}
+
+func (contractModerationRepo) ListActions(_ context.Context, _, _ int) ([]models.ModerationAction, int, error) {
+	report := 1
+	return []models.ModerationAction{{ID: 1, ModeratorID: 5, Action: models.ModerationHide,
+		TargetType: models.TargetComment, TargetID: 5, ReportID: &report, CreatedAt: time.Now()}}, 1, nil
+}
+
+// contractAuditRepo keeps recorded entries so listings return what the
+// earlier cases wrote.
+type contractAuditRepo struct {
+	entries []models.AuditEntry
+}
+
+func (r *contractAuditRepo) Record(_ context.Context, entry *models.AuditEntry) error {
+	entry.ID = int64(len(r.entries) + 1)
+	entry.CreatedAt = time.Now()
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+func (r *contractAuditRepo) List(_ context.Context, _ models.AuditFilter, _, _ int) ([]models.AuditEntry, int, error) {
+	return r.entries, len(r.entries), nil
+}
+
+// contractTrashRepo has film 3 in the trash.
+type contractTrashRepo struct{}
+
+func (contractTrashRepo) List(_ context.Context, _ string, _, _ int) ([]models.TrashItem, int, error) {
+	deletedBy := 5
+	return []models.TrashItem{{Type: models.EntityFilm, ID: 3, Title: "Alien", DeletedAt: time.Now(), DeletedBy: &deletedBy}}, 1, nil
+}
+
+func (contractTrashRepo) Restore(_ context.Context, itemType string, id int) (*models.FilmRating, error) {
+	if itemType != models.EntityFilm || id != 3 {
+		return nil, pgx.ErrNoRows
+	}
+	return nil, nil
+}
+
+func (contractTrashRepo) Purge(_ context.Context, _ time.Time) (int64, int64, error) {
+	return 0, 0, nil
+}
+
+// contractWebhookRepo has subscription 1 with a dead-lettered delivery 10.
+type contractWebhookRepo struct{}
+
+func (contractWebhookRepo) CreateSubscription(_ context.Context, sub *models.WebhookSubscription) (int, error) {
+	sub.CreatedAt = time.Now()
+	return 2, nil
+}
+
+func (contractWebhookRepo) ListSubscriptions(_ context.Context) ([]models.WebhookSubscription, error) {
+	createdBy := 1
+	return []models.WebhookSubscription{{
+		ID: 1, URL: "https://partner.example.com/hooks", EventTypes: []string{models.EventFilmCreated},
+		CreatedBy: &createdBy, CreatedAt: time.Now(),
+	}}, nil
+}
+
+func (r contractWebhookRepo) DeleteSubscription(ctx context.Context, id int) (*models.WebhookSubscription, error) {
+	if id != 1 {
+		return nil, pgx.ErrNoRows
+	}
+	subs, _ := r.ListSubscriptions(ctx)
+	return &subs[0], nil
+}
+
+func (contractWebhookRepo) Enqueue(_ context.Context, _ int64, _ string, _ []byte) error { return nil }
+
+func (contractWebhookRepo) ListDeliveries(_ context.Context, subscriptionID int, _ string, _, _ int) ([]models.WebhookDelivery, int, error) {
+	if subscriptionID != 1 {
+		return nil, 0, pgx.ErrNoRows
+	}
+	code, reason, at := 502, "endpoint responded 502 Bad Gateway", time.Now()
+	return []models.WebhookDelivery{{
+		ID: 10, SubscriptionID: 1, EventID: 42, EventType: models.EventFilmCreated, Status: models.DeliveryDead,
+		Attempts: 8, LastAttemptAt: &at, LastStatusCode: &code, LastError: &reason, CreatedAt: at,
+	}}, 1, nil
+}
+
+func (contractWebhookRepo) Redeliver(_ context.Context, subscriptionID int, id int64) error {
+	if subscriptionID != 1 || id != 10 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// contractNotificationRepo knows notification 1 of user 1.
+type contractNotificationRepo struct{}
+
+func (contractNotificationRepo) CreateNotification(_ context.Context, _ *models.Notification) error {
+	return nil
+}
+
+func (contractNotificationRepo) NotifyWatchers(_ context.Context, _ *models.Notification) error {
+	return nil
+}
+
+func (contractNotificationRepo) ListNotifications(_ context.Context, userID int, _ bool, _, _ int) ([]models.Notification, int, int, error) {
+	actor, review := 2, 9
+	return []models.Notification{{ID: 1, UserID: userID, Type: models.NotificationLike, ActorID: &actor,
+		ReviewID: &review, CreatedAt: time.Now()}}, 1, 1, nil
+}
+
+func (contractNotificationRepo) MarkRead(_ context.Context, userID int, id int64) error {
+	if userID != 1 || id != 1 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (contractNotificationRepo) MarkAllRead(_ context.Context, _ int) error { return nil }
+
+func (contractNotificationRepo) DisabledTypes(_ context.Context, _ int) ([]string, error) {
+	return []string{models.NotificationReleaseDate}, nil
+}
+
+func (contractNotificationRepo) SetPreference(_ context.Context, _ int, _ string, _ bool) error {
+	return nil
+}
+
+// contractWatchlistRepo has film 1 on every watchlist.
+type contractWatchlistRepo struct{}
+
+func (contractWatchlistRepo) AddToWatchlist(_ context.Context, _, _ int) error { return nil }
+
+func (contractWatchlistRepo) RemoveFromWatchlist(_ context.Context, _, filmID int) error {
+	if filmID != 1 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (contractWatchlistRepo) ListWatchlist(_ context.Context, _, _, _ int) ([]models.WatchlistItem, int, error) {
+	return []models.WatchlistItem{{FilmID: 1, Title: "The Matrix", ReleaseDate: contractRelease, AddedAt: time.Now()}}, 1, nil
+}
+
+// contractRecommendationRepo has user 1 rate film 1, similar to film 2.
+type contractRecommendationRepo struct{}
+
+func (contractRecommendationRepo) Ratings(_ context.Context) ([]recommend.Rating, error) {
+	return nil, nil
+}
+
+func (contractRecommendationRepo) ReplaceSimilarities(_ context.Context, _ []recommend.Similarity) error {
+	return nil
+}
+
+func (contractRecommendationRepo) ComputedAt(_ context.Context) (*time.Time, error) {
+	return &contractRelease, nil
+}
+
+func (contractRecommendationRepo) UserRatings(_ context.Context, _ int) ([]models.RatedFilm, error) {
+	return []models.RatedFilm{{FilmID: 1, Title: "The Matrix", Rating: 9, Genres: []string{"sci-fi"}}}, nil
+}
+
+func (contractRecommendationRepo) Neighbors(_ context.Context, _ []int) ([]models.FilmNeighbor, error) {
+	return []models.FilmNeighbor{{RatedID: 1, FilmID: 2, Title: "Dark City", Rating: 7.6, Score: 0.8}}, nil
+}
+
+func (contractRecommendationRepo) PopularFilms(_ context.Context, genres []string, _ []int, _ int) ([]models.PopularFilm, error) {
+	if len(genres) > 0 {
+		return []models.PopularFilm{{FilmID: 3, Title: "Gattaca", Rating: 7.8, Reviews: 4, Genre: genres[0]}}, nil
+	}
+	return []models.PopularFilm{{FilmID: 4, Title: "Heat", Rating: 8.3, Reviews: 12}}, nil
+}
+
+// contractSimilarRepo knows three films; films 1 and 2 share a genre, a tag
+// and a director.
+type contractSimilarRepo struct{}
+
+func (contractSimilarRepo) FilmFeatures(_ context.Context) ([]models.FilmFeatures, error) {
+	return []models.FilmFeatures{
+		{FilmID: 1, Title: "The Matrix", Rating: 8.7, Description: "A hacker learns reality is a simulation.",
+			Genres: []string{"sci-fi"}, Tags: []string{"dystopia"}, People: []string{"Lana Wachowski"}},
+		{FilmID: 2, Title: "Cloud Atlas", Rating: 7.4, Description: "Six stories across centuries.",
+			Genres: []string{"sci-fi", "drama"}, Tags: []string{"dystopia"}, People: []string{"Lana Wachowski", "Tom Hanks"}},
+		{FilmID: 3, Title: "Heat", Rating: 8.3, Description: "A detective hunts a crew of robbers.", Genres: []string{"crime"}},
+	}, nil
+}
+
+func (contractSimilarRepo) CoRatings(_ context.Context, id int) ([]recommend.Similarity, error) {
+	return []recommend.Similarity{{ItemID: id, OtherID: 2, Score: 0.6, Support: 5}}, nil
+}
+
+// contractChartRepo has film 1 top every chart, up from second place.
+type contractChartRepo struct{}
+
+func (contractChartRepo) ReviewActivity(_ context.Context, _ time.Time) ([]charts.Activity, error) {
+	return nil, nil
+}
+
+func (contractChartRepo) RatingSummaries(_ context.Context) ([]charts.RatingSummary, error) {
+	return nil, nil
+}
+
+func (contractChartRepo) Releases(_ context.Context, _, _ time.Time) ([]charts.Score, error) {
+	return nil, nil
+}
+
+func (contractChartRepo) WatchlistCounts(_ context.Context) ([]charts.Score, error) {
+	return nil, nil
+}
+
+func (contractChartRepo) ChartRanks(_ context.Context, _ string) (map[int]int, error) {
+	return nil, nil
+}
+
+func (contractChartRepo) ReplaceChart(_ context.Context, _ string, _ []charts.Entry) error {
+	return nil
+}
+
+func (contractChartRepo) ChartEntries(_ context.Context, _ string, _ int) ([]models.ChartEntry, error) {
+	previous := 2
+	return []models.ChartEntry{{Rank: 1, FilmID: 1, Title: "The Matrix", Rating: 8.7, ReleaseDate: contractRelease, Score: 9.1, PreviousRank: &previous}}, nil
+}
+
+func (contractChartRepo) ChartComputedAt(_ context.Context, _ string) (*time.Time, error) {
+	return &contractRelease, nil
+}
+
+// contractListRepo has list 1 of user 1, holding film 1 and shared with
+// user 2, list 2 of user 2, private list 3 of user 2 and full list 4 of
+// user 1.
+type contractListRepo struct{}
+
+func (contractListRepo) CreateList(_ context.Context, _ int, _ *models.FilmListRequest) (int, error) {
+	return 5, nil
+}
+
+func (contractListRepo) GetList(_ context.Context, id, _ int) (*models.FilmList, error) {
+	switch id {
+	case 1:
+		return &models.FilmList{ID: 1, OwnerID: 1, OwnerName: "john", Title: "Cyberpunk", Visibility: models.ListPublic,
+			EntryCount: 1, LikeCount: 3, Collaborators: []models.ListCollaborator{{UserID: 2, Username: "jane"}},
+			CreatedAt: contractRelease, UpdatedAt: contractRelease}, nil
+	case 2:
+		return &models.FilmList{ID: 2, OwnerID: 2, OwnerName: "jane", Title: "Heists", Visibility: models.ListPublic,
+			CreatedAt: contractRelease, UpdatedAt: contractRelease}, nil
+	case 3:
+		return &models.FilmList{ID: 3, OwnerID: 2, OwnerName: "jane", Title: "Drafts", Visibility: models.ListPrivate,
+			CreatedAt: contractRelease, UpdatedAt: contractRelease}, nil
+	case 4:
+		return &models.FilmList{ID: 4, OwnerID: 1, OwnerName: "john", Title: "Everything", Visibility: models.ListUnlisted,
+			EntryCount: 500, CreatedAt: contractRelease, UpdatedAt: contractRelease}, nil
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (contractListRepo) UpdateList(_ context.Context, _ int, _ *models.FilmListRequest) error {
+	return nil
+}
+
+func (contractListRepo) DeleteList(_ context.Context, _ int) error { return nil }
+
+func (r contractListRepo) PublicLists(ctx context.Context, _, viewerID, _, _ int) ([]models.FilmList, int, error) {
+	list, _ := r.GetList(ctx, 1, viewerID)
+	return []models.FilmList{*list}, 1, nil
+}
+
+func (r contractListRepo) UserLists(ctx context.Context, userID, _, _ int) ([]models.FilmList, int, error) {
+	list, _ := r.GetList(ctx, 1, userID)
+	return []models.FilmList{*list}, 1, nil
+}
+
+func (contractListRepo) ListEntries(_ context.Context, listID int) ([]models.FilmListEntry, error) {
+	if listID != 1 {
+		return nil, nil
+	}
+	addedBy := 1
+	return []models.FilmListEntry{{Position: 1, FilmID: 1, Title: "The Matrix", Rating: 8.7, ReleaseDate: contractRelease,
+		Note: "Where it started", AddedBy: &addedBy, AddedAt: contractRelease}}, nil
+}
+
+func (contractListRepo) AddEntry(_ context.Context, _, _, _ int, _ string) (bool, error) {
+	return true, nil
+}
+
+func (contractListRepo) UpdateEntry(_ context.Context, _, filmID int, _ *string, _ int) error {
+	if filmID != 1 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (contractListRepo) RemoveEntry(_ context.Context, _, filmID int) error {
+	if filmID != 1 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// ReorderEntries accepts the one order of list 1, which holds film 1.
+func (contractListRepo) ReorderEntries(_ context.Context, _ int, filmIDs []int) (bool, error) {
+	return len(filmIDs) == 1 && filmIDs[0] == 1, nil
+}
+
+func (contractListRepo) AddCollaborator(_ context.Context, _, _ int) error { return nil }
+
+func (contractListRepo) RemoveCollaborator(_ context.Context, _, userID int) error {
+	if userID != 2 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (contractListRepo) Like(_ context.Context, _, _ int) error { return nil }
+
+func (contractListRepo) Unlike(_ context.Context, _, _ int) error { return nil }
+
+type contractAPIKeyRepo struct {
+	keys []models.APIKey
+}
+
+func (r *contractAPIKeyRepo) Create(_ context.Context, key *models.APIKey, _ string) error {
+	key.ID = len(r.keys) + 1
+	key.CreatedAt = time.Now()
+	r.keys = append(r.keys, *key)
+	return nil
+}
+
+func (r *contractAPIKeyRepo) FindByHash(_ context.Context, _ string) (*models.APIKey, error) {
+	return nil, pgx.ErrNoRows
+}
+
+func (r *contractAPIKeyRepo) ListByUser(_ context.Context, userID int) ([]models.APIKey, error) {
+	var out []models.APIKey
+	for _, k := range r.keys {
+		if k.UserID == userID && k.RevokedAt == nil {
+			out = append(out, k)
+		}
+	}
+	return out, nil
+}
+
+func (r *contractAPIKeyRepo) Revoke(_ context.Context, userID, id int) error {
+	for n, k := range r.keys {
+		if k.ID == id && k.UserID == userID && k.RevokedAt == nil {
+			now := time.Now()
+			r.keys[n].RevokedAt = &now
+			return nil
+		}
+	}
+	return pgx.ErrNoRows
+}
+
+func (r *contractAPIKeyRepo) TouchLastUsed(_ context.Context, _ int) error { return nil }
+
+type discardMailer struct{}
+
+func (discardMailer) Send(_ context.Context, _ mailer.Message) error { return nil }
+
+func newContractRouter(provider *oidc.Provider) (*gin.Engine, *service.SocialAuthService) {
+	gin.SetMode(gin.TestMode)
+	keys, _ := jwtpkg.NewKeyring(jwtpkg.KeyringConfig{Algorithm: jwtpkg.EdDSA})
+	jwtpkg.Init(keys, jwtpkg.Config{})
+
+	users := &contractUserRepo{users: map[string]*models.User{}}
+	signer, _ := signedtoken.New("testsecret")
+	accounts := service.NewAccountService(users, contractTokenRepo{}, signer, discardMailer{}, "http://localhost:3000")
+	social := service.NewSocialAuthService("oidc", provider, users, &contractIdentityRepo{}, signer)
+
+	auditor := service.NewAuditService(&contractAuditRepo{})
+	films := service.NewCachedFilmRepo(contractFilmRepo{}, cache.NewLRU(100, time.Minute))
+	filmService := service.NewFilmService(films).WithAudit(auditor)
+	filmHandler := NewFilmHandler(filmService)
+	// A closed hub ends event streams right after their preamble.
+	hub := pubsub.New(10, 10)
+	hub.Close()
+	eventsHandler := NewEventsHandler(hub, filmService, time.Minute)
+	cacheHandler := NewCacheHandler(films)
+	filter := contentfilter.NewPipeline(1, 3,
+		contentfilter.NewBannedWords([]string{"идиот"}, contentfilter.DefaultBannedWordWeight),
+		contentfilter.NewLinkFilter(1), contentfilter.NewShoutingFilter())
+	reviewHandler := NewReviewHandler(service.NewReviewService(contractReviewRepo{}).WithContentFilter(filter).WithAudit(auditor).WithFilmCache(films))
+	commentHandler := NewCommentHandler(service.NewCommentService(contractCommentRepo{}, contractReviewRepo{}).WithAudit(auditor))
+	moderationHandler := NewModerationHandler(service.NewModerationService(contractModerationRepo{}).WithAudit(auditor))
+	trashHandler := NewTrashHandler(service.NewTrashService(contractTrashRepo{}, 30*24*time.Hour).WithAudit(auditor).WithFilmCache(films))
+	webhookHandler := NewWebhookHandler(service.NewWebhookService(contractWebhookRepo{}).WithAudit(auditor))
+	notificationHandler := NewNotificationHandler(service.NewNotificationService(contractNotificationRepo{}))
+	watchlistHandler := NewWatchlistHandler(service.NewWatchlistService(contractWatchlistRepo{}, contractFilmRepo{}))
+	recommendationHandler := NewRecommendationHandler(service.NewRecommendationService(contractRecommendationRepo{}, recommend.Options{}))
+	similarHandler := NewSimilarHandler(service.NewSimilarService(contractSimilarRepo{}, contractFilmRepo{}, service.SimilarWeights{Genres: 1, Tags: 1, People: 1, Ratings: 1, Text: 1}, nil))
+	chartHandler := NewChartHandler(service.NewChartService(contractChartRepo{}, service.ChartOptions{}))
+	listHandler := NewListHandler(service.NewListService(contractListRepo{}, contractFilmRepo{}, users).WithAudit(auditor))
+	adminHandler := NewAdminHandler(service.NewAdminService(users).WithAudit(auditor), auditor)
+	authHandler := NewAuthHandler(service.NewAuthService(users), accounts)
+	accountHandler := NewAccountHandler(accounts)
+	apiKeys := service.NewAPIKeyService(&contractAPIKeyRepo{}, users)
+	apiKeyHandler := NewAPIKeyHandler(apiKeys)
+	oidcHandler := NewOIDCHandler(social)
+
+	r := gin.New()
+	r.GET("/.well-known/jwks.json", NewJWKSHandler(keys).JWKS)
+	r.POST("/register", authHandler.Register)
+	r.POST("/login", authHandler.Login)
+	r.POST("/auth/verify-email/confirm", accountHandler.ConfirmEmail)
+	r.POST("/auth/password-reset", accountHandler.RequestPasswordReset)
+	r.POST("/auth/password-reset/confirm", accountHandler.ConfirmPasswordReset)
+	r.GET("/auth/oidc/login", oidcHandler.Login)
+	r.GET("/auth/oidc/callback", oidcHandler.Callback)
+	r.GET("/films", filmHandler.SearchFilms)
+	r.GET("/films/:id", filmHandler.GetFilm)
+	r.GET("/films/:id/revisions", filmHandler.ListRevisions)
+	r.GET("/films/:id/revisions/diff", filmHandler.DiffRevisions)
+	r.GET("/films/:id/events", eventsHandler.FilmEvents)
+	r.GET("/films/:id/similar", similarHandler.ListSimilar)
+	r.GET("/charts/:chart", chartHandler.GetChart)
+	optionalAuth := jwtpkg.AuthMiddleware(jwtpkg.WithAPIKeys(apiKeys), jwtpkg.Optional())
+	r.GET("/films/:id/reviews", optionalAuth, reviewHandler.ListReviews)
+	r.GET("/reviews/:id", optionalAuth, reviewHandler.GetReview)
+	r.GET("/reviews/:id/comments", optionalAuth, commentHandler.ListComments)
+	r.GET("/lists", optionalAuth, listHandler.DiscoverLists)
+	r.GET("/lists/:id", optionalAuth, listHandler.GetList)
+	auth := r.Group("/")
+	auth.Use(jwtpkg.AuthMiddleware(jwtpkg.WithAPIKeys(apiKeys)), audit.Middleware())
+	auth.POST("/auth/verify-email", accountHandler.RequestEmailVerification)
+	auth.POST("/films", filmHandler.CreateFilm)
+	auth.POST("/films/import", filmHandler.ImportFilms)
+	auth.PUT("/films/:id", filmHandler.UpdateFilm)
+	auth.DELETE("/films/:id", filmHandler.DeleteFilm)
+	auth.POST("/films/:id/revisions/:revision/rollback", filmHandler.Rollback)
+	auth.POST("/films/:id/reviews", reviewHandler.CreateReview)
+	auth.POST("/reviews/:id/vote", reviewHandler.VoteReview)
+	auth.PUT("/reviews/:id", reviewHandler.UpdateReview)
+	auth.DELETE("/reviews/:id", reviewHandler.DeleteReview)
+	auth.POST("/reviews/:id/comments", commentHandler.CreateComment)
+	auth.PATCH("/comments/:id", commentHandler.UpdateComment)
+	auth.DELETE("/comments/:id", commentHandler.DeleteComment)
+	auth.POST("/reviews/:id/report", moderationHandler.ReportReview)
+	auth.POST("/comments/:id/report", moderationHandler.ReportComment)
+	auth.GET("/moderation/reports", moderationHandler.ListReports)
+	auth.POST("/moderation/reports/:id/hide", moderationHandler.HideReported)
+	auth.POST("/moderation/reports/:id/restore", moderationHandler.RestoreReported)
+	auth.POST("/moderation/reports/:id/dismiss", moderationHandler.DismissReport)
+	auth.GET("/moderation/log", moderationHandler.ModerationLog)
+	auth.GET("/events", eventsHandler.AllEvents)
+	auth.GET("/admin/audit", adminHandler.ListAudit)
+	auth.PUT("/admin/users/:id/role", adminHandler.SetRole)
+	auth.GET("/admin/trash", trashHandler.ListTrash)
+	auth.POST("/admin/trash/:type/:id/restore", trashHandler.RestoreTrash)
+	auth.GET("/admin/cache/films", cacheHandler.FilmCacheStats)
+	auth.POST("/admin/webhooks", webhookHandler.CreateWebhook)
+	auth.GET("/admin/webhooks", webhookHandler.ListWebhooks)
+	auth.DELETE("/admin/webhooks/:id", webhookHandler.DeleteWebhook)
+	auth.GET("/admin/webhooks/:id/deliveries", webhookHandler.ListWebhookDeliveries)
+	auth.POST("/admin/webhooks/:id/deliveries/:delivery/redeliver", webhookHandler.RedeliverWebhook)
+	auth.POST("/me/api-keys", apiKeyHandler.Create)
+	auth.GET("/me/api-keys", apiKeyHandler.List)
+	auth.DELETE("/me/api-keys/:id", apiKeyHandler.Revoke)
+	auth.GET("/me/notifications", notificationHandler.ListNotifications)
+	auth.POST("/me/notifications/read-all", notificationHandler.MarkAllNotificationsRead)
+	auth.POST("/me/notifications/:id/read", notificationHandler.MarkNotificationRead)
+	auth.GET("/me/notification-preferences", notificationHandler.GetNotificationPreferences)
+	auth.PUT("/me/notification-preferences", notificationHandler.UpdateNotificationPreferences)
+	auth.GET("/me/recommendations", recommendationHandler.ListRecommendations)
+	auth.GET("/me/watchlist", watchlistHandler.ListWatchlist)
+	auth.PUT("/me/watchlist/:film_id", watchlistHandler.AddToWatchlist)
+	auth.DELETE("/me/watchlist/:film_id", watchlistHandler.RemoveFromWatchlist)
+	auth.GET("/me/lists", listHandler.ListMyLists)
+	auth.POST("/lists", listHandler.CreateList)
+	auth.PUT("/lists/:id", listHandler.UpdateList)
+	auth.DELETE("/lists/:id", listHandler.DeleteList)
+	auth.POST("/lists/:id/entries", listHandler.AddListEntry)
+	auth.PATCH("/lists/:id/entries/:film_id", listHandler.UpdateListEntry)
+	auth.DELETE("/lists/:id/entries/:film_id", listHandler.RemoveListEntry)
+	auth.PUT("/lists/:id/order", listHandler.ReorderList)
+	auth.PUT("/lists/:id/collaborators/:user_id", listHandler.AddListCollaborator)
+	auth.DELETE("/lists/:id/collaborators/:user_id", listHandler.RemoveListCollaborator)
+	auth.PUT("/lists/:id/like", listHandler.LikeList)
+	auth.DELETE("/lists/:id/like", listHandler.UnlikeList)
+	identities := auth.Group("/me/identities", jwtpkg.SessionOnly())
+	identities.POST("/link", oidcHandler.Link)
+	identities.GET("", oidcHandler.ListIdentities)
+	identities.DELETE("/:provider", oidcHandler.Unlink)
+	return r, social
+}
+
+type contractCase struct {
+	name   string
+	method string
+	route  string // path template as documented in the spec
+	url    string
+	body   any
+	role   string // issue a token with this role when non-empty
+	want   int
+	cookie *http.Cookie
+}
+
+// contractHeaders holds extra request headers by case name.
+var contractHeaders = map[string]http.Header{
+	"get film not modified":   {"If-None-Match": {`"1-0-7e41c41c"`}},
+	"get film modified":       {"If-None-Match": {`"0"`}},
+	"update film":             {"If-Match": {`"1"`}},
+	"update film invalid":     {"If-Match": {`"1"`}},
+	"update film forbidden":   {"If-Match": {`"1"`}},
+	"update film not found":   {"If-Match": {`"1"`}},
+	"update film stale":       {"If-Match": {`"5"`}},
+	"update film any version": {"If-Match": {"*"}},
+	"rollback film":           {"If-Match": {`"1"`}},
+	"rollback film current":   {"If-Match": {`"1"`}},
+	"rollback film not found": {"If-Match": {`"1"`}},
+	"rollback film forbidden": {"If-Match": {`"1"`}},
+	"rollback film stale":     {"If-Match": {`"5"`}},
+	"get review not modified": {"If-None-Match": {`"1-0-0-0"`}},
+	"update own review":       {"If-Match": {`"1-0-0-0"`}},
+	"update review forbidden": {"If-Match": {`"1"`}},
+	"update review not found": {"If-Match": {`"1"`}},
+	"update review stale":     {"If-Match": {`"2-0-0-0"`}},
+	"update review weak etag": {"If-Match": {`W/"1-0-0-0"`}},
+	"update review invalid":   {"If-Match": {`"1"`}},
+}
+
+func TestHandlersMatchOpenAPISpec(t *testing.T) {
+	var spec openAPISpec
+	if err := json.Unmarshal(swagger.Spec, &spec); err != nil {
+		t.Fatalf("parse swagger.json: %v", err)
+	}
+
+	signer, _ := signedtoken.New("testsecret")
+	verifyToken, _, _ := signer.Issue(service.PurposeVerifyEmail, 1, time.Hour)
+	resetToken, _, _ := signer.Issue(service.PurposeResetPassword, 1, time.Hour)
+	film := models.FilmRequest{Title: "The Matrix", Description: "Sci-fi", ReleaseDate: time.Now()}
+
+	idp := oidctest.NewProvider("filmhub", "secret")
+	defer idp.Close()
+	// The provider logs in as the registered user, so once "confirm email"
+	// has run the identity gets linked to user 1 by verified email.
+	idp.SetUser(oidctest.User{Subject: "ext-1", Email: "john@example.com", EmailVerified: true})
+	provider, err := oidc.Discover(context.Background(), idp.Config("http://localhost/auth/oidc/callback"), idp.Client())
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	router, social := newContractRouter(provider)
+	authURL, stateToken, err := social.Begin(0)
+	if err != nil {
+		t.Fatalf("begin oidc login: %v", err)
+	}
+	code, state, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	stateCookie := &http.Cookie{Name: oidcStateCookie, Value: stateToken}
+	callbackURL := "/auth/oidc/callback?code=" + code + "&state=" + state
+
+	cases := []contractCase{
+		{"register", http.MethodPost, "/register", "/register",
+			gin.H{"username": "john", "email": "john@example.com", "password": "password123"}, "", http.StatusCreated, nil},
+		{"register invalid", http.MethodPost, "/register", "/register", gin.H{"email": "bad"}, "", http.StatusBadRequest, nil},
+		{"login", http.MethodPost, "/login", "/login",
+			gin.H{"email": "john@example.com", "password": "password123"}, "", http.StatusOK, nil},
+		{"login wrong password", http.MethodPost, "/login", "/login",
+			gin.H{"email": "john@example.com", "password": "nope"}, "", http.StatusUnauthorized, nil},
+		{"request verification", http.MethodPost, "/auth/verify-email", "/auth/verify-email", nil, "user", http.StatusAccepted, nil},
+		{"confirm email", http.MethodPost, "/auth/verify-email/confirm", "/auth/verify-email/confirm",
+			gin.H{"token": verifyToken}, "", http.StatusNoContent, nil},
+		{"confirm email bad token", http.MethodPost, "/auth/verify-email/confirm", "/auth/verify-email/confirm",
+			gin.H{"token": "garbage"}, "", http.StatusBadRequest, nil},
+		{"request password reset", http.MethodPost, "/auth/password-reset", "/auth/password-reset",
+			gin.H{"email": "john@example.com"}, "", http.StatusAccepted, nil},
+		{"confirm password reset", http.MethodPost, "/auth/password-reset/confirm", "/auth/password-reset/confirm",
+			gin.H{"token": resetToken, "password": "n3wPassword"}, "", http.StatusNoContent, nil},
+		{"search films", http.MethodGet, "/films", "/films?query=matrix", nil, "", http.StatusOK, nil},
+		{"get film", http.MethodGet, "/films/{id}", "/films/1", nil, "", http.StatusOK, nil},
+		{"get film bad id", http.MethodGet, "/films/{id}", "/films/abc", nil, "", http.StatusBadRequest, nil},
+		{"list similar films", http.MethodGet, "/films/{id}/similar", "/films/1/similar?limit=5", nil, "", http.StatusOK, nil},
+		{"list similar films bad id", http.MethodGet, "/films/{id}/similar", "/films/abc/similar", nil, "", http.StatusBadRequest, nil},
+		{"get chart", http.MethodGet, "/charts/{chart}", "/charts/trending?limit=10", nil, "", http.StatusOK, nil},
+		{"get chart unknown", http.MethodGet, "/charts/{chart}", "/charts/worst", nil, "", http.StatusNotFound, nil},
+		{"list similar films unknown film", http.MethodGet, "/films/{id}/similar", "/films/9/similar", nil, "", http.StatusNotFound, nil},
+		{"get missing film", http.MethodGet, "/films/{id}", "/films/2", nil, "", http.StatusNotFound, nil},
+		{"film events", http.MethodGet, "/films/{id}/events", "/films/1/events", nil, "", http.StatusOK, nil},
+		{"film events bad id", http.MethodGet, "/films/{id}/events", "/films/x/events", nil, "", http.StatusBadRequest, nil},
+		{"missing film events", http.MethodGet, "/films/{id}/events", "/films/2/events", nil, "", http.StatusNotFound, nil},
+		{"all events", http.MethodGet, "/events", "/events", nil, "moderator", http.StatusOK, nil},
+		{"all events forbidden", http.MethodGet, "/events", "/events", nil, "user", http.StatusForbidden, nil},
+		{"all events unauthorized", http.MethodGet, "/events", "/events", nil, "", http.StatusUnauthorized, nil},
+		{"create film", http.MethodPost, "/films", "/films", film, "admin", http.StatusCreated, nil},
+		{"import films", http.MethodPost, "/films/import", "/films/import", gin.H{"films": []gin.H{
+			{"title": "The Matrix", "description": "Sci-fi", "genres": []string{"sci-fi", "action"},
+				"credits": []gin.H{{"name": "Keanu Reeves", "role": "actor"}, {"name": "Lana Wachowski", "role": "director"}}},
+			{"title": "Heat", "description": "Crime drama"},
+		}}, "moderator", http.StatusCreated, nil},
+		{"import films invalid", http.MethodPost, "/films/import", "/films/import",
+			gin.H{"films": []gin.H{{"title": "Heat", "description": "Crime drama", "genres": []string{""}}}}, "moderator", http.StatusBadRequest, nil},
+		{"import films bad credit role", http.MethodPost, "/films/import", "/films/import",
+			gin.H{"films": []gin.H{{"title": "Heat", "description": "Crime drama", "credits": []gin.H{{"name": "Al Pacino", "role": "star"}}}}}, "moderator", http.StatusBadRequest, nil},
+		{"import films empty", http.MethodPost, "/films/import", "/films/import", gin.H{"films": []gin.H{}}, "moderator", http.StatusBadRequest, nil},
+		{"import films forbidden", http.MethodPost, "/films/import", "/films/import", gin.H{"films": []gin.H{}}, "user", http.StatusForbidden, nil},
+		{"import films unauthorized", http.MethodPost, "/films/import", "/films/import", gin.H{"films": []gin.H{}}, "", http.StatusUnauthorized, nil},
+		{"create film forbidden", http.MethodPost, "/films", "/films", film, "user", http.StatusForbidden, nil},
+		{"create film unauthorized", http.MethodPost, "/films", "/films", film, "", http.StatusUnauthorized, nil},
+		{"get film not modified", http.MethodGet, "/films/{id}", "/films/1", nil, "", http.StatusNotModified, nil},
+		{"get film modified", http.MethodGet, "/films/{id}", "/films/1", nil, "", http.StatusOK, nil},
+		{"update film", http.MethodPut, "/films/{id}", "/films/1", film, "moderator", http.StatusOK, nil},
+		{"update film invalid", http.MethodPut, "/films/{id}", "/films/1", gin.H{"title": ""}, "moderator", http.StatusBadRequest, nil},
+		{"update film forbidden", http.MethodPut, "/films/{id}", "/films/1", film, "user", http.StatusForbidden, nil},
+		{"update film unauthorized", http.MethodPut, "/films/{id}", "/films/1", film, "", http.StatusUnauthorized, nil},
+		{"update film not found", http.MethodPut, "/films/{id}", "/films/2", film, "admin", http.StatusNotFound, nil},
+		{"update film stale", http.MethodPut, "/films/{id}", "/films/1", film, "moderator", http.StatusPreconditionFailed, nil},
+		{"update film any version", http.MethodPut, "/films/{id}", "/films/1", film, "moderator", http.StatusOK, nil},
+		{"update film without if-match", http.MethodPut, "/films/{id}", "/films/1", film, "moderator", http.StatusPreconditionRequired, nil},
+		{"delete film", http.MethodDelete, "/films/{id}", "/films/1", nil, "moderator", http.StatusNoContent, nil},
+		{"delete film bad id", http.MethodDelete, "/films/{id}", "/films/abc", nil, "moderator", http.StatusBadRequest, nil},
+		{"delete film not found", http.MethodDelete, "/films/{id}", "/films/2", nil, "moderator", http.StatusNotFound, nil},
+		{"delete film forbidden", http.MethodDelete, "/films/{id}", "/films/1", nil, "user", http.StatusForbidden, nil},
+		{"delete film unauthorized", http.MethodDelete, "/films/{id}", "/films/1", nil, "", http.StatusUnauthorized, nil},
+		{"film cache stats", http.MethodGet, "/admin/cache/films", "/admin/cache/films", nil, "admin", http.StatusOK, nil},
+		{"film cache stats forbidden", http.MethodGet, "/admin/cache/films", "/admin/cache/films", nil, "moderator", http.StatusForbidden, nil},
+		{"film cache stats unauthorized", http.MethodGet, "/admin/cache/films", "/admin/cache/films", nil, "", http.StatusUnauthorized, nil},
+		{"trash", http.MethodGet, "/admin/trash", "/admin/trash?type=film", nil, "admin", http.StatusOK, nil},
+		{"trash bad type", http.MethodGet, "/admin/trash", "/admin/trash?type=user", nil, "admin", http.StatusBadRequest, nil},
+		{"trash forbidden", http.MethodGet, "/admin/trash", "/admin/trash", nil, "moderator", http.StatusForbidden, nil},
+		{"trash unauthorized", http.MethodGet, "/admin/trash", "/admin/trash", nil, "", http.StatusUnauthorized, nil},
+		{"restore from trash", http.MethodPost, "/admin/trash/{type}/{id}/restore", "/admin/trash/film/3/restore",
+			nil, "admin", http.StatusNoContent, nil},
+		{"restore from trash bad type", http.MethodPost, "/admin/trash/{type}/{id}/restore", "/admin/trash/user/3/restore",
+			nil, "admin", http.StatusBadRequest, nil},
+		{"restore from trash not found", http.MethodPost, "/admin/trash/{type}/{id}/restore", "/admin/trash/review/3/restore",
+			nil, "admin", http.StatusNotFound, nil},
+		{"restore from trash forbidden", http.MethodPost, "/admin/trash/{type}/{id}/restore", "/admin/trash/film/3/restore",
+			nil, "moderator", http.StatusForbidden, nil},
+		{"restore from trash unauthorized", http.MethodPost, "/admin/trash/{type}/{id}/restore", "/admin/trash/film/3/restore",
+			nil, "", http.StatusUnauthorized, nil},
+		{"film revisions", http.MethodGet, "/films/{id}/revisions", "/films/1/revisions", nil, "", http.StatusOK, nil},
+		{"film revisions bad id", http.MethodGet, "/films/{id}/revisions", "/films/abc/revisions", nil, "", http.StatusBadRequest, nil},
+		{"film revisions not found", http.MethodGet, "/films/{id}/revisions", "/films/2/revisions", nil, "", http.StatusNotFound, nil},
+		{"diff revisions", http.MethodGet, "/films/{id}/revisions/diff", "/films/1/revisions/diff?from=1&to=2", nil, "", http.StatusOK, nil},
+		{"diff revisions bad query", http.MethodGet, "/films/{id}/revisions/diff", "/films/1/revisions/diff?from=1", nil, "", http.StatusBadRequest, nil},
+		{"diff revisions not found", http.MethodGet, "/films/{id}/revisions/diff", "/films/1/revisions/diff?from=1&to=9", nil, "", http.StatusNotFound, nil},
+		{"rollback film", http.MethodPost, "/films/{id}/revisions/{revision}/rollback", "/films/1/revisions/1/rollback",
+			nil, "admin", http.StatusOK, nil},
+		{"rollback film current", http.MethodPost, "/films/{id}/revisions/{revision}/rollback", "/films/1/revisions/2/rollback",
+			nil, "admin", http.StatusConflict, nil},
+		{"rollback film bad revision", http.MethodPost, "/films/{id}/revisions/{revision}/rollback", "/films/1/revisions/x/rollback",
+			nil, "admin", http.StatusBadRequest, nil},
+		{"rollback film not found", http.MethodPost, "/films/{id}/revisions/{revision}/rollback", "/films/1/revisions/9/rollback",
+			nil, "admin", http.StatusNotFound, nil},
+		{"rollback film stale", http.MethodPost, "/films/{id}/revisions/{revision}/rollback", "/films/1/revisions/1/rollback",
+			nil, "admin", http.StatusPreconditionFailed, nil},
+		{"rollback film without if-match", http.MethodPost, "/films/{id}/revisions/{revision}/rollback", "/films/1/revisions/1/rollback",
+			nil, "admin", http.StatusPreconditionRequired, nil},
+		{"rollback film forbidden", http.MethodPost, "/films/{id}/revisions/{revision}/rollback", "/films/1/revisions/1/rollback",
+			nil, "moderator", http.StatusForbidden, nil},
+		{"rollback film unauthorized", http.MethodPost, "/films/{id}/revisions/{revision}/rollback", "/films/1/revisions/1/rollback",
+			nil, "", http.StatusUnauthorized, nil},
+		{"create review", http.MethodPost, "/films/{id}/reviews", "/films/1/reviews",
+			gin.H{"rating": 9, "comment": "Отличный фильм!"}, "user", http.StatusCreated, nil},
+		{"create review held", http.MethodPost, "/films/{id}/reviews", "/films/1/reviews",
+			gin.H{"rating": 2, "comment": "Режиссёр идиот"}, "user", http.StatusAccepted, nil},
+		{"create review rejected", http.MethodPost, "/films/{id}/reviews", "/films/1/reviews",
+			gin.H{"rating": 1, "comment": "РЕЖИССЁР ИДИОТ, ВСЕ НА bit.ly/free"}, "user", http.StatusUnprocessableEntity, nil},
+		{"list reviews", http.MethodGet, "/films/{id}/reviews", "/films/1/reviews?sort=helpful", nil, "", http.StatusOK, nil},
+		{"list reviews signed in", http.MethodGet, "/films/{id}/reviews", "/films/1/reviews", nil, "user", http.StatusOK, nil},
+		{"list reviews bad sort", http.MethodGet, "/films/{id}/reviews", "/films/1/reviews?sort=random", nil, "", http.StatusBadRequest, nil},
+		{"vote review", http.MethodPost, "/reviews/{id}/vote", "/reviews/7/vote", gin.H{"helpful": true}, "user", http.StatusOK, nil},
+		{"get review", http.MethodGet, "/reviews/{id}", "/reviews/7", nil, "", http.StatusOK, nil},
+		{"get review not modified", http.MethodGet, "/reviews/{id}", "/reviews/7", nil, "", http.StatusNotModified, nil},
+		{"get review bad id", http.MethodGet, "/reviews/{id}", "/reviews/abc", nil, "", http.StatusBadRequest, nil},
+		{"get missing review", http.MethodGet, "/reviews/{id}", "/reviews/99", nil, "", http.StatusNotFound, nil},
+		{"update own review", http.MethodPut, "/reviews/{id}", "/reviews/9",
+			gin.H{"rating": 7, "comment": "Со второго раза понравился"}, "user", http.StatusOK, nil},
+		{"update review invalid", http.MethodPut, "/reviews/{id}", "/reviews/9", gin.H{"rating": 11}, "user", http.StatusBadRequest, nil},
+		{"update review forbidden", http.MethodPut, "/reviews/{id}", "/reviews/7", gin.H{"rating": 7}, "user", http.StatusForbidden, nil},
+		{"update review not found", http.MethodPut, "/reviews/{id}", "/reviews/99", gin.H{"rating": 7}, "user", http.StatusNotFound, nil},
+		{"update review stale", http.MethodPut, "/reviews/{id}", "/reviews/9", gin.H{"rating": 7}, "user", http.StatusPreconditionFailed, nil},
+		{"update review weak etag", http.MethodPut, "/reviews/{id}", "/reviews/9", gin.H{"rating": 7}, "user", http.StatusPreconditionFailed, nil},
+		{"update review without if-match", http.MethodPut, "/reviews/{id}", "/reviews/9", gin.H{"rating": 7}, "user", http.StatusPreconditionRequired, nil},
+		{"update review unauthorized", http.MethodPut, "/reviews/{id}", "/reviews/9", gin.H{"rating": 7}, "", http.StatusUnauthorized, nil},
+		{"delete own review", http.MethodDelete, "/reviews/{id}", "/reviews/9", nil, "user", http.StatusNoContent, nil},
+		{"delete review bad id", http.MethodDelete, "/reviews/{id}", "/reviews/abc", nil, "user", http.StatusBadRequest, nil},
+		{"delete review forbidden", http.MethodDelete, "/reviews/{id}", "/reviews/7", nil, "user", http.StatusForbidden, nil},
+		{"delete review not found", http.MethodDelete, "/reviews/{id}", "/reviews/99", nil, "moderator", http.StatusNotFound, nil},
+		{"delete review unauthorized", http.MethodDelete, "/reviews/{id}", "/reviews/9", nil, "", http.StatusUnauthorized, nil},
+		{"vote own review", http.MethodPost, "/reviews/{id}/vote", "/reviews/9/vote", gin.H{"helpful": true}, "user", http.StatusForbidden, nil},
+		{"vote missing review", http.MethodPost, "/reviews/{id}/vote", "/reviews/8/vote", gin.H{"helpful": false}, "user", http.StatusNotFound, nil},
+		{"vote without value", http.MethodPost, "/reviews/{id}/vote", "/reviews/7/vote", gin.H{}, "user", http.StatusBadRequest, nil},
+		{"list comments", http.MethodGet, "/reviews/{id}/comments", "/reviews/7/comments?page=1&limit=10", nil, "", http.StatusOK, nil},
+		{"list comments bad id", http.MethodGet, "/reviews/{id}/comments", "/reviews/x/comments", nil, "", http.StatusBadRequest, nil},
+		{"list comments missing review", http.MethodGet, "/reviews/{id}/comments", "/reviews/8/comments", nil, "", http.StatusNotFound, nil},
+		{"create comment", http.MethodPost, "/reviews/{id}/comments", "/reviews/7/comments",
+			gin.H{"body": "Согласен"}, "user", http.StatusCreated, nil},
+		{"reply to comment", http.MethodPost, "/reviews/{id}/comments", "/reviews/7/comments",
+			gin.H{"body": "И я", "parent_id": 3}, "user", http.StatusCreated, nil},
+		{"reply to reply", http.MethodPost, "/reviews/{id}/comments", "/reviews/7/comments",
+			gin.H{"body": "И я", "parent_id": 4}, "user", http.StatusBadRequest, nil},
+		{"comment missing review", http.MethodPost, "/reviews/{id}/comments", "/reviews/8/comments",
+			gin.H{"body": "Согласен"}, "user", http.StatusNotFound, nil},
+		{"comment unauthorized", http.MethodPost, "/reviews/{id}/comments", "/reviews/7/comments",
+			gin.H{"body": "Согласен"}, "", http.StatusUnauthorized, nil},
+		{"update comment", http.MethodPatch, "/comments/{id}", "/comments/3", gin.H{"body": "Передумал"}, "user", http.StatusOK, nil},
+		{"update foreign comment", http.MethodPatch, "/comments/{id}", "/comments/5", gin.H{"body": "Передумал"}, "user", http.StatusForbidden, nil},
+		{"update missing comment", http.MethodPatch, "/comments/{id}", "/comments/9", gin.H{"body": "Передумал"}, "user", http.StatusNotFound, nil},
+		{"update comment empty", http.MethodPatch, "/comments/{id}", "/comments/3", gin.H{"body": ""}, "user", http.StatusBadRequest, nil},
+		{"update comment unauthorized", http.MethodPatch, "/comments/{id}", "/comments/3", gin.H{"body": "x"}, "", http.StatusUnauthorized, nil},
+		{"delete comment", http.MethodDelete, "/comments/{id}", "/comments/3", nil, "user", http.StatusNoContent, nil},
+		{"delete foreign comment", http.MethodDelete, "/comments/{id}", "/comments/5", nil, "user", http.StatusForbidden, nil},
+		{"moderator deletes comment", http.MethodDelete, "/comments/{id}", "/comments/5", nil, "moderator", http.StatusNoContent, nil},
+		{"delete missing comment", http.MethodDelete, "/comments/{id}", "/comments/9", nil, "user", http.StatusNotFound, nil},
+		{"delete comment bad id", http.MethodDelete, "/comments/{id}", "/comments/x", nil, "user", http.StatusBadRequest, nil},
+		{"delete comment unauthorized", http.MethodDelete, "/comments/{id}", "/comments/3", nil, "", http.StatusUnauthorized, nil},
+		{"report review", http.MethodPost, "/reviews/{id}/report", "/reviews/7/report",
+			gin.H{"reason": "spoiler", "details": "Раскрывает концовку"}, "user", http.StatusCreated, nil},
+		{"report review bad reason", http.MethodPost, "/reviews/{id}/report", "/reviews/7/report",
+			gin.H{"reason": "boring"}, "user", http.StatusBadRequest, nil},
+		{"report missing review", http.MethodPost, "/reviews/{id}/report", "/reviews/8/report",
+			gin.H{"reason": "spam"}, "user", http.StatusNotFound, nil},
+		{"report comment", http.MethodPost, "/comments/{id}/report", "/comments/3/report",
+			gin.H{"reason": "off_topic"}, "user", http.StatusCreated, nil},
+		{"report comment twice", http.MethodPost, "/comments/{id}/report", "/comments/5/report",
+			gin.H{"reason": "spam"}, "user", http.StatusConflict, nil},
+		{"report hidden comment", http.MethodPost, "/comments/{id}/report", "/comments/6/report",
+			gin.H{"reason": "spam"}, "user", http.StatusNotFound, nil},
+		{"report comment unauthorized", http.MethodPost, "/comments/{id}/report", "/comments/5/report",
+			gin.H{"reason": "spam"}, "", http.StatusUnauthorized, nil},
+		{"list reports", http.MethodGet, "/moderation/reports", "/moderation/reports", nil, "moderator", http.StatusOK, nil},
+		{"list reports bad status", http.MethodGet, "/moderation/reports", "/moderation/reports?status=new", nil, "admin", http.StatusBadRequest, nil},
+		{"list reports forbidden", http.MethodGet, "/moderation/reports", "/moderation/reports", nil, "user", http.StatusForbidden, nil},
+		{"hide reported", http.MethodPost, "/moderation/reports/{id}/hide", "/moderation/reports/1/hide",
+			gin.H{"note": "Реклама"}, "moderator", http.StatusOK, nil},
+		{"hide reported forbidden", http.MethodPost, "/moderation/reports/{id}/hide", "/moderation/reports/1/hide",
+			nil, "user", http.StatusForbidden, nil},
+		{"hide missing report", http.MethodPost, "/moderation/reports/{id}/hide", "/moderation/reports/99/hide",
+			nil, "moderator", http.StatusNotFound, nil},
+		{"restore reported", http.MethodPost, "/moderation/reports/{id}/restore", "/moderation/reports/2/restore",
+			nil, "moderator", http.StatusOK, nil},
+		{"restore visible content", http.MethodPost, "/moderation/reports/{id}/restore", "/moderation/reports/1/restore",
+			nil, "moderator", http.StatusConflict, nil},
+		{"dismiss report", http.MethodPost, "/moderation/reports/{id}/dismiss", "/moderation/reports/1/dismiss",
+			nil, "admin", http.StatusOK, nil},
+		{"dismiss closed report", http.MethodPost, "/moderation/reports/{id}/dismiss", "/moderation/reports/3/dismiss",
+			nil, "moderator", http.StatusConflict, nil},
+		{"dismiss report bad id", http.MethodPost, "/moderation/reports/{id}/dismiss", "/moderation/reports/x/dismiss",
+			nil, "moderator", http.StatusBadRequest, nil},
+		{"moderation log", http.MethodGet, "/moderation/log", "/moderation/log?page=1", nil, "moderator", http.StatusOK, nil},
+		{"moderation log forbidden", http.MethodGet, "/moderation/log", "/moderation/log", nil, "user", http.StatusForbidden, nil},
+		{"register second user", http.MethodPost, "/register", "/register",
+			gin.H{"username": "jane", "email": "jane@example.com", "password": "password123"}, "", http.StatusCreated, nil},
+		{"set role", http.MethodPut, "/admin/users/{id}/role", "/admin/users/2/role",
+			gin.H{"role": "moderator"}, "admin", http.StatusOK, nil},
+		{"set role invalid", http.MethodPut, "/admin/users/{id}/role", "/admin/users/2/role",
+			gin.H{"role": "owner"}, "admin", http.StatusBadRequest, nil},
+		{"set own role", http.MethodPut, "/admin/users/{id}/role", "/admin/users/1/role",
+			gin.H{"role": "user"}, "admin", http.StatusConflict, nil},
+		{"set role not found", http.MethodPut, "/admin/users/{id}/role", "/admin/users/99/role",
+			gin.H{"role": "user"}, "admin", http.StatusNotFound, nil},
+		{"set role forbidden", http.MethodPut, "/admin/users/{id}/role", "/admin/users/2/role",
+			gin.H{"role": "admin"}, "moderator", http.StatusForbidden, nil},
+		{"set role unauthorized", http.MethodPut, "/admin/users/{id}/role", "/admin/users/2/role",
+			gin.H{"role": "admin"}, "", http.StatusUnauthorized, nil},
+		{"audit log", http.MethodGet, "/admin/audit", "/admin/audit?entity_type=film&from=2024-01-01T00:00:00Z", nil, "admin", http.StatusOK, nil},
+		{"audit log bad filter", http.MethodGet, "/admin/audit", "/admin/audit?from=yesterday", nil, "admin", http.StatusBadRequest, nil},
+		{"audit log forbidden", http.MethodGet, "/admin/audit", "/admin/audit", nil, "moderator", http.StatusForbidden, nil},
+		{"audit log unauthorized", http.MethodGet, "/admin/audit", "/admin/audit", nil, "", http.StatusUnauthorized, nil},
+		{"create webhook", http.MethodPost, "/admin/webhooks", "/admin/webhooks",
+			gin.H{"url": "https://partner.example.com/hooks", "event_types": []string{"film.created", "review.created"}}, "admin", http.StatusCreated, nil},
+		{"create webhook bad event type", http.MethodPost, "/admin/webhooks", "/admin/webhooks",
+			gin.H{"url": "https://partner.example.com/hooks", "event_types": []string{"film.deleted"}}, "admin", http.StatusBadRequest, nil},
+		{"create webhook forbidden", http.MethodPost, "/admin/webhooks", "/admin/webhooks",
+			gin.H{"url": "https://partner.example.com/hooks", "event_types": []string{"film.created"}}, "moderator", http.StatusForbidden, nil},
+		{"create webhook unauthorized", http.MethodPost, "/admin/webhooks", "/admin/webhooks",
+			gin.H{"url": "https://partner.example.com/hooks", "event_types": []string{"film.created"}}, "", http.StatusUnauthorized, nil},
+		{"list webhooks", http.MethodGet, "/admin/webhooks", "/admin/webhooks", nil, "admin", http.StatusOK, nil},
+		{"list webhooks forbidden", http.MethodGet, "/admin/webhooks", "/admin/webhooks", nil, "user", http.StatusForbidden, nil},
+		{"list webhooks unauthorized", http.MethodGet, "/admin/webhooks", "/admin/webhooks", nil, "", http.StatusUnauthorized, nil},
+		{"webhook deliveries", http.MethodGet, "/admin/webhooks/{id}/deliveries", "/admin/webhooks/1/deliveries?status=dead",
+			nil, "admin", http.StatusOK, nil},
+		{"webhook deliveries bad status", http.MethodGet, "/admin/webhooks/{id}/deliveries", "/admin/webhooks/1/deliveries?status=lost",
+			nil, "admin", http.StatusBadRequest, nil},
+		{"webhook deliveries not found", http.MethodGet, "/admin/webhooks/{id}/deliveries", "/admin/webhooks/9/deliveries",
+			nil, "admin", http.StatusNotFound, nil},
+		{"webhook deliveries forbidden", http.MethodGet, "/admin/webhooks/{id}/deliveries", "/admin/webhooks/1/deliveries",
+			nil, "moderator", http.StatusForbidden, nil},
+		{"webhook deliveries unauthorized", http.MethodGet, "/admin/webhooks/{id}/deliveries", "/admin/webhooks/1/deliveries",
+			nil, "", http.StatusUnauthorized, nil},
+		{"redeliver webhook", http.MethodPost, "/admin/webhooks/{id}/deliveries/{delivery}/redeliver", "/admin/webhooks/1/deliveries/10/redeliver",
+			nil, "admin", http.StatusAccepted, nil},
+		{"redeliver webhook bad id", http.MethodPost, "/admin/webhooks/{id}/deliveries/{delivery}/redeliver", "/admin/webhooks/1/deliveries/x/redeliver",
+			nil, "admin", http.StatusBadRequest, nil},
+		{"redeliver webhook not found", http.MethodPost, "/admin/webhooks/{id}/deliveries/{delivery}/redeliver", "/admin/webhooks/2/deliveries/10/redeliver",
+			nil, "admin", http.StatusNotFound, nil},
+		{"redeliver webhook forbidden", http.MethodPost, "/admin/webhooks/{id}/deliveries/{delivery}/redeliver", "/admin/webhooks/1/deliveries/10/redeliver",
+			nil, "moderator", http.StatusForbidden, nil},
+		{"redeliver webhook unauthorized", http.MethodPost, "/admin/webhooks/{id}/deliveries/{delivery}/redeliver", "/admin/webhooks/1/deliveries/10/redeliver",
+			nil, "", http.StatusUnauthorized, nil},
+		{"delete webhook", http.MethodDelete, "/admin/webhooks/{id}", "/admin/webhooks/1", nil, "admin", http.StatusNoContent, nil},
+		{"delete webhook bad id", http.MethodDelete, "/admin/webhooks/{id}", "/admin/webhooks/x", nil, "admin", http.StatusBadRequest, nil},
+		{"delete webhook not found", http.MethodDelete, "/admin/webhooks/{id}", "/admin/webhooks/9", nil, "admin", http.StatusNotFound, nil},
+		{"delete webhook forbidden", http.MethodDelete, "/admin/webhooks/{id}", "/admin/webhooks/1", nil, "moderator", http.StatusForbidden, nil},
+		{"delete webhook unauthorized", http.MethodDelete, "/admin/webhooks/{id}", "/admin/webhooks/1", nil, "", http.StatusUnauthorized, nil},
+		{"create api key", http.MethodPost, "/me/api-keys", "/me/api-keys",
+			gin.H{"name": "export", "scopes": []string{"read"}}, "user", http.StatusCreated, nil},
+		{"create api key invalid", http.MethodPost, "/me/api-keys", "/me/api-keys",
+			gin.H{"name": "export", "scopes": []string{"admin"}}, "user", http.StatusBadRequest, nil},
+		{"list api keys", http.MethodGet, "/me/api-keys", "/me/api-keys", nil, "user", http.StatusOK, nil},
+		{"list api keys unauthorized", http.MethodGet, "/me/api-keys", "/me/api-keys", nil, "", http.StatusUnauthorized, nil},
+		{"revoke api key", http.MethodDelete, "/me/api-keys/{id}", "/me/api-keys/1", nil, "user", http.StatusNoContent, nil},
+		{"revoke api key again", http.MethodDelete, "/me/api-keys/{id}", "/me/api-keys/1", nil, "user", http.StatusNotFound, nil},
+		{"revoke api key bad id", http.MethodDelete, "/me/api-keys/{id}", "/me/api-keys/x", nil, "user", http.StatusBadRequest, nil},
+		{"list notifications", http.MethodGet, "/me/notifications", "/me/notifications?unread=true", nil, "user", http.StatusOK, nil},
+		{"list notifications unauthorized", http.MethodGet, "/me/notifications", "/me/notifications", nil, "", http.StatusUnauthorized, nil},
+		{"mark notification read", http.MethodPost, "/me/notifications/{id}/read", "/me/notifications/1/read",
+			nil, "user", http.StatusNoContent, nil},
+		{"mark notification read bad id", http.MethodPost, "/me/notifications/{id}/read", "/me/notifications/x/read",
+			nil, "user", http.StatusBadRequest, nil},
+		{"mark notification read not found", http.MethodPost, "/me/notifications/{id}/read", "/me/notifications/2/read",
+			nil, "user", http.StatusNotFound, nil},
+		{"mark notification read unauthorized", http.MethodPost, "/me/notifications/{id}/read", "/me/notifications/1/read",
+			nil, "", http.StatusUnauthorized, nil},
+		{"mark all notifications read", http.MethodPost, "/me/notifications/read-all", "/me/notifications/read-all",
+			nil, "user", http.StatusNoContent, nil},
+		{"mark all notifications read unauthorized", http.MethodPost, "/me/notifications/read-all", "/me/notifications/read-all",
+			nil, "", http.StatusUnauthorized, nil},
+		{"get notification preferences", http.MethodGet, "/me/notification-preferences", "/me/notification-preferences",
+			nil, "user", http.StatusOK, nil},
+		{"get notification preferences unauthorized", http.MethodGet, "/me/notification-preferences", "/me/notification-preferences",
+			nil, "", http.StatusUnauthorized, nil},
+		{"update notification preferences", http.MethodPut, "/me/notification-preferences", "/me/notification-preferences",
+			gin.H{"like": false}, "user", http.StatusOK, nil},
+		{"update notification preferences invalid", http.MethodPut, "/me/notification-preferences", "/me/notification-preferences",
+			gin.H{"like": "no"}, "user", http.StatusBadRequest, nil},
+		{"update notification preferences unauthorized", http.MethodPut, "/me/notification-preferences", "/me/notification-preferences",
+			gin.H{"like": false}, "", http.StatusUnauthorized, nil},
+		{"list recommendations", http.MethodGet, "/me/recommendations", "/me/recommendations?limit=5", nil, "user", http.StatusOK, nil},
+		{"list recommendations unauthorized", http.MethodGet, "/me/recommendations", "/me/recommendations", nil, "", http.StatusUnauthorized, nil},
+		{"list watchlist", http.MethodGet, "/me/watchlist", "/me/watchlist", nil, "user", http.StatusOK, nil},
+		{"list watchlist unauthorized", http.MethodGet, "/me/watchlist", "/me/watchlist", nil, "", http.StatusUnauthorized, nil},
+		{"add to watchlist", http.MethodPut, "/me/watchlist/{film_id}", "/me/watchlist/1", nil, "user", http.StatusNoContent, nil},
+		{"add to watchlist bad id", http.MethodPut, "/me/watchlist/{film_id}", "/me/watchlist/x", nil, "user", http.StatusBadRequest, nil},
+		{"add to watchlist not found", http.MethodPut, "/me/watchlist/{film_id}", "/me/watchlist/9", nil, "user", http.StatusNotFound, nil},
+		{"add to watchlist unauthorized", http.MethodPut, "/me/watchlist/{film_id}", "/me/watchlist/1", nil, "", http.StatusUnauthorized, nil},
+		{"remove from watchlist", http.MethodDelete, "/me/watchlist/{film_id}", "/me/watchlist/1", nil, "user", http.StatusNoContent, nil},
+		{"remove from watchlist bad id", http.MethodDelete, "/me/watchlist/{film_id}", "/me/watchlist/x", nil, "user", http.StatusBadRequest, nil},
+		{"remove from watchlist not found", http.MethodDelete, "/me/watchlist/{film_id}", "/me/watchlist/9",
+			nil, "user", http.StatusNotFound, nil},
+		{"remove from watchlist unauthorized", http.MethodDelete, "/me/watchlist/{film_id}", "/me/watchlist/1",
+			nil, "", http.StatusUnauthorized, nil},
+		{"discover lists", http.MethodGet, "/lists", "/lists?film_id=1&page=1", nil, "", http.StatusOK, nil},
+		{"discover lists signed in", http.MethodGet, "/lists", "/lists", nil, "user", http.StatusOK, nil},
+		{"discover lists bad film id", http.MethodGet, "/lists", "/lists?film_id=x", nil, "", http.StatusBadRequest, nil},
+		{"my lists", http.MethodGet, "/me/lists", "/me/lists", nil, "user", http.StatusOK, nil},
+		{"my lists unauthorized", http.MethodGet, "/me/lists", "/me/lists", nil, "", http.StatusUnauthorized, nil},
+		{"get list", http.MethodGet, "/lists/{id}", "/lists/1", nil, "", http.StatusOK, nil},
+		{"get list bad id", http.MethodGet, "/lists/{id}", "/lists/x", nil, "", http.StatusBadRequest, nil},
+		{"get private list", http.MethodGet, "/lists/{id}", "/lists/3", nil, "user", http.StatusNotFound, nil},
+		{"create list", http.MethodPost, "/lists", "/lists", gin.H{"title": "Cyberpunk"}, "user", http.StatusCreated, nil},
+		{"create list invalid", http.MethodPost, "/lists", "/lists",
+			gin.H{"title": "Cyberpunk", "visibility": "friends"}, "user", http.StatusBadRequest, nil},
+		{"create list unauthorized", http.MethodPost, "/lists", "/lists", gin.H{"title": "Cyberpunk"}, "", http.StatusUnauthorized, nil},
+		{"update list", http.MethodPut, "/lists/{id}", "/lists/1",
+			gin.H{"title": "Cyberpunk", "visibility": "unlisted"}, "user", http.StatusOK, nil},
+		{"update list invalid", http.MethodPut, "/lists/{id}", "/lists/1", gin.H{"title": ""}, "user", http.StatusBadRequest, nil},
+		{"update list forbidden", http.MethodPut, "/lists/{id}", "/lists/2", gin.H{"title": "Mine"}, "user", http.StatusForbidden, nil},
+		{"update list not found", http.MethodPut, "/lists/{id}", "/lists/9", gin.H{"title": "Mine"}, "user", http.StatusNotFound, nil},
+		{"update list unauthorized", http.MethodPut, "/lists/{id}", "/lists/1", gin.H{"title": "Mine"}, "", http.StatusUnauthorized, nil},
+		{"delete list", http.MethodDelete, "/lists/{id}", "/lists/1", nil, "user", http.StatusNoContent, nil},
+		{"delete list bad id", http.MethodDelete, "/lists/{id}", "/lists/x", nil, "user", http.StatusBadRequest, nil},
+		{"delete list forbidden", http.MethodDelete, "/lists/{id}", "/lists/2", nil, "user", http.StatusForbidden, nil},
+		{"delete list not found", http.MethodDelete, "/lists/{id}", "/lists/9", nil, "user", http.StatusNotFound, nil},
+		{"delete list unauthorized", http.MethodDelete, "/lists/{id}", "/lists/1", nil, "", http.StatusUnauthorized, nil},
+		{"add list entry", http.MethodPost, "/lists/{id}/entries", "/lists/1/entries",
+			gin.H{"film_id": 1, "note": "Where it started"}, "user", http.StatusCreated, nil},
+		{"add list entry invalid", http.MethodPost, "/lists/{id}/entries", "/lists/1/entries", gin.H{}, "user", http.StatusBadRequest, nil},
+		{"add list entry forbidden", http.MethodPost, "/lists/{id}/entries", "/lists/2/entries",
+			gin.H{"film_id": 1}, "user", http.StatusForbidden, nil},
+		{"add list entry unknown film", http.MethodPost, "/lists/{id}/entries", "/lists/1/entries",
+			gin.H{"film_id": 9}, "user", http.StatusNotFound, nil},
+		{"add list entry full", http.MethodPost, "/lists/{id}/entries", "/lists/4/entries",
+			gin.H{"film_id": 1}, "user", http.StatusConflict, nil},
+		{"add list entry unauthorized", http.MethodPost, "/lists/{id}/entries", "/lists/1/entries",
+			gin.H{"film_id": 1}, "", http.StatusUnauthorized, nil},
+		{"update list entry", http.MethodPatch, "/lists/{id}/entries/{film_id}", "/lists/1/entries/1",
+			gin.H{"note": "Still the best", "position": 1}, "user", http.StatusOK, nil},
+		{"update list entry invalid", http.MethodPatch, "/lists/{id}/entries/{film_id}", "/lists/1/entries/1",
+			gin.H{"position": 0}, "user", http.StatusBadRequest, nil},
+		{"update list entry forbidden", http.MethodPatch, "/lists/{id}/entries/{film_id}", "/lists/2/entries/1",
+			gin.H{"position": 1}, "user", http.StatusForbidden, nil},
+		{"update list entry not in list", http.MethodPatch, "/lists/{id}/entries/{film_id}", "/lists/1/entries/9",
+			gin.H{"position": 1}, "user", http.StatusNotFound, nil},
+		{"update list entry unauthorized", http.MethodPatch, "/lists/{id}/entries/{film_id}", "/lists/1/entries/1",
+			gin.H{"position": 1}, "", http.StatusUnauthorized, nil},
+		{"remove list entry", http.MethodDelete, "/lists/{id}/entries/{film_id}", "/lists/1/entries/1", nil, "user", http.StatusNoContent, nil},
+		{"remove list entry bad id", http.MethodDelete, "/lists/{id}/entries/{film_id}", "/lists/1/entries/x",
+			nil, "user", http.StatusBadRequest, nil},
+		{"remove list entry forbidden", http.MethodDelete, "/lists/{id}/entries/{film_id}", "/lists/2/entries/1",
+			nil, "user", http.StatusForbidden, nil},
+		{"remove list entry not in list", http.MethodDelete, "/lists/{id}/entries/{film_id}", "/lists/1/entries/9",
+			nil, "user", http.StatusNotFound, nil},
+		{"remove list entry unauthorized", http.MethodDelete, "/lists/{id}/entries/{film_id}", "/lists/1/entries/1",
+			nil, "", http.StatusUnauthorized, nil},
+		{"reorder list", http.MethodPut, "/lists/{id}/order", "/lists/1/order", gin.H{"film_ids": []int{1}}, "user", http.StatusNoContent, nil},
+		{"reorder list mismatch", http.MethodPut, "/lists/{id}/order", "/lists/1/order",
+			gin.H{"film_ids": []int{1, 2}}, "user", http.StatusBadRequest, nil},
+		{"reorder list forbidden", http.MethodPut, "/lists/{id}/order", "/lists/2/order",
+			gin.H{"film_ids": []int{1}}, "user", http.StatusForbidden, nil},
+		{"reorder list not found", http.MethodPut, "/lists/{id}/order", "/lists/9/order",
+			gin.H{"film_ids": []int{1}}, "user", http.StatusNotFound, nil},
+		{"reorder list unauthorized", http.MethodPut, "/lists/{id}/order", "/lists/1/order",
+			gin.H{"film_ids": []int{1}}, "", http.StatusUnauthorized, nil},
+		{"add list collaborator", http.MethodPut, "/lists/{id}/collaborators/{user_id}", "/lists/1/collaborators/2",
+			nil, "user", http.StatusNoContent, nil},
+		{"add list collaborator owner", http.MethodPut, "/lists/{id}/collaborators/{user_id}", "/lists/1/collaborators/1",
+			nil, "user", http.StatusBadRequest, nil},
+		{"add list collaborator forbidden", http.MethodPut, "/lists/{id}/collaborators/{user_id}", "/lists/2/collaborators/1",
+			nil, "user", http.StatusForbidden, nil},
+		{"add list collaborator unknown user", http.MethodPut, "/lists/{id}/collaborators/{user_id}", "/lists/1/collaborators/99",
+			nil, "user", http.StatusNotFound, nil},
+		{"add list collaborator unauthorized", http.MethodPut, "/lists/{id}/collaborators/{user_id}", "/lists/1/collaborators/2",
+			nil, "", http.StatusUnauthorized, nil},
+		{"remove list collaborator", http.MethodDelete, "/lists/{id}/collaborators/{user_id}", "/lists/1/collaborators/2",
+			nil, "user", http.StatusNoContent, nil},
+		{"remove list collaborator bad id", http.MethodDelete, "/lists/{id}/collaborators/{user_id}", "/lists/1/collaborators/x",
+			nil, "user", http.StatusBadRequest, nil},
+		{"remove list collaborator forbidden", http.MethodDelete, "/lists/{id}/collaborators/{user_id}", "/lists/2/collaborators/2",
+			nil, "user", http.StatusForbidden, nil},
+		{"remove list collaborator not collaborator", http.MethodDelete, "/lists/{id}/collaborators/{user_id}", "/lists/1/collaborators/3",
+			nil, "user", http.StatusNotFound, nil},
+		{"remove list collaborator unauthorized", http.MethodDelete, "/lists/{id}/collaborators/{user_id}", "/lists/1/collaborators/2",
+			nil, "", http.StatusUnauthorized, nil},
+		{"like list", http.MethodPut, "/lists/{id}/like", "/lists/2/like", nil, "user", http.StatusNoContent, nil},
+		{"like list bad id", http.MethodPut, "/lists/{id}/like", "/lists/x/like", nil, "user", http.StatusBadRequest, nil},
+		{"like private list", http.MethodPut, "/lists/{id}/like", "/lists/3/like", nil, "user", http.StatusNotFound, nil},
+		{"like list unauthorized", http.MethodPut, "/lists/{id}/like", "/lists/2/like", nil, "", http.StatusUnauthorized, nil},
+		{"unlike list", http.MethodDelete, "/lists/{id}/like", "/lists/2/like", nil, "user", http.StatusNoContent, nil},
+		{"unlike list bad id", http.MethodDelete, "/lists/{id}/like", "/lists/x/like", nil, "user", http.StatusBadRequest, nil},
+		{"unlike list not found", http.MethodDelete, "/lists/{id}/like", "/lists/9/like", nil, "user", http.StatusNotFound, nil},
+		{"unlike list unauthorized", http.MethodDelete, "/lists/{id}/like", "/lists/2/like", nil, "", http.StatusUnauthorized, nil},
+		{"jwks", http.MethodGet, "/.well-known/jwks.json", "/.well-known/jwks.json", nil, "", http.StatusOK, nil},
+		{"oidc login", http.MethodGet, "/auth/oidc/login", "/auth/oidc/login", nil, "", http.StatusFound, nil},
+		{"oidc callback bad state", http.MethodGet, "/auth/oidc/callback", "/auth/oidc/callback?code=x&state=y",
+			nil, "", http.StatusBadRequest, nil},
+		{"oidc callback", http.MethodGet, "/auth/oidc/callback", callbackURL, nil, "", http.StatusOK, stateCookie},
+		{"link identity", http.MethodPost, "/me/identities/link", "/me/identities/link", nil, "user", http.StatusOK, nil},
+		{"list identities", http.MethodGet, "/me/identities", "/me/identities", nil, "user", http.StatusOK, nil},
+		{"unlink identity", http.MethodDelete, "/me/identities/{provider}", "/me/identities/oidc",
+			nil, "user", http.StatusNoContent, nil},
+		{"unlink missing identity", http.MethodDelete, "/me/identities/{provider}", "/me/identities/oidc",
+			nil, "user", http.StatusNotFound, nil},
+	}
+
+	covered := map[string]bool{}
+	for _, tc := range cases {
+		covered[strings.ToLower(tc.method)+" "+tc.route] = true
+		t.Run(tc.name, func(t *testing.T) {
+			resp := doContractRequest(t, router, tc)
+			if resp.Code != tc.want {
+				t.Fatalf("expected %d, got %d: %s", tc.want, resp.Code, resp.Body.String())
+			}
+
+			op, ok := spec.Paths[tc.route][strings.ToLower(tc.method)]
+			if !ok {
+				t.Fatalf("%s %s is not documented", tc.method, tc.route)
+			}
+			documented, ok := op.Responses[fmt.Sprint(resp.Code)]
+			if !ok {
+				t.Fatalf("status %d of %s %s is not documented", resp.Code, tc.method, tc.route)
+			}
+			media, hasBody := documented.Content["application/json"]
+			if !hasBody {
+				// net/http adds a short HTML body to GET redirects.
+				if resp.Body.Len() > 0 && strings.HasPrefix(resp.Header().Get("Content-Type"), "application/json") {
+					t.Fatalf("spec documents no body, got %s", resp.Body.String())
+				}
+				return
+			}
+			var body any
+			if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+				t.Fatalf("response is not JSON: %v", err)
+			}
+			if err := validateSchema(&spec, media.Schema, body, "$"); err != nil {
+				t.Errorf("response drifted from spec: %v\nbody: %s", err, resp.Body.String())
+			}
+		})
+	}
+
+	var missing []string
+	for path, ops := range spec.Paths {
+		for method := range ops {
+			if !covered[method+" "+path] {
+				missing = append(missing, strings.ToUpper(method)+" "+path)
+			}
+		}
+	}
+	sort.Strings(missing)
+	if len(missing) > 0 {
+		t.Errorf("documented operations without a contract case: %v", missing)
+	}
+}
+
+func doContractRequest(t *testing.T, router http.Handler, tc contractCase) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	if tc.body != nil {
+		if err := json.NewEncoder(&body).Encode(tc.body); err != nil {
+			t.Fatalf("encode body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(tc.method, tc.url, &body)
+	req.Header.Set("Content-Type", "application/json")
+	if tc.role != "" {
+		token, err := jwtpkg.GenerateToken(1, tc.role)
+		if err != nil {
+			t.Fatalf("generate token: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if tc.cookie != nil {
+		req.AddCookie(tc.cookie)
+	}
+	for name, values := range contractHeaders[tc.name] {
+		req.Header[name] = values
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+// validateSchema checks v against the subset of JSON Schema emitted by
+// cmd/swaggen: $ref, type, nullable, properties, required, items and
+// additionalProperties.
+func validateSchema(spec *openAPISpec, schema map[string]any, v any, at string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		resolved, ok := spec.Components.Schemas[name]
+		if !ok {
+			return fmt.Errorf("%s: unresolved $ref %s", at, ref)
+		}
+		return validateSchema(spec, resolved, v, at)
+	}
+
+	if v == nil && schema["nullable"] == true {
+		return nil
+	}
+	switch schema["type"] {
+	case nil:
+		return nil
+	case "string":
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("%s: expected string, got %T", at, v)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean, got %T", at, v)
+		}
+	case "number":
+		if _, ok := v.(float64); !ok {
+			return fmt.Errorf("%s: expected number, got %T", at, v)
+		}
+	case "integer":
+		n, ok := v.(float64)
+		if !ok || n != float64(int64(n)) {
+			return fmt.Errorf("%s: expected integer, got %v", at, v)
+		}
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("%s: expected array, got %T", at, v)
+		}
+		items, _ := schema["items"].(map[string]any)
+		for i, item := range arr {
+			if err := validateSchema(spec, items, item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected object, got %T", at, v)
+		}
+		return validateObject(spec, schema, obj, at)
+	}
+	return nil
+}
+
+func validateObject(spec *openAPISpec, schema, obj map[string]any, at string) error {
+	required, _ := schema["required"].([]any)
+	for _, r := range required {
+		if _, ok := obj[r.(string)]; !ok {
+			return fmt.Errorf("%s: missing required property %q", at, r)
+		}
+	}
+	props, _ := schema["properties"].(map[string]any)
+	extra, _ := schema["additionalProperties"].(map[string]any)
+	for key, val := range obj {
+		propSchema, ok := props[key].(map[string]any)
+		switch {
+		case ok:
+		case extra != nil:
+			propSchema = extra
+		case props != nil:
+			return fmt.Errorf("%s: undocumented property %q", at, key)
+		default:
+			continue
+		}
+		if err := validateSchema(spec, propSchema, val, at+"."+key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
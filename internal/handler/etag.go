@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/internal/models"
+	"filmhub/internal/service"
+)
+
+// filmETag starts with the film's version, which is what If-Match is
+// checked against, followed by its rating, which changes with reviews, and
+// a hash of its genres and tags, which are replaced without a new version.
+func filmETag(f *models.Film) string {
+	h := fnv.New32a()
+	for _, g := range f.Genres {
+		h.Write([]byte(g))
+		h.Write([]byte{0})
+	}
+	// A separator keeps a genre from hashing like a tag of the same name.
+	h.Write([]byte{1})
+	for _, t := range f.Tags {
+		h.Write([]byte(t))
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf(`"%d-%s-%x"`, f.Version, strconv.FormatFloat(float64(f.Rating), 'f', -1, 32), h.Sum32())
+}
+
+// reviewETag starts with the review's version, which is what If-Match is
+// checked against, followed by the counters that change without an edit so
+// that revalidation picks them up.
+func reviewETag(r *models.Review) string {
+	return fmt.Sprintf(`"%d-%d-%d-%d"`, r.Version, r.HelpfulCount, r.UnhelpfulCount, r.CommentCount)
+}
+
+// notModified sets the ETag header and answers 304 when If-None-Match
+// already names it. Comparison is weak, as RFC 9110 prescribes for
+// If-None-Match.
+func notModified(c *gin.Context, etag string) bool {
+	c.Header("ETag", etag)
+	for _, tag := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion returns the version named by the If-Match header of an
+// update, or service.AnyVersion for "*", which RFC 9110 matches against any
+// current representation. A missing header aborts with 428 and a tag that is
+// not one of our ETags with 412; ok is false in both cases.
+func ifMatchVersion(c *gin.Context) (version int, ok bool) {
+	tag := strings.TrimSpace(c.GetHeader("If-Match"))
+	if tag == "" {
+		c.AbortWithStatusJSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header is required; send the ETag of the resource"})
+		return 0, false
+	}
+	if tag == "*" {
+		return service.AnyVersion, true
+	}
+	v, _, _ := strings.Cut(strings.Trim(tag, `"`), "-")
+	version, err := strconv.Atoi(v)
+	// Versions start at 1; anything lower is not a tag we handed out.
+	if err != nil || version < 1 || strings.HasPrefix(tag, "W/") {
+		c.AbortWithStatusJSON(http.StatusPreconditionFailed, gin.H{"error": "If-Match does not match the current version"})
+		return 0, false
+	}
+	return version, true
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/internal/models"
+	"filmhub/internal/service"
+)
+
+func TestFilmETag_ChangesWithGenres(t *testing.T) {
+	f := &models.Film{Version: 3, Rating: 8.5, Genres: []string{"action", "sci-fi"}}
+	before := filmETag(f)
+	f.Genres = []string{"action"}
+	if filmETag(f) == before {
+		t.Fatalf("expected a genre change to change the ETag %s", before)
+	}
+	f.Genres = []string{"action", "sci-fi"}
+	if filmETag(f) != before {
+		t.Fatal("expected the same genres to give the same ETag")
+	}
+}
+
+func TestFilmETag_ChangesWithTags(t *testing.T) {
+	f := &models.Film{Version: 3, Genres: []string{"sci-fi"}}
+	before := filmETag(f)
+	f.Genres, f.Tags = nil, []string{"sci-fi"}
+	if filmETag(f) == before {
+		t.Fatalf("expected a genre turned tag to change the ETag %s", before)
+	}
+}
+
+func TestIfMatchVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		header  string
+		version int
+		status  int
+	}{
+		{`"3-8.5-1a2b"`, 3, 0},
+		{"*", service.AnyVersion, 0},
+		{"", 0, http.StatusPreconditionRequired},
+		{`W/"3-8.5-1a2b"`, 0, http.StatusPreconditionFailed},
+		{`"abc"`, 0, http.StatusPreconditionFailed},
+		// No version is 0 or less, so these must not pass as the wildcard.
+		{`"0"`, 0, http.StatusPreconditionFailed},
+		{`"0-0-0-0"`, 0, http.StatusPreconditionFailed},
+		{`"-1"`, 0, http.StatusPreconditionFailed},
+	}
+	for _, tc := range cases {
+		resp := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(resp)
+		c.Request = httptest.NewRequest(http.MethodPut, "/films/1", nil)
+		c.Request.Header.Set("If-Match", tc.header)
+		version, ok := ifMatchVersion(c)
+		if tc.status == 0 {
+			if !ok || version != tc.version {
+				t.Errorf("If-Match %s: expected version %d, got %d, %v", tc.header, tc.version, version, ok)
+			}
+			continue
+		}
+		if ok || resp.Code != tc.status {
+			t.Errorf("If-Match %s: expected %d, got %d", tc.header, tc.status, resp.Code)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/internal/service"
+	"filmhub/pkg/pubsub"
+)
+
+// EventsHandler streams live updates as Server-Sent Events.
+type EventsHandler struct {
+	hub       *pubsub.Hub
+	films     *service.FilmService
+	heartbeat time.Duration
+}
+
+// NewEventsHandler streams from hub, sending a comment every heartbeat so
+// that proxies keep idle streams open.
+func NewEventsHandler(hub *pubsub.Hub, films *service.FilmService, heartbeat time.Duration) *EventsHandler {
+	return &EventsHandler{hub: hub, films: films, heartbeat: heartbeat}
+}
+
+// FilmEvents godoc
+// @Summary Обновления фильма в реальном времени
+// @Description Поток Server-Sent Events (text/event-stream) страницы фильма: review.created, review.updated, review.removed, film.updated, film.rating. Данные событий — JSON. После переподключения с заголовком Last-Event-ID пропущенные события досылаются из буфера; если их там уже нет, первым приходит событие reset — состояние нужно загрузить заново
+// @Tags films
+// @Produce text/event-stream
+// @Param id path int true "ID фильма"
+// @Param Last-Event-ID header int false "ID последнего полученного события"
+// @Success 200 "Поток событий"
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse "Фильм не найден"
+// @Failure 500 {object} errorResponse
+// @Router /films/{id}/events [get]
+func (h *EventsHandler) FilmEvents(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if _, err := h.films.GetFilm(c.Request.Context(), id); err != nil {
+		if errors.Is(err, service.ErrFilmNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.stream(c, service.FilmTopic(id))
+}
+
+// AllEvents godoc
+// @Summary Все обновления в реальном времени
+// @Description Поток Server-Sent Events по всем фильмам и событиям модерации (review.held). Формат и возобновление — как у /films/{id}/events. Доступно модераторам и администраторам
+// @Tags moderation
+// @Produce text/event-stream
+// @Param Last-Event-ID header int false "ID последнего полученного события"
+// @Security BearerAuth
+// @Success 200 "Поток событий"
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Router /events [get]
+func (h *EventsHandler) AllEvents(c *gin.Context) {
+	if !requireModerator(c) {
+		return
+	}
+	h.stream(c, "")
+}
+
+// stream writes the messages of topic until the client disconnects or the
+// hub shuts down; the subscription is closed either way.
+func (h *EventsHandler) stream(c *gin.Context, topic string) {
+	lastID, _ := strconv.ParseUint(c.GetHeader("Last-Event-ID"), 10, 64)
+	sub := h.hub.Subscribe(topic, lastID)
+	defer sub.Close()
+
+	// The stream outlives the server's write timeout.
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	w := c.Writer
+	_, _ = io.WriteString(w, "retry: 3000\n\n")
+	if sub.Gap {
+		_, _ = io.WriteString(w, "event: reset\ndata: {}\n\n")
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case m, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			_, _ = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", m.ID, m.Event, m.Data)
+		case <-heartbeat.C:
+			_, _ = io.WriteString(w, ": heartbeat\n\n")
+		}
+		w.Flush()
+	}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/internal/models"
+	"filmhub/internal/service"
+	"filmhub/pkg/pubsub"
+)
+
+// sseEvent is one parsed Server-Sent Event.
+type sseEvent struct {
+	id, event, data string
+}
+
+// readEvent returns the next event from r, skipping comments and retry
+// hints.
+func readEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+	var e sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			if e.event != "" {
+				return e
+			}
+			continue
+		}
+		field, value, _ := strings.Cut(line, ": ")
+		switch field {
+		case "id":
+			e.id = value
+		case "event":
+			e.event = value
+		case "data":
+			e.data = value
+		}
+	}
+}
+
+func openStream(t *testing.T, url, lastEventID string) (*bufio.Reader, context.CancelFunc) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return bufio.NewReader(resp.Body), cancel
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFilmEventsStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hub := pubsub.New(10, 10)
+	r := gin.New()
+	r.GET("/films/:id/events", NewEventsHandler(hub, service.NewFilmService(stubFilmRepo{}), 20*time.Millisecond).FilmEvents)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	stream, cancel := openStream(t, srv.URL+"/films/1/events", "")
+	waitFor(t, func() bool { return hub.Subscribers() == 1 })
+	hub.Publish(service.FilmTopic(2), models.LiveFilmRating, models.FilmRating{FilmID: 2, Rating: 5})
+	hub.Publish(service.FilmTopic(1), models.LiveFilmRating, models.FilmRating{FilmID: 1, Rating: 8})
+
+	e := readEvent(t, stream)
+	if e.id != "2" || e.event != models.LiveFilmRating || e.data != `{"film_id":1,"rating":8}` {
+		t.Fatalf("unexpected event %+v", e)
+	}
+	// Heartbeats keep the connection busy while nothing happens.
+	line, _ := stream.ReadString('\n')
+	if line != ": heartbeat\n" {
+		t.Fatalf("expected a heartbeat, got %q", line)
+	}
+
+	// Disconnecting unsubscribes.
+	cancel()
+	waitFor(t, func() bool { return hub.Subscribers() == 0 })
+
+	hub.Publish(service.FilmTopic(1), models.LiveReviewRemoved, models.ReviewRef{ID: 4, FilmID: 1})
+	stream, cancel = openStream(t, srv.URL+"/films/1/events", "2")
+	defer cancel()
+	if e := readEvent(t, stream); e.id != "3" || e.event != models.LiveReviewRemoved {
+		t.Fatalf("expected the missed event on resume, got %+v", e)
+	}
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"filmhub/internal/models"
 	"filmhub/internal/service"
 	"net/http"
@@ -24,24 +25,23 @@ func NewFilmHandler(service *service.FilmService) *FilmHandler {
 // @Produce json
 // @Security BearerAuth
 // @Param film body models.FilmRequest true "Данные фильма"
-// @Success 201 {object} models.Film "Фильм успешно создан"
-// @Failure 400 {object} map[string]interface{} "Ошибка валидации"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Success 201 {object} idResponse "Фильм успешно создан"
+// @Failure 400 {object} errorResponse "Ошибка валидации"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
 // @Router /films [post]
 func (h *FilmHandler) CreateFilm(c *gin.Context) {
-	roleVal, _ := c.Get("role")
-	if roleStr, ok := roleVal.(string); !ok || (roleStr != "admin" && roleStr != "moderator") {
-		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+	userID, ok := currentUserID(c)
+	if !ok || !requireModerator(c) {
 		return
 	}
 	var req models.FilmRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	id, err := h.service.CreateFilm(c.Request.Context(), &req)
+	id, err := h.service.CreateFilm(c.Request.Context(), &req, userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -50,16 +50,99 @@ func (h *FilmHandler) CreateFilm(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"id": id})
 }
 
+// ImportFilms godoc
+// @Summary Импорт фильмов
+// @Description Добавляет до 100 фильмов с жанрами (модераторы и администраторы). Импорт выполняется целиком или не выполняется вовсе
+// @Tags films
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param films body models.FilmImportRequest true "Импортируемые фильмы"
+// @Success 201 {object} models.FilmImportResult "Фильмы добавлены"
+// @Failure 400 {object} errorResponse "Ошибка валидации"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /films/import [post]
+func (h *FilmHandler) ImportFilms(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok || !requireModerator(c) {
+		return
+	}
+	var req models.FilmImportRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	ids, err := h.service.ImportFilms(c.Request.Context(), req.Films, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, models.FilmImportResult{IDs: ids})
+}
+
+// @Summary Изменение фильма
+// @Description Заменяет название, описание и дату выхода фильма (модераторы и администраторы). В If-Match передаётся ETag из GET /films/{id}; новый ETag возвращается в ответе
+// @Tags films
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID фильма"
+// @Param If-Match header string true "ETag изменяемой версии"
+// @Param film body models.FilmRequest true "Данные фильма"
+// @Success 200 {object} models.Film "Обновлённый фильм"
+// @Failure 400 {object} errorResponse "Ошибка валидации"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 404 {object} errorResponse "Фильм не найден"
+// @Failure 412 {object} errorResponse "Фильм изменён с момента чтения"
+// @Failure 428 {object} errorResponse "Не передан If-Match"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /films/{id} [put]
+func (h *FilmHandler) UpdateFilm(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok || !requireModerator(c) {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film id"})
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	var req models.FilmRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	film, err := h.service.UpdateFilm(c.Request.Context(), id, &req, userID, version)
+	switch {
+	case errors.Is(err, service.ErrFilmNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrVersionMismatch):
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.Header("ETag", filmETag(film))
+		c.JSON(http.StatusOK, film)
+	}
+}
+
 // @Summary Получение фильма по ID
-// @Description Возвращает информацию о фильме по его ID
+// @Description Возвращает информацию о фильме по его ID и его ETag; с If-None-Match отвечает 304, если фильм не менялся
 // @Tags films
 // @Accept json
 // @Produce json
 // @Param id path int true "ID фильма"
+// @Param If-None-Match header string false "ETag, полученный ранее"
 // @Success 200 {object} models.Film "Информация о фильме"
-// @Failure 400 {object} map[string]interface{} "Неверный ID"
-// @Failure 404 {object} map[string]interface{} "Фильм не найден"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Success 304 "Фильм не изменился"
+// @Failure 400 {object} errorResponse "Неверный ID"
+// @Failure 404 {object} errorResponse "Фильм не найден"
 // @Router /films/{id} [get]
 func (h *FilmHandler) GetFilm(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -73,6 +156,9 @@ func (h *FilmHandler) GetFilm(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Film not found"})
 		return
 	}
+	if notModified(c, filmETag(film)) {
+		return
+	}
 
 	c.JSON(http.StatusOK, film)
 }
@@ -84,7 +170,7 @@ func (h *FilmHandler) GetFilm(c *gin.Context) {
 // @Produce json
 // @Param query query string false "Поисковый запрос"
 // @Success 200 {array} models.Film "Список найденных фильмов"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
 // @Router /films [get]
 func (h *FilmHandler) SearchFilms(c *gin.Context) {
 	query := c.Query("query")
@@ -97,3 +183,152 @@ func (h *FilmHandler) SearchFilms(c *gin.Context) {
 
 	c.JSON(http.StatusOK, films)
 }
+
+// @Summary Удаление фильма
+// @Description Фильм попадает в корзину и пропадает из каталога; администратор может восстановить его до окончательного удаления (модераторы и администраторы)
+// @Tags films
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID фильма"
+// @Success 204 "Фильм удалён"
+// @Failure 400 {object} errorResponse "Неверный ID"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 404 {object} errorResponse "Фильм не найден"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /films/{id} [delete]
+func (h *FilmHandler) DeleteFilm(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok || !requireModerator(c) {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film id"})
+		return
+	}
+	err = h.service.DeleteFilm(c.Request.Context(), id, userID)
+	switch {
+	case errors.Is(err, service.ErrFilmNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// @Summary История изменений фильма
+// @Description Ревизии фильма, новые первыми. Каждое изменение и каждый откат добавляют ревизию
+// @Tags films
+// @Produce json
+// @Param id path int true "ID фильма"
+// @Success 200 {array} models.FilmRevision "Ревизии"
+// @Failure 400 {object} errorResponse "Неверный ID"
+// @Failure 404 {object} errorResponse "Фильм не найден"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /films/{id}/revisions [get]
+func (h *FilmHandler) ListRevisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film id"})
+		return
+	}
+	revisions, err := h.service.Revisions(c.Request.Context(), id)
+	switch {
+	case errors.Is(err, service.ErrFilmNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, revisions)
+	}
+}
+
+// @Summary Сравнение ревизий фильма
+// @Description Поля, отличающиеся между двумя ревизиями
+// @Tags films
+// @Produce json
+// @Param id path int true "ID фильма"
+// @Param from query int true "Исходная ревизия"
+// @Param to query int true "Сравниваемая ревизия"
+// @Success 200 {object} models.FilmDiff "Изменённые поля"
+// @Failure 400 {object} errorResponse "Неверные параметры"
+// @Failure 404 {object} errorResponse "Фильм или ревизия не найдены"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /films/{id}/revisions/diff [get]
+func (h *FilmHandler) DiffRevisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film id"})
+		return
+	}
+	from, errFrom := strconv.Atoi(c.Query("from"))
+	to, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be revision numbers"})
+		return
+	}
+	diff, err := h.service.DiffRevisions(c.Request.Context(), id, from, to)
+	switch {
+	case errors.Is(err, service.ErrRevisionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, diff)
+	}
+}
+
+// @Summary Откат фильма к ревизии
+// @Description Восстанавливает поля фильма из ревизии и сохраняет результат как новую ревизию (администраторы). В If-Match передаётся ETag из GET /films/{id}; новый ETag возвращается в ответе
+// @Tags films
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID фильма"
+// @Param revision path int true "Номер ревизии"
+// @Param If-Match header string true "ETag изменяемой версии"
+// @Success 200 {object} models.Film "Фильм после отката"
+// @Failure 400 {object} errorResponse "Неверные параметры"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 404 {object} errorResponse "Фильм или ревизия не найдены"
+// @Failure 409 {object} errorResponse "Фильм уже совпадает с ревизией"
+// @Failure 412 {object} errorResponse "Фильм изменён с момента чтения"
+// @Failure 428 {object} errorResponse "Не передан If-Match"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /films/{id}/revisions/{revision}/rollback [post]
+func (h *FilmHandler) Rollback(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok || !requireAdmin(c) {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film id"})
+		return
+	}
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision"})
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	film, err := h.service.Rollback(c.Request.Context(), id, revision, userID, version)
+	switch {
+	case errors.Is(err, service.ErrFilmNotFound), errors.Is(err, service.ErrRevisionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNothingToRollback):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrVersionMismatch):
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.Header("ETag", filmETag(film))
+		c.JSON(http.StatusOK, film)
+	}
+}
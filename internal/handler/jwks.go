@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/pkg/login"
+)
+
+type JWKSHandler struct {
+	keys *login.Keyring
+}
+
+func NewJWKSHandler(keys *login.Keyring) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// @Summary Публичные ключи подписи JWT
+// @Description JWK Set для проверки токенов FilmHub другими сервисами; включает ключи, выведенные из ротации, пока выданные ими токены действительны
+// @Tags auth
+// @Produce json
+// @Success 200 {object} jwk.Set "Набор ключей"
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) JWKS(c *gin.Context) {
+	// Short cache so verifiers pick up a rotated key quickly.
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
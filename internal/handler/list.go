@@ -0,0 +1,490 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/internal/models"
+	"filmhub/internal/service"
+)
+
+type ListHandler struct {
+	service *service.ListService
+}
+
+func NewListHandler(s *service.ListService) *ListHandler {
+	return &ListHandler{service: s}
+}
+
+// DiscoverLists godoc
+// @Summary Публичные списки фильмов
+// @Description Публичные списки, самые популярные первыми; с film_id — только содержащие этот фильм. Списки unlisted и private сюда не попадают. Токен необязателен: с ним отмечено, лайкнул ли список пользователь
+// @Tags lists
+// @Produce json
+// @Param film_id query int false "ID фильма, который должен быть в списке"
+// @Param page query int false "Номер страницы (с 1)"
+// @Param limit query int false "Размер страницы (1–100, по умолчанию 20)"
+// @Success 200 {object} models.FilmListPage
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse "Недействительный токен"
+// @Failure 500 {object} errorResponse
+// @Router /lists [get]
+func (h *ListHandler) DiscoverLists(c *gin.Context) {
+	filmID := 0
+	if v := c.Query("film_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film id"})
+			return
+		}
+		filmID = id
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	lists, err := h.service.Discover(c.Request.Context(), filmID, viewerID(c), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, lists)
+}
+
+// ListMyLists godoc
+// @Summary Мои списки фильмов
+// @Description Списки, которыми пользователь владеет или в которых он соавтор, с любой видимостью; недавно изменённые первыми
+// @Tags lists
+// @Produce json
+// @Param page query int false "Номер страницы (с 1)"
+// @Param limit query int false "Размер страницы (1–100, по умолчанию 20)"
+// @Security BearerAuth
+// @Success 200 {object} models.FilmListPage
+// @Failure 401 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /me/lists [get]
+func (h *ListHandler) ListMyLists(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	lists, err := h.service.Mine(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, lists)
+}
+
+// GetList godoc
+// @Summary Список фильмов
+// @Description Список с фильмами по порядку. Приватный список видят только владелец и соавторы, остальным он не найден. Токен необязателен
+// @Tags lists
+// @Produce json
+// @Param id path int true "ID списка"
+// @Success 200 {object} models.FilmList
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse "Недействительный токен"
+// @Failure 404 {object} errorResponse "Список не найден"
+// @Failure 500 {object} errorResponse
+// @Router /lists/{id} [get]
+func (h *ListHandler) GetList(c *gin.Context) {
+	id, ok := listID(c)
+	if !ok {
+		return
+	}
+	list, err := h.service.Get(c.Request.Context(), id, viewerID(c))
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// CreateList godoc
+// @Summary Создать список фильмов
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Param list body models.FilmListRequest true "Список"
+// @Security BearerAuth
+// @Success 201 {object} idResponse
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /lists [post]
+func (h *ListHandler) CreateList(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req models.FilmListRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	id, err := h.service.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+// UpdateList godoc
+// @Summary Изменить список фильмов
+// @Description Меняет название, описание и видимость; доступно только владельцу. Без visibility видимость не меняется
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Param id path int true "ID списка"
+// @Param list body models.FilmListRequest true "Список"
+// @Security BearerAuth
+// @Success 200 {object} models.FilmList
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Изменять список может только владелец"
+// @Failure 404 {object} errorResponse "Список не найден"
+// @Failure 500 {object} errorResponse
+// @Router /lists/{id} [put]
+func (h *ListHandler) UpdateList(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := listID(c)
+	if !ok {
+		return
+	}
+	var req models.FilmListRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	list, err := h.service.Update(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// DeleteList godoc
+// @Summary Удалить список фильмов
+// @Description Доступно только владельцу
+// @Tags lists
+// @Produce json
+// @Param id path int true "ID списка"
+// @Security BearerAuth
+// @Success 204 "Список удалён"
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Удалить список может только владелец"
+// @Failure 404 {object} errorResponse "Список не найден"
+// @Failure 500 {object} errorResponse
+// @Router /lists/{id} [delete]
+func (h *ListHandler) DeleteList(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := listID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.Delete(c.Request.Context(), id, userID); err != nil {
+		respondListError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// AddListEntry godoc
+// @Summary Добавить фильм в список
+// @Description Фильм добавляется в конец списка. Доступно владельцу и соавторам
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Param id path int true "ID списка"
+// @Param entry body models.FilmListEntryRequest true "Фильм и заметка"
+// @Security BearerAuth
+// @Success 201 {object} models.FilmListEntry
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 404 {object} errorResponse "Список или фильм не найден"
+// @Failure 409 {object} errorResponse "Фильм уже в списке или список заполнен"
+// @Failure 500 {object} errorResponse
+// @Router /lists/{id}/entries [post]
+func (h *ListHandler) AddListEntry(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := listID(c)
+	if !ok {
+		return
+	}
+	var req models.FilmListEntryRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	entry, err := h.service.AddEntry(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, entry)
+}
+
+// UpdateListEntry godoc
+// @Summary Изменить фильм в списке
+// @Description Меняет заметку и/или переносит фильм на другое место; остальные фильмы сдвигаются. Доступно владельцу и соавторам
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Param id path int true "ID списка"
+// @Param film_id path int true "ID фильма"
+// @Param entry body models.FilmListEntryUpdateRequest true "Заметка и/или место"
+// @Security BearerAuth
+// @Success 200 {object} models.FilmListEntry
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 404 {object} errorResponse "Список не найден или фильма в нём нет"
+// @Failure 500 {object} errorResponse
+// @Router /lists/{id}/entries/{film_id} [patch]
+func (h *ListHandler) UpdateListEntry(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := listID(c)
+	if !ok {
+		return
+	}
+	filmID, err := strconv.Atoi(c.Param("film_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film id"})
+		return
+	}
+	var req models.FilmListEntryUpdateRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	entry, err := h.service.UpdateEntry(c.Request.Context(), id, filmID, userID, &req)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// RemoveListEntry godoc
+// @Summary Убрать фильм из списка
+// @Description Фильмы после него поднимаются на место выше. Доступно владельцу и соавторам
+// @Tags lists
+// @Produce json
+// @Param id path int true "ID списка"
+// @Param film_id path int true "ID фильма"
+// @Security BearerAuth
+// @Success 204 "Фильм убран"
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 404 {object} errorResponse "Список не найден или фильма в нём нет"
+// @Failure 500 {object} errorResponse
+// @Router /lists/{id}/entries/{film_id} [delete]
+func (h *ListHandler) RemoveListEntry(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := listID(c)
+	if !ok {
+		return
+	}
+	filmID, err := strconv.Atoi(c.Param("film_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film id"})
+		return
+	}
+	if err := h.service.RemoveEntry(c.Request.Context(), id, filmID, userID); err != nil {
+		respondListError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ReorderList godoc
+// @Summary Упорядочить фильмы списка
+// @Description Задаёт новый порядок всех фильмов списка. Доступно владельцу и соавторам
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Param id path int true "ID списка"
+// @Param order body models.FilmListOrderRequest true "Новый порядок"
+// @Security BearerAuth
+// @Success 204 "Порядок изменён"
+// @Failure 400 {object} errorResponse "Порядок должен содержать каждый фильм списка ровно один раз"
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 404 {object} errorResponse "Список не найден"
+// @Failure 500 {object} errorResponse
+// @Router /lists/{id}/order [put]
+func (h *ListHandler) ReorderList(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := listID(c)
+	if !ok {
+		return
+	}
+	var req models.FilmListOrderRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if err := h.service.Reorder(c.Request.Context(), id, userID, req.FilmIDs); err != nil {
+		respondListError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// AddListCollaborator godoc
+// @Summary Добавить соавтора списка
+// @Description Соавтор может добавлять, убирать и переставлять фильмы списка. Доступно только владельцу; повторное добавление ничего не меняет
+// @Tags lists
+// @Produce json
+// @Param id path int true "ID списка"
+// @Param user_id path int true "ID пользователя"
+// @Security BearerAuth
+// @Success 204 "Соавтор добавлен"
+// @Failure 400 {object} errorResponse "Владелец не может быть соавтором"
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Соавторов добавляет только владелец"
+// @Failure 404 {object} errorResponse "Список или пользователь не найден"
+// @Failure 500 {object} errorResponse
+// @Router /lists/{id}/collaborators/{user_id} [put]
+func (h *ListHandler) AddListCollaborator(c *gin.Context) {
+	h.changeCollaborator(c, h.service.AddCollaborator)
+}
+
+// RemoveListCollaborator godoc
+// @Summary Убрать соавтора списка
+// @Description Владелец убирает любого соавтора, соавтор — себя
+// @Tags lists
+// @Produce json
+// @Param id path int true "ID списка"
+// @Param user_id path int true "ID пользователя"
+// @Security BearerAuth
+// @Success 204 "Соавтор убран"
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 404 {object} errorResponse "Список не найден или пользователь не соавтор"
+// @Failure 500 {object} errorResponse
+// @Router /lists/{id}/collaborators/{user_id} [delete]
+func (h *ListHandler) RemoveListCollaborator(c *gin.Context) {
+	h.changeCollaborator(c, h.service.RemoveCollaborator)
+}
+
+func (h *ListHandler) changeCollaborator(c *gin.Context, change func(ctx context.Context, listID, actorID, userID int) error) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := listID(c)
+	if !ok {
+		return
+	}
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	if err := change(c.Request.Context(), id, actorID, userID); err != nil {
+		respondListError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// LikeList godoc
+// @Summary Лайкнуть список
+// @Description Повторный лайк ничего не меняет
+// @Tags lists
+// @Produce json
+// @Param id path int true "ID списка"
+// @Security BearerAuth
+// @Success 204 "Лайк поставлен"
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 404 {object} errorResponse "Список не найден"
+// @Failure 500 {object} errorResponse
+// @Router /lists/{id}/like [put]
+func (h *ListHandler) LikeList(c *gin.Context) {
+	h.like(c, true)
+}
+
+// UnlikeList godoc
+// @Summary Убрать лайк списка
+// @Tags lists
+// @Produce json
+// @Param id path int true "ID списка"
+// @Security BearerAuth
+// @Success 204 "Лайк убран"
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 404 {object} errorResponse "Список не найден"
+// @Failure 500 {object} errorResponse
+// @Router /lists/{id}/like [delete]
+func (h *ListHandler) UnlikeList(c *gin.Context) {
+	h.like(c, false)
+}
+
+func (h *ListHandler) like(c *gin.Context, liked bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := listID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.Like(c.Request.Context(), id, userID, liked); err != nil {
+		respondListError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// listID parses the list ID path parameter, responding with 400 if it is
+// not a number.
+func listID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid list id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func respondListError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrListNotFound), errors.Is(err, service.ErrNotInList), errors.Is(err, service.ErrFilmNotFound),
+		errors.Is(err, service.ErrUserNotFound), errors.Is(err, service.ErrNotCollaborator):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAlreadyInList), errors.Is(err, service.ErrListFull):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrListOrder), errors.Is(err, service.ErrOwnerCollaborator):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
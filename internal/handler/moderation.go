@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/internal/models"
+	"filmhub/internal/service"
+)
+
+type ModerationHandler struct {
+	service *service.ModerationService
+}
+
+func NewModerationHandler(s *service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{service: s}
+}
+
+// ReportReview godoc
+// @Summary Пожаловаться на отзыв
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path int true "ID отзыва"
+// @Param report body models.ReportRequest true "Жалоба"
+// @Security BearerAuth
+// @Success 201 {object} idResponse
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 404 {object} errorResponse "Отзыв не найден"
+// @Failure 409 {object} errorResponse "Жалоба уже подана"
+// @Failure 500 {object} errorResponse
+// @Router /reviews/{id}/report [post]
+func (h *ModerationHandler) ReportReview(c *gin.Context) {
+	h.report(c, models.TargetReview)
+}
+
+// ReportComment godoc
+// @Summary Пожаловаться на комментарий
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path int true "ID комментария"
+// @Param report body models.ReportRequest true "Жалоба"
+// @Security BearerAuth
+// @Success 201 {object} idResponse
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 404 {object} errorResponse "Комментарий не найден"
+// @Failure 409 {object} errorResponse "Жалоба уже подана"
+// @Failure 500 {object} errorResponse
+// @Router /comments/{id}/report [post]
+func (h *ModerationHandler) ReportComment(c *gin.Context) {
+	h.report(c, models.TargetComment)
+}
+
+func (h *ModerationHandler) report(c *gin.Context, targetType string) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + targetType + " id"})
+		return
+	}
+	var req models.ReportRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	report := models.Report{
+		TargetType: targetType,
+		TargetID:   targetID,
+		ReporterID: &userID,
+		Reason:     req.Reason,
+		Details:    req.Details,
+	}
+	id, err := h.service.Report(c.Request.Context(), &report)
+	switch {
+	case errors.Is(err, service.ErrReviewNotFound), errors.Is(err, service.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAlreadyReported):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusCreated, gin.H{"id": id})
+	}
+}
+
+// ListReports godoc
+// @Summary Очередь жалоб
+// @Description Доступно модераторам и администраторам
+// @Tags moderation
+// @Produce json
+// @Param status query string false "Статус: open (по умолчанию), actioned, dismissed"
+// @Param page query int false "Номер страницы (с 1)"
+// @Param limit query int false "Размер страницы (1–100, по умолчанию 20)"
+// @Security BearerAuth
+// @Success 200 {object} models.ReportPage
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 500 {object} errorResponse
+// @Router /moderation/reports [get]
+func (h *ModerationHandler) ListReports(c *gin.Context) {
+	if !requireModerator(c) {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	reports, err := h.service.Queue(c.Request.Context(), c.Query("status"), page, limit)
+	switch {
+	case errors.Is(err, service.ErrInvalidStatus):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, reports)
+	}
+}
+
+// HideReported godoc
+// @Summary Скрыть содержимое по жалобе
+// @Description Скрывает отзыв или комментарий и закрывает все открытые жалобы на него
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path int true "ID жалобы"
+// @Param request body models.ModerationRequest false "Комментарий модератора"
+// @Security BearerAuth
+// @Success 200 {object} models.ModerationAction
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 404 {object} errorResponse "Жалоба или содержимое не найдены"
+// @Failure 500 {object} errorResponse
+// @Router /moderation/reports/{id}/hide [post]
+func (h *ModerationHandler) HideReported(c *gin.Context) {
+	h.resolve(c, models.ModerationHide)
+}
+
+// RestoreReported godoc
+// @Summary Восстановить скрытое содержимое
+// @Description Делает содержимое снова публичным; открытые жалобы на него отклоняются
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path int true "ID жалобы"
+// @Param request body models.ModerationRequest false "Комментарий модератора"
+// @Security BearerAuth
+// @Success 200 {object} models.ModerationAction
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 404 {object} errorResponse "Жалоба или содержимое не найдены"
+// @Failure 409 {object} errorResponse "Содержимое не скрыто"
+// @Failure 500 {object} errorResponse
+// @Router /moderation/reports/{id}/restore [post]
+func (h *ModerationHandler) RestoreReported(c *gin.Context) {
+	h.resolve(c, models.ModerationRestore)
+}
+
+// DismissReport godoc
+// @Summary Отклонить жалобу
+// @Description Закрывает все открытые жалобы на содержимое, не меняя его
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path int true "ID жалобы"
+// @Param request body models.ModerationRequest false "Комментарий модератора"
+// @Security BearerAuth
+// @Success 200 {object} models.ModerationAction
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 404 {object} errorResponse "Жалоба или содержимое не найдены"
+// @Failure 409 {object} errorResponse "Жалоба уже закрыта"
+// @Failure 500 {object} errorResponse
+// @Router /moderation/reports/{id}/dismiss [post]
+func (h *ModerationHandler) DismissReport(c *gin.Context) {
+	h.resolve(c, models.ModerationDismiss)
+}
+
+func (h *ModerationHandler) resolve(c *gin.Context, action string) {
+	moderatorID, ok := currentUserID(c)
+	if !ok || !requireModerator(c) {
+		return
+	}
+	reportID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report id"})
+		return
+	}
+	var req models.ModerationRequest
+	if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
+		return
+	}
+	entry, err := h.service.Resolve(c.Request.Context(), reportID, moderatorID, action, req.Note)
+	switch {
+	case errors.Is(err, service.ErrReportNotFound), errors.Is(err, service.ErrReviewNotFound),
+		errors.Is(err, service.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrReportClosed), errors.Is(err, service.ErrNotHidden):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, entry)
+	}
+}
+
+// ModerationLog godoc
+// @Summary Журнал действий модераторов
+// @Description Доступно модераторам и администраторам
+// @Tags moderation
+// @Produce json
+// @Param page query int false "Номер страницы (с 1)"
+// @Param limit query int false "Размер страницы (1–100, по умолчанию 20)"
+// @Security BearerAuth
+// @Success 200 {object} models.ModerationLogPage
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 500 {object} errorResponse
+// @Router /moderation/log [get]
+func (h *ModerationHandler) ModerationLog(c *gin.Context) {
+	if !requireModerator(c) {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	log, err := h.service.AuditLog(c.Request.Context(), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, log)
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/internal/models"
+	"filmhub/internal/service"
+)
+
+type NotificationHandler struct {
+	service *service.NotificationService
+}
+
+func NewNotificationHandler(s *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{service: s}
+}
+
+// ListNotifications godoc
+// @Summary Мои уведомления
+// @Description Уведомления об ответах на отзывы и комментарии (reply), отметках «полезно» (like) и датах выхода фильмов из списка «Буду смотреть» (release_date), новые первыми, с числом непрочитанных
+// @Tags notifications
+// @Produce json
+// @Param unread query bool false "Только непрочитанные"
+// @Param page query int false "Номер страницы (с 1)"
+// @Param limit query int false "Размер страницы (1–100, по умолчанию 20)"
+// @Security BearerAuth
+// @Success 200 {object} models.NotificationPage
+// @Failure 401 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /me/notifications [get]
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	unread, _ := strconv.ParseBool(c.Query("unread"))
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	notifications, err := h.service.List(c.Request.Context(), userID, unread, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, notifications)
+}
+
+// MarkNotificationRead godoc
+// @Summary Отметить уведомление прочитанным
+// @Tags notifications
+// @Param id path int true "ID уведомления"
+// @Security BearerAuth
+// @Success 204 "Отмечено"
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 404 {object} errorResponse "Уведомление не найдено"
+// @Failure 500 {object} errorResponse
+// @Router /me/notifications/{id}/read [post]
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	err = h.service.MarkRead(c.Request.Context(), userID, id)
+	switch {
+	case errors.Is(err, service.ErrNotificationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// MarkAllNotificationsRead godoc
+// @Summary Отметить все уведомления прочитанными
+// @Tags notifications
+// @Security BearerAuth
+// @Success 204 "Отмечено"
+// @Failure 401 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /me/notifications/read-all [post]
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.MarkAllRead(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetNotificationPreferences godoc
+// @Summary Настройки уведомлений
+// @Description Какие типы уведомлений получает пользователь; по умолчанию включены все
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.NotificationPreferences
+// @Failure 401 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /me/notification-preferences [get]
+func (h *NotificationHandler) GetNotificationPreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	prefs, err := h.service.Preferences(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, prefs)
+}
+
+// UpdateNotificationPreferences godoc
+// @Summary Изменить настройки уведомлений
+// @Description Включает или выключает переданные типы уведомлений; остальные не меняются
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body models.NotificationPreferencesRequest true "Типы уведомлений"
+// @Security BearerAuth
+// @Success 200 {object} models.NotificationPreferences
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /me/notification-preferences [put]
+func (h *NotificationHandler) UpdateNotificationPreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req models.NotificationPreferencesRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	prefs, err := h.service.SetPreferences(c.Request.Context(), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, prefs)
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/service"
+)
+
+// oidcStateCookie carries the signed login state between the redirect to the
+// provider and the callback.
+const oidcStateCookie = "oidc_state"
+
+type OIDCHandler struct {
+	service *service.SocialAuthService
+}
+
+func NewOIDCHandler(service *service.SocialAuthService) *OIDCHandler {
+	return &OIDCHandler{service: service}
+}
+
+type redirectResponse struct {
+	URL string `json:"url" example:"https://accounts.example.com/authorize?client_id=filmhub" description:"Адрес, на который нужно перенаправить браузер"`
+}
+
+// @Summary Вход через OpenID Connect
+// @Description Перенаправляет браузер к OIDC-провайдеру (authorization code + PKCE)
+// @Tags auth
+// @Success 302 "Перенаправление к провайдеру"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /auth/oidc/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	url, ok := h.begin(c, 0)
+	if !ok {
+		return
+	}
+	c.Redirect(http.StatusFound, url)
+}
+
+// @Summary Завершение входа через OpenID Connect
+// @Description Принимает ответ OIDC-провайдера, находит или создаёт пользователя и выдаёт JWT
+// @Tags auth
+// @Produce json
+// @Param code query string true "Код авторизации"
+// @Param state query string true "Состояние, выданное при входе"
+// @Success 200 {object} loginResponse "JWT токен"
+// @Failure 400 {object} errorResponse "Недействительное состояние или ответ провайдера"
+// @Failure 409 {object} errorResponse "Учётная запись уже связана или требует привязки"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /auth/oidc/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	stateToken, _ := c.Cookie(oidcStateCookie)
+	h.setStateCookie(c, "", -1)
+	if msg := c.Query("error"); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	token, err := h.service.Callback(c.Request.Context(), stateToken, c.Query("state"), c.Query("code"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidState), errors.Is(err, service.ErrLoginRejected):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrIdentityLinked), errors.Is(err, service.ErrProviderLinked),
+			errors.Is(err, service.ErrAccountExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, loginResponse{Token: token})
+}
+
+// @Summary Привязка внешней учётной записи
+// @Description Начинает вход через OIDC-провайдера, после которого его учётная запись будет привязана к текущему пользователю
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} redirectResponse "Адрес провайдера"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Привязками нельзя управлять с помощью API-ключа"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /me/identities/link [post]
+func (h *OIDCHandler) Link(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	url, ok := h.begin(c, userID)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, redirectResponse{URL: url})
+}
+
+// @Summary Привязанные внешние учётные записи
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Identity "Список привязок"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Привязками нельзя управлять с помощью API-ключа"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /me/identities [get]
+func (h *OIDCHandler) ListIdentities(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	identities, err := h.service.Identities(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, identities)
+}
+
+// @Summary Отвязка внешней учётной записи
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Имя провайдера"
+// @Success 204 "Привязка удалена"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Привязками нельзя управлять с помощью API-ключа"
+// @Failure 404 {object} errorResponse "Привязка не найдена"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /me/identities/{provider} [delete]
+func (h *OIDCHandler) Unlink(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.Unlink(c.Request.Context(), userID, c.Param("provider")); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "identity not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *OIDCHandler) begin(c *gin.Context, linkUserID int) (string, bool) {
+	url, stateToken, err := h.service.Begin(linkUserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return "", false
+	}
+	h.setStateCookie(c, stateToken, 600)
+	return url, true
+}
+
+// setStateCookie scopes the cookie to the callback path. SameSite=Lax lets
+// it ride along on the top-level redirect back from the provider.
+func (h *OIDCHandler) setStateCookie(c *gin.Context, value string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, value, maxAge, "/auth/oidc", "", c.Request.TLS != nil, true)
+}
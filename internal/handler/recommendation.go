@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/internal/service"
+)
+
+type RecommendationHandler struct {
+	service *service.RecommendationService
+}
+
+func NewRecommendationHandler(s *service.RecommendationService) *RecommendationHandler {
+	return &RecommendationHandler{service: s}
+}
+
+// ListRecommendations godoc
+// @Summary Рекомендации фильмов
+// @Description Фильмы, похожие на высоко оценённые пользователем: сходство фильмов считается по оценкам в отзывах (item-item collaborative filtering) и периодически пересчитывается. Пользователям без оценок или с недостаточным числом оценок предлагаются популярные фильмы любимых жанров, затем просто популярные. Фильмы, на которые пользователь уже написал отзыв, не предлагаются. У каждой рекомендации есть причина и объяснение
+// @Tags recommendations
+// @Produce json
+// @Param limit query int false "Сколько фильмов вернуть (1–100, по умолчанию 20)"
+// @Security BearerAuth
+// @Success 200 {object} models.RecommendationList
+// @Failure 401 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /me/recommendations [get]
+func (h *RecommendationHandler) ListRecommendations(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	recommendations, err := h.service.Recommend(c.Request.Context(), userID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, recommendations)
+}
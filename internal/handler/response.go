@@ -0,0 +1,25 @@
+package handler
+
+// errorResponse is the body of every JSON error returned by the handlers.
+type errorResponse struct {
+	Error  string            `json:"error" example:"invalid film id" description:"Описание ошибки"`
+	Fields map[string]string `json:"fields,omitempty" description:"Ошибки валидации по полям"`
+}
+
+// idResponse is returned after an entity has been created.
+type idResponse struct {
+	ID int `json:"id" example:"1" description:"Идентификатор созданной записи"`
+}
+
+// heldResponse is returned when new content awaits moderation.
+type heldResponse struct {
+	ID      int      `json:"id" example:"1" description:"Идентификатор созданной записи"`
+	Status  string   `json:"status" example:"held" description:"Всегда held: запись видна только автору до проверки модератором"`
+	Reasons []string `json:"reasons" example:"excessive_caps,repetition" description:"Что насторожило автоматический фильтр"`
+}
+
+// rejectedResponse is returned when the content filter refuses content.
+type rejectedResponse struct {
+	Error   string   `json:"error" example:"review rejected by content filter: banned_words" description:"Описание ошибки"`
+	Reasons []string `json:"reasons" example:"banned_words" description:"Причины: banned_words, too_many_links, link_shortener, contact_info, excessive_caps, repetition"`
+}
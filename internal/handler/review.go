@@ -1,6 +1,7 @@
 package handler
 
 import (
+    "errors"
     "filmhub/internal/models"
     "filmhub/internal/service"
     "net/http"
@@ -19,24 +20,24 @@ func NewReviewHandler(s *service.ReviewService) *ReviewHandler {
 
 // CreateReview godoc
 // @Summary Добавить отзыв
+// @Description Текст проверяется автоматическим фильтром: сомнительный отзыв скрывается до проверки модератором (202), явно недопустимый отклоняется (422)
 // @Tags reviews
 // @Accept json
 // @Produce json
 // @Param id path int true "ID фильма"
-// @Param review body models.Review true "Отзыв"
-// @Success 201 {object} map[string]int {"id":1}
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Param review body models.ReviewRequest true "Отзыв"
+// @Security BearerAuth
+// @Success 201 {object} idResponse
+// @Success 202 {object} heldResponse "Отзыв ожидает проверки модератором"
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Email не подтверждён"
+// @Failure 422 {object} rejectedResponse "Отзыв отклонён фильтром"
+// @Failure 500 {object} errorResponse
 // @Router /films/{id}/reviews [post]
 func (h *ReviewHandler) CreateReview(c *gin.Context) {
-    userIDVal, exists := c.Get("user_id")
-    if !exists {
-        c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-        return
-    }
-    userID, ok := userIDVal.(int)
+    userID, ok := currentUserID(c)
     if !ok {
-        c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
         return
     }
     filmID, err := strconv.Atoi(c.Param("id"))
@@ -44,27 +45,48 @@ func (h *ReviewHandler) CreateReview(c *gin.Context) {
         c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film id"})
         return
     }
-    var req models.Review
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    var req models.ReviewRequest
+    if !bindJSON(c, &req) {
+        return
+    }
+    review := models.Review{
+        FilmID:  filmID,
+        UserID:  userID,
+        Rating:  req.Rating,
+        Comment: req.Comment,
+    }
+    id, err := h.service.CreateReview(c.Request.Context(), &review)
+    if errors.Is(err, service.ErrEmailNotVerified) {
+        c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+        return
+    }
+    var rejected *service.ContentRejectedError
+    if errors.As(err, &rejected) {
+        c.JSON(http.StatusUnprocessableEntity, rejectedResponse{Error: err.Error(), Reasons: rejected.Reasons})
         return
     }
-    req.FilmID = filmID
-    req.UserID = userID
-    id, err := h.service.CreateReview(c.Request.Context(), &req)
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
+    if review.HiddenAt != nil {
+        c.JSON(http.StatusAccepted, heldResponse{ID: id, Status: "held", Reasons: review.HeldReasons})
+        return
+    }
     c.JSON(http.StatusCreated, gin.H{"id": id})
 }
 
 // ListReviews godoc
 // @Summary Список отзывов фильма
+// @Description Токен необязателен: скрытые модератором отзывы видны только их авторам
 // @Tags reviews
 // @Produce json
 // @Param id path int true "ID фильма"
+// @Param sort query string false "Порядок: newest (по умолчанию), helpful, rating"
 // @Success 200 {array} models.Review
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse "Недействительный токен"
+// @Failure 500 {object} errorResponse
 // @Router /films/{id}/reviews [get]
 func (h *ReviewHandler) ListReviews(c *gin.Context) {
     filmID, err := strconv.Atoi(c.Param("id"))
@@ -72,10 +94,187 @@ func (h *ReviewHandler) ListReviews(c *gin.Context) {
         c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film id"})
         return
     }
-    reviews, err := h.service.ListReviews(c.Request.Context(), filmID)
+    reviews, err := h.service.ListReviews(c.Request.Context(), filmID, c.Query("sort"), viewerID(c))
+    if errors.Is(err, service.ErrInvalidSort) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
     c.JSON(http.StatusOK, reviews)
-} 
\ No newline at end of file
+}
+
+// VoteReview godoc
+// @Summary Оценить полезность отзыва
+// @Description Один голос на пользователя; повторный такой же голос отменяет его, противоположный — заменяет
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "ID отзыва"
+// @Param vote body models.ReviewVoteRequest true "Голос"
+// @Security BearerAuth
+// @Success 200 {object} models.ReviewVotes
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Нельзя голосовать за свой отзыв"
+// @Failure 404 {object} errorResponse "Отзыв не найден"
+// @Failure 500 {object} errorResponse
+// @Router /reviews/{id}/vote [post]
+func (h *ReviewHandler) VoteReview(c *gin.Context) {
+    userID, ok := currentUserID(c)
+    if !ok {
+        return
+    }
+    reviewID, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
+        return
+    }
+    var req models.ReviewVoteRequest
+    if !bindJSON(c, &req) {
+        return
+    }
+    votes, err := h.service.Vote(c.Request.Context(), reviewID, userID, *req.Helpful)
+    switch {
+    case errors.Is(err, service.ErrReviewNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrOwnReview):
+        c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+    case err != nil:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    default:
+        c.JSON(http.StatusOK, votes)
+    }
+}
+
+// DeleteReview godoc
+// @Summary Удалить отзыв
+// @Description Отзыв попадает в корзину, откуда его может восстановить администратор. Автор удаляет свой отзыв, модератор или администратор — любой
+// @Tags reviews
+// @Produce json
+// @Param id path int true "ID отзыва"
+// @Security BearerAuth
+// @Success 204 "Отзыв удалён"
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 404 {object} errorResponse "Отзыв не найден"
+// @Failure 500 {object} errorResponse
+// @Router /reviews/{id} [delete]
+func (h *ReviewHandler) DeleteReview(c *gin.Context) {
+    userID, ok := currentUserID(c)
+    if !ok {
+        return
+    }
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
+        return
+    }
+    err = h.service.DeleteReview(c.Request.Context(), id, userID, currentRole(c))
+    switch {
+    case errors.Is(err, service.ErrReviewNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrForbidden):
+        c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+    case err != nil:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    default:
+        c.Status(http.StatusNoContent)
+    }
+}
+
+// GetReview godoc
+// @Summary Получить отзыв
+// @Description Возвращает отзыв и его ETag; с If-None-Match отвечает 304, если отзыв и его счётчики не менялись. Скрытый отзыв виден только автору
+// @Tags reviews
+// @Produce json
+// @Param id path int true "ID отзыва"
+// @Param If-None-Match header string false "ETag, полученный ранее"
+// @Success 200 {object} models.Review
+// @Success 304 "Отзыв не изменился"
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse "Недействительные учётные данные"
+// @Failure 404 {object} errorResponse "Отзыв не найден"
+// @Failure 500 {object} errorResponse
+// @Router /reviews/{id} [get]
+func (h *ReviewHandler) GetReview(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
+        return
+    }
+    review, err := h.service.GetReview(c.Request.Context(), id, viewerID(c))
+    switch {
+    case errors.Is(err, service.ErrReviewNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+    case err != nil:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    case notModified(c, reviewETag(review)):
+    default:
+        c.JSON(http.StatusOK, review)
+    }
+}
+
+// UpdateReview godoc
+// @Summary Изменить свой отзыв
+// @Description В If-Match передаётся ETag из GET /reviews/{id}. Новый текст проверяется фильтром, как при создании: сомнительный отзыв скрывается до проверки модератором (202)
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "ID отзыва"
+// @Param If-Match header string true "ETag изменяемой версии"
+// @Param review body models.ReviewRequest true "Отзыв"
+// @Security BearerAuth
+// @Success 200 {object} models.Review
+// @Success 202 {object} heldResponse "Отзыв ожидает проверки модератором"
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Чужой отзыв"
+// @Failure 404 {object} errorResponse "Отзыв не найден"
+// @Failure 412 {object} errorResponse "Отзыв изменён с момента чтения"
+// @Failure 422 {object} rejectedResponse "Отзыв отклонён фильтром"
+// @Failure 428 {object} errorResponse "Не передан If-Match"
+// @Failure 500 {object} errorResponse
+// @Router /reviews/{id} [put]
+func (h *ReviewHandler) UpdateReview(c *gin.Context) {
+    userID, ok := currentUserID(c)
+    if !ok {
+        return
+    }
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
+        return
+    }
+    version, ok := ifMatchVersion(c)
+    if !ok {
+        return
+    }
+    var req models.ReviewRequest
+    if !bindJSON(c, &req) {
+        return
+    }
+    review, err := h.service.UpdateReview(c.Request.Context(), id, userID, version, &req)
+    var rejected *service.ContentRejectedError
+    switch {
+    case errors.Is(err, service.ErrReviewNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrForbidden):
+        c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrVersionMismatch):
+        c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+    case errors.As(err, &rejected):
+        c.JSON(http.StatusUnprocessableEntity, rejectedResponse{Error: err.Error(), Reasons: rejected.Reasons})
+    case err != nil:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    case review.HeldReasons != nil:
+        c.Header("ETag", reviewETag(review))
+        c.JSON(http.StatusAccepted, heldResponse{ID: review.ID, Status: "held", Reasons: review.HeldReasons})
+    default:
+        c.Header("ETag", reviewETag(review))
+        c.JSON(http.StatusOK, review)
+    }
+}
@@ -14,15 +14,32 @@ import (
     "github.com/gin-gonic/gin"
 )
 
+// initTestKeys installs a fresh EdDSA keyring for token signing.
+func initTestKeys() {
+    keys, err := jwtpkg.NewKeyring(jwtpkg.KeyringConfig{Algorithm: jwtpkg.EdDSA})
+    if err != nil {
+        panic(err)
+    }
+    jwtpkg.Init(keys, jwtpkg.Config{})
+}
+
 type stubFilmRepo struct{}
 
-func (stubFilmRepo) CreateFilm(_ context.Context, _ *models.FilmRequest) (int, error) { return 1, nil }
+func (stubFilmRepo) CreateFilm(_ context.Context, _ *models.FilmRequest, _ int) (int, error) { return 1, nil }
+func (stubFilmRepo) UpdateFilm(_ context.Context, _ int, _ *models.FilmRequest, _, _ int) error { return nil }
+func (stubFilmRepo) DeleteFilm(_ context.Context, _, _ int) error { return nil }
+func (stubFilmRepo) SetGenres(_ context.Context, _ int, _ []string) error { return nil }
+func (stubFilmRepo) SetTags(_ context.Context, _ int, _ []string) error { return nil }
+func (stubFilmRepo) SetCredits(_ context.Context, _ int, _ []models.FilmCredit) error { return nil }
+func (stubFilmRepo) RollbackFilm(_ context.Context, _, _, _, _ int) error { return nil }
+func (stubFilmRepo) ListRevisions(_ context.Context, _ int) ([]models.FilmRevision, error) { return nil, nil }
+func (stubFilmRepo) GetRevision(_ context.Context, _, _ int) (*models.FilmRevision, error) { return nil, nil }
 func (stubFilmRepo) GetFilmByID(_ context.Context, id int) (*models.Film, error) { return &models.Film{ID: id, Title: "Test", Description: "",}, nil }
 func (stubFilmRepo) SearchFilms(_ context.Context, _ string) ([]models.Film, error) { return []models.Film{}, nil }
 
 func TestAuthAndCreateFilmRoute(t *testing.T) {
     gin.SetMode(gin.TestMode)
-    jwtpkg.Init("testsecret")
+    initTestKeys()
     // Prepare token
     token, _ := jwtpkg.GenerateToken(1, "admin")
 
@@ -39,7 +56,7 @@ func TestAuthAndCreateFilmRoute(t *testing.T) {
     }
 
     // Create film request through protected route
-    filmBody, _ := json.Marshal(models.FilmRequest{Title: "Test", Description: "",})
+    filmBody, _ := json.Marshal(models.FilmRequest{Title: "Test", Description: "Test description",})
     req := httptest.NewRequest(http.MethodPost, "/films", bytes.NewReader(filmBody))
     req.Header.Set("Authorization", "Bearer "+token)
     req.Header.Set("Content-Type", "application/json")
@@ -48,4 +65,38 @@ func TestAuthAndCreateFilmRoute(t *testing.T) {
     if resp.Code != http.StatusCreated {
         t.Fatalf("expected 201 created, got %d", resp.Code)
     }
-} 
\ No newline at end of file
+} 
+
+func TestCreateFilmRoute_ValidationErrors(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+    initTestKeys()
+    token, _ := jwtpkg.GenerateToken(1, "admin")
+
+    r := gin.New()
+    r.POST("/films", jwtpkg.AuthMiddleware(), NewFilmHandler(service.NewFilmService(stubFilmRepo{})).CreateFilm)
+
+    cases := map[string]string{
+        "ru-RU,ru;q=0.9": "обязательное поле",
+        "en-US":          "is required",
+    }
+    for lang, want := range cases {
+        filmBody, _ := json.Marshal(models.FilmRequest{Title: "", Description: ""})
+        req := httptest.NewRequest(http.MethodPost, "/films", bytes.NewReader(filmBody))
+        req.Header.Set("Authorization", "Bearer "+token)
+        req.Header.Set("Accept-Language", lang)
+        resp := httptest.NewRecorder()
+        r.ServeHTTP(resp, req)
+        if resp.Code != http.StatusBadRequest {
+            t.Fatalf("%s: expected 400, got %d", lang, resp.Code)
+        }
+        var body struct {
+            Fields map[string]string `json:"fields"`
+        }
+        if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+            t.Fatalf("decode response: %v", err)
+        }
+        if body.Fields["title"] != want || body.Fields["description"] != want {
+            t.Errorf("%s: unexpected field errors %v", lang, body.Fields)
+        }
+    }
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/internal/service"
+)
+
+type SimilarHandler struct {
+	service *service.SimilarService
+}
+
+func NewSimilarHandler(s *service.SimilarService) *SimilarHandler {
+	return &SimilarHandler{service: s}
+}
+
+// ListSimilar godoc
+// @Summary Похожие фильмы
+// @Description Фильмы, похожие на данный, по взвешенной сумме сигналов: общие жанры, общие актёры и создатели, похожие оценки зрителей и похожие описания. Веса сигналов задаются в настройках. У каждого фильма перечислены сигналы, давшие вклад, с объяснением. Результат кэшируется
+// @Tags films
+// @Produce json
+// @Param id path int true "ID фильма"
+// @Param limit query int false "Сколько фильмов вернуть (1–100, по умолчанию 20)"
+// @Success 200 {array} models.SimilarFilm
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /films/{id}/similar [get]
+func (h *SimilarHandler) ListSimilar(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid film ID"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	films, err := h.service.Similar(c.Request.Context(), id, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrFilmNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Film not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, films)
+}
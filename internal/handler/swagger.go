@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/swagger"
+)
+
+// swaggerCSP relaxes the default policy just enough for the Swagger UI page,
+// whose assets come from unpkg.
+const swaggerCSP = "default-src 'self'; script-src 'self' https://unpkg.com; " +
+	"style-src 'self' https://unpkg.com; img-src 'self' data: https://unpkg.com; frame-ancestors 'none'"
+
+// RegisterSwagger serves Swagger UI at /swagger and the spec at /swagger/doc.json.
+func RegisterSwagger(r gin.IRouter) {
+	r.GET("/swagger", func(c *gin.Context) {
+		c.Redirect(http.StatusMovedPermanently, "/swagger/index.html")
+	})
+	r.GET("/swagger/index.html", func(c *gin.Context) {
+		c.Header("Content-Security-Policy", swaggerCSP)
+		c.Data(http.StatusOK, "text/html; charset=utf-8", swagger.IndexHTML)
+	})
+	r.GET("/swagger/swagger-initializer.js", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/javascript; charset=utf-8", swagger.InitializerJS)
+	})
+	r.GET("/swagger/doc.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", swagger.Spec)
+	})
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/internal/service"
+)
+
+type TrashHandler struct {
+	service *service.TrashService
+}
+
+func NewTrashHandler(s *service.TrashService) *TrashHandler {
+	return &TrashHandler{service: s}
+}
+
+// ListTrash godoc
+// @Summary Корзина
+// @Description Удалённые фильмы и отзывы с датой окончательного удаления. Доступно администраторам
+// @Tags admin
+// @Produce json
+// @Param type query string false "Тип: film, review (по умолчанию — все)"
+// @Param page query int false "Номер страницы (с 1)"
+// @Param limit query int false "Размер страницы (1–100, по умолчанию 20)"
+// @Security BearerAuth
+// @Success 200 {object} models.TrashPage
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 500 {object} errorResponse
+// @Router /admin/trash [get]
+func (h *TrashHandler) ListTrash(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	items, err := h.service.List(c.Request.Context(), c.Query("type"), page, limit)
+	switch {
+	case errors.Is(err, service.ErrInvalidTrashType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, items)
+	}
+}
+
+// RestoreTrash godoc
+// @Summary Восстановить из корзины
+// @Description Доступно администраторам. Отзывы удалённого фильма снова видны после восстановления фильма
+// @Tags admin
+// @Produce json
+// @Param type path string true "Тип: film, review"
+// @Param id path int true "ID фильма или отзыва"
+// @Security BearerAuth
+// @Success 204 "Восстановлено"
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 404 {object} errorResponse "Нет в корзине"
+// @Failure 500 {object} errorResponse
+// @Router /admin/trash/{type}/{id}/restore [post]
+func (h *TrashHandler) RestoreTrash(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	err = h.service.Restore(c.Request.Context(), c.Param("type"), id)
+	switch {
+	case errors.Is(err, service.ErrInvalidTrashType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotInTrash):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
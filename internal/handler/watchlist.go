@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/internal/service"
+)
+
+type WatchlistHandler struct {
+	service *service.WatchlistService
+}
+
+func NewWatchlistHandler(s *service.WatchlistService) *WatchlistHandler {
+	return &WatchlistHandler{service: s}
+}
+
+// ListWatchlist godoc
+// @Summary Список «Буду смотреть»
+// @Tags watchlist
+// @Produce json
+// @Param page query int false "Номер страницы (с 1)"
+// @Param limit query int false "Размер страницы (1–100, по умолчанию 20)"
+// @Security BearerAuth
+// @Success 200 {object} models.WatchlistPage
+// @Failure 401 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /me/watchlist [get]
+func (h *WatchlistHandler) ListWatchlist(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	watchlist, err := h.service.List(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, watchlist)
+}
+
+// AddToWatchlist godoc
+// @Summary Добавить фильм в «Буду смотреть»
+// @Description Когда у фильма появится дата выхода, придёт уведомление release_date. Повторное добавление ничего не меняет
+// @Tags watchlist
+// @Param film_id path int true "ID фильма"
+// @Security BearerAuth
+// @Success 204 "Добавлено"
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 404 {object} errorResponse "Фильм не найден"
+// @Failure 500 {object} errorResponse
+// @Router /me/watchlist/{film_id} [put]
+func (h *WatchlistHandler) AddToWatchlist(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	filmID, err := strconv.Atoi(c.Param("film_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film id"})
+		return
+	}
+	err = h.service.Add(c.Request.Context(), userID, filmID)
+	switch {
+	case errors.Is(err, service.ErrFilmNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// RemoveFromWatchlist godoc
+// @Summary Убрать фильм из «Буду смотреть»
+// @Tags watchlist
+// @Param film_id path int true "ID фильма"
+// @Security BearerAuth
+// @Success 204 "Убрано"
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 404 {object} errorResponse "Фильма нет в списке"
+// @Failure 500 {object} errorResponse
+// @Router /me/watchlist/{film_id} [delete]
+func (h *WatchlistHandler) RemoveFromWatchlist(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	filmID, err := strconv.Atoi(c.Param("film_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film id"})
+		return
+	}
+	err = h.service.Remove(c.Request.Context(), userID, filmID)
+	switch {
+	case errors.Is(err, service.ErrNotOnWatchlist):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"filmhub/internal/models"
+	"filmhub/internal/service"
+)
+
+type WebhookHandler struct {
+	service *service.WebhookService
+}
+
+func NewWebhookHandler(s *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: s}
+}
+
+// CreateWebhook godoc
+// @Summary Подписка на события
+// @Description Отправляет события выбранных типов POST-запросом на указанный адрес. Тело запроса подписывается HMAC-SHA256 ключом подписки: заголовок X-FilmHub-Signature имеет вид t=<unix-время>,v1=<hex(HMAC(ключ, "<t>.<тело>"))>. Ключ показывается только в ответе на создание. Доступно администраторам
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body models.WebhookRequest true "Подписка"
+// @Security BearerAuth
+// @Success 201 {object} models.WebhookSubscription
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 500 {object} errorResponse
+// @Router /admin/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	var req models.WebhookRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	userID, _ := currentUserID(c)
+	sub, err := h.service.Create(c.Request.Context(), &req, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, sub)
+}
+
+// ListWebhooks godoc
+// @Summary Подписки на события
+// @Description Доступно администраторам. Ключи подписи не возвращаются
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.WebhookSubscription
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 500 {object} errorResponse
+// @Router /admin/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	subs, err := h.service.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, subs)
+}
+
+// DeleteWebhook godoc
+// @Summary Удалить подписку
+// @Description Удаляет подписку вместе с журналом доставок. Доступно администраторам
+// @Tags admin
+// @Param id path int true "ID подписки"
+// @Security BearerAuth
+// @Success 204 "Удалено"
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 404 {object} errorResponse "Подписка не найдена"
+// @Failure 500 {object} errorResponse
+// @Router /admin/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	err = h.service.Delete(c.Request.Context(), id)
+	switch {
+	case errors.Is(err, service.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// ListWebhookDeliveries godoc
+// @Summary Журнал доставок
+// @Description Доставки событий подписки с результатом последней попытки. После исчерпания попыток доставка получает статус dead. Доступно администраторам
+// @Tags admin
+// @Produce json
+// @Param id path int true "ID подписки"
+// @Param status query string false "Статус: pending, delivered, dead (по умолчанию — все)"
+// @Param page query int false "Номер страницы (с 1)"
+// @Param limit query int false "Размер страницы (1–100, по умолчанию 20)"
+// @Security BearerAuth
+// @Success 200 {object} models.WebhookDeliveryPage
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 404 {object} errorResponse "Подписка не найдена"
+// @Failure 500 {object} errorResponse
+// @Router /admin/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListWebhookDeliveries(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	deliveries, err := h.service.Deliveries(c.Request.Context(), id, c.Query("status"), page, limit)
+	switch {
+	case errors.Is(err, service.ErrInvalidDeliveryStatus):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, deliveries)
+	}
+}
+
+// RedeliverWebhook godoc
+// @Summary Повторить доставку
+// @Description Ставит доставку в очередь заново с полным числом попыток, в том числе уже доставленную или исчерпавшую попытки. Доступно администраторам
+// @Tags admin
+// @Param id path int true "ID подписки"
+// @Param delivery path int true "ID доставки"
+// @Security BearerAuth
+// @Success 202 "Поставлено в очередь"
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Недостаточно прав"
+// @Failure 404 {object} errorResponse "Доставка не найдена"
+// @Failure 500 {object} errorResponse
+// @Router /admin/webhooks/{id}/deliveries/{delivery}/redeliver [post]
+func (h *WebhookHandler) RedeliverWebhook(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	deliveryID, err := strconv.ParseInt(c.Param("delivery"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delivery id"})
+		return
+	}
+	err = h.service.Redeliver(c.Request.Context(), id, deliveryID)
+	switch {
+	case errors.Is(err, service.ErrDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.Status(http.StatusAccepted)
+	}
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// API key scopes. A read key may only call safe (GET/HEAD) methods.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// APIKey is a personal access key. The secret itself is shown once on
+// creation and only its hash is stored.
+type APIKey struct {
+	ID         int        `json:"id" example:"1" description:"Идентификатор ключа"`
+	UserID     int        `json:"-"`
+	Name       string     `json:"name" example:"data-export" description:"Название ключа"`
+	Prefix     string     `json:"prefix" example:"fh_3f9a1c2b" description:"Открытая часть ключа"`
+	Scopes     []string   `json:"scopes" example:"read,write" description:"Права: read, write"`
+	ExpiresAt  *time.Time `json:"expires_at" example:"2025-01-01T00:00:00Z" description:"Срок действия (null — бессрочный)"`
+	LastUsedAt *time.Time `json:"last_used_at" example:"2024-06-01T12:00:00Z" description:"Время последнего использования"`
+	RevokedAt  *time.Time `json:"-"`
+	CreatedAt  time.Time  `json:"created_at" example:"2024-01-01T00:00:00Z" description:"Дата создания"`
+}
+
+// HasScope reports whether the key grants scope.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// Audited entity types.
+const (
+	EntityFilm    = "film"
+	EntityReview  = "review"
+	EntityComment = "comment"
+	EntityReport  = "report"
+	EntityUser    = "user"
+	EntityWebhook = "webhook"
+	EntityList    = "list"
+)
+
+// Audited actions.
+const (
+	AuditCreate     = "create"
+	AuditUpdate     = "update"
+	AuditDelete     = "delete"
+	AuditHold       = "hold"
+	AuditReport     = "report"
+	AuditRoleChange = "role_change"
+	AuditRollback   = "rollback"
+	AuditRestore    = "restore"
+	AuditRedeliver  = "redeliver"
+)
+
+// AuditEntry is one row of the append-only audit log. Before and After hold
+// only the fields that changed; creations have no Before, deletions no After.
+type AuditEntry struct {
+	ID         int64          `json:"id" example:"1" description:"ID записи"`
+	ActorID    *int           `json:"actor_id" example:"5" description:"ID пользователя, выполнившего действие"`
+	ActorRole  string         `json:"actor_role" example:"admin" description:"Роль пользователя в момент действия"`
+	Action     string         `json:"action" example:"update" description:"Действие: create, update, delete, hold, report, role_change, rollback, restore, redeliver, hide, dismiss"`
+	EntityType string         `json:"entity_type" example:"film" description:"Тип сущности: film, review, comment, report, user, webhook, list"`
+	EntityID   int            `json:"entity_id" example:"1" description:"ID сущности"`
+	Before     map[string]any `json:"before,omitempty" description:"Изменённые поля до операции"`
+	After      map[string]any `json:"after,omitempty" description:"Изменённые поля после операции"`
+	IP         string         `json:"ip" example:"203.0.113.7" description:"IP-адрес клиента"`
+	RequestID  string         `json:"request_id" example:"4f1c2a9e8b7d6c5a" description:"ID запроса (заголовок X-Request-ID)"`
+	CreatedAt  time.Time      `json:"created_at" example:"2024-01-02T00:00:00Z" description:"Время действия"`
+}
+
+// AuditFilter narrows the audit log listing; zero values match everything.
+type AuditFilter struct {
+	ActorID    int
+	EntityType string
+	EntityID   int
+	Action     string
+	From       time.Time
+	To         time.Time
+}
+
+// AuditPage is one page of the audit log.
+type AuditPage struct {
+	Items []AuditEntry `json:"items" description:"Записи, новые первыми"`
+	Total int          `json:"total" example:"42" description:"Всего записей по фильтру"`
+	Page  int          `json:"page" example:"1" description:"Номер страницы"`
+	Limit int          `json:"limit" example:"20" description:"Размер страницы"`
+}
+
+type RoleRequest struct {
+	Role UserRole `json:"role" validate:"required,oneof=admin moderator user" example:"moderator" description:"Новая роль: admin, moderator, user"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// CacheStats reports how a read cache performs since startup.
+type CacheStats struct {
+	Hits     uint64  `json:"hits" example:"1200" description:"Запросы, обслуженные из кэша"`
+	Misses   uint64  `json:"misses" example:"80" description:"Запросы, ушедшие в базу данных"`
+	Shared   uint64  `json:"shared" example:"15" description:"Промахи, дождавшиеся уже идущего запроса к базе вместо своего"`
+	HitRatio float64 `json:"hit_ratio" example:"0.94" description:"Доля попаданий от всех запросов"`
+	Entries  int     `json:"entries" example:"350" description:"Записей в кэше сейчас"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Charts of films.
+const (
+	// ChartTrending: most reviewed lately, recent reviews counting more.
+	ChartTrending = "trending"
+	// ChartTopRated: best rated by Bayesian average.
+	ChartTopRated = "top-rated"
+	// ChartNewReleases: released most recently.
+	ChartNewReleases = "new-releases"
+	// ChartMostWatchlisted: on the most watchlists.
+	ChartMostWatchlisted = "most-watchlisted"
+)
+
+// Charts lists every chart in the order they are computed.
+var Charts = []string{ChartTrending, ChartTopRated, ChartNewReleases, ChartMostWatchlisted}
+
+// How a film moved since the previous computation of a chart.
+const (
+	ChartMoveUp   = "up"
+	ChartMoveDown = "down"
+	ChartMoveSame = "same"
+	ChartMoveNew  = "new"
+)
+
+// ChartEntry is a film's place in a chart.
+type ChartEntry struct {
+	Rank         int       `json:"rank" example:"1" description:"Место в чарте, начиная с 1"`
+	FilmID       int       `json:"film_id" example:"12" description:"ID фильма"`
+	Title        string    `json:"title" example:"Heat" description:"Название фильма"`
+	Rating       float32   `json:"rating" example:"8.3" description:"Средняя оценка фильма"`
+	ReleaseDate  time.Time `json:"release_date" example:"1995-12-15T00:00:00Z" description:"Дата выхода фильма"`
+	Score        float64   `json:"score" example:"4.71" description:"Значение, по которому построен чарт: trending — взвешенное по давности число отзывов, top-rated — байесовская средняя оценка, new-releases — время выхода (Unix), most-watchlisted — число пользователей"`
+	PreviousRank *int      `json:"previous_rank" example:"3" description:"Место при прошлом пересчёте (null — фильма в чарте не было)"`
+	Change       int       `json:"change" example:"2" description:"На сколько мест фильм поднялся (отрицательное — опустился)"`
+	Movement     string    `json:"movement" example:"up" description:"Изменение места: up, down, same, new"`
+}
+
+// Chart is the current entries of a chart.
+type Chart struct {
+	Chart      string       `json:"chart" example:"trending" description:"Чарт: trending, top-rated, new-releases, most-watchlisted"`
+	Items      []ChartEntry `json:"items" description:"Фильмы по местам"`
+	ComputedAt *time.Time   `json:"computed_at" example:"2024-01-02T03:00:00Z" description:"Когда чарт последний раз пересчитан (null — ещё не пересчитывался)"`
+}
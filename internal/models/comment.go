@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Comment is a comment on a review. Top-level comments carry their replies;
+// replies cannot be replied to.
+type Comment struct {
+	ID        int        `json:"id" example:"1" description:"ID комментария"`
+	ReviewID  int        `json:"review_id" example:"1" description:"ID отзыва"`
+	ParentID  *int       `json:"parent_id" example:"1" description:"ID комментария, на который дан ответ (null — комментарий верхнего уровня)"`
+	UserID    int        `json:"user_id" example:"1" description:"ID автора"`
+	Body      string     `json:"body" example:"Согласен, концовка сильная" description:"Текст комментария"`
+	CreatedAt time.Time  `json:"created_at" example:"2023-01-01T00:00:00Z" description:"Дата создания"`
+	UpdatedAt *time.Time `json:"updated_at" example:"2023-01-02T00:00:00Z" description:"Дата последнего изменения"`
+	HiddenAt  *time.Time `json:"hidden_at,omitempty" example:"2024-01-02T00:00:00Z" description:"Когда комментарий скрыт модератором (видно только автору)"`
+	Replies   []Comment  `json:"replies,omitempty" description:"Ответы (только у комментариев верхнего уровня)"`
+}
+
+type CommentRequest struct {
+	Body     string `json:"body" validate:"required,comment" example:"Согласен, концовка сильная" description:"Текст комментария"`
+	ParentID *int   `json:"parent_id" validate:"omitempty,min=1" example:"1" description:"ID комментария, на который отвечаете"`
+}
+
+type CommentUpdateRequest struct {
+	Body string `json:"body" validate:"required,comment" example:"Согласен, концовка очень сильная" description:"Новый текст комментария"`
+}
+
+// CommentPage is one page of top-level comments of a review.
+type CommentPage struct {
+	Items []Comment `json:"items" description:"Комментарии верхнего уровня с ответами"`
+	Total int       `json:"total" example:"42" description:"Всего комментариев верхнего уровня"`
+	Page  int       `json:"page" example:"1" description:"Номер страницы"`
+	Limit int       `json:"limit" example:"20" description:"Размер страницы"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Domain event types published through the outbox.
+const (
+	EventFilmCreated    = "film.created"
+	EventFilmUpdated    = "film.updated"
+	EventReviewCreated  = "review.created"
+	EventUserRegistered = "user.registered"
+)
+
+// FilmEvent is the payload of film events.
+type FilmEvent struct {
+	ID          int       `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	ReleaseDate time.Time `json:"release_date"`
+	Genres      []string  `json:"genres,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Version     int       `json:"version"`
+}
+
+// ReviewEvent is the payload of review events.
+type ReviewEvent struct {
+	ID      int    `json:"id"`
+	FilmID  int    `json:"film_id"`
+	UserID  int    `json:"user_id"`
+	Rating  int    `json:"rating"`
+	Comment string `json:"comment"`
+}
+
+// UserEvent is the payload of user events; it leaves out contact details.
+type UserEvent struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+}
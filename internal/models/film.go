@@ -7,14 +7,47 @@ type Film struct {
 	Title       string    `json:"title" validate:"required" example:"The Matrix" description:"Название фильма"`
 	Description string    `json:"description" validate:"required" example:"Sci-fi action movie about virtual reality" description:"Описание фильма"`
 	ReleaseDate time.Time `json:"release_date" example:"1999-03-31T00:00:00Z" description:"Дата выхода фильма"`
-	Rating      float32   `json:"rating" example:"8.7" description:"Рейтинг фильма"`
+	Rating      float32   `json:"rating" example:"8.7" description:"Средняя оценка по опубликованным отзывам; 0, если их нет"`
 	CreatedAt   time.Time `json:"created_at" example:"2023-01-01T00:00:00Z" description:"Дата создания записи"`
+
+	CreatedBy *int       `json:"created_by" example:"5" description:"ID пользователя, добавившего фильм"`
+	UpdatedBy *int       `json:"updated_by" example:"5" description:"ID пользователя, последним изменившего фильм"`
+	UpdatedAt *time.Time `json:"updated_at" example:"2023-01-02T00:00:00Z" description:"Дата последнего изменения"`
+	Version   int        `json:"version" example:"3" description:"Версия записи; передаётся в If-Match при изменении"`
+	Genres    []string   `json:"genres" example:"action,sci-fi" description:"Жанры фильма по алфавиту"`
+	Tags      []string   `json:"tags" example:"cyberpunk,dystopia" description:"Теги фильма по алфавиту"`
 }
 
 type FilmRequest struct {
-	Title       string    `json:"title" validate:"required" example:"The Matrix" description:"Название фильма"`
+	Title       string    `json:"title" validate:"required,max=255" example:"The Matrix" description:"Название фильма"`
 	Description string    `json:"description" validate:"required" example:"Sci-fi action movie about virtual reality" description:"Описание фильма"`
-	ReleaseDate time.Time `json:"release_date" example:"1999-03-31T00:00:00Z" description:"Дата выхода фильма"`
+	ReleaseDate time.Time `json:"release_date" validate:"notfarfuture" example:"1999-03-31T00:00:00Z" description:"Дата выхода фильма"`
+}
+
+// FilmCredit is a person who worked on a film and in what role.
+type FilmCredit struct {
+	Name string `json:"name" validate:"required,max=255" example:"Keanu Reeves" description:"Имя"`
+	Role string `json:"role" validate:"required,oneof=actor director writer producer composer cinematographer" example:"actor" description:"Роль: actor, director, writer, producer, composer, cinematographer"`
+}
+
+// FilmImport is one film of an import, with its genres, tags and credits.
+type FilmImport struct {
+	Title       string       `json:"title" validate:"required,max=255" example:"The Matrix" description:"Название фильма"`
+	Description string       `json:"description" validate:"required" example:"Sci-fi action movie about virtual reality" description:"Описание фильма"`
+	ReleaseDate time.Time    `json:"release_date" validate:"notfarfuture" example:"1999-03-31T00:00:00Z" description:"Дата выхода фильма"`
+	Genres      []string     `json:"genres" validate:"max=10,dive,required,max=64" example:"sci-fi,action" description:"Жанры; неизвестные создаются"`
+	Tags        []string     `json:"tags" validate:"max=20,dive,required,max=64" example:"cyberpunk,dystopia" description:"Теги; неизвестные создаются"`
+	Credits     []FilmCredit `json:"credits" validate:"max=100,dive" description:"Актёры и съёмочная группа; неизвестные люди создаются"`
+}
+
+// FilmImportRequest is a batch of films imported all or nothing.
+type FilmImportRequest struct {
+	Films []FilmImport `json:"films" validate:"required,min=1,max=100,dive" description:"Импортируемые фильмы (до 100)"`
+}
+
+// FilmImportResult lists the IDs of imported films in request order.
+type FilmImportResult struct {
+	IDs []int `json:"ids" example:"1" description:"ID созданных фильмов в порядке запроса"`
 }
 
 type Review struct {
@@ -24,4 +57,74 @@ type Review struct {
 	Rating    int       `json:"rating" validate:"required,min=1,max=10" example:"8" description:"Оценка от 1 до 10"`
 	Comment   string    `json:"comment" example:"Отличный фильм!" description:"Комментарий к отзыву"`
 	CreatedAt time.Time `json:"created_at" example:"2023-01-01T00:00:00Z" description:"Дата создания отзыва"`
+
+	HelpfulCount   int `json:"helpful_count" example:"12" description:"Сколько пользователей отметили отзыв полезным"`
+	UnhelpfulCount int `json:"unhelpful_count" example:"1" description:"Сколько пользователей отметили отзыв бесполезным"`
+	CommentCount   int `json:"comment_count" example:"4" description:"Количество комментариев, включая ответы"`
+
+	UpdatedAt *time.Time `json:"updated_at" example:"2023-01-02T00:00:00Z" description:"Дата последнего изменения текста или оценки"`
+	Version   int        `json:"version" example:"1" description:"Версия отзыва; меняется при правке автором"`
+
+	HiddenAt *time.Time `json:"hidden_at,omitempty" example:"2024-01-02T00:00:00Z" description:"Когда отзыв скрыт модератором (видно только автору)"`
+	// HeldReasons lists what the content filter found when it held the review.
+	HeldReasons []string `json:"-"`
+}
+
+// Orderings accepted by the review listing.
+const (
+	ReviewSortNewest  = "newest"
+	ReviewSortHelpful = "helpful"
+	ReviewSortRating  = "rating"
+)
+
+type ReviewVoteRequest struct {
+	Helpful *bool `json:"helpful" validate:"required" example:"true" description:"true — полезный, false — бесполезный; повторный такой же голос отменяет его"`
+}
+
+// ReviewVotes is the vote tally of a review after a vote.
+type ReviewVotes struct {
+	ReviewID       int `json:"review_id" example:"1" description:"ID отзыва"`
+	HelpfulCount   int `json:"helpful_count" example:"12" description:"Голоса «полезно»"`
+	UnhelpfulCount int `json:"unhelpful_count" example:"1" description:"Голоса «бесполезно»"`
+	MyVote         int `json:"my_vote" example:"1" description:"Голос текущего пользователя: 1, -1 или 0"`
+}
+
+type ReviewRequest struct {
+	Rating  int    `json:"rating" validate:"required,min=1,max=10" example:"8" description:"Оценка от 1 до 10"`
+	Comment string `json:"comment" validate:"comment" example:"Отличный фильм!" description:"Комментарий к отзыву"`
+}
+
+// Kinds of film revisions.
+const (
+	RevisionCreate   = "create"
+	RevisionUpdate   = "update"
+	RevisionRollback = "rollback"
+)
+
+// FilmRevision is a snapshot of a film's editable fields after a change.
+type FilmRevision struct {
+	FilmID       int       `json:"film_id" example:"1" description:"ID фильма"`
+	Revision     int       `json:"revision" example:"3" description:"Номер ревизии, начиная с 1"`
+	Title        string    `json:"title" example:"The Matrix" description:"Название фильма"`
+	Description  string    `json:"description" example:"Sci-fi action movie about virtual reality" description:"Описание фильма"`
+	ReleaseDate  time.Time `json:"release_date" example:"1999-03-31T00:00:00Z" description:"Дата выхода фильма"`
+	AuthorID     *int      `json:"author_id" example:"5" description:"ID пользователя, внёсшего изменение"`
+	Action       string    `json:"action" example:"update" description:"Изменение: create, update, rollback"`
+	RestoredFrom *int      `json:"restored_from" example:"1" description:"Номер ревизии, к которой выполнен откат"`
+	CreatedAt    time.Time `json:"created_at" example:"2023-01-02T00:00:00Z" description:"Время изменения"`
+}
+
+// FieldChange is one field that differs between two revisions.
+type FieldChange struct {
+	Field string `json:"field" example:"title" description:"Поле: title, description, release_date"`
+	From  any    `json:"from" example:"Matrix" description:"Значение в ревизии from"`
+	To    any    `json:"to" example:"The Matrix" description:"Значение в ревизии to"`
+}
+
+// FilmDiff lists the fields that differ between two revisions of a film.
+type FilmDiff struct {
+	FilmID  int           `json:"film_id" example:"1" description:"ID фильма"`
+	From    int           `json:"from" example:"1" description:"Исходная ревизия"`
+	To      int           `json:"to" example:"3" description:"Сравниваемая ревизия"`
+	Changes []FieldChange `json:"changes" description:"Изменённые поля; пусто, если ревизии совпадают"`
 }
@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// Visibility of a film list.
+const (
+	// ListPublic lists are shown in discovery.
+	ListPublic = "public"
+	// ListUnlisted lists are seen by anyone with the link.
+	ListUnlisted = "unlisted"
+	// ListPrivate lists are seen by the owner and collaborators only.
+	ListPrivate = "private"
+)
+
+// FilmList is a user-curated, ordered list of films.
+type FilmList struct {
+	ID            int                `json:"id" example:"1" description:"ID списка"`
+	OwnerID       int                `json:"owner_id" example:"5" description:"ID владельца"`
+	OwnerName     string             `json:"owner_name" example:"john" description:"Имя владельца"`
+	Title         string             `json:"title" example:"Лучшая фантастика 90-х" description:"Название"`
+	Description   string             `json:"description" example:"От «Терминатора 2» до «Матрицы»" description:"Описание"`
+	Visibility    string             `json:"visibility" example:"public" description:"Видимость: public — виден всем и в поиске, unlisted — по ссылке, private — только владельцу и соавторам"`
+	EntryCount    int                `json:"entry_count" example:"10" description:"Количество фильмов"`
+	LikeCount     int                `json:"like_count" example:"42" description:"Количество лайков"`
+	Liked         bool               `json:"liked" example:"false" description:"Лайкнул ли список текущий пользователь"`
+	CanEdit       bool               `json:"can_edit" example:"false" description:"Может ли текущий пользователь менять фильмы списка"`
+	Collaborators []ListCollaborator `json:"collaborators,omitempty" description:"Соавторы, которые могут менять фильмы списка"`
+	Entries       []FilmListEntry    `json:"entries,omitempty" description:"Фильмы по порядку"`
+	CreatedAt     time.Time          `json:"created_at" example:"2024-01-02T03:04:05Z" description:"Дата создания"`
+	UpdatedAt     time.Time          `json:"updated_at" example:"2024-01-03T03:04:05Z" description:"Дата последнего изменения списка или его фильмов"`
+}
+
+// ListCollaborator is a user who may edit the entries of a list.
+type ListCollaborator struct {
+	UserID   int    `json:"user_id" example:"7" description:"ID пользователя"`
+	Username string `json:"username" example:"jane" description:"Имя пользователя"`
+}
+
+// FilmListEntry is a film in a list.
+type FilmListEntry struct {
+	Position    int       `json:"position" example:"1" description:"Место в списке, начиная с 1"`
+	FilmID      int       `json:"film_id" example:"12" description:"ID фильма"`
+	Title       string    `json:"title" example:"The Matrix" description:"Название фильма"`
+	Rating      float32   `json:"rating" example:"8.7" description:"Средняя оценка фильма"`
+	ReleaseDate time.Time `json:"release_date" example:"1999-03-31T00:00:00Z" description:"Дата выхода фильма"`
+	Note        string    `json:"note" example:"Начните с этого" description:"Заметка к фильму"`
+	AddedBy     *int      `json:"added_by" example:"5" description:"ID пользователя, добавившего фильм"`
+	AddedAt     time.Time `json:"added_at" example:"2024-01-02T03:04:05Z" description:"Когда фильм добавлен"`
+}
+
+// FilmListPage is a page of lists without their entries.
+type FilmListPage struct {
+	Items []FilmList `json:"items" description:"Списки"`
+	Total int        `json:"total" example:"42" description:"Всего списков"`
+	Page  int        `json:"page" example:"1" description:"Номер страницы"`
+	Limit int        `json:"limit" example:"20" description:"Размер страницы"`
+}
+
+type FilmListRequest struct {
+	Title       string `json:"title" validate:"required,max=255" example:"Лучшая фантастика 90-х" description:"Название"`
+	Description string `json:"description" validate:"max=5000" example:"От «Терминатора 2» до «Матрицы»" description:"Описание"`
+	Visibility  string `json:"visibility" validate:"omitempty,oneof=public unlisted private" example:"public" description:"Видимость: public (по умолчанию), unlisted, private"`
+}
+
+type FilmListEntryRequest struct {
+	FilmID int    `json:"film_id" validate:"required" example:"12" description:"ID фильма"`
+	Note   string `json:"note" validate:"max=1000" example:"Начните с этого" description:"Заметка к фильму"`
+}
+
+// FilmListEntryUpdateRequest changes the note of an entry, moves it, or
+// both.
+type FilmListEntryUpdateRequest struct {
+	Note     *string `json:"note" validate:"omitempty,max=1000" example:"Начните с этого" description:"Новая заметка"`
+	Position *int    `json:"position" validate:"omitempty,min=1" example:"1" description:"Новое место; больше числа фильмов — в конец"`
+}
+
+// FilmListOrderRequest orders all films of a list.
+type FilmListOrderRequest struct {
+	FilmIDs []int `json:"film_ids" validate:"required,min=1" example:"12,7,3" description:"ID всех фильмов списка в новом порядке"`
+}
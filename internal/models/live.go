@@ -0,0 +1,23 @@
+package models
+
+// Live update events streamed to clients over SSE.
+const (
+	LiveReviewCreated = "review.created" // data: Review
+	LiveReviewUpdated = "review.updated" // data: Review
+	LiveReviewRemoved = "review.removed" // data: ReviewRef; deleted or held on edit
+	LiveReviewHeld    = "review.held"    // data: Review; moderation stream only
+	LiveFilmUpdated   = "film.updated"   // data: Film
+	LiveFilmRating    = "film.rating"    // data: FilmRating
+)
+
+// ReviewRef identifies a review that left a film page.
+type ReviewRef struct {
+	ID     int `json:"id"`
+	FilmID int `json:"film_id"`
+}
+
+// FilmRating is a film's new average rating.
+type FilmRating struct {
+	FilmID int     `json:"film_id"`
+	Rating float32 `json:"rating"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// Kinds of reportable content.
+const (
+	TargetReview  = "review"
+	TargetComment = "comment"
+)
+
+// ReasonAutoFilter marks reports filed by the automatic content filter.
+const ReasonAutoFilter = "auto_filter"
+
+// Report statuses. An open report waits in the moderator queue.
+const (
+	ReportOpen      = "open"
+	ReportActioned  = "actioned"
+	ReportDismissed = "dismissed"
+)
+
+// Moderator actions recorded in the audit trail.
+const (
+	ModerationHide    = "hide"
+	ModerationRestore = "restore"
+	ModerationDismiss = "dismiss"
+)
+
+// Report is a complaint about a review or a comment, filed by a user or by
+// the automatic content filter.
+type Report struct {
+	ID           int        `json:"id" example:"1" description:"ID жалобы"`
+	TargetType   string     `json:"target_type" example:"comment" description:"Тип содержимого: review, comment"`
+	TargetID     int        `json:"target_id" example:"3" description:"ID отзыва или комментария"`
+	ReporterID   *int       `json:"reporter_id" example:"2" description:"ID пожаловавшегося пользователя (null — автоматический фильтр)"`
+	Reason       string     `json:"reason" example:"spam" description:"Причина: spam, abuse, spoiler, off_topic, other, auto_filter"`
+	Details      string     `json:"details" example:"Ссылка на сторонний сайт" description:"Пояснение"`
+	HeldOnCreate bool       `json:"held_on_create" example:"false" description:"Отзыв задержан фильтром при публикации, а не при изменении"`
+	Status       string     `json:"status" example:"open" description:"Статус: open, actioned, dismissed"`
+	CreatedAt    time.Time  `json:"created_at" example:"2024-01-01T00:00:00Z" description:"Дата жалобы"`
+	ResolvedAt   *time.Time `json:"resolved_at" example:"2024-01-02T00:00:00Z" description:"Дата рассмотрения"`
+	ResolvedBy   *int       `json:"resolved_by" example:"5" description:"ID модератора"`
+}
+
+type ReportRequest struct {
+	Reason  string `json:"reason" validate:"required,oneof=spam abuse spoiler off_topic other" example:"spam" description:"Причина: spam, abuse, spoiler, off_topic, other"`
+	Details string `json:"details" validate:"max=1000" example:"Ссылка на сторонний сайт" description:"Пояснение (необязательно)"`
+}
+
+// ReportPage is one page of the moderator queue.
+type ReportPage struct {
+	Items []Report `json:"items" description:"Жалобы, старые первыми"`
+	Total int      `json:"total" example:"42" description:"Всего жалоб с этим статусом"`
+	Page  int      `json:"page" example:"1" description:"Номер страницы"`
+	Limit int      `json:"limit" example:"20" description:"Размер страницы"`
+}
+
+type ModerationRequest struct {
+	Note string `json:"note" validate:"max=1000" example:"Реклама" description:"Комментарий модератора (необязательно)"`
+}
+
+// ModerationAction is an entry of the moderation audit trail.
+type ModerationAction struct {
+	ID          int       `json:"id" example:"1" description:"ID записи"`
+	ModeratorID int       `json:"moderator_id" example:"5" description:"ID модератора"`
+	Action      string    `json:"action" example:"hide" description:"Действие: hide, restore, dismiss"`
+	TargetType  string    `json:"target_type" example:"comment" description:"Тип содержимого: review, comment"`
+	TargetID    int       `json:"target_id" example:"3" description:"ID отзыва или комментария"`
+	ReportID    *int      `json:"report_id" example:"1" description:"ID жалобы, по которой принято решение"`
+	Note        string    `json:"note" example:"Реклама" description:"Комментарий модератора"`
+	CreatedAt   time.Time `json:"created_at" example:"2024-01-02T00:00:00Z" description:"Время действия"`
+}
+
+// ModerationLogPage is one page of the audit trail.
+type ModerationLogPage struct {
+	Items []ModerationAction `json:"items" description:"Действия, новые первыми"`
+	Total int                `json:"total" example:"42" description:"Всего записей"`
+	Page  int                `json:"page" example:"1" description:"Номер страницы"`
+	Limit int                `json:"limit" example:"20" description:"Размер страницы"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Notification types.
+const (
+	// NotificationReply: someone commented on the user's review or replied
+	// to their comment.
+	NotificationReply = "reply"
+	// NotificationLike: someone marked the user's review helpful.
+	NotificationLike = "like"
+	// NotificationReleaseDate: a film on the user's watchlist got a release
+	// date.
+	NotificationReleaseDate = "release_date"
+)
+
+// Notification is an in-app notification of UserID.
+type Notification struct {
+	ID        int64      `json:"id" example:"15" description:"ID уведомления"`
+	UserID    int        `json:"-"`
+	Type      string     `json:"type" example:"reply" description:"Тип: reply, like, release_date"`
+	ActorID   *int       `json:"actor_id" example:"2" description:"Пользователь, вызвавший уведомление (null — системное)"`
+	FilmID    *int       `json:"film_id" example:"1" description:"ID фильма"`
+	ReviewID  *int       `json:"review_id" example:"7" description:"ID отзыва"`
+	CommentID *int       `json:"comment_id" example:"3" description:"ID комментария"`
+	CreatedAt time.Time  `json:"created_at" example:"2024-01-02T00:00:00Z" description:"Когда создано"`
+	ReadAt    *time.Time `json:"read_at" example:"2024-01-02T00:05:00Z" description:"Когда прочитано (null — не прочитано)"`
+}
+
+// NotificationPage is one page of a user's notifications.
+type NotificationPage struct {
+	Items  []Notification `json:"items" description:"Уведомления, новые первыми"`
+	Total  int            `json:"total" example:"12" description:"Всего уведомлений по фильтру"`
+	Unread int            `json:"unread" example:"3" description:"Всего непрочитанных уведомлений"`
+	Page   int            `json:"page" example:"1" description:"Номер страницы"`
+	Limit  int            `json:"limit" example:"20" description:"Размер страницы"`
+}
+
+// NotificationPreferences tells which notification types a user receives.
+type NotificationPreferences struct {
+	Reply       bool `json:"reply" example:"true" description:"Ответы на отзывы и комментарии"`
+	Like        bool `json:"like" example:"true" description:"Отметки «полезно» на отзывах"`
+	ReleaseDate bool `json:"release_date" example:"false" description:"Дата выхода фильма из списка «Буду смотреть»"`
+}
+
+// NotificationPreferencesRequest changes the given types and leaves the
+// others as they are.
+type NotificationPreferencesRequest struct {
+	Reply       *bool `json:"reply" example:"true" description:"Ответы на отзывы и комментарии"`
+	Like        *bool `json:"like" example:"false" description:"Отметки «полезно» на отзывах"`
+	ReleaseDate *bool `json:"release_date" example:"true" description:"Дата выхода фильма из списка «Буду смотреть»"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// Reasons a film is recommended.
+const (
+	// RecommendSimilar: users who liked films the user rated highly also
+	// liked this one.
+	RecommendSimilar = "similar"
+	// RecommendGenre: popular in a genre the user rates highly.
+	RecommendGenre = "genre"
+	// RecommendPopular: popular overall, for users the service knows little
+	// about.
+	RecommendPopular = "popular"
+)
+
+// Recommendation is a film recommended to a user and why.
+type Recommendation struct {
+	FilmID      int                    `json:"film_id" example:"12" description:"ID фильма"`
+	Title       string                 `json:"title" example:"Heat" description:"Название фильма"`
+	Rating      float32                `json:"rating" example:"8.3" description:"Средняя оценка фильма"`
+	Score       float64                `json:"score" example:"3.42" description:"Вес рекомендации; чем больше, тем выше в списке"`
+	Reason      string                 `json:"reason" example:"similar" description:"Причина: similar — похож на высоко оценённые вами фильмы, genre — популярен в любимом жанре, popular — популярен у зрителей"`
+	BasedOn     []RecommendationSource `json:"based_on,omitempty" description:"Ваши оценки, на которых основана рекомендация similar"`
+	Genre       string                 `json:"genre,omitempty" example:"crime" description:"Жанр рекомендации genre"`
+	Explanation string                 `json:"explanation" example:"Похож на фильмы, которые вы оценили высоко: «Ronin»" description:"Объяснение для показа пользователю"`
+}
+
+// RecommendationSource is a film the user rated that a recommendation is
+// based on.
+type RecommendationSource struct {
+	FilmID int    `json:"film_id" example:"7" description:"ID фильма"`
+	Title  string `json:"title" example:"Ronin" description:"Название фильма"`
+	Rating int    `json:"rating" example:"9" description:"Ваша оценка"`
+}
+
+// RecommendationList is the recommendations for a user.
+type RecommendationList struct {
+	Items      []Recommendation `json:"items" description:"Рекомендации, лучшие первыми"`
+	ComputedAt *time.Time       `json:"computed_at" example:"2024-01-02T03:00:00Z" description:"Когда последний раз пересчитано сходство фильмов (null — ещё не пересчитывалось)"`
+}
+
+// RatedFilm is a film the user reviewed, with the genres of the film.
+type RatedFilm struct {
+	FilmID int
+	Title  string
+	Rating int
+	Genres []string
+}
+
+// FilmNeighbor is a film similar to one the user rated.
+type FilmNeighbor struct {
+	RatedID int
+	FilmID  int
+	Title   string
+	Rating  float32
+	Score   float64
+}
+
+// PopularFilm is a film ranked by the number of its reviews, with the
+// first of the requested genres it has.
+type PopularFilm struct {
+	FilmID  int
+	Title   string
+	Rating  float32
+	Reviews int
+	Genre   string
+}
@@ -0,0 +1,47 @@
+package models
+
+// Signals a film similarity is built from.
+const (
+	// SignalGenres: the films share genres.
+	SignalGenres = "genres"
+	// SignalTags: the films share tags.
+	SignalTags = "tags"
+	// SignalPeople: the films share cast or crew.
+	SignalPeople = "people"
+	// SignalRatings: the same users rate both films alike.
+	SignalRatings = "ratings"
+	// SignalText: the descriptions use the same words.
+	SignalText = "text"
+)
+
+// SimilarFilm is a film similar to another and why.
+type SimilarFilm struct {
+	FilmID  int                `json:"film_id" example:"12" description:"ID фильма"`
+	Title   string             `json:"title" example:"Ronin" description:"Название фильма"`
+	Rating  float32            `json:"rating" example:"7.2" description:"Средняя оценка фильма"`
+	Score   float64            `json:"score" example:"0.41" description:"Взвешенное сходство от 0 до 1"`
+	Signals []SimilaritySignal `json:"signals" description:"Сигналы, давшие вклад в сходство, самый весомый первым"`
+}
+
+// SimilaritySignal is how much one signal contributes to a film
+// similarity.
+type SimilaritySignal struct {
+	Signal       string   `json:"signal" example:"genres" description:"Сигнал: genres — общие жанры, tags — общие теги, people — общие актёры и создатели, ratings — похожие оценки зрителей, text — похожие описания"`
+	Similarity   float64  `json:"similarity" example:"0.67" description:"Сходство по сигналу от 0 до 1"`
+	Contribution float64  `json:"contribution" example:"0.2" description:"Вклад в итоговое сходство с учётом веса сигнала"`
+	Shared       []string `json:"shared,omitempty" example:"crime,thriller" description:"Общие жанры, теги, люди или слова описания"`
+	CoRaters     int      `json:"co_raters,omitempty" example:"14" description:"Сколько зрителей оценили оба фильма (для ratings)"`
+	Explanation  string   `json:"explanation" example:"Общие жанры: crime, thriller" description:"Объяснение для показа пользователю"`
+}
+
+// FilmFeatures is what film similarity is computed from: a film's genres
+// and tags, the names of its cast and crew, and its description.
+type FilmFeatures struct {
+	FilmID      int
+	Title       string
+	Rating      float32
+	Description string
+	Genres      []string
+	Tags        []string
+	People      []string
+}
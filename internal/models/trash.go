@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// TrashItem is a soft-deleted film or review awaiting restore or purge.
+type TrashItem struct {
+	Type      string    `json:"type" example:"film" description:"Тип: film, review"`
+	ID        int       `json:"id" example:"1" description:"ID фильма или отзыва"`
+	Title     string    `json:"title" example:"The Matrix" description:"Название фильма или начало текста отзыва"`
+	DeletedAt time.Time `json:"deleted_at" example:"2024-01-02T00:00:00Z" description:"Когда удалено"`
+	DeletedBy *int      `json:"deleted_by" example:"5" description:"ID удалившего пользователя"`
+	PurgeAt   time.Time `json:"purge_at" example:"2024-02-01T00:00:00Z" description:"Когда будет удалено окончательно"`
+}
+
+// TrashPage is one page of the trash.
+type TrashPage struct {
+	Items []TrashItem `json:"items" description:"Удалённые записи, новые первыми"`
+	Total int         `json:"total" example:"3" description:"Всего записей этого типа в корзине"`
+	Page  int         `json:"page" example:"1" description:"Номер страницы"`
+	Limit int         `json:"limit" example:"20" description:"Размер страницы"`
+}
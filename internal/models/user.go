@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type UserRole string
 
 const (
@@ -8,10 +10,26 @@ const (
 	RoleUser      UserRole = "user"
 )
 
+// CanModerate reports whether the role may act on other users' content.
+func (r UserRole) CanModerate() bool {
+	return r == RoleAdmin || r == RoleModerator
+}
+
 type User struct {
-	ID       int      `json:"id"`
-	Username string   `json:"username"`
-	Email    string   `json:"email"`
+	ID       int      `json:"id" example:"1" description:"ID пользователя"`
+	Username string   `json:"username" example:"john" description:"Имя пользователя"`
+	Email    string   `json:"email" example:"john@example.com" description:"Email"`
 	Password string   `json:"-"`
-	Role     UserRole `json:"role"`
+	Role     UserRole `json:"role" example:"user" description:"Роль: admin, moderator, user"`
+
+	EmailVerified bool `json:"email_verified" example:"true" description:"Подтверждён ли email"`
+}
+
+// Identity links an external OpenID Connect account to a user.
+type Identity struct {
+	Provider  string    `json:"provider" example:"google" description:"Имя OIDC-провайдера"`
+	Subject   string    `json:"subject" example:"1234567890" description:"Идентификатор пользователя у провайдера"`
+	UserID    int       `json:"user_id" example:"1" description:"ID пользователя"`
+	Email     string    `json:"email" example:"john@example.com" description:"Email у провайдера"`
+	CreatedAt time.Time `json:"created_at" example:"2023-01-01T00:00:00Z" description:"Дата привязки"`
 }
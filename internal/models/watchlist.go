@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// WatchlistItem is a film on a user's watchlist.
+type WatchlistItem struct {
+	FilmID      int       `json:"film_id" example:"1" description:"ID фильма"`
+	Title       string    `json:"title" example:"The Matrix" description:"Название фильма"`
+	ReleaseDate time.Time `json:"release_date" example:"1999-03-31T00:00:00Z" description:"Дата выхода фильма"`
+	AddedAt     time.Time `json:"added_at" example:"2024-01-02T00:00:00Z" description:"Когда фильм добавлен в список"`
+}
+
+// WatchlistPage is one page of a user's watchlist.
+type WatchlistPage struct {
+	Items []WatchlistItem `json:"items" description:"Фильмы, добавленные последними — первыми"`
+	Total int             `json:"total" example:"4" description:"Всего фильмов в списке"`
+	Page  int             `json:"page" example:"1" description:"Номер страницы"`
+	Limit int             `json:"limit" example:"20" description:"Размер страницы"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// WebhookSubscription sends events of the listed types to URL.
+type WebhookSubscription struct {
+	ID         int       `json:"id" example:"1" description:"ID подписки"`
+	URL        string    `json:"url" example:"https://partner.example.com/hooks/filmhub" description:"Адрес, на который отправляются события"`
+	EventTypes []string  `json:"event_types" example:"film.created,film.updated" description:"Типы событий"`
+	Secret     string    `json:"secret,omitempty" example:"whsec_4f1c2a9e8b7d6c5a4f1c2a9e8b7d6c5a" description:"Ключ подписи; показывается только при создании"`
+	CreatedBy  *int      `json:"created_by" example:"1" description:"ID создавшего администратора"`
+	CreatedAt  time.Time `json:"created_at" example:"2024-01-01T00:00:00Z" description:"Дата создания"`
+}
+
+// WebhookRequest creates a subscription. Without a secret one is generated.
+type WebhookRequest struct {
+	URL        string   `json:"url" validate:"required,url,max=2000" example:"https://partner.example.com/hooks/filmhub" description:"Адрес получателя (http или https)"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,oneof=film.created film.updated review.created user.registered" example:"film.created,film.updated" description:"Типы событий: film.created, film.updated, review.created, user.registered"`
+	Secret     string   `json:"secret" validate:"omitempty,min=16,max=200" example:"" description:"Ключ подписи (необязательно, не короче 16 символов)"`
+}
+
+// WebhookDelivery is one event queued for one subscription, with the
+// outcome of its latest attempt.
+type WebhookDelivery struct {
+	ID             int64      `json:"id" example:"10" description:"ID доставки"`
+	SubscriptionID int        `json:"subscription_id" example:"1" description:"ID подписки"`
+	EventID        int64      `json:"event_id" example:"42" description:"ID события (совпадает у повторных доставок)"`
+	EventType      string     `json:"event_type" example:"film.created" description:"Тип события"`
+	Status         string     `json:"status" example:"pending" description:"Статус: pending, delivered, dead"`
+	Attempts       int        `json:"attempts" example:"2" description:"Число неудачных попыток"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at" example:"2024-01-02T00:00:20Z" description:"Время следующей попытки (для pending)"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at" example:"2024-01-02T00:00:00Z" description:"Время последней попытки"`
+	LastStatusCode *int       `json:"last_status_code" example:"502" description:"HTTP-код последнего ответа"`
+	LastError      *string    `json:"last_error" example:"endpoint responded 502 Bad Gateway" description:"Ошибка последней попытки"`
+	CreatedAt      time.Time  `json:"created_at" example:"2024-01-02T00:00:00Z" description:"Когда событие поставлено в очередь"`
+	DeliveredAt    *time.Time `json:"delivered_at" example:"2024-01-02T00:00:21Z" description:"Когда доставлено"`
+}
+
+// WebhookDeliveryPage is one page of a subscription's delivery log.
+type WebhookDeliveryPage struct {
+	Items []WebhookDelivery `json:"items" description:"Доставки, новые первыми"`
+	Total int               `json:"total" example:"3" description:"Всего доставок с этим статусом"`
+	Page  int               `json:"page" example:"1" description:"Номер страницы"`
+	Limit int               `json:"limit" example:"20" description:"Размер страницы"`
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"filmhub/internal/models"
+)
+
+// APIKeyRepository stores personal API keys by the SHA-256 of the secret.
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey, hash string) error
+	// FindByHash returns pgx.ErrNoRows for unknown keys.
+	FindByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	// ListByUser returns the user's keys that are not revoked.
+	ListByUser(ctx context.Context, userID int) ([]models.APIKey, error)
+	// Revoke returns pgx.ErrNoRows when the user has no such active key.
+	Revoke(ctx context.Context, userID, id int) error
+	TouchLastUsed(ctx context.Context, id int) error
+}
+
+type apiKeyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAPIKeyRepository(db *pgxpool.Pool) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+const apiKeyColumns = `id, user_id, name, prefix, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *models.APIKey, hash string) error {
+	return conn(ctx, r.db).QueryRow(ctx,
+		`INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at)
+         VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`,
+		key.UserID, key.Name, key.Prefix, hash, key.Scopes, key.ExpiresAt,
+	).Scan(&key.ID, &key.CreatedAt)
+}
+
+func (r *apiKeyRepository) FindByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	return scanAPIKey(conn(ctx, r.db).QueryRow(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, hash))
+}
+
+func (r *apiKeyRepository) ListByUser(ctx context.Context, userID int) ([]models.APIKey, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys
+         WHERE user_id = $1 AND revoked_at IS NULL ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, userID, id int) error {
+	tag, err := conn(ctx, r.db).Exec(ctx,
+		`UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id int) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE api_keys SET last_used_at = now() WHERE id = $1`, id)
+	return err
+}
+
+func scanAPIKey(row pgx.Row) (*models.APIKey, error) {
+	var k models.APIKey
+	if err := row.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.Scopes, &k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt, &k.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"filmhub/internal/models"
+)
+
+// AuditRepository appends to and reads the audit log. The table rejects
+// updates and deletes, so there are no methods for them.
+type AuditRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAuditRepository(db *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+const auditColumns = `id, actor_id, actor_role, action, entity_type, entity_id, before, after, ip, request_id, created_at`
+
+// Record appends entry and fills its ID and creation time.
+func (r *AuditRepository) Record(ctx context.Context, entry *models.AuditEntry) error {
+	return conn(ctx, r.db).QueryRow(ctx,
+		`INSERT INTO audit_log (actor_id, actor_role, action, entity_type, entity_id, before, after, ip, request_id)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at`,
+		entry.ActorID, entry.ActorRole, entry.Action, entry.EntityType, entry.EntityID,
+		entry.Before, entry.After, entry.IP, entry.RequestID,
+	).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+// List returns a page of entries matching filter, newest first, and the
+// number of matching entries.
+func (r *AuditRepository) List(ctx context.Context, filter models.AuditFilter, limit, offset int) ([]models.AuditEntry, int, error) {
+	var conds []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if filter.ActorID != 0 {
+		add("actor_id = $%d", filter.ActorID)
+	}
+	if filter.EntityType != "" {
+		add("entity_type = $%d", filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		add("entity_id = $%d", filter.EntityID)
+	}
+	if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+	if !filter.From.IsZero() {
+		add("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add("created_at < $%d", filter.To)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx, `SELECT count(*) FROM audit_log`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	args = append(args, limit, offset)
+	rows, err := conn(ctx, r.db).Query(ctx,
+		fmt.Sprintf(`SELECT `+auditColumns+` FROM audit_log%s ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d`,
+			where, len(args)-1, len(args)),
+		args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var entries []models.AuditEntry
+	for rows.Next() {
+		var e models.AuditEntry
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.ActorRole, &e.Action, &e.EntityType, &e.EntityID,
+			&e.Before, &e.After, &e.IP, &e.RequestID, &e.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, total, rows.Err()
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"filmhub/internal/models"
+	"filmhub/pkg/charts"
+)
+
+// ChartRepository reads what charts are computed from and stores the
+// computed charts.
+type ChartRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewChartRepository(db *pgxpool.Pool) *ChartRepository {
+	return &ChartRepository{db: db}
+}
+
+// ReviewActivity returns when each published review written since since
+// was written, for films not in the trash.
+func (r *ChartRepository) ReviewActivity(ctx context.Context, since time.Time) ([]charts.Activity, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT r.film_id, r.created_at FROM reviews r JOIN films f ON f.id = r.film_id
+         WHERE r.created_at >= $1 AND r.deleted_at IS NULL AND r.hidden_at IS NULL AND f.deleted_at IS NULL`, since)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (charts.Activity, error) {
+		var a charts.Activity
+		err := row.Scan(&a.ItemID, &a.At)
+		return a, err
+	})
+}
+
+// RatingSummaries returns the number and mean of the published review
+// ratings of every reviewed film that is not in the trash.
+func (r *ChartRepository) RatingSummaries(ctx context.Context) ([]charts.RatingSummary, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT r.film_id, count(*), avg(r.rating)::float8 FROM reviews r JOIN films f ON f.id = r.film_id
+         WHERE r.deleted_at IS NULL AND r.hidden_at IS NULL AND f.deleted_at IS NULL
+         GROUP BY r.film_id`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (charts.RatingSummary, error) {
+		var s charts.RatingSummary
+		err := row.Scan(&s.ItemID, &s.Count, &s.Mean)
+		return s, err
+	})
+}
+
+// Releases returns the films released after since and up to until, scored
+// by release time as Unix seconds.
+func (r *ChartRepository) Releases(ctx context.Context, since, until time.Time) ([]charts.Score, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT id, extract(epoch FROM release_date)::float8 FROM films
+         WHERE release_date > $1 AND release_date <= $2 AND deleted_at IS NULL`, since, until)
+	if err != nil {
+		return nil, err
+	}
+	return collectScores(rows)
+}
+
+// WatchlistCounts returns how many users have each film not in the trash on
+// their watchlist.
+func (r *ChartRepository) WatchlistCounts(ctx context.Context) ([]charts.Score, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT w.film_id, count(*)::float8 FROM watchlist w JOIN films f ON f.id = w.film_id
+         WHERE f.deleted_at IS NULL
+         GROUP BY w.film_id`)
+	if err != nil {
+		return nil, err
+	}
+	return collectScores(rows)
+}
+
+func collectScores(rows pgx.Rows) ([]charts.Score, error) {
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (charts.Score, error) {
+		var s charts.Score
+		err := row.Scan(&s.ItemID, &s.Value)
+		return s, err
+	})
+}
+
+// ChartRanks returns the current rank of every film in chart.
+func (r *ChartRepository) ChartRanks(ctx context.Context, chart string) (map[int]int, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT film_id, rank FROM chart_entries WHERE chart = $1`, chart)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ranks := map[int]int{}
+	for rows.Next() {
+		var filmID, rank int
+		if err := rows.Scan(&filmID, &rank); err != nil {
+			return nil, err
+		}
+		ranks[filmID] = rank
+	}
+	return ranks, rows.Err()
+}
+
+// ReplaceChart makes entries the ranking of chart and stamps when it was
+// computed. The swap is atomic, like ReplaceSimilarities, and serialised per
+// chart, since two refreshes copying at once would clash on the ranks.
+func (r *ChartRepository) ReplaceChart(ctx context.Context, chart string, entries []charts.Entry) error {
+	return pgx.BeginFunc(ctx, conn(ctx, r.db), func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('chart_entries:' || $1))`, chart); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM chart_entries WHERE chart = $1`, chart); err != nil {
+			return err
+		}
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{"chart_entries"},
+			[]string{"chart", "rank", "film_id", "score", "previous_rank"},
+			pgx.CopyFromSlice(len(entries), func(i int) ([]any, error) {
+				e := entries[i]
+				var previous *int
+				if e.PreviousRank > 0 {
+					previous = &e.PreviousRank
+				}
+				return []any{chart, e.Rank, e.ItemID, e.Score, previous}, nil
+			}),
+		); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx,
+			`INSERT INTO chart_runs (chart, computed_at) VALUES ($1, now())
+             ON CONFLICT (chart) DO UPDATE SET computed_at = EXCLUDED.computed_at`, chart)
+		return err
+	})
+}
+
+// ChartEntries returns the first limit entries of chart, leaving out films
+// moved to the trash since it was computed.
+func (r *ChartRepository) ChartEntries(ctx context.Context, chart string, limit int) ([]models.ChartEntry, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT c.rank, f.id, f.title, f.rating, f.release_date, c.score, c.previous_rank
+         FROM chart_entries c JOIN films f ON f.id = c.film_id
+         WHERE c.chart = $1 AND f.deleted_at IS NULL
+         ORDER BY c.rank
+         LIMIT $2`, chart, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.ChartEntry, error) {
+		var e models.ChartEntry
+		err := row.Scan(&e.Rank, &e.FilmID, &e.Title, &e.Rating, &e.ReleaseDate, &e.Score, &e.PreviousRank)
+		return e, err
+	})
+}
+
+// ChartComputedAt returns when chart was last computed, or nil before the
+// first run.
+func (r *ChartRepository) ChartComputedAt(ctx context.Context, chart string) (*time.Time, error) {
+	var at time.Time
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT computed_at FROM chart_runs WHERE chart = $1`, chart).Scan(&at)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &at, nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"filmhub/internal/models"
+)
+
+// CommentRepository stores comments on reviews.
+type CommentRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCommentRepository(db *pgxpool.Pool) *CommentRepository {
+	return &CommentRepository{db: db}
+}
+
+const commentColumns = `id, review_id, parent_id, user_id, body, created_at, updated_at, hidden_at`
+
+// commentVisible keeps hidden comments for their author only; $2 is the viewer.
+const commentVisible = `(hidden_at IS NULL OR user_id = $2)`
+
+func (r *CommentRepository) CreateComment(ctx context.Context, comment *models.Comment) (int, error) {
+	err := conn(ctx, r.db).QueryRow(ctx,
+		`INSERT INTO review_comments (review_id, parent_id, user_id, body) VALUES ($1, $2, $3, $4)
+         RETURNING id, created_at`,
+		comment.ReviewID, comment.ParentID, comment.UserID, comment.Body,
+	).Scan(&comment.ID, &comment.CreatedAt)
+	return comment.ID, err
+}
+
+func (r *CommentRepository) GetCommentByID(ctx context.Context, id int) (*models.Comment, error) {
+	return scanComment(conn(ctx, r.db).QueryRow(ctx, `SELECT `+commentColumns+` FROM review_comments WHERE id = $1`, id))
+}
+
+// ListCommentsByReview returns a page of top-level comments, oldest first,
+// each with all of its replies, and the number of top-level comments. Hidden
+// comments are only included for their author, viewerID; pass 0 for
+// anonymous viewers.
+func (r *CommentRepository) ListCommentsByReview(ctx context.Context, reviewID, viewerID, limit, offset int) ([]models.Comment, int, error) {
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx,
+		`SELECT count(*) FROM review_comments WHERE review_id = $1 AND parent_id IS NULL AND `+commentVisible,
+		reviewID, viewerID,
+	).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	top, err := r.queryComments(ctx,
+		`SELECT `+commentColumns+` FROM review_comments
+         WHERE review_id = $1 AND parent_id IS NULL AND `+commentVisible+`
+         ORDER BY created_at, id LIMIT $3 OFFSET $4`,
+		reviewID, viewerID, limit, offset)
+	if err != nil || len(top) == 0 {
+		return top, total, err
+	}
+
+	ids := make([]int, len(top))
+	index := make(map[int]int, len(top))
+	for i, c := range top {
+		ids[i] = c.ID
+		index[c.ID] = i
+	}
+	replies, err := r.queryComments(ctx,
+		`SELECT `+commentColumns+` FROM review_comments
+         WHERE parent_id = ANY($1) AND `+commentVisible+` ORDER BY created_at, id`, ids, viewerID)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, reply := range replies {
+		parent := &top[index[*reply.ParentID]]
+		parent.Replies = append(parent.Replies, reply)
+	}
+	return top, total, nil
+}
+
+func (r *CommentRepository) UpdateComment(ctx context.Context, id int, body string) (*models.Comment, error) {
+	return scanComment(conn(ctx, r.db).QueryRow(ctx,
+		`UPDATE review_comments SET body = $2, updated_at = now() WHERE id = $1 RETURNING `+commentColumns,
+		id, body))
+}
+
+// DeleteComment removes the comment and its replies.
+func (r *CommentRepository) DeleteComment(ctx context.Context, id int) error {
+	tag, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM review_comments WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *CommentRepository) queryComments(ctx context.Context, sql string, args ...any) ([]models.Comment, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []models.Comment
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, *c)
+	}
+	return comments, rows.Err()
+}
+
+func scanComment(row pgx.Row) (*models.Comment, error) {
+	var c models.Comment
+	if err := row.Scan(&c.ID, &c.ReviewID, &c.ParentID, &c.UserID, &c.Body, &c.CreatedAt, &c.UpdatedAt, &c.HiddenAt); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
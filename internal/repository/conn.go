@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"filmhub/pkg/database"
+)
+
+// conn returns what a repository call runs on: the transaction of the
+// current unit of work if ctx carries one, db otherwise.
+func conn(ctx context.Context, db *pgxpool.Pool) database.Querier {
+	return database.Conn(ctx, db)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"filmhub/internal/models"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -15,45 +16,234 @@ func NewFilmRepository(db *pgxpool.Pool) *FilmRepository {
 	return &FilmRepository{db: db}
 }
 
-func (r *FilmRepository) CreateFilm(ctx context.Context, film *models.FilmRequest) (int, error) {
+const filmColumns = `id, title, description, release_date, rating, created_at, created_by, updated_by, updated_at, version,
+    ARRAY(SELECT g.name FROM film_genres fg JOIN genres g ON g.id = fg.genre_id WHERE fg.film_id = films.id ORDER BY g.name) AS genres,
+    ARRAY(SELECT t.name FROM film_tags ft JOIN tags t ON t.id = ft.tag_id WHERE ft.film_id = films.id ORDER BY t.name) AS tags`
+
+// CreateFilm stores a film together with its first revision.
+func (r *FilmRepository) CreateFilm(ctx context.Context, film *models.FilmRequest, createdBy int) (int, error) {
 	var id int
-	err := r.db.QueryRow(ctx,
-		`INSERT INTO films (title, description, release_date) 
-         VALUES ($1, $2, $3) RETURNING id`,
-		film.Title, film.Description, film.ReleaseDate).Scan(&id)
+	err := pgx.BeginFunc(ctx, conn(ctx, r.db), func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx,
+			`INSERT INTO films (title, description, release_date, created_by) 
+         VALUES ($1, $2, $3, $4) RETURNING id`,
+			film.Title, film.Description, film.ReleaseDate, createdBy).Scan(&id); err != nil {
+			return err
+		}
+		return addRevision(ctx, tx, id, createdBy, models.RevisionCreate, nil)
+	})
 	return id, err
 }
 
+// UpdateFilm replaces the editable fields of film id if it is still at
+// version, records who changed it and stores the result as a new revision.
+// It returns pgx.ErrNoRows when the film does not exist or has moved on to
+// another version.
+func (r *FilmRepository) UpdateFilm(ctx context.Context, id int, film *models.FilmRequest, updatedBy, version int) error {
+	return pgx.BeginFunc(ctx, conn(ctx, r.db), func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx,
+			`UPDATE films SET title = $2, description = $3, release_date = $4, updated_by = $5, updated_at = now(),
+                version = version + 1
+         WHERE id = $1 AND version = $6 AND deleted_at IS NULL`,
+			id, film.Title, film.Description, film.ReleaseDate, updatedBy, version)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+		return addRevision(ctx, tx, id, updatedBy, models.RevisionUpdate, nil)
+	})
+}
+
+// RollbackFilm restores the fields of film id from revision if the film is
+// still at version and stores the result as a new revision. It returns
+// pgx.ErrNoRows when the film or the revision does not exist or the film has
+// moved on to another version.
+func (r *FilmRepository) RollbackFilm(ctx context.Context, id, revision, updatedBy, version int) error {
+	return pgx.BeginFunc(ctx, conn(ctx, r.db), func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx,
+			`UPDATE films SET title = rv.title, description = rv.description, release_date = rv.release_date,
+                updated_by = $3, updated_at = now(), version = films.version + 1
+         FROM film_revisions rv
+         WHERE films.id = $1 AND films.version = $4 AND films.deleted_at IS NULL
+           AND rv.film_id = $1 AND rv.revision = $2`,
+			id, revision, updatedBy, version)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+		return addRevision(ctx, tx, id, updatedBy, models.RevisionRollback, &revision)
+	})
+}
+
+// DeleteFilm moves film id to the trash. It returns pgx.ErrNoRows when the
+// film does not exist or is already deleted.
+func (r *FilmRepository) DeleteFilm(ctx context.Context, id, deletedBy int) error {
+	tag, err := conn(ctx, r.db).Exec(ctx,
+		`UPDATE films SET deleted_at = now(), deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL`, id, deletedBy)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// addRevision snapshots the current fields of film id as its next revision.
+// The caller's UPDATE or INSERT holds the row lock, so numbers don't race.
+func addRevision(ctx context.Context, tx pgx.Tx, id, authorID int, action string, restoredFrom *int) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO film_revisions (film_id, revision, title, description, release_date, author_id, action, restored_from)
+         SELECT f.id, COALESCE((SELECT MAX(revision) FROM film_revisions WHERE film_id = f.id), 0) + 1,
+                f.title, f.description, f.release_date, $2, $3, $4
+         FROM films f WHERE f.id = $1`,
+		id, authorID, action, restoredFrom)
+	return err
+}
+
+const revisionColumns = `film_id, revision, title, description, release_date, author_id, action, restored_from, created_at`
+
+// ListRevisions returns the film's revisions, newest first.
+func (r *FilmRepository) ListRevisions(ctx context.Context, filmID int) ([]models.FilmRevision, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT `+revisionColumns+` FROM film_revisions WHERE film_id = $1 ORDER BY revision DESC`, filmID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []models.FilmRevision
+	for rows.Next() {
+		rv, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, *rv)
+	}
+	return revisions, rows.Err()
+}
+
+func (r *FilmRepository) GetRevision(ctx context.Context, filmID, revision int) (*models.FilmRevision, error) {
+	return scanRevision(conn(ctx, r.db).QueryRow(ctx,
+		`SELECT `+revisionColumns+` FROM film_revisions WHERE film_id = $1 AND revision = $2`, filmID, revision))
+}
+
+func scanRevision(row pgx.Row) (*models.FilmRevision, error) {
+	var rv models.FilmRevision
+	if err := row.Scan(&rv.FilmID, &rv.Revision, &rv.Title, &rv.Description, &rv.ReleaseDate,
+		&rv.AuthorID, &rv.Action, &rv.RestoredFrom, &rv.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &rv, nil
+}
+
 func (r *FilmRepository) GetFilmByID(ctx context.Context, id int) (*models.Film, error) {
-	var film models.Film
-	err := r.db.QueryRow(ctx,
-		`SELECT id, title, description, release_date, rating, created_at 
-         FROM films WHERE id = $1`, id).Scan(
-		&film.ID, &film.Title, &film.Description, &film.ReleaseDate, &film.Rating, &film.CreatedAt,
-	)
-	return &film, err
+	return scanFilm(conn(ctx, r.db).QueryRow(ctx,
+		`SELECT `+filmColumns+` FROM films WHERE id = $1 AND deleted_at IS NULL`, id))
 }
 
 func (r *FilmRepository) SearchFilms(ctx context.Context, query string) ([]models.Film, error) {
-	rows, err := r.db.Query(ctx,
-		`SELECT id, title, description, release_date, rating, created_at 
-         FROM films WHERE title ILIKE '%' || $1 || '%' OR description ILIKE '%' || $1 || '%'`,
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT `+filmColumns+` FROM films
+         WHERE deleted_at IS NULL AND (title ILIKE '%' || $1 || '%' OR description ILIKE '%' || $1 || '%')`,
 		query)
 	if err != nil {
 		return nil, err
 	}
+	return scanFilms(rows)
+}
+
+// scanFilms reads and closes rows selecting filmColumns.
+func scanFilms(rows pgx.Rows) ([]models.Film, error) {
 	defer rows.Close()
 
 	var films []models.Film
 	for rows.Next() {
-		var film models.Film
-		if err := rows.Scan(
-			&film.ID, &film.Title, &film.Description, &film.ReleaseDate, &film.Rating, &film.CreatedAt,
-		); err != nil {
+		film, err := scanFilm(rows)
+		if err != nil {
 			return nil, err
 		}
-		films = append(films, film)
+		films = append(films, *film)
+	}
+
+	return films, rows.Err()
+}
+
+func scanFilm(row pgx.Row) (*models.Film, error) {
+	var film models.Film
+	if err := row.Scan(
+		&film.ID, &film.Title, &film.Description, &film.ReleaseDate, &film.Rating, &film.CreatedAt,
+		&film.CreatedBy, &film.UpdatedBy, &film.UpdatedAt, &film.Version, &film.Genres, &film.Tags,
+	); err != nil {
+		return nil, err
 	}
+	return &film, nil
+}
+
+// SetGenres replaces the genres of film id, creating unknown ones.
+func (r *FilmRepository) SetGenres(ctx context.Context, id int, genres []string) error {
+	return pgx.BeginFunc(ctx, conn(ctx, r.db), func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM film_genres WHERE film_id = $1`, id); err != nil {
+			return err
+		}
+		if len(genres) == 0 {
+			return nil
+		}
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO genres (name) SELECT unnest($1::text[]) ON CONFLICT (name) DO NOTHING`, genres); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx,
+			`INSERT INTO film_genres (film_id, genre_id) SELECT $1, id FROM genres WHERE name = ANY($2)`, id, genres)
+		return err
+	})
+}
 
-	return films, nil
+// SetTags replaces the tags of film id, creating unknown ones.
+func (r *FilmRepository) SetTags(ctx context.Context, id int, tags []string) error {
+	return pgx.BeginFunc(ctx, conn(ctx, r.db), func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM film_tags WHERE film_id = $1`, id); err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO tags (name) SELECT unnest($1::text[]) ON CONFLICT (name) DO NOTHING`, tags); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx,
+			`INSERT INTO film_tags (film_id, tag_id) SELECT $1, id FROM tags WHERE name = ANY($2)`, id, tags)
+		return err
+	})
+}
+
+// SetCredits replaces the credits of film id, creating unknown people.
+func (r *FilmRepository) SetCredits(ctx context.Context, id int, credits []models.FilmCredit) error {
+	return pgx.BeginFunc(ctx, conn(ctx, r.db), func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM film_credits WHERE film_id = $1`, id); err != nil {
+			return err
+		}
+		if len(credits) == 0 {
+			return nil
+		}
+		names := make([]string, len(credits))
+		roles := make([]string, len(credits))
+		for i, c := range credits {
+			names[i], roles[i] = c.Name, c.Role
+		}
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO people (name) SELECT unnest($1::text[]) ON CONFLICT (name) DO NOTHING`, names); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx,
+			`INSERT INTO film_credits (film_id, person_id, role)
+             SELECT $1, p.id, c.role FROM unnest($2::text[], $3::text[]) AS c(name, role) JOIN people p ON p.name = c.name
+             ON CONFLICT DO NOTHING`, id, names, roles)
+		return err
+	})
 }
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// filmRows is a pgx.Rows with one row per title, each selecting columns
+// values. Scan fails like pgx does when the destinations do not match.
+type filmRows struct {
+	pgx.Rows
+	titles  []string
+	columns int
+	next    int
+	closed  bool
+}
+
+func (r *filmRows) Next() bool {
+	if r.next == len(r.titles) {
+		return false
+	}
+	r.next++
+	return true
+}
+
+func (r *filmRows) Scan(dest ...any) error {
+	if len(dest) != r.columns {
+		return fmt.Errorf("number of field descriptions must equal number of destinations, got %d and %d", r.columns, len(dest))
+	}
+	for _, d := range dest {
+		switch d := d.(type) {
+		case *int:
+			*d = r.next
+		case *string:
+			*d = r.titles[r.next-1]
+		case *time.Time:
+			*d = time.Date(1999, 3, 31, 0, 0, 0, 0, time.UTC)
+		case *[]string:
+			*d = []string{"sci-fi"}
+		}
+	}
+	return nil
+}
+
+func (r *filmRows) Err() error                                   { return nil }
+func (r *filmRows) Close()                                       { r.closed = true }
+func (r *filmRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+
+// selectedColumns counts the top-level items of a select list.
+func selectedColumns(list string) int {
+	n, depth := 1, 0
+	for _, c := range list {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestScanFilms_ReadsEveryFilmColumn(t *testing.T) {
+	rows := &filmRows{titles: []string{"The Matrix", "The Matrix Reloaded"}, columns: selectedColumns(filmColumns)}
+	films, err := scanFilms(rows)
+	if err != nil {
+		t.Fatalf("scan search results: %v", err)
+	}
+	if len(films) != 2 || films[0].ID != 1 || films[1].Title != "The Matrix Reloaded" || len(films[1].Genres) != 1 {
+		t.Fatalf("unexpected films %+v", films)
+	}
+	if !rows.closed {
+		t.Fatal("expected rows to be closed")
+	}
+}
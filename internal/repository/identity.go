@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"filmhub/internal/models"
+)
+
+var (
+	// ErrSubjectLinked is returned by Link when the identity is linked
+	// already, to whichever user.
+	ErrSubjectLinked = errors.New("identity is already linked")
+	// ErrProviderLinked is returned by Link when the user has another
+	// identity at the provider.
+	ErrProviderLinked = errors.New("user already has an identity at the provider")
+)
+
+// IdentityRepository stores external identities linked to users.
+type IdentityRepository interface {
+	// FindUserID returns pgx.ErrNoRows when the identity is not linked.
+	FindUserID(ctx context.Context, provider, subject string) (int, error)
+	// Link returns ErrSubjectLinked or ErrProviderLinked when the identity
+	// or the user's provider is taken.
+	Link(ctx context.Context, identity *models.Identity) error
+	ListByUser(ctx context.Context, userID int) ([]models.Identity, error)
+	// Unlink returns pgx.ErrNoRows when nothing was linked.
+	Unlink(ctx context.Context, userID int, provider string) error
+}
+
+type identityRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewIdentityRepository(db *pgxpool.Pool) IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+func (r *identityRepository) FindUserID(ctx context.Context, provider, subject string) (int, error) {
+	var userID int
+	err := conn(ctx, r.db).QueryRow(ctx,
+		`SELECT user_id FROM user_identities WHERE provider = $1 AND subject = $2`, provider, subject,
+	).Scan(&userID)
+	return userID, err
+}
+
+func (r *identityRepository) Link(ctx context.Context, identity *models.Identity) error {
+	err := conn(ctx, r.db).QueryRow(ctx,
+		`INSERT INTO user_identities (provider, subject, user_id, email) VALUES ($1, $2, $3, $4)
+         RETURNING created_at`,
+		identity.Provider, identity.Subject, identity.UserID, identity.Email,
+	).Scan(&identity.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		if pgErr.ConstraintName == "user_identities_pkey" {
+			return ErrSubjectLinked
+		}
+		return ErrProviderLinked
+	}
+	return err
+}
+
+func (r *identityRepository) ListByUser(ctx context.Context, userID int) ([]models.Identity, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT provider, subject, user_id, COALESCE(email, ''), created_at
+         FROM user_identities WHERE user_id = $1 ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var identities []models.Identity
+	for rows.Next() {
+		var i models.Identity
+		if err := rows.Scan(&i.Provider, &i.Subject, &i.UserID, &i.Email, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		identities = append(identities, i)
+	}
+	return identities, rows.Err()
+}
+
+func (r *identityRepository) Unlink(ctx context.Context, userID int, provider string) error {
+	tag, err := conn(ctx, r.db).Exec(ctx,
+		`DELETE FROM user_identities WHERE user_id = $1 AND provider = $2`, userID, provider)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,342 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"filmhub/internal/models"
+)
+
+// ListRepository stores user-curated film lists, their entries,
+// collaborators and likes.
+type ListRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewListRepository(db *pgxpool.Pool) *ListRepository {
+	return &ListRepository{db: db}
+}
+
+// listSelect selects lists l for the viewer in $1; films in the trash are
+// not counted.
+const listSelect = `SELECT l.id, l.owner_id, u.username, l.title, l.description, l.visibility,
+        (SELECT count(*) FROM film_list_entries e JOIN films f ON f.id = e.film_id
+         WHERE e.list_id = l.id AND f.deleted_at IS NULL) AS entry_count,
+        (SELECT count(*) FROM film_list_likes k WHERE k.list_id = l.id) AS like_count,
+        EXISTS (SELECT 1 FROM film_list_likes k WHERE k.list_id = l.id AND k.user_id = $1),
+        l.created_at, l.updated_at
+    FROM film_lists l JOIN users u ON u.id = l.owner_id`
+
+func scanList(row pgx.CollectableRow) (models.FilmList, error) {
+	var l models.FilmList
+	err := row.Scan(&l.ID, &l.OwnerID, &l.OwnerName, &l.Title, &l.Description, &l.Visibility,
+		&l.EntryCount, &l.LikeCount, &l.Liked, &l.CreatedAt, &l.UpdatedAt)
+	return l, err
+}
+
+func (r *ListRepository) CreateList(ctx context.Context, ownerID int, req *models.FilmListRequest) (int, error) {
+	var id int
+	err := conn(ctx, r.db).QueryRow(ctx,
+		`INSERT INTO film_lists (owner_id, title, description, visibility) VALUES ($1, $2, $3, $4) RETURNING id`,
+		ownerID, req.Title, req.Description, req.Visibility,
+	).Scan(&id)
+	return id, err
+}
+
+// GetList returns list id with its collaborators, or pgx.ErrNoRows.
+func (r *ListRepository) GetList(ctx context.Context, id, viewerID int) (*models.FilmList, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, listSelect+` WHERE l.id = $2`, viewerID, id)
+	if err != nil {
+		return nil, err
+	}
+	list, err := pgx.CollectExactlyOneRow(rows, scanList)
+	if err != nil {
+		return nil, err
+	}
+	rows, err = conn(ctx, r.db).Query(ctx,
+		`SELECT u.id, u.username FROM film_list_collaborators c JOIN users u ON u.id = c.user_id
+         WHERE c.list_id = $1 ORDER BY c.created_at, u.id`, id)
+	if err != nil {
+		return nil, err
+	}
+	list.Collaborators, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.ListCollaborator, error) {
+		var c models.ListCollaborator
+		err := row.Scan(&c.UserID, &c.Username)
+		return c, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// UpdateList returns pgx.ErrNoRows when the list does not exist.
+func (r *ListRepository) UpdateList(ctx context.Context, id int, req *models.FilmListRequest) error {
+	tag, err := conn(ctx, r.db).Exec(ctx,
+		`UPDATE film_lists SET title = $2, description = $3, visibility = $4, updated_at = now() WHERE id = $1`,
+		id, req.Title, req.Description, req.Visibility)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteList returns pgx.ErrNoRows when the list does not exist.
+func (r *ListRepository) DeleteList(ctx context.Context, id int) error {
+	tag, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM film_lists WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// PublicLists returns a page of public lists, those containing filmID only
+// unless it is 0, most liked first, and their number. A film in the trash
+// is in no list.
+func (r *ListRepository) PublicLists(ctx context.Context, filmID, viewerID, limit, offset int) ([]models.FilmList, int, error) {
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx,
+		`SELECT count(*) FROM film_lists l WHERE l.visibility = 'public' AND ($1 = 0 OR EXISTS (
+             SELECT 1 FROM film_list_entries e JOIN films f ON f.id = e.film_id
+             WHERE e.list_id = l.id AND e.film_id = $1 AND f.deleted_at IS NULL))`, filmID,
+	).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := conn(ctx, r.db).Query(ctx,
+		listSelect+` WHERE l.visibility = 'public' AND ($2 = 0 OR EXISTS (
+             SELECT 1 FROM film_list_entries e JOIN films f ON f.id = e.film_id
+             WHERE e.list_id = l.id AND e.film_id = $2 AND f.deleted_at IS NULL))
+         ORDER BY like_count DESC, l.updated_at DESC, l.id DESC LIMIT $3 OFFSET $4`,
+		viewerID, filmID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	items, err := pgx.CollectRows(rows, scanList)
+	return items, total, err
+}
+
+// UserLists returns a page of the lists userID owns or collaborates on,
+// latest changed first, and their number.
+func (r *ListRepository) UserLists(ctx context.Context, userID, limit, offset int) ([]models.FilmList, int, error) {
+	const where = ` WHERE l.owner_id = $1 OR EXISTS (
+        SELECT 1 FROM film_list_collaborators c WHERE c.list_id = l.id AND c.user_id = $1)`
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx, `SELECT count(*) FROM film_lists l`+where, userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := conn(ctx, r.db).Query(ctx,
+		listSelect+where+` ORDER BY l.updated_at DESC, l.id DESC LIMIT $2 OFFSET $3`, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	items, err := pgx.CollectRows(rows, scanList)
+	return items, total, err
+}
+
+// ListEntries returns the films of a list in order, leaving out those in
+// the trash. Positions are counted among the films returned, so they run
+// 1..n even while a film in the trash keeps its place.
+func (r *ListRepository) ListEntries(ctx context.Context, listID int) ([]models.FilmListEntry, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT row_number() OVER (ORDER BY e.position, e.created_at), f.id, f.title, f.rating, f.release_date,
+                e.note, e.added_by, e.created_at
+         FROM film_list_entries e JOIN films f ON f.id = e.film_id
+         WHERE e.list_id = $1 AND f.deleted_at IS NULL
+         ORDER BY e.position, e.created_at`, listID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.FilmListEntry, error) {
+		var e models.FilmListEntry
+		err := row.Scan(&e.Position, &e.FilmID, &e.Title, &e.Rating, &e.ReleaseDate, &e.Note, &e.AddedBy, &e.AddedAt)
+		return e, err
+	})
+}
+
+// visibleEntries returns the film IDs and stored positions of the entries
+// of a list that are not in the trash, in order.
+func visibleEntries(ctx context.Context, tx pgx.Tx, listID int) (filmIDs, positions []int, err error) {
+	rows, err := tx.Query(ctx,
+		`SELECT e.film_id, e.position FROM film_list_entries e JOIN films f ON f.id = e.film_id
+         WHERE e.list_id = $1 AND f.deleted_at IS NULL
+         ORDER BY e.position, e.created_at`, listID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var filmID, position int
+		if err := rows.Scan(&filmID, &position); err != nil {
+			return nil, nil, err
+		}
+		filmIDs = append(filmIDs, filmID)
+		positions = append(positions, position)
+	}
+	return filmIDs, positions, rows.Err()
+}
+
+// AddEntry appends filmID to the list and reports whether it was added; it
+// was not if the list has it already.
+func (r *ListRepository) AddEntry(ctx context.Context, listID, filmID, addedBy int, note string) (bool, error) {
+	added := false
+	err := r.changeEntries(ctx, listID, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx,
+			`INSERT INTO film_list_entries (list_id, film_id, position, note, added_by)
+             SELECT $1, $2, COALESCE(max(position), 0) + 1, $3, $4 FROM film_list_entries WHERE list_id = $1
+             ON CONFLICT DO NOTHING`, listID, filmID, note, addedBy)
+		added = tag.RowsAffected() == 1
+		return err
+	})
+	return added, err
+}
+
+// UpdateEntry changes the note of an entry unless note is nil and moves it
+// to position unless that is 0. Positions count the films ListEntries
+// returns, and those past the end move the film last. It returns
+// pgx.ErrNoRows when the film is not in the list or is in the trash.
+func (r *ListRepository) UpdateEntry(ctx context.Context, listID, filmID int, note *string, position int) error {
+	return r.changeEntries(ctx, listID, func(tx pgx.Tx) error {
+		filmIDs, positions, err := visibleEntries(ctx, tx, listID)
+		if err != nil {
+			return err
+		}
+		i := slices.Index(filmIDs, filmID)
+		if i < 0 {
+			return pgx.ErrNoRows
+		}
+		if note != nil {
+			if _, err := tx.Exec(ctx,
+				`UPDATE film_list_entries SET note = $3 WHERE list_id = $1 AND film_id = $2`, listID, filmID, *note); err != nil {
+				return err
+			}
+		}
+		if position == 0 {
+			return nil
+		}
+		// Take the place of the film now shown at position; films in the
+		// trash in between shift along with the others.
+		from, to := positions[i], positions[min(position, len(positions))-1]
+		if to == from {
+			return nil
+		}
+		shift := `UPDATE film_list_entries SET position = position + 1 WHERE list_id = $1 AND position >= $2 AND position < $3`
+		if to > from {
+			shift = `UPDATE film_list_entries SET position = position - 1 WHERE list_id = $1 AND position > $3 AND position <= $2`
+		}
+		if _, err := tx.Exec(ctx, shift, listID, to, from); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx,
+			`UPDATE film_list_entries SET position = $3 WHERE list_id = $1 AND film_id = $2`, listID, filmID, to)
+		return err
+	})
+}
+
+// RemoveEntry takes filmID out of the list and moves the films after it up.
+// It returns pgx.ErrNoRows when the film is not in the list.
+func (r *ListRepository) RemoveEntry(ctx context.Context, listID, filmID int) error {
+	return r.changeEntries(ctx, listID, func(tx pgx.Tx) error {
+		var position int
+		if err := tx.QueryRow(ctx,
+			`DELETE FROM film_list_entries WHERE list_id = $1 AND film_id = $2 RETURNING position`, listID, filmID,
+		).Scan(&position); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx,
+			`UPDATE film_list_entries SET position = position - 1 WHERE list_id = $1 AND position > $2`, listID, position)
+		return err
+	})
+}
+
+// errListOrder rolls back a reorder that does not match the list.
+var errListOrder = errors.New("order does not match the list")
+
+// ReorderEntries numbers the films of the list in the order of filmIDs and
+// reports whether it did; it does not unless filmIDs names every film of
+// the list not in the trash exactly once. Films in the trash follow, in
+// the order they had.
+func (r *ListRepository) ReorderEntries(ctx context.Context, listID int, filmIDs []int) (bool, error) {
+	err := r.changeEntries(ctx, listID, func(tx pgx.Tx) error {
+		current, _, err := visibleEntries(ctx, tx, listID)
+		if err != nil {
+			return err
+		}
+		wanted := slices.Clone(filmIDs)
+		slices.Sort(current)
+		slices.Sort(wanted)
+		if !slices.Equal(current, wanted) {
+			return errListOrder
+		}
+		_, err = tx.Exec(ctx,
+			`UPDATE film_list_entries e SET position = n.position
+             FROM (SELECT e.film_id, row_number() OVER (ORDER BY o.position NULLS LAST, e.position, e.created_at) AS position
+                   FROM film_list_entries e
+                   LEFT JOIN unnest($2::int[]) WITH ORDINALITY AS o(film_id, position) ON o.film_id = e.film_id
+                   WHERE e.list_id = $1) n
+             WHERE e.list_id = $1 AND e.film_id = n.film_id`, listID, filmIDs)
+		return err
+	})
+	if errors.Is(err, errListOrder) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// changeEntries runs fn holding the list's row lock, so concurrent changes
+// keep positions numbered 1..n, and marks the list updated. It returns
+// pgx.ErrNoRows when the list does not exist. Inside a unit of work fn
+// runs in a savepoint of it.
+func (r *ListRepository) changeEntries(ctx context.Context, listID int, fn func(tx pgx.Tx) error) error {
+	return pgx.BeginFunc(ctx, conn(ctx, r.db), func(tx pgx.Tx) error {
+		var id int
+		if err := tx.QueryRow(ctx, `SELECT id FROM film_lists WHERE id = $1 FOR UPDATE`, listID).Scan(&id); err != nil {
+			return err
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `UPDATE film_lists SET updated_at = now() WHERE id = $1`, listID)
+		return err
+	})
+}
+
+func (r *ListRepository) AddCollaborator(ctx context.Context, listID, userID int) error {
+	_, err := conn(ctx, r.db).Exec(ctx,
+		`INSERT INTO film_list_collaborators (list_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, listID, userID)
+	return err
+}
+
+// RemoveCollaborator returns pgx.ErrNoRows when userID is not a
+// collaborator of the list.
+func (r *ListRepository) RemoveCollaborator(ctx context.Context, listID, userID int) error {
+	tag, err := conn(ctx, r.db).Exec(ctx,
+		`DELETE FROM film_list_collaborators WHERE list_id = $1 AND user_id = $2`, listID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *ListRepository) Like(ctx context.Context, listID, userID int) error {
+	_, err := conn(ctx, r.db).Exec(ctx,
+		`INSERT INTO film_list_likes (list_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, listID, userID)
+	return err
+}
+
+func (r *ListRepository) Unlike(ctx context.Context, listID, userID int) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM film_list_likes WHERE list_id = $1 AND user_id = $2`, listID, userID)
+	return err
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"filmhub/internal/models"
+)
+
+// ModerationRepository stores user reports and the moderation audit trail.
+type ModerationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewModerationRepository(db *pgxpool.Pool) *ModerationRepository {
+	return &ModerationRepository{db: db}
+}
+
+// targetTables maps a reportable target type to its table.
+var targetTables = map[string]string{
+	models.TargetReview:  "reviews",
+	models.TargetComment: "review_comments",
+}
+
+// targetLive excludes deleted targets; reports on them count as missing.
+var targetLive = map[string]string{
+	models.TargetReview: ` AND deleted_at IS NULL`,
+}
+
+const reportColumns = `id, target_type, target_id, reporter_id, reason, details, held_on_create, status, created_at, resolved_at, resolved_by`
+
+const moderationColumns = `id, moderator_id, action, target_type, target_id, report_id, note, created_at`
+
+// GetTarget returns the author of a review or comment and whether it is
+// hidden. It returns pgx.ErrNoRows when the target does not exist.
+func (r *ModerationRepository) GetTarget(ctx context.Context, targetType string, id int) (authorID int, hidden bool, err error) {
+	table, ok := targetTables[targetType]
+	if !ok {
+		return 0, false, fmt.Errorf("unknown target type %q", targetType)
+	}
+	err = conn(ctx, r.db).QueryRow(ctx,
+		`SELECT user_id, hidden_at IS NOT NULL FROM `+table+` WHERE id = $1`+targetLive[targetType], id,
+	).Scan(&authorID, &hidden)
+	return authorID, hidden, err
+}
+
+// CreateReport files a report. It returns false without an error when the
+// reporter already has an open report on the same target.
+func (r *ModerationRepository) CreateReport(ctx context.Context, report *models.Report) (bool, error) {
+	err := conn(ctx, r.db).QueryRow(ctx,
+		`INSERT INTO reports (target_type, target_id, reporter_id, reason, details)
+         VALUES ($1, $2, $3, $4, $5)
+         ON CONFLICT (target_type, target_id, reporter_id) WHERE status = 'open' DO NOTHING
+         RETURNING id, status, created_at`,
+		report.TargetType, report.TargetID, report.ReporterID, report.Reason, report.Details,
+	).Scan(&report.ID, &report.Status, &report.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *ModerationRepository) GetReport(ctx context.Context, id int) (*models.Report, error) {
+	return scanReport(conn(ctx, r.db).QueryRow(ctx, `SELECT `+reportColumns+` FROM reports WHERE id = $1`, id))
+}
+
+// ListReports returns a page of reports with the given status, oldest first,
+// and the number of such reports.
+func (r *ModerationRepository) ListReports(ctx context.Context, status string, limit, offset int) ([]models.Report, int, error) {
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx, `SELECT count(*) FROM reports WHERE status = $1`, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT `+reportColumns+` FROM reports WHERE status = $1 ORDER BY created_at, id LIMIT $2 OFFSET $3`,
+		status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var reports []models.Report
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		reports = append(reports, *report)
+	}
+	return reports, total, rows.Err()
+}
+
+// Apply carries out a moderator action in one transaction: it hides or
+// restores the target, recomputes the film's rating for reviews, closes
+// the open reports on it (as actioned for hide, as dismissed otherwise) and
+// appends the action to the audit trail. ID and CreatedAt of action are
+// filled in; the recomputed rating is returned, nil when there is none.
+func (r *ModerationRepository) Apply(ctx context.Context, action *models.ModerationAction) (*models.FilmRating, error) {
+	table, ok := targetTables[action.TargetType]
+	if !ok {
+		return nil, fmt.Errorf("unknown target type %q", action.TargetType)
+	}
+	var rating *models.FilmRating
+	err := pgx.BeginFunc(ctx, conn(ctx, r.db), func(tx pgx.Tx) error {
+		// status closes the open reports on the target.
+		var status string
+		switch action.Action {
+		case models.ModerationHide:
+			status = models.ReportActioned
+			if _, err := tx.Exec(ctx,
+				`UPDATE `+table+` SET hidden_at = COALESCE(hidden_at, now()) WHERE id = $1`, action.TargetID,
+			); err != nil {
+				return err
+			}
+		case models.ModerationRestore:
+			// Restoring rules the content fine, so reports still open on
+			// it, such as the one holding a filtered review, are unfounded.
+			status = models.ReportDismissed
+			if _, err := tx.Exec(ctx, `UPDATE `+table+` SET hidden_at = NULL WHERE id = $1`, action.TargetID); err != nil {
+				return err
+			}
+		case models.ModerationDismiss:
+			status = models.ReportDismissed
+		default:
+			return fmt.Errorf("unknown moderation action %q", action.Action)
+		}
+
+		if action.TargetType == models.TargetReview && action.Action != models.ModerationDismiss {
+			rating = &models.FilmRating{}
+			if err := tx.QueryRow(ctx,
+				refreshRating+`(SELECT film_id FROM reviews WHERE id = $1) RETURNING id, rating`, action.TargetID,
+			).Scan(&rating.FilmID, &rating.Rating); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.Exec(ctx,
+			`UPDATE reports SET status = $3, resolved_at = now(), resolved_by = $4
+             WHERE target_type = $1 AND target_id = $2 AND status = 'open'`,
+			action.TargetType, action.TargetID, status, action.ModeratorID,
+		); err != nil {
+			return err
+		}
+
+		return tx.QueryRow(ctx,
+			`INSERT INTO moderation_log (moderator_id, action, target_type, target_id, report_id, note)
+             VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`,
+			action.ModeratorID, action.Action, action.TargetType, action.TargetID, action.ReportID, action.Note,
+		).Scan(&action.ID, &action.CreatedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rating, nil
+}
+
+// ListActions returns a page of the audit trail, newest first, and its size.
+func (r *ModerationRepository) ListActions(ctx context.Context, limit, offset int) ([]models.ModerationAction, int, error) {
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx, `SELECT count(*) FROM moderation_log`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT `+moderationColumns+` FROM moderation_log ORDER BY created_at DESC, id DESC LIMIT $1 OFFSET $2`,
+		limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var actions []models.ModerationAction
+	for rows.Next() {
+		var a models.ModerationAction
+		var moderatorID *int
+		if err := rows.Scan(&a.ID, &moderatorID, &a.Action, &a.TargetType, &a.TargetID, &a.ReportID, &a.Note, &a.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		if moderatorID != nil {
+			a.ModeratorID = *moderatorID
+		}
+		actions = append(actions, a)
+	}
+	return actions, total, rows.Err()
+}
+
+func scanReport(row pgx.Row) (*models.Report, error) {
+	var rp models.Report
+	if err := row.Scan(&rp.ID, &rp.TargetType, &rp.TargetID, &rp.ReporterID, &rp.Reason, &rp.Details,
+		&rp.HeldOnCreate, &rp.Status, &rp.CreatedAt, &rp.ResolvedAt, &rp.ResolvedBy); err != nil {
+		return nil, err
+	}
+	return &rp, nil
+}
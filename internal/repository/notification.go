@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"filmhub/internal/models"
+)
+
+// NotificationRepository stores in-app notifications and notification
+// preferences.
+type NotificationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationRepository(db *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// typeEnabled holds when user $1 has not switched notification type $2 off.
+const typeEnabled = `NOT EXISTS (SELECT 1 FROM notification_preferences p
+                    WHERE p.user_id = $1 AND p.type = $2 AND NOT p.enabled)`
+
+// CreateNotification stores n unless its user has switched its type off.
+// Repeated likes of a review by the same user are stored once.
+func (r *NotificationRepository) CreateNotification(ctx context.Context, n *models.Notification) error {
+	_, err := conn(ctx, r.db).Exec(ctx,
+		`INSERT INTO notifications (user_id, type, actor_id, film_id, review_id, comment_id)
+         SELECT $1, $2, $3::int, $4::int, $5::int, $6::int WHERE `+typeEnabled+`
+         ON CONFLICT DO NOTHING`,
+		n.UserID, n.Type, n.ActorID, n.FilmID, n.ReviewID, n.CommentID)
+	return err
+}
+
+func (r *NotificationRepository) NotifyWatchers(ctx context.Context, n *models.Notification) error {
+	_, err := conn(ctx, r.db).Exec(ctx,
+		`INSERT INTO notifications (user_id, type, actor_id, film_id, review_id, comment_id)
+         SELECT w.user_id, $1::varchar, $2::int, $3::int, $4::int, $5::int FROM watchlist w
+         WHERE w.film_id = $3 AND NOT EXISTS (SELECT 1 FROM notification_preferences p
+               WHERE p.user_id = w.user_id AND p.type = $1 AND NOT p.enabled)`,
+		n.Type, n.ActorID, n.FilmID, n.ReviewID, n.CommentID)
+	return err
+}
+
+func (r *NotificationRepository) ListNotifications(ctx context.Context, userID int, unreadOnly bool, limit, offset int) ([]models.Notification, int, int, error) {
+	var total, unread int
+	if err := conn(ctx, r.db).QueryRow(ctx,
+		`SELECT count(*) FILTER (WHERE NOT $2 OR read_at IS NULL), count(*) FILTER (WHERE read_at IS NULL)
+         FROM notifications WHERE user_id = $1`, userID, unreadOnly,
+	).Scan(&total, &unread); err != nil {
+		return nil, 0, 0, err
+	}
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT id, user_id, type, actor_id, film_id, review_id, comment_id, created_at, read_at
+         FROM notifications WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+         ORDER BY id DESC LIMIT $3 OFFSET $4`,
+		userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	items, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Notification, error) {
+		var n models.Notification
+		err := row.Scan(&n.ID, &n.UserID, &n.Type, &n.ActorID, &n.FilmID, &n.ReviewID, &n.CommentID, &n.CreatedAt, &n.ReadAt)
+		return n, err
+	})
+	return items, total, unread, err
+}
+
+// MarkRead sets the read time of a notification of the user unless it is
+// set already. It returns pgx.ErrNoRows when the user has no such
+// notification.
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID int, id int64) error {
+	tag, err := conn(ctx, r.db).Exec(ctx,
+		`UPDATE notifications SET read_at = COALESCE(read_at, now()) WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID int) error {
+	_, err := conn(ctx, r.db).Exec(ctx,
+		`UPDATE notifications SET read_at = now() WHERE user_id = $1 AND read_at IS NULL`, userID)
+	return err
+}
+
+func (r *NotificationRepository) DisabledTypes(ctx context.Context, userID int) ([]string, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT type FROM notification_preferences WHERE user_id = $1 AND NOT enabled ORDER BY type`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func (r *NotificationRepository) SetPreference(ctx context.Context, userID int, notificationType string, enabled bool) error {
+	_, err := conn(ctx, r.db).Exec(ctx,
+		`INSERT INTO notification_preferences (user_id, type, enabled) VALUES ($1, $2, $3)
+         ON CONFLICT (user_id, type) DO UPDATE SET enabled = EXCLUDED.enabled`,
+		userID, notificationType, enabled)
+	return err
+}
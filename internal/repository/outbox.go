@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"filmhub/pkg/outbox"
+)
+
+// OutboxRepository stores domain events and serves them to the dispatcher;
+// it implements outbox.Store.
+type OutboxRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewOutboxRepository(db *pgxpool.Pool) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// Publish stores an event with payload marshalled to JSON. Called inside a
+// unit of work, the event is only stored if the change commits.
+func (r *OutboxRepository) Publish(ctx context.Context, eventType string, aggregateID int, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = conn(ctx, r.db).Exec(ctx,
+		`INSERT INTO outbox (event_type, aggregate_id, payload) VALUES ($1, $2, $3)`, eventType, aggregateID, data)
+	return err
+}
+
+// Claim returns up to limit due events, oldest first, and postpones them by
+// lease. Rows locked by another dispatcher are skipped.
+func (r *OutboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]outbox.Event, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`UPDATE outbox SET next_attempt_at = now() + $2 * interval '1 millisecond'
+         WHERE id IN (
+             SELECT id FROM outbox
+             WHERE delivered_at IS NULL AND failed_at IS NULL AND next_attempt_at <= now()
+             ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED)
+         RETURNING id, event_type, aggregate_id, payload, created_at, attempts`,
+		limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	events, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (outbox.Event, error) {
+		var e outbox.Event
+		err := row.Scan(&e.ID, &e.Type, &e.AggregateID, &e.Payload, &e.CreatedAt, &e.Attempts)
+		return e, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	// RETURNING does not keep the subquery's order.
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
+
+func (r *OutboxRepository) MarkDelivered(ctx context.Context, id int64) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE outbox SET delivered_at = now() WHERE id = $1`, id)
+	return err
+}
+
+// MarkFailed counts a failed attempt. A nil retryAt gives the event up.
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id int64, reason string, retryAt *time.Time) error {
+	_, err := conn(ctx, r.db).Exec(ctx,
+		`UPDATE outbox SET attempts = attempts + 1, last_error = $2,
+                next_attempt_at = COALESCE($3, next_attempt_at),
+                failed_at = CASE WHEN $3::timestamptz IS NULL THEN now() END
+         WHERE id = $1`, id, reason, retryAt)
+	return err
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"filmhub/internal/models"
+	"filmhub/pkg/recommend"
+)
+
+// RecommendationRepository reads review ratings and stores the film
+// similarities computed from them.
+type RecommendationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRecommendationRepository(db *pgxpool.Pool) *RecommendationRepository {
+	return &RecommendationRepository{db: db}
+}
+
+// visibleReviewCount counts the published reviews of films f.
+const visibleReviewCount = `(SELECT count(*) FROM reviews r
+    WHERE r.film_id = f.id AND r.deleted_at IS NULL AND r.hidden_at IS NULL)`
+
+// Ratings returns the ratings of every published review of a film that is
+// not in the trash.
+func (r *RecommendationRepository) Ratings(ctx context.Context) ([]recommend.Rating, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT r.user_id, r.film_id, r.rating FROM reviews r JOIN films f ON f.id = r.film_id
+         WHERE r.deleted_at IS NULL AND r.hidden_at IS NULL AND f.deleted_at IS NULL`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (recommend.Rating, error) {
+		var rt recommend.Rating
+		var value int
+		err := row.Scan(&rt.UserID, &rt.ItemID, &value)
+		rt.Value = float64(value)
+		return rt, err
+	})
+}
+
+// ReplaceSimilarities swaps the stored similarities for sims in one
+// transaction, so readers see either the old or the new set. Instances
+// refreshing at the same time take turns on an advisory lock; otherwise
+// both would delete the old rows and then collide inserting the new ones.
+func (r *RecommendationRepository) ReplaceSimilarities(ctx context.Context, sims []recommend.Similarity) error {
+	return pgx.BeginFunc(ctx, conn(ctx, r.db), func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('film_similarities'))`); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM film_similarities`); err != nil {
+			return err
+		}
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{"film_similarities"},
+			[]string{"film_id", "similar_film_id", "score", "support"},
+			pgx.CopyFromSlice(len(sims), func(i int) ([]any, error) {
+				s := sims[i]
+				return []any{s.ItemID, s.OtherID, float32(s.Score), s.Support}, nil
+			}),
+		); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx,
+			`INSERT INTO film_similarity_runs (computed_at, pairs) VALUES (now(), $1)
+             ON CONFLICT (id) DO UPDATE SET computed_at = EXCLUDED.computed_at, pairs = EXCLUDED.pairs`, len(sims))
+		return err
+	})
+}
+
+// ComputedAt returns when the similarities were last computed, or nil
+// before the first run.
+func (r *RecommendationRepository) ComputedAt(ctx context.Context) (*time.Time, error) {
+	var at time.Time
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT computed_at FROM film_similarity_runs`).Scan(&at)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &at, nil
+}
+
+// UserRatings returns the films the user has reviewed, hidden reviews
+// included, with the user's rating and the genres of each film.
+func (r *RecommendationRepository) UserRatings(ctx context.Context, userID int) ([]models.RatedFilm, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT f.id, f.title, r.rating,
+                ARRAY(SELECT g.name FROM film_genres fg JOIN genres g ON g.id = fg.genre_id WHERE fg.film_id = f.id ORDER BY g.name)
+         FROM reviews r JOIN films f ON f.id = r.film_id
+         WHERE r.user_id = $1 AND r.deleted_at IS NULL AND f.deleted_at IS NULL
+         ORDER BY r.created_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.RatedFilm, error) {
+		var f models.RatedFilm
+		err := row.Scan(&f.FilmID, &f.Title, &f.Rating, &f.Genres)
+		return f, err
+	})
+}
+
+// Neighbors returns the stored similarities of the rated films to films
+// outside rated.
+func (r *RecommendationRepository) Neighbors(ctx context.Context, rated []int) ([]models.FilmNeighbor, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT s.film_id, f.id, f.title, f.rating, s.score
+         FROM film_similarities s JOIN films f ON f.id = s.similar_film_id
+         WHERE s.film_id = ANY($1) AND s.similar_film_id <> ALL($1) AND f.deleted_at IS NULL`, rated)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.FilmNeighbor, error) {
+		var n models.FilmNeighbor
+		err := row.Scan(&n.RatedID, &n.FilmID, &n.Title, &n.Rating, &n.Score)
+		return n, err
+	})
+}
+
+// PopularFilms returns up to limit films outside exclude with the most
+// published reviews, best rated first among equals. With genres, only films
+// of one of them are returned, each with the first of genres it has.
+func (r *RecommendationRepository) PopularFilms(ctx context.Context, genres []string, exclude []int, limit int) ([]models.PopularFilm, error) {
+	// NULL arrays would match nothing.
+	if genres == nil {
+		genres = []string{}
+	}
+	if exclude == nil {
+		exclude = []int{}
+	}
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT f.id, f.title, f.rating, `+visibleReviewCount+` AS reviews,
+                COALESCE((SELECT g.name FROM film_genres fg JOIN genres g ON g.id = fg.genre_id
+                          WHERE fg.film_id = f.id AND g.name = ANY($1)
+                          ORDER BY array_position($1, g.name::text) LIMIT 1), '') AS genre
+         FROM films f
+         WHERE f.deleted_at IS NULL AND f.id <> ALL($2)
+           AND (cardinality($1::text[]) = 0 OR EXISTS (
+                SELECT 1 FROM film_genres fg JOIN genres g ON g.id = fg.genre_id
+                WHERE fg.film_id = f.id AND g.name = ANY($1)))
+         ORDER BY reviews DESC, f.rating DESC, f.id
+         LIMIT $3`, genres, exclude, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.PopularFilm, error) {
+		var p models.PopularFilm
+		err := row.Scan(&p.FilmID, &p.Title, &p.Rating, &p.Reviews, &p.Genre)
+		return p, err
+	})
+}
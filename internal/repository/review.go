@@ -2,8 +2,10 @@ package repository
 
 import (
     "context"
+    "errors"
 
     "filmhub/internal/models"
+    "github.com/jackc/pgx/v5"
     "github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -16,19 +18,122 @@ func NewReviewRepository(db *pgxpool.Pool) *ReviewRepository {
     return &ReviewRepository{db: db}
 }
 
+const reviewColumns = `id, film_id, user_id, rating, comment, created_at, helpful_count, unhelpful_count,
+    (SELECT count(*) FROM review_comments rc WHERE rc.review_id = reviews.id AND rc.hidden_at IS NULL) AS comment_count,
+    hidden_at, updated_at, version`
+
+// reviewOrder maps a models.ReviewSort* value to its ORDER BY clause.
+var reviewOrder = map[string]string{
+    models.ReviewSortNewest:  `created_at DESC, id DESC`,
+    models.ReviewSortHelpful: `helpful_count - unhelpful_count DESC, created_at DESC, id DESC`,
+    models.ReviewSortRating:  `rating DESC, created_at DESC, id DESC`,
+}
+
 func (r *ReviewRepository) CreateReview(ctx context.Context, review *models.Review) (int, error) {
     var id int
-    err := r.db.QueryRow(ctx,
+    err := conn(ctx, r.db).QueryRow(ctx,
         `INSERT INTO reviews (film_id, user_id, rating, comment) VALUES ($1, $2, $3, $4) RETURNING id`,
         review.FilmID, review.UserID, review.Rating, review.Comment,
     ).Scan(&id)
     return id, err
 }
 
-func (r *ReviewRepository) ListReviewsByFilm(ctx context.Context, filmID int) ([]models.Review, error) {
-    rows, err := r.db.Query(ctx,
-        `SELECT id, film_id, user_id, rating, comment, created_at FROM reviews WHERE film_id = $1 ORDER BY created_at DESC`,
-        filmID,
+// HoldReview stores a review hidden and queues it for moderation with a
+// report on behalf of the content filter, whose findings go to details. The
+// report is marked as held on create.
+func (r *ReviewRepository) HoldReview(ctx context.Context, review *models.Review, details string) (int, error) {
+    err := pgx.BeginFunc(ctx, conn(ctx, r.db), func(tx pgx.Tx) error {
+        if err := tx.QueryRow(ctx,
+            `INSERT INTO reviews (film_id, user_id, rating, comment, hidden_at) VALUES ($1, $2, $3, $4, now())
+             RETURNING id, hidden_at`,
+            review.FilmID, review.UserID, review.Rating, review.Comment,
+        ).Scan(&review.ID, &review.HiddenAt); err != nil {
+            return err
+        }
+        _, err := tx.Exec(ctx,
+            `INSERT INTO reports (target_type, target_id, reason, details, held_on_create) VALUES ($1, $2, $3, $4, true)`,
+            models.TargetReview, review.ID, models.ReasonAutoFilter, details)
+        return err
+    })
+    return review.ID, err
+}
+
+func (r *ReviewRepository) GetReviewByID(ctx context.Context, id int) (*models.Review, error) {
+    return scanReview(conn(ctx, r.db).QueryRow(ctx, `SELECT `+reviewColumns+` FROM reviews WHERE id = $1 AND deleted_at IS NULL`, id))
+}
+
+// UpdateReview changes the rating and text of a review if it is still at
+// version. A non-empty holdDetails hides the review and queues it for
+// moderation on behalf of the content filter, as HoldReview does. It
+// returns pgx.ErrNoRows when the review does not exist or has moved on to
+// another version.
+func (r *ReviewRepository) UpdateReview(ctx context.Context, review *models.Review, version int, holdDetails string) error {
+    return pgx.BeginFunc(ctx, conn(ctx, r.db), func(tx pgx.Tx) error {
+        tag, err := tx.Exec(ctx,
+            `UPDATE reviews SET rating = $3, comment = $4, updated_at = now(), version = version + 1,
+                    hidden_at = CASE WHEN $5 THEN COALESCE(hidden_at, now()) ELSE hidden_at END
+             WHERE id = $1 AND version = $2 AND deleted_at IS NULL`,
+            review.ID, version, review.Rating, review.Comment, holdDetails != "")
+        if err != nil {
+            return err
+        }
+        if tag.RowsAffected() == 0 {
+            return pgx.ErrNoRows
+        }
+        if holdDetails == "" {
+            return nil
+        }
+        _, err = tx.Exec(ctx,
+            `INSERT INTO reports (target_type, target_id, reason, details) VALUES ($1, $2, $3, $4)`,
+            models.TargetReview, review.ID, models.ReasonAutoFilter, holdDetails)
+        return err
+    })
+}
+
+// refreshRating recomputes films.rating as the average of the film's
+// published reviews; append the film condition.
+const refreshRating = `UPDATE films SET rating = COALESCE((
+        SELECT avg(r.rating) FROM reviews r
+        WHERE r.film_id = films.id AND r.deleted_at IS NULL AND r.hidden_at IS NULL), 0)
+    WHERE id = `
+
+// RefreshFilmRating recomputes the film's rating from its published
+// reviews and returns it. Run it in the transaction that changed them.
+func (r *ReviewRepository) RefreshFilmRating(ctx context.Context, filmID int) (float32, error) {
+    var rating float32
+    err := conn(ctx, r.db).QueryRow(ctx, refreshRating+`$1 RETURNING rating`, filmID).Scan(&rating)
+    return rating, err
+}
+
+// DeleteReview moves review id to the trash. It returns pgx.ErrNoRows when
+// the review does not exist or is already deleted.
+func (r *ReviewRepository) DeleteReview(ctx context.Context, id, deletedBy int) error {
+    tag, err := conn(ctx, r.db).Exec(ctx,
+        `UPDATE reviews SET deleted_at = now(), deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL`, id, deletedBy)
+    if err != nil {
+        return err
+    }
+    if tag.RowsAffected() == 0 {
+        return pgx.ErrNoRows
+    }
+    return nil
+}
+
+// ListReviewsByFilm returns the film's reviews in the given order; unknown
+// orders fall back to newest first. Hidden reviews are only included for
+// their author, viewerID; pass 0 for anonymous viewers. Deleted reviews and
+// reviews of deleted films are left out.
+func (r *ReviewRepository) ListReviewsByFilm(ctx context.Context, filmID int, sort string, viewerID int) ([]models.Review, error) {
+    order, ok := reviewOrder[sort]
+    if !ok {
+        order = reviewOrder[models.ReviewSortNewest]
+    }
+    rows, err := conn(ctx, r.db).Query(ctx,
+        `SELECT `+reviewColumns+` FROM reviews
+         WHERE film_id = $1 AND deleted_at IS NULL AND (hidden_at IS NULL OR user_id = $2)
+           AND EXISTS (SELECT 1 FROM films WHERE films.id = $1 AND films.deleted_at IS NULL)
+         ORDER BY `+order,
+        filmID, viewerID,
     )
     if err != nil {
         return nil, err
@@ -37,11 +142,66 @@ func (r *ReviewRepository) ListReviewsByFilm(ctx context.Context, filmID int) ([
 
     var reviews []models.Review
     for rows.Next() {
-        var rv models.Review
-        if err := rows.Scan(&rv.ID, &rv.FilmID, &rv.UserID, &rv.Rating, &rv.Comment, &rv.CreatedAt); err != nil {
+        rv, err := scanReview(rows)
+        if err != nil {
             return nil, err
         }
-        reviews = append(reviews, rv)
+        reviews = append(reviews, *rv)
     }
-    return reviews, nil
-} 
\ No newline at end of file
+    return reviews, rows.Err()
+}
+
+// Vote records the user's vote (1 helpful, -1 unhelpful). Repeating the
+// current vote withdraws it. The review row is locked so the counters stay
+// consistent under concurrent votes. It returns pgx.ErrNoRows for unknown
+// reviews.
+func (r *ReviewRepository) Vote(ctx context.Context, reviewID, userID, value int) (*models.ReviewVotes, error) {
+    votes := &models.ReviewVotes{ReviewID: reviewID}
+    err := pgx.BeginFunc(ctx, conn(ctx, r.db), func(tx pgx.Tx) error {
+        var locked int
+        if err := tx.QueryRow(ctx, `SELECT id FROM reviews WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, reviewID).Scan(&locked); err != nil {
+            return err
+        }
+        var current int
+        err := tx.QueryRow(ctx,
+            `SELECT value FROM review_votes WHERE review_id = $1 AND user_id = $2`, reviewID, userID,
+        ).Scan(&current)
+        if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+            return err
+        }
+
+        if current == value {
+            _, err = tx.Exec(ctx, `DELETE FROM review_votes WHERE review_id = $1 AND user_id = $2`, reviewID, userID)
+        } else {
+            votes.MyVote = value
+            _, err = tx.Exec(ctx,
+                `INSERT INTO review_votes (review_id, user_id, value) VALUES ($1, $2, $3)
+                 ON CONFLICT (review_id, user_id) DO UPDATE SET value = EXCLUDED.value, created_at = now()`,
+                reviewID, userID, value)
+        }
+        if err != nil {
+            return err
+        }
+
+        return tx.QueryRow(ctx,
+            `UPDATE reviews SET
+                 helpful_count = (SELECT count(*) FROM review_votes WHERE review_id = $1 AND value = 1),
+                 unhelpful_count = (SELECT count(*) FROM review_votes WHERE review_id = $1 AND value = -1)
+             WHERE id = $1
+             RETURNING helpful_count, unhelpful_count`, reviewID,
+        ).Scan(&votes.HelpfulCount, &votes.UnhelpfulCount)
+    })
+    if err != nil {
+        return nil, err
+    }
+    return votes, nil
+}
+
+func scanReview(row pgx.Row) (*models.Review, error) {
+    var rv models.Review
+    if err := row.Scan(&rv.ID, &rv.FilmID, &rv.UserID, &rv.Rating, &rv.Comment, &rv.CreatedAt, &rv.HelpfulCount, &rv.UnhelpfulCount, &rv.CommentCount, &rv.HiddenAt,
+        &rv.UpdatedAt, &rv.Version); err != nil {
+        return nil, err
+    }
+    return &rv, nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"filmhub/internal/models"
+	"filmhub/pkg/recommend"
+)
+
+// SimilarRepository reads what similar films are found by.
+type SimilarRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSimilarRepository(db *pgxpool.Pool) *SimilarRepository {
+	return &SimilarRepository{db: db}
+}
+
+// FilmFeatures returns the genres, tags, people and description of every film
+// that is not in the trash.
+func (r *SimilarRepository) FilmFeatures(ctx context.Context) ([]models.FilmFeatures, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT f.id, f.title, f.rating, f.description,
+                ARRAY(SELECT g.name FROM film_genres fg JOIN genres g ON g.id = fg.genre_id WHERE fg.film_id = f.id ORDER BY g.name),
+                ARRAY(SELECT t.name FROM film_tags ft JOIN tags t ON t.id = ft.tag_id WHERE ft.film_id = f.id ORDER BY t.name),
+                ARRAY(SELECT DISTINCT p.name FROM film_credits fc JOIN people p ON p.id = fc.person_id WHERE fc.film_id = f.id ORDER BY p.name)
+         FROM films f
+         WHERE f.deleted_at IS NULL`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.FilmFeatures, error) {
+		var f models.FilmFeatures
+		err := row.Scan(&f.FilmID, &f.Title, &f.Rating, &f.Description, &f.Genres, &f.Tags, &f.People)
+		return f, err
+	})
+}
+
+// CoRatings returns the stored rating similarities of film id; see
+// RecommendationRepository.ReplaceSimilarities.
+func (r *SimilarRepository) CoRatings(ctx context.Context, id int) ([]recommend.Similarity, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT film_id, similar_film_id, score, support FROM film_similarities WHERE film_id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (recommend.Similarity, error) {
+		var s recommend.Similarity
+		var score float32
+		err := row.Scan(&s.ItemID, &s.OtherID, &score, &s.Support)
+		s.Score = float64(score)
+		return s, err
+	})
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"filmhub/internal/models"
+)
+
+// TrashRepository lists, restores and purges soft-deleted films and reviews.
+type TrashRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTrashRepository(db *pgxpool.Pool) *TrashRepository {
+	return &TrashRepository{db: db}
+}
+
+// trashTables maps a trash item type to its table.
+var trashTables = map[string]string{
+	models.EntityFilm:   "films",
+	models.EntityReview: "reviews",
+}
+
+// trashItems lists every deleted item; reviews are titled by the start of
+// their text.
+const trashItems = `(
+    SELECT 'film' AS type, id, title, deleted_at, deleted_by FROM films WHERE deleted_at IS NOT NULL
+    UNION ALL
+    SELECT 'review', id, left(COALESCE(comment, ''), 80), deleted_at, deleted_by FROM reviews WHERE deleted_at IS NOT NULL
+) AS trash`
+
+// List returns a page of deleted items of itemType (empty means all),
+// most recently deleted first, and the number of such items.
+func (r *TrashRepository) List(ctx context.Context, itemType string, limit, offset int) ([]models.TrashItem, int, error) {
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx,
+		`SELECT count(*) FROM `+trashItems+` WHERE $1 = '' OR type = $1`, itemType,
+	).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT type, id, title, deleted_at, deleted_by FROM `+trashItems+`
+         WHERE $1 = '' OR type = $1 ORDER BY deleted_at DESC, id DESC LIMIT $2 OFFSET $3`,
+		itemType, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var items []models.TrashItem
+	for rows.Next() {
+		var it models.TrashItem
+		if err := rows.Scan(&it.Type, &it.ID, &it.Title, &it.DeletedAt, &it.DeletedBy); err != nil {
+			return nil, 0, err
+		}
+		items = append(items, it)
+	}
+	return items, total, rows.Err()
+}
+
+// Restore takes an item out of the trash; a restored review counts towards
+// the film's rating again, which is returned. It returns pgx.ErrNoRows when
+// the item does not exist or is not deleted.
+func (r *TrashRepository) Restore(ctx context.Context, itemType string, id int) (*models.FilmRating, error) {
+	table, ok := trashTables[itemType]
+	if !ok {
+		return nil, fmt.Errorf("unknown trash type %q", itemType)
+	}
+	var rating *models.FilmRating
+	err := pgx.BeginFunc(ctx, conn(ctx, r.db), func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx,
+			`UPDATE `+table+` SET deleted_at = NULL, deleted_by = NULL WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+		if itemType != models.EntityReview {
+			return nil
+		}
+		rating = &models.FilmRating{}
+		return tx.QueryRow(ctx, refreshRating+`(SELECT film_id FROM reviews WHERE id = $1) RETURNING id, rating`, id).
+			Scan(&rating.FilmID, &rating.Rating)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rating, nil
+}
+
+// Purge permanently deletes items that were deleted before cutoff and
+// returns how many films and reviews were removed. Reviews, votes, comments
+// and revisions of purged films go with them.
+func (r *TrashRepository) Purge(ctx context.Context, cutoff time.Time) (films, reviews int64, err error) {
+	err = pgx.BeginFunc(ctx, conn(ctx, r.db), func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `DELETE FROM reviews WHERE deleted_at < $1`, cutoff)
+		if err != nil {
+			return err
+		}
+		reviews = tag.RowsAffected()
+		tag, err = tx.Exec(ctx, `DELETE FROM films WHERE deleted_at < $1`, cutoff)
+		if err != nil {
+			return err
+		}
+		films = tag.RowsAffected()
+		return nil
+	})
+	return films, reviews, err
+}
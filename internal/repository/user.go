@@ -4,12 +4,17 @@ import (
 	"context"
 	"filmhub/internal/models"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
 	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	FindByID(ctx context.Context, id int) (*models.User, error)
+	MarkEmailVerified(ctx context.Context, id int) error
+	UpdatePassword(ctx context.Context, id int, hash string) error
+	UpdateRole(ctx context.Context, id int, role models.UserRole) error
 }
 
 type userRepository struct {
@@ -20,20 +25,42 @@ func NewUserRepository(db *pgxpool.Pool) UserRepository {
 	return &userRepository{db: db}
 }
 
+const userColumns = `id, username, email, password, role, email_verified_at IS NOT NULL`
+
 func (r *userRepository) Create(ctx context.Context, user *models.User) error {
-	_, err := r.db.Exec(ctx,
-		`INSERT INTO users (username, email, password, role) VALUES ($1, $2, $3, $4)`,
+	return conn(ctx, r.db).QueryRow(ctx,
+		`INSERT INTO users (username, email, password, role) VALUES ($1, $2, $3, $4) RETURNING id`,
 		user.Username, user.Email, user.Password, user.Role,
-	)
-	return err
+	).Scan(&user.ID)
 }
 
 func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return scanUser(conn(ctx, r.db).QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE email = $1`, email))
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id int) (*models.User, error) {
+	return scanUser(conn(ctx, r.db).QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id))
+}
+
+func (r *userRepository) MarkEmailVerified(ctx context.Context, id int) error {
+	_, err := conn(ctx, r.db).Exec(ctx,
+		`UPDATE users SET email_verified_at = COALESCE(email_verified_at, now()) WHERE id = $1`, id)
+	return err
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, id int, hash string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE users SET password = $2 WHERE id = $1`, id, hash)
+	return err
+}
+
+func (r *userRepository) UpdateRole(ctx context.Context, id int, role models.UserRole) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE users SET role = $2 WHERE id = $1`, id, role)
+	return err
+}
+
+func scanUser(row pgx.Row) (*models.User, error) {
 	var user models.User
-	err := r.db.QueryRow(ctx,
-		`SELECT id, username, email, password, role FROM users WHERE email = $1`, email,
-	).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role)
-	if err != nil {
+	if err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role, &user.EmailVerified); err != nil {
 		return nil, err
 	}
 	return &user, nil
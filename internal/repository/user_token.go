@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UserTokenRepository stores nonces of emailed tokens so each can be used once.
+type UserTokenRepository interface {
+	Save(ctx context.Context, nonce string, userID int, purpose string, expiresAt time.Time) error
+	// Consume marks the nonce used. It returns pgx.ErrNoRows when the nonce
+	// is unknown, already used or expired.
+	Consume(ctx context.Context, nonce, purpose string) error
+}
+
+type userTokenRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewUserTokenRepository(db *pgxpool.Pool) UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+func (r *userTokenRepository) Save(ctx context.Context, nonce string, userID int, purpose string, expiresAt time.Time) error {
+	_, err := conn(ctx, r.db).Exec(ctx,
+		`INSERT INTO user_tokens (nonce, user_id, purpose, expires_at) VALUES ($1, $2, $3, $4)`,
+		nonce, userID, purpose, expiresAt,
+	)
+	return err
+}
+
+func (r *userTokenRepository) Consume(ctx context.Context, nonce, purpose string) error {
+	tag, err := conn(ctx, r.db).Exec(ctx,
+		`UPDATE user_tokens SET used_at = now()
+         WHERE nonce = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > now()`,
+		nonce, purpose,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
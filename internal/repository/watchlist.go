@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"filmhub/internal/models"
+)
+
+// WatchlistRepository stores the films users want to watch.
+type WatchlistRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWatchlistRepository(db *pgxpool.Pool) *WatchlistRepository {
+	return &WatchlistRepository{db: db}
+}
+
+func (r *WatchlistRepository) AddToWatchlist(ctx context.Context, userID, filmID int) error {
+	_, err := conn(ctx, r.db).Exec(ctx,
+		`INSERT INTO watchlist (user_id, film_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, userID, filmID)
+	return err
+}
+
+// RemoveFromWatchlist returns pgx.ErrNoRows when the film is not on the
+// user's watchlist.
+func (r *WatchlistRepository) RemoveFromWatchlist(ctx context.Context, userID, filmID int) error {
+	tag, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM watchlist WHERE user_id = $1 AND film_id = $2`, userID, filmID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// ListWatchlist returns a page of the user's watchlist, latest additions
+// first, and its size. Films in the trash are left out.
+func (r *WatchlistRepository) ListWatchlist(ctx context.Context, userID, limit, offset int) ([]models.WatchlistItem, int, error) {
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx,
+		`SELECT count(*) FROM watchlist w JOIN films f ON f.id = w.film_id
+         WHERE w.user_id = $1 AND f.deleted_at IS NULL`, userID,
+	).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT f.id, f.title, f.release_date, w.created_at FROM watchlist w JOIN films f ON f.id = w.film_id
+         WHERE w.user_id = $1 AND f.deleted_at IS NULL
+         ORDER BY w.created_at DESC, f.id DESC LIMIT $2 OFFSET $3`,
+		userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	items, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.WatchlistItem, error) {
+		var item models.WatchlistItem
+		err := row.Scan(&item.FilmID, &item.Title, &item.ReleaseDate, &item.AddedAt)
+		return item, err
+	})
+	return items, total, err
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"filmhub/internal/models"
+	"filmhub/pkg/webhook"
+)
+
+// WebhookRepository stores webhook subscriptions and their deliveries; it
+// implements webhook.Store.
+type WebhookRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhookRepository(db *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) (int, error) {
+	var id int
+	err := conn(ctx, r.db).QueryRow(ctx,
+		`INSERT INTO webhook_subscriptions (url, secret, event_types, created_by)
+         VALUES ($1, $2, $3, $4) RETURNING id, created_at`,
+		sub.URL, sub.Secret, sub.EventTypes, sub.CreatedBy,
+	).Scan(&id, &sub.CreatedAt)
+	return id, err
+}
+
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT id, url, event_types, created_by, created_at FROM webhook_subscriptions ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.WebhookSubscription, error) {
+		var s models.WebhookSubscription
+		err := row.Scan(&s.ID, &s.URL, &s.EventTypes, &s.CreatedBy, &s.CreatedAt)
+		return s, err
+	})
+}
+
+// DeleteSubscription removes a subscription with its deliveries. It
+// returns pgx.ErrNoRows when there is none.
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id int) (*models.WebhookSubscription, error) {
+	var s models.WebhookSubscription
+	err := conn(ctx, r.db).QueryRow(ctx,
+		`DELETE FROM webhook_subscriptions WHERE id = $1 RETURNING id, url, event_types, created_by, created_at`, id,
+	).Scan(&s.ID, &s.URL, &s.EventTypes, &s.CreatedBy, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Enqueue adds a delivery for every subscription to eventType. An event
+// handed over again by the outbox is not queued twice.
+func (r *WebhookRepository) Enqueue(ctx context.Context, eventID int64, eventType string, body []byte) error {
+	_, err := conn(ctx, r.db).Exec(ctx,
+		`INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, body)
+         SELECT id, $1, $2, $3 FROM webhook_subscriptions WHERE $2 = ANY (event_types)
+         ON CONFLICT (subscription_id, event_id) DO NOTHING`,
+		eventID, eventType, string(body))
+	return err
+}
+
+const deliveryColumns = `id, subscription_id, event_id, event_type, status, attempts,
+    CASE WHEN status = 'pending' THEN next_attempt_at END, last_attempt_at,
+    last_status_code, last_error, created_at, delivered_at`
+
+// ListDeliveries returns a page of a subscription's deliveries with status
+// (empty means all), newest first, and the number of such deliveries. It
+// returns pgx.ErrNoRows when the subscription does not exist.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID int, status string, limit, offset int) ([]models.WebhookDelivery, int, error) {
+	var exists bool
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM webhook_subscriptions WHERE id = $1),
+                (SELECT count(*) FROM webhook_deliveries WHERE subscription_id = $1 AND ($2 = '' OR status = $2))`,
+		subscriptionID, status,
+	).Scan(&exists, &total); err != nil {
+		return nil, 0, err
+	}
+	if !exists {
+		return nil, 0, pgx.ErrNoRows
+	}
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT `+deliveryColumns+` FROM webhook_deliveries
+         WHERE subscription_id = $1 AND ($2 = '' OR status = $2)
+         ORDER BY id DESC LIMIT $3 OFFSET $4`,
+		subscriptionID, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	items, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.WebhookDelivery, error) {
+		var d models.WebhookDelivery
+		err := row.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt)
+		return d, err
+	})
+	return items, total, err
+}
+
+// Redeliver makes a delivery pending and due now with no failed attempts.
+// It returns pgx.ErrNoRows when the subscription has no such delivery.
+func (r *WebhookRepository) Redeliver(ctx context.Context, subscriptionID int, id int64) error {
+	tag, err := conn(ctx, r.db).Exec(ctx,
+		`UPDATE webhook_deliveries SET status = 'pending', attempts = 0, next_attempt_at = now(), delivered_at = NULL
+         WHERE id = $1 AND subscription_id = $2`, id, subscriptionID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Claim returns up to limit due deliveries, oldest first, and postpones
+// them by lease. Rows locked by another sender are skipped.
+func (r *WebhookRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]webhook.Delivery, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`UPDATE webhook_deliveries d SET next_attempt_at = now() + $2 * interval '1 millisecond'
+         FROM webhook_subscriptions s
+         WHERE s.id = d.subscription_id AND d.id IN (
+             SELECT id FROM webhook_deliveries
+             WHERE status = 'pending' AND next_attempt_at <= now()
+             ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED)
+         RETURNING d.id, s.url, s.secret, d.event_type, d.body, d.attempts`,
+		limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (webhook.Delivery, error) {
+		var d webhook.Delivery
+		var body string
+		err := row.Scan(&d.ID, &d.URL, &d.Secret, &d.EventType, &body, &d.Attempts)
+		d.Body = []byte(body)
+		return d, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	// RETURNING does not keep the subquery's order.
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, id int64, a webhook.Attempt) error {
+	_, err := conn(ctx, r.db).Exec(ctx,
+		`UPDATE webhook_deliveries SET status = 'delivered', delivered_at = now(), last_attempt_at = now(),
+                last_status_code = $2, last_error = NULL
+         WHERE id = $1`, id, a.StatusCode)
+	return err
+}
+
+// MarkFailed counts a failed attempt. A nil retryAt dead-letters the
+// delivery.
+func (r *WebhookRepository) MarkFailed(ctx context.Context, id int64, a webhook.Attempt, retryAt *time.Time) error {
+	_, err := conn(ctx, r.db).Exec(ctx,
+		`UPDATE webhook_deliveries SET attempts = attempts + 1, last_attempt_at = now(),
+                last_status_code = NULLIF($2, 0), last_error = $3,
+                next_attempt_at = COALESCE($4, next_attempt_at),
+                status = CASE WHEN $4::timestamptz IS NULL THEN 'dead' ELSE 'pending' END
+         WHERE id = $1`, id, a.StatusCode, a.Error, retryAt)
+	return err
+}
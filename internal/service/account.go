@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/bcrypt"
+
+	"filmhub/internal/models"
+	"filmhub/internal/repository"
+	"filmhub/pkg/mailer"
+	"filmhub/pkg/signedtoken"
+)
+
+// Token purposes, also stored in user_tokens.purpose.
+const (
+	PurposeVerifyEmail   = "verify_email"
+	PurposeResetPassword = "reset_password"
+)
+
+const (
+	verifyEmailTTL   = 48 * time.Hour
+	resetPasswordTTL = time.Hour
+)
+
+var (
+	// ErrInvalidToken covers malformed, expired and already used tokens.
+	ErrInvalidToken = errors.New("invalid or expired token")
+	// ErrEmailNotVerified is returned when an action requires a verified email.
+	ErrEmailNotVerified = errors.New("email is not verified")
+)
+
+// AccountService implements email verification and password reset using
+// signed single-use tokens delivered by email.
+type AccountService struct {
+	users   repository.UserRepository
+	tokens  repository.UserTokenRepository
+	signer  *signedtoken.Signer
+	mail    mailer.Mailer
+	baseURL string
+	tx      Transactor
+}
+
+func NewAccountService(
+	users repository.UserRepository,
+	tokens repository.UserTokenRepository,
+	signer *signedtoken.Signer,
+	mail mailer.Mailer,
+	baseURL string,
+) *AccountService {
+	return &AccountService{users: users, tokens: tokens, signer: signer, mail: mail, baseURL: baseURL}
+}
+
+// WithTransactions spends a token in one unit of work with t together with
+// the change it authorises, so a failed change leaves the link usable.
+func (s *AccountService) WithTransactions(t Transactor) *AccountService {
+	s.tx = t
+	return s
+}
+
+// SendEmailVerification emails a verification link to the user. It is a
+// no-op for already verified users.
+func (s *AccountService) SendEmailVerification(ctx context.Context, userID int) error {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("find user: %w", err)
+	}
+	if user.EmailVerified {
+		return nil
+	}
+	link, err := s.issueLink(ctx, user, PurposeVerifyEmail, verifyEmailTTL, "/verify-email")
+	if err != nil {
+		return err
+	}
+	return s.mail.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "FilmHub: подтверждение email",
+		Body: fmt.Sprintf("Здравствуйте, %s!\n\nПодтвердите адрес, перейдя по ссылке:\n%s\n\n"+
+			"Ссылка действует %d ч.", user.Username, link, int(verifyEmailTTL.Hours())),
+	})
+}
+
+// ConfirmEmail consumes a verification token and marks the email verified.
+func (s *AccountService) ConfirmEmail(ctx context.Context, token string) error {
+	return inTx(ctx, s.tx, func(ctx context.Context) error {
+		p, err := s.consume(ctx, token, PurposeVerifyEmail)
+		if err != nil {
+			return err
+		}
+		if err := s.users.MarkEmailVerified(ctx, p.UserID); err != nil {
+			return fmt.Errorf("mark email verified: %w", err)
+		}
+		return nil
+	})
+}
+
+// RequestPasswordReset emails a reset link. Unknown emails are silently
+// ignored so the endpoint cannot be used to probe for accounts.
+func (s *AccountService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.users.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("find user: %w", err)
+	}
+	link, err := s.issueLink(ctx, user, PurposeResetPassword, resetPasswordTTL, "/reset-password")
+	if err != nil {
+		return err
+	}
+	return s.mail.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "FilmHub: восстановление пароля",
+		Body: fmt.Sprintf("Здравствуйте, %s!\n\nЧтобы задать новый пароль, перейдите по ссылке:\n%s\n\n"+
+			"Ссылка действует %d ч. Если вы не запрашивали сброс, просто проигнорируйте письмо.",
+			user.Username, link, int(resetPasswordTTL.Hours())),
+	})
+}
+
+// ResetPassword consumes a reset token and sets a new password. Resetting
+// via an emailed link also proves ownership of the address.
+func (s *AccountService) ResetPassword(ctx context.Context, token, password string) error {
+	// Hash outside the unit of work, which may be retried, but not for
+	// tokens that are plainly forged.
+	if _, err := s.signer.Verify(token, PurposeResetPassword); err != nil {
+		return ErrInvalidToken
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return inTx(ctx, s.tx, func(ctx context.Context) error {
+		p, err := s.consume(ctx, token, PurposeResetPassword)
+		if err != nil {
+			return err
+		}
+		if err := s.users.UpdatePassword(ctx, p.UserID, string(hash)); err != nil {
+			return fmt.Errorf("update password: %w", err)
+		}
+		if err := s.users.MarkEmailVerified(ctx, p.UserID); err != nil {
+			return fmt.Errorf("mark email verified: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *AccountService) issueLink(ctx context.Context, user *models.User, purpose string, ttl time.Duration, path string) (string, error) {
+	token, p, err := s.signer.Issue(purpose, user.ID, ttl)
+	if err != nil {
+		return "", fmt.Errorf("issue token: %w", err)
+	}
+	if err := s.tokens.Save(ctx, p.Nonce, user.ID, purpose, p.Expires()); err != nil {
+		return "", fmt.Errorf("save token: %w", err)
+	}
+	return s.baseURL + path + "?token=" + url.QueryEscape(token), nil
+}
+
+func (s *AccountService) consume(ctx context.Context, token, purpose string) (signedtoken.Payload, error) {
+	p, err := s.signer.Verify(token, purpose)
+	if err != nil {
+		return signedtoken.Payload{}, ErrInvalidToken
+	}
+	if err := s.tokens.Consume(ctx, p.Nonce, purpose); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return signedtoken.Payload{}, ErrInvalidToken
+		}
+		return signedtoken.Payload{}, fmt.Errorf("consume token: %w", err)
+	}
+	return p, nil
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/bcrypt"
+
+	"filmhub/internal/models"
+	"filmhub/pkg/mailer"
+	"filmhub/pkg/signedtoken"
+)
+
+// stubTokenRepo keeps issued nonces in memory.
+type stubTokenRepo struct {
+	used map[string]bool
+}
+
+func (s *stubTokenRepo) Save(_ context.Context, nonce string, _ int, _ string, _ time.Time) error {
+	s.used[nonce] = false
+	return nil
+}
+
+func (s *stubTokenRepo) Consume(_ context.Context, nonce, _ string) error {
+	used, ok := s.used[nonce]
+	if !ok || used {
+		return pgx.ErrNoRows
+	}
+	s.used[nonce] = true
+	return nil
+}
+
+// mailOutbox records sent mail.
+type mailOutbox struct {
+	sent []mailer.Message
+}
+
+func (o *mailOutbox) Send(_ context.Context, msg mailer.Message) error {
+	o.sent = append(o.sent, msg)
+	return nil
+}
+
+// tokenFromMail extracts the token query parameter from the link in a mail.
+func tokenFromMail(t *testing.T, msg mailer.Message) string {
+	t.Helper()
+	for _, line := range strings.Split(msg.Body, "\n") {
+		if u, err := url.Parse(strings.TrimSpace(line)); err == nil && u.Query().Get("token") != "" {
+			return u.Query().Get("token")
+		}
+	}
+	t.Fatalf("no link in mail: %q", msg.Body)
+	return ""
+}
+
+func newAccountFixture(t *testing.T) (*AccountService, *stubUserRepo, *mailOutbox, *models.User) {
+	t.Helper()
+	users := newStubUserRepo()
+	user := &models.User{Username: "john", Email: "john@example.com", Password: "x"}
+	if err := users.Create(context.Background(), user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	signer, err := signedtoken.New("testsecret")
+	if err != nil {
+		t.Fatalf("signer: %v", err)
+	}
+	mail := &mailOutbox{}
+	svc := NewAccountService(users, &stubTokenRepo{used: map[string]bool{}}, signer, mail, "http://localhost:3000")
+	return svc, users, mail, user
+}
+
+func TestAccountService_EmailVerification(t *testing.T) {
+	svc, _, mail, user := newAccountFixture(t)
+	ctx := context.Background()
+
+	if err := svc.SendEmailVerification(ctx, user.ID); err != nil {
+		t.Fatalf("send verification: %v", err)
+	}
+	if len(mail.sent) != 1 || mail.sent[0].To != user.Email {
+		t.Fatalf("expected one mail to %s, got %+v", user.Email, mail.sent)
+	}
+	token := tokenFromMail(t, mail.sent[0])
+
+	if err := svc.ConfirmEmail(ctx, token); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	if !user.EmailVerified {
+		t.Errorf("email not marked verified")
+	}
+	if err := svc.ConfirmEmail(ctx, token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected token to be single-use, got %v", err)
+	}
+}
+
+func TestAccountService_PasswordReset(t *testing.T) {
+	svc, _, mail, user := newAccountFixture(t)
+	ctx := context.Background()
+
+	if err := svc.RequestPasswordReset(ctx, "nobody@example.com"); err != nil || len(mail.sent) != 0 {
+		t.Fatalf("unknown email must be ignored silently, err=%v sent=%d", err, len(mail.sent))
+	}
+	if err := svc.RequestPasswordReset(ctx, user.Email); err != nil {
+		t.Fatalf("request reset: %v", err)
+	}
+	token := tokenFromMail(t, mail.sent[0])
+
+	if err := svc.ConfirmEmail(ctx, token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("reset token must not verify email, got %v", err)
+	}
+	if err := svc.ResetPassword(ctx, token, "n3wPassword"); err != nil {
+		t.Fatalf("reset password: %v", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("n3wPassword")) != nil {
+		t.Errorf("password was not updated")
+	}
+	if err := svc.ResetPassword(ctx, token, "another1"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected token to be single-use, got %v", err)
+	}
+}
+
+// tokenTx is a Transactor that rolls back spent tokens when fn fails.
+type tokenTx struct {
+	tokens *stubTokenRepo
+}
+
+func (t tokenTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	saved := maps.Clone(t.tokens.used)
+	err := fn(ctx)
+	if err != nil {
+		t.tokens.used = saved
+	}
+	return err
+}
+
+// failingPasswordRepo fails password updates.
+type failingPasswordRepo struct {
+	*stubUserRepo
+}
+
+func (failingPasswordRepo) UpdatePassword(context.Context, int, string) error {
+	return errors.New("connection reset")
+}
+
+func TestAccountService_FailedResetKeepsLink(t *testing.T) {
+	ctx := context.Background()
+	users := newStubUserRepo()
+	user := &models.User{Username: "john", Email: "john@example.com", Password: "x"}
+	if err := users.Create(ctx, user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	signer, _ := signedtoken.New("testsecret")
+	tokens := &stubTokenRepo{used: map[string]bool{}}
+	mail := &mailOutbox{}
+	svc := NewAccountService(failingPasswordRepo{users}, tokens, signer, mail, "http://localhost:3000").
+		WithTransactions(tokenTx{tokens})
+
+	if err := svc.RequestPasswordReset(ctx, user.Email); err != nil {
+		t.Fatalf("request reset: %v", err)
+	}
+	token := tokenFromMail(t, mail.sent[0])
+	if err := svc.ResetPassword(ctx, token, "n3wPassword"); err == nil {
+		t.Fatal("expected the failed update to fail the reset")
+	}
+
+	// The link was not spent, so it works once the database does.
+	svc.users = users
+	if err := svc.ResetPassword(ctx, token, "n3wPassword"); err != nil {
+		t.Fatalf("expected the link to still work, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+)
+
+var (
+	// ErrUserNotFound is returned when the user can't be located in storage.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrOwnRole is returned when admins try to change their own role, which
+	// could leave the service without an admin.
+	ErrOwnRole = errors.New("cannot change your own role")
+)
+
+// RoleRepo is the subset of the user repository AdminService needs.
+type RoleRepo interface {
+	FindByID(ctx context.Context, id int) (*models.User, error)
+	UpdateRole(ctx context.Context, id int, role models.UserRole) error
+}
+
+// AdminService holds operations reserved for admins.
+type AdminService struct {
+	users RoleRepo
+	audit Auditor
+	tx    Transactor
+}
+
+func NewAdminService(users RoleRepo) *AdminService {
+	return &AdminService{users: users}
+}
+
+// WithAudit records role changes with a.
+func (s *AdminService) WithAudit(a Auditor) *AdminService {
+	s.audit = a
+	return s
+}
+
+// WithTransactions stores role changes and their audit entries in one unit
+// of work with t.
+func (s *AdminService) WithTransactions(t Transactor) *AdminService {
+	s.tx = t
+	return s
+}
+
+// SetRole changes the role of user id on behalf of adminID and returns the
+// updated user.
+func (s *AdminService) SetRole(ctx context.Context, adminID, id int, role models.UserRole) (*models.User, error) {
+	if id == adminID {
+		return nil, ErrOwnRole
+	}
+	user, err := s.users.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("find user: %w", err)
+	}
+	if user.Role == role {
+		return user, nil
+	}
+	before := roleState{Role: user.Role}
+	err = inTx(ctx, s.tx, func(ctx context.Context) error {
+		if err := s.users.UpdateRole(ctx, id, role); err != nil {
+			return fmt.Errorf("update role: %w", err)
+		}
+		return record(ctx, s.audit, models.AuditRoleChange, models.EntityUser, id, before, roleState{Role: role})
+	})
+	if err != nil {
+		return nil, err
+	}
+	user.Role = role
+	return user, nil
+}
+
+// roleState keeps credentials and contact details out of the audit log.
+type roleState struct {
+	Role models.UserRole `json:"role"`
+}
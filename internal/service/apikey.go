@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+	"filmhub/internal/repository"
+	"filmhub/pkg/login"
+)
+
+// lastUsedGranularity throttles last_used_at writes for busy keys.
+const lastUsedGranularity = time.Minute
+
+var (
+	// ErrInvalidAPIKey covers unknown, revoked and expired keys.
+	ErrInvalidAPIKey = login.ErrInvalidAPIKey
+	// ErrInvalidExpiry is returned for an expiry that is not in the future.
+	ErrInvalidExpiry = errors.New("expires_at must be in the future")
+)
+
+// APIKeyService manages personal API keys. A key looks like
+// fh_<8 hex prefix>_<secret>; only its SHA-256 is stored, which is enough
+// for a 256-bit random secret and keeps lookups a single index hit.
+type APIKeyService struct {
+	keys  repository.APIKeyRepository
+	users repository.UserRepository
+	now   func() time.Time
+}
+
+func NewAPIKeyService(keys repository.APIKeyRepository, users repository.UserRepository) *APIKeyService {
+	return &APIKeyService{keys: keys, users: users, now: time.Now}
+}
+
+// Create issues a key and returns it with the plaintext secret, which cannot
+// be recovered later.
+func (s *APIKeyService) Create(ctx context.Context, userID int, name string, scopes []string, expiresAt *time.Time) (*models.APIKey, string, error) {
+	if expiresAt != nil && !expiresAt.After(s.now()) {
+		return nil, "", ErrInvalidExpiry
+	}
+	prefix := make([]byte, 4)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	key := &models.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    login.APIKeyPrefix + hex.EncodeToString(prefix),
+		Scopes:    normalizeScopes(scopes),
+		ExpiresAt: expiresAt,
+	}
+	plain := key.Prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	if err := s.keys.Create(ctx, key, hashAPIKey(plain)); err != nil {
+		return nil, "", fmt.Errorf("create api key: %w", err)
+	}
+	return key, plain, nil
+}
+
+// List returns the user's active keys.
+func (s *APIKeyService) List(ctx context.Context, userID int) ([]models.APIKey, error) {
+	return s.keys.ListByUser(ctx, userID)
+}
+
+// Revoke disables a key of the user. It returns pgx.ErrNoRows when there is
+// no such active key.
+func (s *APIKeyService) Revoke(ctx context.Context, userID, id int) error {
+	return s.keys.Revoke(ctx, userID, id)
+}
+
+// AuthenticateAPIKey implements login.APIKeyAuthenticator. The role comes
+// from the user record so role changes apply to existing keys.
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, plain string) (*login.Principal, error) {
+	if !strings.HasPrefix(plain, login.APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+	key, err := s.keys.FindByHash(ctx, hashAPIKey(plain))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("find api key: %w", err)
+	}
+	now := s.now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && !now.Before(*key.ExpiresAt)) {
+		return nil, ErrInvalidAPIKey
+	}
+	user, err := s.users.FindByID(ctx, key.UserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("find user: %w", err)
+	}
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedGranularity {
+		if err := s.keys.TouchLastUsed(ctx, key.ID); err != nil {
+			return nil, fmt.Errorf("touch api key: %w", err)
+		}
+	}
+	return &login.Principal{
+		UserID:   user.ID,
+		Role:     string(user.Role),
+		ReadOnly: !key.HasScope(models.ScopeWrite),
+	}, nil
+}
+
+func hashAPIKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeScopes drops duplicates; write implies read.
+func normalizeScopes(scopes []string) []string {
+	var read, write bool
+	for _, s := range scopes {
+		switch s {
+		case models.ScopeRead:
+			read = true
+		case models.ScopeWrite:
+			read, write = true, true
+		}
+	}
+	var out []string
+	if read {
+		out = append(out, models.ScopeRead)
+	}
+	if write {
+		out = append(out, models.ScopeWrite)
+	}
+	return out
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+)
+
+// stubAPIKeyRepo keeps keys in memory, indexed by hash.
+type stubAPIKeyRepo struct {
+	keys    map[string]*models.APIKey
+	touches int
+	err     error // returned by FindByHash when set
+}
+
+func (s *stubAPIKeyRepo) Create(_ context.Context, key *models.APIKey, hash string) error {
+	key.ID = len(s.keys) + 1
+	key.CreatedAt = time.Now()
+	s.keys[hash] = key
+	return nil
+}
+
+func (s *stubAPIKeyRepo) FindByHash(_ context.Context, hash string) (*models.APIKey, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if k, ok := s.keys[hash]; ok {
+		return k, nil
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (s *stubAPIKeyRepo) ListByUser(_ context.Context, userID int) ([]models.APIKey, error) {
+	var out []models.APIKey
+	for _, k := range s.keys {
+		if k.UserID == userID && k.RevokedAt == nil {
+			out = append(out, *k)
+		}
+	}
+	return out, nil
+}
+
+func (s *stubAPIKeyRepo) Revoke(_ context.Context, userID, id int) error {
+	for _, k := range s.keys {
+		if k.ID == id && k.UserID == userID && k.RevokedAt == nil {
+			now := time.Now()
+			k.RevokedAt = &now
+			return nil
+		}
+	}
+	return pgx.ErrNoRows
+}
+
+func (s *stubAPIKeyRepo) TouchLastUsed(_ context.Context, id int) error {
+	for _, k := range s.keys {
+		if k.ID == id {
+			now := time.Now()
+			k.LastUsedAt = &now
+			s.touches++
+		}
+	}
+	return nil
+}
+
+func newAPIKeyFixture(t *testing.T) (*APIKeyService, *stubAPIKeyRepo, *models.User) {
+	t.Helper()
+	users := newStubUserRepo()
+	user := &models.User{Username: "analyst", Email: "analyst@example.com", Role: models.RoleModerator}
+	_ = users.Create(context.Background(), user)
+	keys := &stubAPIKeyRepo{keys: map[string]*models.APIKey{}}
+	return NewAPIKeyService(keys, users), keys, user
+}
+
+func TestAPIKeyService_CreateAndAuthenticate(t *testing.T) {
+	svc, repo, user := newAPIKeyFixture(t)
+	ctx := context.Background()
+
+	key, plain, err := svc.Create(ctx, user.ID, "export", []string{"write"}, nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	for hash := range repo.keys {
+		if hash == plain {
+			t.Fatal("key stored in plaintext")
+		}
+	}
+	if len(key.Scopes) != 2 {
+		t.Fatalf("write should imply read, got %v", key.Scopes)
+	}
+
+	p, err := svc.AuthenticateAPIKey(ctx, plain)
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	if p.UserID != user.ID || p.Role != string(models.RoleModerator) || p.ReadOnly {
+		t.Fatalf("unexpected principal %+v", p)
+	}
+	// Repeated use within a minute records last use only once.
+	_, _ = svc.AuthenticateAPIKey(ctx, plain)
+	if repo.touches != 1 || key.LastUsedAt == nil {
+		t.Fatalf("expected one last_used update, got %d", repo.touches)
+	}
+
+	if _, err := svc.AuthenticateAPIKey(ctx, plain+"x"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("expected ErrInvalidAPIKey for wrong key, got %v", err)
+	}
+	if err := svc.Revoke(ctx, user.ID, key.ID); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, err := svc.AuthenticateAPIKey(ctx, plain); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("expected ErrInvalidAPIKey after revoke, got %v", err)
+	}
+	if keys, _ := svc.List(ctx, user.ID); len(keys) != 0 {
+		t.Fatalf("revoked key still listed: %+v", keys)
+	}
+}
+
+func TestAPIKeyService_ScopesAndExpiry(t *testing.T) {
+	svc, _, user := newAPIKeyFixture(t)
+	ctx := context.Background()
+
+	past := time.Now().Add(-time.Hour)
+	if _, _, err := svc.Create(ctx, user.ID, "old", []string{"read"}, &past); !errors.Is(err, ErrInvalidExpiry) {
+		t.Fatalf("expected ErrInvalidExpiry, got %v", err)
+	}
+
+	soon := time.Now().Add(time.Hour)
+	_, plain, err := svc.Create(ctx, user.ID, "reader", []string{"read"}, &soon)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	p, err := svc.AuthenticateAPIKey(ctx, plain)
+	if err != nil || !p.ReadOnly {
+		t.Fatalf("expected read-only principal, got %+v, %v", p, err)
+	}
+
+	svc.now = func() time.Time { return soon.Add(time.Second) }
+	if _, err := svc.AuthenticateAPIKey(ctx, plain); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("expected ErrInvalidAPIKey after expiry, got %v", err)
+	}
+}
+
+func TestAPIKeyService_StorageErrorIsNotInvalidKey(t *testing.T) {
+	svc, repo, user := newAPIKeyFixture(t)
+	ctx := context.Background()
+	_, plain, err := svc.Create(ctx, user.ID, "export", []string{"read"}, nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	repo.err = errors.New("connection refused")
+	if _, err := svc.AuthenticateAPIKey(ctx, plain); err == nil || errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("expected a storage error distinct from ErrInvalidAPIKey, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"filmhub/internal/models"
+	"filmhub/pkg/audit"
+)
+
+// Auditor records write operations; see AuditService. Services take one
+// through their WithAudit setter and record nothing without it.
+type Auditor interface {
+	Record(ctx context.Context, action, entityType string, entityID int, before, after any) error
+}
+
+// AuditRepo describes repository dependencies for the audit log.
+type AuditRepo interface {
+	Record(ctx context.Context, entry *models.AuditEntry) error
+	List(ctx context.Context, filter models.AuditFilter, limit, offset int) ([]models.AuditEntry, int, error)
+}
+
+// AuditService writes the audit log. The actor, client IP and request ID
+// come from the context, see audit.Middleware.
+type AuditService struct {
+	repo AuditRepo
+}
+
+func NewAuditService(repo AuditRepo) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Record stores an entry for action on the entity. before and after are the
+// entity's state around the operation, either may be nil; only the fields
+// that differ are kept.
+func (s *AuditService) Record(ctx context.Context, action, entityType string, entityID int, before, after any) error {
+	changedBefore, changedAfter, err := audit.Diff(before, after)
+	if err != nil {
+		return fmt.Errorf("audit diff: %w", err)
+	}
+	actor := audit.ActorFrom(ctx)
+	entry := &models.AuditEntry{
+		ActorRole:  actor.Role,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     changedBefore,
+		After:      changedAfter,
+		IP:         actor.IP,
+		RequestID:  actor.RequestID,
+	}
+	if actor.UserID != 0 {
+		entry.ActorID = &actor.UserID
+	}
+	return s.repo.Record(ctx, entry)
+}
+
+// List returns a page of entries matching filter, newest first.
+func (s *AuditService) List(ctx context.Context, filter models.AuditFilter, page, limit int) (*models.AuditPage, error) {
+	page, limit = pageBounds(page, limit)
+	items, total, err := s.repo.List(ctx, filter, limit, (page-1)*limit)
+	if err != nil {
+		return nil, fmt.Errorf("list audit log: %w", err)
+	}
+	if items == nil {
+		items = []models.AuditEntry{}
+	}
+	return &models.AuditPage{Items: items, Total: total, Page: page, Limit: limit}, nil
+}
+
+// record writes to a, if any. Callers run it in the unit of work of the
+// change it describes, so a failed entry rolls the change back and every
+// stored change is audited.
+func record(ctx context.Context, a Auditor, action, entityType string, entityID int, before, after any) error {
+	if a == nil {
+		return nil
+	}
+	if err := a.Record(ctx, action, entityType, entityID, before, after); err != nil {
+		return fmt.Errorf("record audit: %w", err)
+	}
+	return nil
+}
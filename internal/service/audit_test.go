@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"filmhub/internal/models"
+	"filmhub/pkg/audit"
+)
+
+type stubAuditRepo struct {
+	entries []models.AuditEntry
+}
+
+func (s *stubAuditRepo) Record(_ context.Context, entry *models.AuditEntry) error {
+	entry.ID = int64(len(s.entries) + 1)
+	s.entries = append(s.entries, *entry)
+	return nil
+}
+
+func (s *stubAuditRepo) List(_ context.Context, _ models.AuditFilter, _, _ int) ([]models.AuditEntry, int, error) {
+	return s.entries, len(s.entries), nil
+}
+
+func TestFilmService_AuditsChanges(t *testing.T) {
+	log := &stubAuditRepo{}
+	svc := NewFilmService(newStubFilmRepo()).WithAudit(NewAuditService(log))
+	ctx := audit.WithActor(context.Background(), audit.Actor{UserID: 5, Role: "moderator", IP: "203.0.113.7", RequestID: "req-1"})
+	released := time.Date(1999, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	id, err := svc.CreateFilm(ctx, &models.FilmRequest{Title: "Matrix", Description: "Sci-fi", ReleaseDate: released}, 5)
+	if err != nil {
+		t.Fatalf("create film: %v", err)
+	}
+	film, err := svc.UpdateFilm(ctx, id, &models.FilmRequest{Title: "The Matrix", Description: "Sci-fi", ReleaseDate: released}, 5, 1)
+	if err != nil {
+		t.Fatalf("update film: %v", err)
+	}
+	if film.UpdatedBy == nil || *film.UpdatedBy != 5 || *film.CreatedBy != 5 {
+		t.Fatalf("expected authorship to be recorded, got %+v", film)
+	}
+
+	if len(log.entries) != 2 {
+		t.Fatalf("expected 2 audit entries, got %d", len(log.entries))
+	}
+	created, updated := log.entries[0], log.entries[1]
+	if created.Action != models.AuditCreate || created.Before != nil || created.After["title"] != "Matrix" {
+		t.Fatalf("unexpected create entry %+v", created)
+	}
+	if updated.Action != models.AuditUpdate || len(updated.After) != 1 || updated.Before["title"] != "Matrix" || updated.After["title"] != "The Matrix" {
+		t.Fatalf("expected only the title in the update diff, got %v -> %v", updated.Before, updated.After)
+	}
+	if *updated.ActorID != 5 || updated.ActorRole != "moderator" || updated.IP != "203.0.113.7" || updated.RequestID != "req-1" {
+		t.Fatalf("actor not recorded: %+v", updated)
+	}
+
+	if _, err := svc.UpdateFilm(ctx, id, &models.FilmRequest{Title: "Matrix"}, 6, 1); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("expected ErrVersionMismatch for a stale version, got %v", err)
+	}
+	if _, err := svc.UpdateFilm(ctx, 42, &models.FilmRequest{Title: "x"}, 5, 1); !errors.Is(err, ErrFilmNotFound) {
+		t.Fatalf("expected ErrFilmNotFound, got %v", err)
+	}
+}
+
+func TestAdminService_SetRole(t *testing.T) {
+	ctx := context.Background()
+	users := newStubUserRepo()
+	admin := &models.User{Email: "admin@example.com", Role: models.RoleAdmin}
+	user := &models.User{Email: "user@example.com", Role: models.RoleUser}
+	_ = users.Create(ctx, admin)
+	_ = users.Create(ctx, user)
+	log := &stubAuditRepo{}
+	svc := NewAdminService(users).WithAudit(NewAuditService(log))
+
+	if _, err := svc.SetRole(ctx, admin.ID, admin.ID, models.RoleUser); !errors.Is(err, ErrOwnRole) {
+		t.Fatalf("expected ErrOwnRole, got %v", err)
+	}
+	if _, err := svc.SetRole(ctx, admin.ID, 99, models.RoleUser); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+	updated, err := svc.SetRole(ctx, admin.ID, user.ID, models.RoleModerator)
+	if err != nil || updated.Role != models.RoleModerator {
+		t.Fatalf("set role: %v %+v", err, updated)
+	}
+	if len(log.entries) != 1 || log.entries[0].Before["role"] != "user" || log.entries[0].After["role"] != "moderator" {
+		t.Fatalf("unexpected audit log %+v", log.entries)
+	}
+	if _, ok := log.entries[0].After["email"]; ok {
+		t.Fatalf("audit entry must not carry user details")
+	}
+}
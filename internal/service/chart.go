@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"filmhub/internal/models"
+	"filmhub/pkg/charts"
+)
+
+// ErrChartNotFound is returned for a chart name other than those in
+// models.Charts.
+var ErrChartNotFound = errors.New("chart not found")
+
+// ChartRepo describes repository dependencies for charts.
+type ChartRepo interface {
+	ReviewActivity(ctx context.Context, since time.Time) ([]charts.Activity, error)
+	RatingSummaries(ctx context.Context) ([]charts.RatingSummary, error)
+	Releases(ctx context.Context, since, until time.Time) ([]charts.Score, error)
+	WatchlistCounts(ctx context.Context) ([]charts.Score, error)
+	ChartRanks(ctx context.Context, chart string) (map[int]int, error)
+	ReplaceChart(ctx context.Context, chart string, entries []charts.Entry) error
+	ChartEntries(ctx context.Context, chart string, limit int) ([]models.ChartEntry, error)
+	ChartComputedAt(ctx context.Context, chart string) (*time.Time, error)
+}
+
+// ChartOptions tune how charts are computed.
+type ChartOptions struct {
+	// Size is the number of films kept per chart.
+	Size int
+	// TrendingWindow is how far back reviews count for trending, and
+	// TrendingHalfLife how quickly their weight fades.
+	TrendingWindow   time.Duration
+	TrendingHalfLife time.Duration
+	// TopRatedMinReviews is the number of reviews a film needs to be top
+	// rated; it is also the weight of the prior in the Bayesian average.
+	TopRatedMinReviews int
+	// NewReleasesWindow is how far back a release is new.
+	NewReleasesWindow time.Duration
+}
+
+// ChartService computes film charts and serves them. Refresh recomputes
+// every chart into storage, remembering each film's previous rank, and
+// Chart reads a stored chart.
+type ChartService struct {
+	repo ChartRepo
+	opts ChartOptions
+	now  func() time.Time
+}
+
+func NewChartService(repo ChartRepo, opts ChartOptions) *ChartService {
+	return &ChartService{repo: repo, opts: opts, now: time.Now}
+}
+
+// Refresh recomputes every chart.
+func (s *ChartService) Refresh(ctx context.Context) error {
+	for _, chart := range models.Charts {
+		if err := s.refresh(ctx, chart); err != nil {
+			return fmt.Errorf("refresh chart %s: %w", chart, err)
+		}
+	}
+	return nil
+}
+
+func (s *ChartService) refresh(ctx context.Context, chart string) error {
+	now := s.now()
+	var scores []charts.Score
+	switch chart {
+	case models.ChartTrending:
+		activity, err := s.repo.ReviewActivity(ctx, now.Add(-s.opts.TrendingWindow))
+		if err != nil {
+			return err
+		}
+		scores = charts.Trending(activity, now, s.opts.TrendingWindow, s.opts.TrendingHalfLife)
+	case models.ChartTopRated:
+		summaries, err := s.repo.RatingSummaries(ctx)
+		if err != nil {
+			return err
+		}
+		scores = charts.TopRated(summaries, s.opts.TopRatedMinReviews)
+	case models.ChartNewReleases:
+		releases, err := s.repo.Releases(ctx, now.Add(-s.opts.NewReleasesWindow), now)
+		if err != nil {
+			return err
+		}
+		scores = releases
+	case models.ChartMostWatchlisted:
+		counts, err := s.repo.WatchlistCounts(ctx)
+		if err != nil {
+			return err
+		}
+		scores = counts
+	}
+	previous, err := s.repo.ChartRanks(ctx, chart)
+	if err != nil {
+		return err
+	}
+	return s.repo.ReplaceChart(ctx, chart, charts.Rank(scores, s.opts.Size, previous))
+}
+
+// Chart returns the first limit films of chart as last computed, with how
+// each moved since the computation before. A limit outside 1..100 falls
+// back to 20.
+func (s *ChartService) Chart(ctx context.Context, chart string, limit int) (*models.Chart, error) {
+	if !slices.Contains(models.Charts, chart) {
+		return nil, ErrChartNotFound
+	}
+	_, limit = pageBounds(1, limit)
+	items, err := s.repo.ChartEntries(ctx, chart, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get chart: %w", err)
+	}
+	computedAt, err := s.repo.ChartComputedAt(ctx, chart)
+	if err != nil {
+		return nil, fmt.Errorf("get chart: %w", err)
+	}
+	if items == nil {
+		items = []models.ChartEntry{}
+	}
+	for i := range items {
+		e := &items[i]
+		switch {
+		case e.PreviousRank == nil:
+			e.Movement = models.ChartMoveNew
+		case *e.PreviousRank > e.Rank:
+			e.Movement = models.ChartMoveUp
+		case *e.PreviousRank < e.Rank:
+			e.Movement = models.ChartMoveDown
+		default:
+			e.Movement = models.ChartMoveSame
+		}
+		if e.PreviousRank != nil {
+			e.Change = *e.PreviousRank - e.Rank
+		}
+	}
+	return &models.Chart{Chart: chart, Items: items, ComputedAt: computedAt}, nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"filmhub/internal/models"
+	"filmhub/pkg/charts"
+)
+
+// stubChartRepo serves chart inputs from memory and keeps the charts
+// Refresh stores.
+type stubChartRepo struct {
+	activity  []charts.Activity
+	ratings   []charts.RatingSummary
+	releases  []charts.Score
+	watchlist []charts.Score
+	stored    map[string][]charts.Entry
+	at        map[string]time.Time
+}
+
+func (s *stubChartRepo) ReviewActivity(_ context.Context, since time.Time) ([]charts.Activity, error) {
+	var out []charts.Activity
+	for _, a := range s.activity {
+		if !a.At.Before(since) {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (s *stubChartRepo) RatingSummaries(_ context.Context) ([]charts.RatingSummary, error) {
+	return s.ratings, nil
+}
+
+func (s *stubChartRepo) Releases(_ context.Context, since, until time.Time) ([]charts.Score, error) {
+	var out []charts.Score
+	for _, r := range s.releases {
+		if at := time.Unix(int64(r.Value), 0); at.After(since) && !at.After(until) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (s *stubChartRepo) WatchlistCounts(_ context.Context) ([]charts.Score, error) {
+	return s.watchlist, nil
+}
+
+func (s *stubChartRepo) ChartRanks(_ context.Context, chart string) (map[int]int, error) {
+	ranks := map[int]int{}
+	for _, e := range s.stored[chart] {
+		ranks[e.ItemID] = e.Rank
+	}
+	return ranks, nil
+}
+
+func (s *stubChartRepo) ReplaceChart(_ context.Context, chart string, entries []charts.Entry) error {
+	if s.stored == nil {
+		s.stored, s.at = map[string][]charts.Entry{}, map[string]time.Time{}
+	}
+	s.stored[chart], s.at[chart] = entries, time.Now()
+	return nil
+}
+
+func (s *stubChartRepo) ChartEntries(_ context.Context, chart string, limit int) ([]models.ChartEntry, error) {
+	var out []models.ChartEntry
+	for _, e := range s.stored[chart][:min(len(s.stored[chart]), limit)] {
+		entry := models.ChartEntry{Rank: e.Rank, FilmID: e.ItemID, Score: e.Score}
+		if e.PreviousRank > 0 {
+			entry.PreviousRank = &e.PreviousRank
+		}
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
+func (s *stubChartRepo) ChartComputedAt(_ context.Context, chart string) (*time.Time, error) {
+	if at, ok := s.at[chart]; ok {
+		return &at, nil
+	}
+	return nil, nil
+}
+
+func TestChartService_RefreshAndRankChanges(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	repo := &stubChartRepo{
+		activity: []charts.Activity{
+			{ItemID: 1, At: now.Add(-time.Hour)}, {ItemID: 2, At: now.Add(-time.Hour)}, {ItemID: 2, At: now.Add(-2 * time.Hour)},
+		},
+		ratings: []charts.RatingSummary{{ItemID: 1, Count: 3, Mean: 9}, {ItemID: 2, Count: 1, Mean: 10}},
+		releases: []charts.Score{
+			{ItemID: 1, Value: float64(now.AddDate(0, 0, -10).Unix())},
+			{ItemID: 2, Value: float64(now.AddDate(0, 0, -2).Unix())},
+			{ItemID: 3, Value: float64(now.AddDate(0, 0, 5).Unix())},
+		},
+		watchlist: []charts.Score{{ItemID: 3, Value: 4}},
+	}
+	svc := NewChartService(repo, ChartOptions{
+		Size: 10, TrendingWindow: 7 * 24 * time.Hour, TrendingHalfLife: 48 * time.Hour,
+		TopRatedMinReviews: 2, NewReleasesWindow: 30 * 24 * time.Hour,
+	})
+	svc.now = func() time.Time { return now }
+
+	if chart, err := svc.Chart(ctx, models.ChartTrending, 10); err != nil || chart.ComputedAt != nil || len(chart.Items) != 0 {
+		t.Fatalf("expected an empty chart before the first refresh, got %+v, %v", chart, err)
+	}
+	if err := svc.Refresh(ctx); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	for chart, want := range map[string][]int{
+		models.ChartTrending:        {2, 1},
+		models.ChartTopRated:        {1},
+		models.ChartNewReleases:     {2, 1},
+		models.ChartMostWatchlisted: {3},
+	} {
+		got, err := svc.Chart(ctx, chart, 10)
+		if err != nil {
+			t.Fatalf("chart %s: %v", chart, err)
+		}
+		if got.ComputedAt == nil || len(got.Items) != len(want) {
+			t.Fatalf("chart %s: expected films %v, got %+v", chart, want, got)
+		}
+		for i, id := range want {
+			if got.Items[i].FilmID != id || got.Items[i].Rank != i+1 || got.Items[i].Movement != models.ChartMoveNew {
+				t.Fatalf("chart %s: expected films %v, all new, got %+v", chart, want, got.Items)
+			}
+		}
+	}
+
+	// Film 1 gets reviewed a lot and overtakes film 2.
+	for range 3 {
+		repo.activity = append(repo.activity, charts.Activity{ItemID: 1, At: now})
+	}
+	repo.activity = append(repo.activity, charts.Activity{ItemID: 4, At: now})
+	if err := svc.Refresh(ctx); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	trending, _ := svc.Chart(ctx, models.ChartTrending, 10)
+	if len(trending.Items) != 3 {
+		t.Fatalf("expected three trending films, got %+v", trending.Items)
+	}
+	first, second, third := trending.Items[0], trending.Items[1], trending.Items[2]
+	if first.FilmID != 1 || first.Movement != models.ChartMoveUp || first.Change != 1 || *first.PreviousRank != 2 {
+		t.Fatalf("expected film 1 to move up, got %+v", first)
+	}
+	if second.FilmID != 2 || second.Movement != models.ChartMoveDown || second.Change != -1 {
+		t.Fatalf("expected film 2 to move down, got %+v", second)
+	}
+	if third.FilmID != 4 || third.Movement != models.ChartMoveNew {
+		t.Fatalf("expected film 4 to be new, got %+v", third)
+	}
+	if top, _ := svc.Chart(ctx, models.ChartTopRated, 10); top.Items[0].Movement != models.ChartMoveSame {
+		t.Fatalf("expected the top rated chart unchanged, got %+v", top.Items)
+	}
+
+	if _, err := svc.Chart(ctx, "worst", 10); !errors.Is(err, ErrChartNotFound) {
+		t.Fatalf("expected an unknown chart to be not found, got %v", err)
+	}
+}
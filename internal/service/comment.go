@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+)
+
+var (
+	// ErrCommentNotFound is returned when the comment can't be located in storage.
+	ErrCommentNotFound = errors.New("comment not found")
+	// ErrReplyDepth is returned when replying to a reply.
+	ErrReplyDepth = errors.New("replies to replies are not allowed")
+	// ErrForbidden is returned when the user may not change someone else's content.
+	ErrForbidden = errors.New("insufficient permissions")
+)
+
+// CommentRepo describes repository dependencies for comments.
+type CommentRepo interface {
+	CreateComment(ctx context.Context, comment *models.Comment) (int, error)
+	GetCommentByID(ctx context.Context, id int) (*models.Comment, error)
+	ListCommentsByReview(ctx context.Context, reviewID, viewerID, limit, offset int) ([]models.Comment, int, error)
+	UpdateComment(ctx context.Context, id int, body string) (*models.Comment, error)
+	DeleteComment(ctx context.Context, id int) error
+}
+
+// ReviewLookup is the subset of the review repository CommentService needs.
+type ReviewLookup interface {
+	GetReviewByID(ctx context.Context, id int) (*models.Review, error)
+}
+
+type CommentService struct {
+	repo     CommentRepo
+	reviews  ReviewLookup
+	audit    Auditor
+	notifier Notifier
+	tx       Transactor
+}
+
+func NewCommentService(repo CommentRepo, reviews ReviewLookup) *CommentService {
+	return &CommentService{repo: repo, reviews: reviews}
+}
+
+// WithAudit records comment changes with a.
+func (s *CommentService) WithAudit(a Auditor) *CommentService {
+	s.audit = a
+	return s
+}
+
+// WithTransactions stores comment changes with their audit entries and
+// the notifications they cause in one unit of work with t.
+func (s *CommentService) WithTransactions(t Transactor) *CommentService {
+	s.tx = t
+	return s
+}
+
+// WithNotifications notifies the author of the review or comment replied to
+// with n.
+func (s *CommentService) WithNotifications(n Notifier) *CommentService {
+	s.notifier = n
+	return s
+}
+
+// CreateComment adds a comment to a review. A reply must target a top-level
+// comment of the same review.
+func (s *CommentService) CreateComment(ctx context.Context, comment *models.Comment) (int, error) {
+	review, err := s.requireReview(ctx, comment.ReviewID, comment.UserID)
+	if err != nil {
+		return 0, err
+	}
+	// The author of what is replied to gets notified.
+	recipient := review.UserID
+	if comment.ParentID != nil {
+		parent, err := s.getComment(ctx, *comment.ParentID)
+		if err != nil {
+			return 0, err
+		}
+		if parent.ReviewID != comment.ReviewID || !visible(parent.HiddenAt, parent.UserID, comment.UserID) {
+			return 0, ErrCommentNotFound
+		}
+		if parent.ParentID != nil {
+			return 0, ErrReplyDepth
+		}
+		recipient = parent.UserID
+	}
+	var id int
+	err = inTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		if id, err = s.repo.CreateComment(ctx, comment); err != nil {
+			return fmt.Errorf("create comment: %w", err)
+		}
+		if err := record(ctx, s.audit, models.AuditCreate, models.EntityComment, id, nil, newCommentState(comment)); err != nil {
+			return err
+		}
+		return notify(ctx, s.notifier, &models.Notification{
+			UserID: recipient, Type: models.NotificationReply, ActorID: &comment.UserID,
+			FilmID: &review.FilmID, ReviewID: &review.ID, CommentID: &id,
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// ListComments returns page (1-based) of the review's top-level comments as
+// seen by viewerID (0 when anonymous). A limit outside 1..100 falls back to
+// the default of 20.
+func (s *CommentService) ListComments(ctx context.Context, reviewID, viewerID, page, limit int) (*models.CommentPage, error) {
+	if _, err := s.requireReview(ctx, reviewID, viewerID); err != nil {
+		return nil, err
+	}
+	page, limit = pageBounds(page, limit)
+	items, total, err := s.repo.ListCommentsByReview(ctx, reviewID, viewerID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, fmt.Errorf("list comments: %w", err)
+	}
+	if items == nil {
+		items = []models.Comment{}
+	}
+	return &models.CommentPage{Items: items, Total: total, Page: page, Limit: limit}, nil
+}
+
+// UpdateComment changes the body of the user's own comment.
+func (s *CommentService) UpdateComment(ctx context.Context, id, userID int, body string) (*models.Comment, error) {
+	comment, err := s.getComment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment.UserID != userID {
+		return nil, ErrForbidden
+	}
+	var updated *models.Comment
+	err = inTx(ctx, s.tx, func(ctx context.Context) error {
+		if updated, err = s.repo.UpdateComment(ctx, id, body); err != nil {
+			return fmt.Errorf("update comment: %w", err)
+		}
+		return record(ctx, s.audit, models.AuditUpdate, models.EntityComment, id, newCommentState(comment), newCommentState(updated))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteComment removes a comment with its replies. Authors may delete their
+// own comments; moderators and admins may remove any.
+func (s *CommentService) DeleteComment(ctx context.Context, id, userID int, role models.UserRole) error {
+	comment, err := s.getComment(ctx, id)
+	if err != nil {
+		return err
+	}
+	if comment.UserID != userID && !role.CanModerate() {
+		return ErrForbidden
+	}
+	return inTx(ctx, s.tx, func(ctx context.Context) error {
+		if err := s.repo.DeleteComment(ctx, id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrCommentNotFound
+			}
+			return fmt.Errorf("delete comment: %w", err)
+		}
+		return record(ctx, s.audit, models.AuditDelete, models.EntityComment, id, newCommentState(comment), nil)
+	})
+}
+
+func (s *CommentService) getComment(ctx context.Context, id int) (*models.Comment, error) {
+	comment, err := s.repo.GetCommentByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, fmt.Errorf("get comment: %w", err)
+	}
+	return comment, nil
+}
+
+// requireReview returns the review if it exists and is visible to viewerID.
+func (s *CommentService) requireReview(ctx context.Context, id, viewerID int) (*models.Review, error) {
+	review, err := s.reviews.GetReviewByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, fmt.Errorf("get review: %w", err)
+	}
+	if !visible(review.HiddenAt, review.UserID, viewerID) {
+		return nil, ErrReviewNotFound
+	}
+	return review, nil
+}
+
+// visible reports whether content hidden at hiddenAt can be seen by viewerID:
+// hidden content stays visible to its author only.
+func visible(hiddenAt *time.Time, authorID, viewerID int) bool {
+	return hiddenAt == nil || (viewerID != 0 && authorID == viewerID)
+}
+
+// commentState is the audited part of a comment.
+type commentState struct {
+	ReviewID int    `json:"review_id"`
+	ParentID *int   `json:"parent_id"`
+	UserID   int    `json:"user_id"`
+	Body     string `json:"body"`
+}
+
+func newCommentState(c *models.Comment) *commentState {
+	return &commentState{ReviewID: c.ReviewID, ParentID: c.ParentID, UserID: c.UserID, Body: c.Body}
+}
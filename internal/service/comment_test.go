@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+)
+
+type stubCommentRepo struct {
+	comments []models.Comment
+}
+
+func (s *stubCommentRepo) CreateComment(_ context.Context, comment *models.Comment) (int, error) {
+	comment.ID = len(s.comments) + 1
+	s.comments = append(s.comments, *comment)
+	return comment.ID, nil
+}
+
+func (s *stubCommentRepo) GetCommentByID(_ context.Context, id int) (*models.Comment, error) {
+	for i := range s.comments {
+		if s.comments[i].ID == id {
+			return &s.comments[i], nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (s *stubCommentRepo) ListCommentsByReview(_ context.Context, reviewID, viewerID, limit, offset int) ([]models.Comment, int, error) {
+	var top []models.Comment
+	for _, c := range s.comments {
+		if c.ReviewID == reviewID && c.ParentID == nil && visible(c.HiddenAt, c.UserID, viewerID) {
+			top = append(top, c)
+		}
+	}
+	total := len(top)
+	if offset >= total {
+		return nil, total, nil
+	}
+	return top[offset:min(offset+limit, total)], total, nil
+}
+
+func (s *stubCommentRepo) UpdateComment(ctx context.Context, id int, body string) (*models.Comment, error) {
+	comment, err := s.GetCommentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	comment.Body = body
+	return comment, nil
+}
+
+func (s *stubCommentRepo) DeleteComment(_ context.Context, id int) error {
+	kept := s.comments[:0]
+	found := false
+	for _, c := range s.comments {
+		switch {
+		case c.ID == id:
+			found = true
+		case c.ParentID != nil && *c.ParentID == id:
+		default:
+			kept = append(kept, c)
+		}
+	}
+	s.comments = kept
+	if !found {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func newCommentFixture() (*CommentService, *stubCommentRepo) {
+	reviews := &stubReviewRepo{reviews: []models.Review{{ID: 1, FilmID: 1, UserID: 1}, {ID: 2, FilmID: 1, UserID: 2}}}
+	comments := &stubCommentRepo{}
+	return NewCommentService(comments, reviews), comments
+}
+
+func TestCommentService_RepliesAreOneLevelDeep(t *testing.T) {
+	svc, _ := newCommentFixture()
+	ctx := context.Background()
+
+	if _, err := svc.CreateComment(ctx, &models.Comment{ReviewID: 3, UserID: 1, Body: "x"}); !errors.Is(err, ErrReviewNotFound) {
+		t.Fatalf("expected ErrReviewNotFound, got %v", err)
+	}
+	top, err := svc.CreateComment(ctx, &models.Comment{ReviewID: 1, UserID: 1, Body: "top"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	reply, err := svc.CreateComment(ctx, &models.Comment{ReviewID: 1, ParentID: &top, UserID: 2, Body: "reply"})
+	if err != nil {
+		t.Fatalf("reply: %v", err)
+	}
+	if _, err := svc.CreateComment(ctx, &models.Comment{ReviewID: 1, ParentID: &reply, UserID: 1, Body: "deep"}); !errors.Is(err, ErrReplyDepth) {
+		t.Fatalf("expected ErrReplyDepth, got %v", err)
+	}
+	// The parent must belong to the same review.
+	if _, err := svc.CreateComment(ctx, &models.Comment{ReviewID: 2, ParentID: &top, UserID: 1, Body: "elsewhere"}); !errors.Is(err, ErrCommentNotFound) {
+		t.Fatalf("expected ErrCommentNotFound, got %v", err)
+	}
+}
+
+func TestCommentService_ListPaginates(t *testing.T) {
+	svc, _ := newCommentFixture()
+	ctx := context.Background()
+	for range 3 {
+		_, _ = svc.CreateComment(ctx, &models.Comment{ReviewID: 1, UserID: 1, Body: "c"})
+	}
+
+	page, err := svc.ListComments(ctx, 1, 0, 2, 2)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if page.Total != 3 || len(page.Items) != 1 || page.Items[0].ID != 3 {
+		t.Fatalf("unexpected page %+v", page)
+	}
+	page, _ = svc.ListComments(ctx, 1, 0, 0, 500)
+	if page.Page != 1 || page.Limit != defaultPageLimit || len(page.Items) != 3 {
+		t.Fatalf("expected defaults for out-of-range params, got %+v", page)
+	}
+	page, _ = svc.ListComments(ctx, 2, 0, 1, 10)
+	if page.Items == nil {
+		t.Fatal("expected an empty, non-nil page for a review without comments")
+	}
+}
+
+func TestCommentService_EditAndDeletePermissions(t *testing.T) {
+	svc, repo := newCommentFixture()
+	ctx := context.Background()
+	id, _ := svc.CreateComment(ctx, &models.Comment{ReviewID: 1, UserID: 1, Body: "original"})
+	_, _ = svc.CreateComment(ctx, &models.Comment{ReviewID: 1, ParentID: &id, UserID: 2, Body: "reply"})
+
+	if _, err := svc.UpdateComment(ctx, id, 2, "hijack"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for another user's edit, got %v", err)
+	}
+	updated, err := svc.UpdateComment(ctx, id, 1, "edited")
+	if err != nil || updated.Body != "edited" {
+		t.Fatalf("author edit failed: %v %+v", err, updated)
+	}
+
+	if err := svc.DeleteComment(ctx, id, 2, models.RoleUser); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for another user's delete, got %v", err)
+	}
+	if err := svc.DeleteComment(ctx, id, 2, models.RoleModerator); err != nil {
+		t.Fatalf("moderator delete: %v", err)
+	}
+	if len(repo.comments) != 0 {
+		t.Fatalf("expected replies to go with the comment, left %+v", repo.comments)
+	}
+	if err := svc.DeleteComment(ctx, id, 1, models.RoleUser); !errors.Is(err, ErrCommentNotFound) {
+		t.Fatalf("expected ErrCommentNotFound, got %v", err)
+	}
+}
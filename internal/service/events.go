@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"fmt"
+)
+
+// EventPublisher stores domain events for delivery; see
+// repository.OutboxRepository. Services take one through their WithEvents
+// setter and publish nothing without it. Publish inside the unit of work of
+// the change, so that the event is stored if and only if the change is.
+type EventPublisher interface {
+	Publish(ctx context.Context, eventType string, aggregateID int, payload any) error
+}
+
+// publish stores an event with p, if any.
+func publish(ctx context.Context, p EventPublisher, eventType string, aggregateID int, payload any) error {
+	if p == nil {
+		return nil
+	}
+	if err := p.Publish(ctx, eventType, aggregateID, payload); err != nil {
+		return fmt.Errorf("publish %s: %w", eventType, err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"filmhub/internal/models"
+	"filmhub/pkg/contentfilter"
+)
+
+type publishedEvent struct {
+	eventType   string
+	aggregateID int
+	payload     any
+}
+
+// stubPublisher records events, and fails them outside a unit of work.
+type stubPublisher struct {
+	events []publishedEvent
+}
+
+func (p *stubPublisher) Publish(ctx context.Context, eventType string, aggregateID int, payload any) error {
+	if ctx.Value(txKey{}) == nil {
+		return errors.New("published outside a transaction")
+	}
+	p.events = append(p.events, publishedEvent{eventType, aggregateID, payload})
+	return nil
+}
+
+func (p *stubPublisher) types() []string {
+	var out []string
+	for _, e := range p.events {
+		out = append(out, e.eventType)
+	}
+	return out
+}
+
+func TestFilmService_PublishesEvents(t *testing.T) {
+	ctx := context.Background()
+	events := &stubPublisher{}
+	svc := NewFilmService(newStubFilmRepo()).WithTransactions(&stubTx{}).WithEvents(events)
+
+	id, err := svc.CreateFilm(ctx, &models.FilmRequest{Title: "Heat", Description: "Crime", ReleaseDate: time.Date(1995, 12, 15, 0, 0, 0, 0, time.UTC)}, 1)
+	if err != nil {
+		t.Fatalf("create film: %v", err)
+	}
+	if _, err := svc.UpdateFilm(ctx, id, &models.FilmRequest{Title: "Heat (1995)", Description: "Crime"}, 1, 1); err != nil {
+		t.Fatalf("update film: %v", err)
+	}
+	if _, err := svc.UpdateFilm(ctx, id, &models.FilmRequest{Title: "Stale", Description: "Crime"}, 1, 1); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("expected a version mismatch, got %v", err)
+	}
+
+	got := events.types()
+	if len(got) != 2 || got[0] != models.EventFilmCreated || got[1] != models.EventFilmUpdated {
+		t.Fatalf("expected film.created and film.updated, got %v", got)
+	}
+	updated := events.events[1].payload.(models.FilmEvent)
+	if events.events[1].aggregateID != id || updated.Title != "Heat (1995)" || updated.Version != 2 {
+		t.Fatalf("unexpected film.updated payload %+v", updated)
+	}
+}
+
+func TestReviewService_PublishesEvents(t *testing.T) {
+	ctx := context.Background()
+	events := &stubPublisher{}
+	filter := contentfilter.NewPipeline(1, 3, contentfilter.NewBannedWords([]string{"идиот"}, contentfilter.DefaultBannedWordWeight))
+	svc := NewReviewService(&stubReviewRepo{}).WithContentFilter(filter).WithTransactions(&stubTx{}).WithEvents(events)
+
+	id, err := svc.CreateReview(ctx, &models.Review{FilmID: 4, UserID: 1, Rating: 9, Comment: "Отличный фильм"})
+	if err != nil {
+		t.Fatalf("create review: %v", err)
+	}
+	if _, err := svc.CreateReview(ctx, &models.Review{FilmID: 4, UserID: 2, Rating: 2, Comment: "Режиссёр идиот"}); err != nil {
+		t.Fatalf("held review: %v", err)
+	}
+	if len(events.events) != 1 || events.events[0].eventType != models.EventReviewCreated || events.events[0].aggregateID != id {
+		t.Fatalf("expected one review.created event for the published review, got %v", events.types())
+	}
+	if p := events.events[0].payload.(models.ReviewEvent); p.FilmID != 4 || p.Rating != 9 {
+		t.Fatalf("unexpected review.created payload %+v", p)
+	}
+}
+
+func TestAuthService_PublishesUserRegistered(t *testing.T) {
+	ctx := context.Background()
+	events := &stubPublisher{}
+	svc := NewAuthService(newStubUserRepo()).WithTransactions(&stubTx{}).WithEvents(events)
+
+	user := &models.User{Username: "jane", Email: "jane@example.com", Password: "s3cr3tPwd"}
+	if err := svc.Register(ctx, user); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if len(events.events) != 1 || events.events[0].eventType != models.EventUserRegistered {
+		t.Fatalf("expected user.registered, got %v", events.types())
+	}
+	if p := events.events[0].payload.(models.UserEvent); p.ID != user.ID || p.Username != "jane" {
+		t.Fatalf("unexpected user.registered payload %+v", p)
+	}
+}
@@ -9,28 +9,192 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
-// ErrFilmNotFound returned when the film can't be located in storage.
-var ErrFilmNotFound = errors.New("film not found")
+var (
+	// ErrFilmNotFound returned when the film can't be located in storage.
+	ErrFilmNotFound = errors.New("film not found")
+	// ErrRevisionNotFound is returned for an unknown revision of a film.
+	ErrRevisionNotFound = errors.New("revision not found")
+	// ErrNothingToRollback is returned when the film already matches the
+	// revision it is rolled back to.
+	ErrNothingToRollback = errors.New("film already matches this revision")
+	// ErrVersionMismatch is returned when an edit is based on an outdated
+	// version of the film or review.
+	ErrVersionMismatch = errors.New("resource has been modified; reload it and retry")
+)
+
+// AnyVersion passed as the version of an edit applies it to whatever version
+// is current, as If-Match: * asks for. Versions start at 1, so no ETag names
+// it.
+const AnyVersion = -1
 
 // FilmRepo describes storage operations required by FilmService. This allows
 // us to inject mocks in tests and keeps the service agnostic of the concrete
 // repository implementation.
 type FilmRepo interface {
-	CreateFilm(ctx context.Context, film *models.FilmRequest) (int, error)
+	CreateFilm(ctx context.Context, film *models.FilmRequest, createdBy int) (int, error)
+	UpdateFilm(ctx context.Context, id int, film *models.FilmRequest, updatedBy, version int) error
+	RollbackFilm(ctx context.Context, id, revision, updatedBy, version int) error
+	DeleteFilm(ctx context.Context, id, deletedBy int) error
+	ListRevisions(ctx context.Context, filmID int) ([]models.FilmRevision, error)
+	GetRevision(ctx context.Context, filmID, revision int) (*models.FilmRevision, error)
 	GetFilmByID(ctx context.Context, id int) (*models.Film, error)
 	SearchFilms(ctx context.Context, query string) ([]models.Film, error)
+	SetGenres(ctx context.Context, id int, genres []string) error
+	SetTags(ctx context.Context, id int, tags []string) error
+	SetCredits(ctx context.Context, id int, credits []models.FilmCredit) error
 }
 
 type FilmService struct {
-	repo FilmRepo
+	repo     FilmRepo
+	audit    Auditor
+	tx       Transactor
+	events   EventPublisher
+	live     Broadcaster
+	notifier Notifier
 }
 
 func NewFilmService(repo FilmRepo) *FilmService {
 	return &FilmService{repo: repo}
 }
 
-func (s *FilmService) CreateFilm(ctx context.Context, film *models.FilmRequest) (int, error) {
-	return s.repo.CreateFilm(ctx, film)
+// WithAudit records film changes with a.
+func (s *FilmService) WithAudit(a Auditor) *FilmService {
+	s.audit = a
+	return s
+}
+
+// WithTransactions runs every write, with its audit entry and events, as
+// one unit of work with t.
+func (s *FilmService) WithTransactions(t Transactor) *FilmService {
+	s.tx = t
+	return s
+}
+
+// WithEvents publishes film.created and film.updated events with p.
+func (s *FilmService) WithEvents(p EventPublisher) *FilmService {
+	s.events = p
+	return s
+}
+
+// WithLiveUpdates broadcasts film changes with b on the film's topic.
+func (s *FilmService) WithLiveUpdates(b Broadcaster) *FilmService {
+	s.live = b
+	return s
+}
+
+// WithNotifications notifies the watchers of a film with n when it gets a
+// release date.
+func (s *FilmService) WithNotifications(n Notifier) *FilmService {
+	s.notifier = n
+	return s
+}
+
+// CreateFilm stores a film added by user createdBy.
+func (s *FilmService) CreateFilm(ctx context.Context, film *models.FilmRequest, createdBy int) (int, error) {
+	var id int
+	err := inTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		if id, err = s.repo.CreateFilm(ctx, film, createdBy); err != nil {
+			return fmt.Errorf("create film: %w", err)
+		}
+		if err := record(ctx, s.audit, models.AuditCreate, models.EntityFilm, id, nil, film); err != nil {
+			return err
+		}
+		return publish(ctx, s.events, models.EventFilmCreated, id, models.FilmEvent{
+			ID: id, Title: film.Title, Description: film.Description, ReleaseDate: film.ReleaseDate, Version: 1,
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// UpdateFilm replaces the film's title, description and release date on
+// behalf of updatedBy and returns the updated film. version is the version
+// the edit is based on, or AnyVersion; ErrVersionMismatch is returned when the
+// film has changed since.
+func (s *FilmService) UpdateFilm(ctx context.Context, id int, film *models.FilmRequest, updatedBy, version int) (*models.Film, error) {
+	before, err := s.GetFilm(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version == AnyVersion {
+		version = before.Version
+	}
+	if before.Version != version {
+		return nil, ErrVersionMismatch
+	}
+	var after *models.Film
+	err = inTx(ctx, s.tx, func(ctx context.Context) error {
+		if err := s.repo.UpdateFilm(ctx, id, film, updatedBy, version); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				// Edited or deleted since the check above.
+				if _, err := s.GetFilm(ctx, id); err != nil {
+					return err
+				}
+				return ErrVersionMismatch
+			}
+			return fmt.Errorf("update film: %w", err)
+		}
+		if after, err = s.GetFilm(ctx, id); err != nil {
+			return err
+		}
+		if err := record(ctx, s.audit, models.AuditUpdate, models.EntityFilm, id, filmState(before), filmState(after)); err != nil {
+			return err
+		}
+		if err := publish(ctx, s.events, models.EventFilmUpdated, id, newFilmEvent(after)); err != nil {
+			return err
+		}
+		return s.notifyReleaseDate(ctx, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	broadcast(s.live, FilmTopic(id), models.LiveFilmUpdated, after)
+	return after, nil
+}
+
+// ImportFilms adds films together with their genres, tags and credits on
+// behalf of createdBy and returns their IDs in order. The import is all or nothing.
+func (s *FilmService) ImportFilms(ctx context.Context, films []models.FilmImport, createdBy int) ([]int, error) {
+	var ids []int
+	err := inTx(ctx, s.tx, func(ctx context.Context) error {
+		ids = make([]int, 0, len(films))
+		for i := range films {
+			film := &films[i]
+			id, err := s.repo.CreateFilm(ctx, &models.FilmRequest{
+				Title: film.Title, Description: film.Description, ReleaseDate: film.ReleaseDate,
+			}, createdBy)
+			if err != nil {
+				return fmt.Errorf("import film %d: %w", i, err)
+			}
+			if err := s.repo.SetGenres(ctx, id, film.Genres); err != nil {
+				return fmt.Errorf("set genres of film %d: %w", i, err)
+			}
+			if err := s.repo.SetTags(ctx, id, film.Tags); err != nil {
+				return fmt.Errorf("set tags of film %d: %w", i, err)
+			}
+			if err := s.repo.SetCredits(ctx, id, film.Credits); err != nil {
+				return fmt.Errorf("set credits of film %d: %w", i, err)
+			}
+			if err := record(ctx, s.audit, models.AuditCreate, models.EntityFilm, id, nil, film); err != nil {
+				return err
+			}
+			if err := publish(ctx, s.events, models.EventFilmCreated, id, models.FilmEvent{
+				ID: id, Title: film.Title, Description: film.Description, ReleaseDate: film.ReleaseDate,
+				Genres: film.Genres, Tags: film.Tags, Version: 1,
+			}); err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
 }
 
 func (s *FilmService) GetFilm(ctx context.Context, id int) (*models.Film, error) {
@@ -52,3 +216,149 @@ func (s *FilmService) SearchFilms(ctx context.Context, query string) ([]models.F
 	}
 	return films, nil
 }
+
+// DeleteFilm moves the film to the trash on behalf of userID. Admins can
+// restore it until the purge job removes it for good.
+func (s *FilmService) DeleteFilm(ctx context.Context, id, userID int) error {
+	before, err := s.GetFilm(ctx, id)
+	if err != nil {
+		return err
+	}
+	return inTx(ctx, s.tx, func(ctx context.Context) error {
+		if err := s.repo.DeleteFilm(ctx, id, userID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrFilmNotFound
+			}
+			return fmt.Errorf("delete film: %w", err)
+		}
+		return record(ctx, s.audit, models.AuditDelete, models.EntityFilm, id, filmState(before), nil)
+	})
+}
+
+// Revisions returns the film's history, newest first.
+func (s *FilmService) Revisions(ctx context.Context, id int) ([]models.FilmRevision, error) {
+	if _, err := s.GetFilm(ctx, id); err != nil {
+		return nil, err
+	}
+	revisions, err := s.repo.ListRevisions(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("list revisions: %w", err)
+	}
+	if revisions == nil {
+		revisions = []models.FilmRevision{}
+	}
+	return revisions, nil
+}
+
+// DiffRevisions lists the fields that differ between two revisions of a film.
+func (s *FilmService) DiffRevisions(ctx context.Context, id, from, to int) (*models.FilmDiff, error) {
+	a, err := s.getRevision(ctx, id, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.getRevision(ctx, id, to)
+	if err != nil {
+		return nil, err
+	}
+	changes := []models.FieldChange{}
+	if a.Title != b.Title {
+		changes = append(changes, models.FieldChange{Field: "title", From: a.Title, To: b.Title})
+	}
+	if a.Description != b.Description {
+		changes = append(changes, models.FieldChange{Field: "description", From: a.Description, To: b.Description})
+	}
+	if !a.ReleaseDate.Equal(b.ReleaseDate) {
+		changes = append(changes, models.FieldChange{Field: "release_date", From: a.ReleaseDate, To: b.ReleaseDate})
+	}
+	return &models.FilmDiff{FilmID: id, From: from, To: to, Changes: changes}, nil
+}
+
+// Rollback restores the film to an earlier revision on behalf of userID.
+// The rollback is stored as a new revision; history is never rewritten.
+// version is the version the rollback is based on, or AnyVersion;
+// ErrVersionMismatch is returned when the film has changed since.
+func (s *FilmService) Rollback(ctx context.Context, id, revision, userID, version int) (*models.Film, error) {
+	before, err := s.GetFilm(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version == AnyVersion {
+		version = before.Version
+	}
+	if before.Version != version {
+		return nil, ErrVersionMismatch
+	}
+	target, err := s.getRevision(ctx, id, revision)
+	if err != nil {
+		return nil, err
+	}
+	if target.Title == before.Title && target.Description == before.Description && target.ReleaseDate.Equal(before.ReleaseDate) {
+		return nil, ErrNothingToRollback
+	}
+	var after *models.Film
+	err = inTx(ctx, s.tx, func(ctx context.Context) error {
+		if err := s.repo.RollbackFilm(ctx, id, revision, userID, version); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				// The revision exists, so the film was edited or deleted
+				// since the check above.
+				if _, err := s.GetFilm(ctx, id); err != nil {
+					return err
+				}
+				return ErrVersionMismatch
+			}
+			return fmt.Errorf("rollback film: %w", err)
+		}
+		if after, err = s.GetFilm(ctx, id); err != nil {
+			return err
+		}
+		if err := record(ctx, s.audit, models.AuditRollback, models.EntityFilm, id, filmState(before), filmState(after)); err != nil {
+			return err
+		}
+		if err := publish(ctx, s.events, models.EventFilmUpdated, id, newFilmEvent(after)); err != nil {
+			return err
+		}
+		return s.notifyReleaseDate(ctx, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	broadcast(s.live, FilmTopic(id), models.LiveFilmUpdated, after)
+	return after, nil
+}
+
+// notifyReleaseDate notifies the watchers of a film whose release date has
+// been set or moved.
+func (s *FilmService) notifyReleaseDate(ctx context.Context, before, after *models.Film) error {
+	if s.notifier == nil || after.ReleaseDate.IsZero() || after.ReleaseDate.Equal(before.ReleaseDate) {
+		return nil
+	}
+	filmID := after.ID
+	if err := s.notifier.NotifyWatchers(ctx, &models.Notification{Type: models.NotificationReleaseDate, FilmID: &filmID}); err != nil {
+		return fmt.Errorf("notify watchers: %w", err)
+	}
+	return nil
+}
+
+func newFilmEvent(f *models.Film) models.FilmEvent {
+	return models.FilmEvent{
+		ID: f.ID, Title: f.Title, Description: f.Description, ReleaseDate: f.ReleaseDate, Genres: f.Genres, Tags: f.Tags,
+		Version: f.Version,
+	}
+}
+
+func (s *FilmService) getRevision(ctx context.Context, id, revision int) (*models.FilmRevision, error) {
+	rv, err := s.repo.GetRevision(ctx, id, revision)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, fmt.Errorf("get revision: %w", err)
+	}
+	return rv, nil
+}
+
+// filmState is the audited part of a film; bookkeeping columns such as
+// updated_at would otherwise show up in every diff.
+func filmState(f *models.Film) *models.FilmRequest {
+	return &models.FilmRequest{Title: f.Title, Description: f.Description, ReleaseDate: f.ReleaseDate}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+
+	"filmhub/internal/models"
+	"filmhub/pkg/cache"
+	"filmhub/pkg/database"
+)
+
+const (
+	filmKeyPrefix   = "film:"
+	searchKeyPrefix = "films:search:"
+)
+
+// FilmInvalidator is told about changes that affect how a film reads but do
+// not go through FilmRepo, such as new reviews; see CachedFilmRepo.
+type FilmInvalidator interface {
+	InvalidateFilm(id int)
+}
+
+// CachedFilmRepo is a read-through cache in front of a FilmRepo. Films and
+// search results are cached; revisions are read from the repository. Writes
+// going through it invalidate the cache, other changes to a film are
+// reported with InvalidateFilm. Concurrent misses of the same key share one
+// repository call.
+type CachedFilmRepo struct {
+	repo  FilmRepo
+	cache cache.Cache
+	group cache.Group
+
+	// gen is bumped by every invalidation; a load started before one is
+	// not stored.
+	gen                  atomic.Uint64
+	hits, misses, shared atomic.Uint64
+}
+
+func NewCachedFilmRepo(repo FilmRepo, c cache.Cache) *CachedFilmRepo {
+	return &CachedFilmRepo{repo: repo, cache: c}
+}
+
+func (r *CachedFilmRepo) GetFilmByID(ctx context.Context, id int) (*models.Film, error) {
+	v, err := r.load(ctx, filmKeyPrefix+strconv.Itoa(id), func(ctx context.Context) (any, error) {
+		film, err := r.repo.GetFilmByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return *film, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	film := v.(models.Film)
+	return &film, nil
+}
+
+func (r *CachedFilmRepo) SearchFilms(ctx context.Context, query string) ([]models.Film, error) {
+	v, err := r.load(ctx, searchKeyPrefix+query, func(ctx context.Context) (any, error) {
+		return r.repo.SearchFilms(ctx, query)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Callers get their own slice so the cached one stays intact.
+	return append([]models.Film(nil), v.([]models.Film)...), nil
+}
+
+// load returns the value cached under key or loads it with fn. Errors,
+// including not found, are not cached. Reads inside a unit of work may see
+// uncommitted changes, so they bypass the cache. A shared load does not end
+// when the caller that started it goes away, as the others still wait for it.
+func (r *CachedFilmRepo) load(ctx context.Context, key string, fn func(context.Context) (any, error)) (any, error) {
+	if database.InTx(ctx) {
+		return fn(ctx)
+	}
+	if v, ok := r.cache.Get(key); ok {
+		r.hits.Add(1)
+		return v, nil
+	}
+	r.misses.Add(1)
+	v, err, shared := r.group.Do(key, func() (any, error) {
+		gen := r.gen.Load()
+		v, err := fn(context.WithoutCancel(ctx))
+		if err == nil && r.gen.Load() == gen {
+			r.cache.Set(key, v)
+		}
+		return v, err
+	})
+	if shared {
+		r.shared.Add(1)
+	}
+	return v, err
+}
+
+func (r *CachedFilmRepo) CreateFilm(ctx context.Context, film *models.FilmRequest, createdBy int) (int, error) {
+	id, err := r.repo.CreateFilm(ctx, film, createdBy)
+	r.invalidate(ctx, id)
+	return id, err
+}
+
+func (r *CachedFilmRepo) UpdateFilm(ctx context.Context, id int, film *models.FilmRequest, updatedBy, version int) error {
+	err := r.repo.UpdateFilm(ctx, id, film, updatedBy, version)
+	r.invalidate(ctx, id)
+	return err
+}
+
+func (r *CachedFilmRepo) RollbackFilm(ctx context.Context, id, revision, updatedBy, version int) error {
+	err := r.repo.RollbackFilm(ctx, id, revision, updatedBy, version)
+	r.invalidate(ctx, id)
+	return err
+}
+
+func (r *CachedFilmRepo) DeleteFilm(ctx context.Context, id, deletedBy int) error {
+	err := r.repo.DeleteFilm(ctx, id, deletedBy)
+	r.invalidate(ctx, id)
+	return err
+}
+
+func (r *CachedFilmRepo) SetGenres(ctx context.Context, id int, genres []string) error {
+	err := r.repo.SetGenres(ctx, id, genres)
+	r.invalidate(ctx, id)
+	return err
+}
+
+func (r *CachedFilmRepo) SetTags(ctx context.Context, id int, tags []string) error {
+	err := r.repo.SetTags(ctx, id, tags)
+	r.invalidate(ctx, id)
+	return err
+}
+
+// SetCredits is not cached: credits are not part of a film read.
+func (r *CachedFilmRepo) SetCredits(ctx context.Context, id int, credits []models.FilmCredit) error {
+	return r.repo.SetCredits(ctx, id, credits)
+}
+
+func (r *CachedFilmRepo) ListRevisions(ctx context.Context, filmID int) ([]models.FilmRevision, error) {
+	return r.repo.ListRevisions(ctx, filmID)
+}
+
+func (r *CachedFilmRepo) GetRevision(ctx context.Context, filmID, revision int) (*models.FilmRevision, error) {
+	return r.repo.GetRevision(ctx, filmID, revision)
+}
+
+// invalidate drops film id now and, when the write is part of a
+// transaction, again once it commits: readers in between still see the old
+// row and may have cached it.
+func (r *CachedFilmRepo) invalidate(ctx context.Context, id int) {
+	r.InvalidateFilm(id)
+	database.AfterCommit(ctx, func() { r.InvalidateFilm(id) })
+}
+
+// InvalidateFilm drops film id and all search results, which may list it.
+func (r *CachedFilmRepo) InvalidateFilm(id int) {
+	r.gen.Add(1)
+	key := filmKeyPrefix + strconv.Itoa(id)
+	r.group.Forget(key)
+	r.cache.Delete(key)
+	r.cache.DeletePrefix(searchKeyPrefix)
+}
+
+// Stats returns the hit and miss counters since startup.
+func (r *CachedFilmRepo) Stats() models.CacheStats {
+	stats := models.CacheStats{
+		Hits:    r.hits.Load(),
+		Misses:  r.misses.Load(),
+		Shared:  r.shared.Load(),
+		Entries: r.cache.Len(),
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
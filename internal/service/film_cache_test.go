@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"filmhub/internal/models"
+	"filmhub/pkg/cache"
+)
+
+// countingFilmRepo counts reads that reach the repository.
+type countingFilmRepo struct {
+	*stubFilmRepo
+	gets, searches int
+}
+
+func (r *countingFilmRepo) GetFilmByID(ctx context.Context, id int) (*models.Film, error) {
+	r.gets++
+	return r.stubFilmRepo.GetFilmByID(ctx, id)
+}
+
+func (r *countingFilmRepo) SearchFilms(ctx context.Context, query string) ([]models.Film, error) {
+	r.searches++
+	return r.stubFilmRepo.SearchFilms(ctx, query)
+}
+
+func TestCachedFilmRepo(t *testing.T) {
+	ctx := context.Background()
+	repo := &countingFilmRepo{stubFilmRepo: newStubFilmRepo()}
+	cached := NewCachedFilmRepo(repo, cache.NewLRU(10, time.Minute))
+	svc := NewFilmService(cached)
+
+	id, _ := svc.CreateFilm(ctx, &models.FilmRequest{Title: "Matrix", Description: "Sci-fi"}, 1)
+	for i := 0; i < 3; i++ {
+		if _, err := svc.GetFilm(ctx, id); err != nil {
+			t.Fatalf("get film: %v", err)
+		}
+		if _, err := svc.SearchFilms(ctx, "matrix"); err != nil {
+			t.Fatalf("search films: %v", err)
+		}
+	}
+	if repo.gets != 1 || repo.searches != 1 {
+		t.Fatalf("expected one repository read each, got %d gets and %d searches", repo.gets, repo.searches)
+	}
+
+	if _, err := svc.UpdateFilm(ctx, id, &models.FilmRequest{Title: "The Matrix", Description: "Sci-fi"}, 1, 1); err != nil {
+		t.Fatalf("update film: %v", err)
+	}
+	film, _ := svc.GetFilm(ctx, id)
+	films, _ := svc.SearchFilms(ctx, "matrix")
+	if film.Title != "The Matrix" || films[0].Title != "The Matrix" {
+		t.Fatalf("expected the update to invalidate the cache, got %q and %q", film.Title, films[0].Title)
+	}
+
+	// Changes made elsewhere are reported explicitly.
+	reviews := NewReviewService(&stubReviewRepo{}).WithFilmCache(cached)
+	if _, err := reviews.CreateReview(ctx, &models.Review{FilmID: id, UserID: 2, Rating: 9}); err != nil {
+		t.Fatalf("create review: %v", err)
+	}
+	gets := repo.gets
+	_, _ = svc.GetFilm(ctx, id)
+	if repo.gets != gets+1 {
+		t.Fatal("expected a new review to invalidate the film")
+	}
+
+	// Misses are not cached.
+	for i := 0; i < 2; i++ {
+		if _, err := svc.GetFilm(ctx, 42); !errors.Is(err, ErrFilmNotFound) {
+			t.Fatalf("expected ErrFilmNotFound, got %v", err)
+		}
+	}
+
+	stats := cached.Stats()
+	if stats.Hits != 6 || stats.Misses != 7 || stats.Entries != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+// cancelAwareFilmRepo fails reads whose context is done, as a database
+// call would.
+type cancelAwareFilmRepo struct {
+	*stubFilmRepo
+}
+
+func (r cancelAwareFilmRepo) GetFilmByID(ctx context.Context, id int) (*models.Film, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return r.stubFilmRepo.GetFilmByID(ctx, id)
+}
+
+func TestCachedFilmRepo_LoadOutlivesCaller(t *testing.T) {
+	repo := cancelAwareFilmRepo{newStubFilmRepo()}
+	id, _ := repo.CreateFilm(context.Background(), &models.FilmRequest{Title: "Matrix"}, 1)
+	cached := NewCachedFilmRepo(repo, cache.NewLRU(10, time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := cached.GetFilmByID(ctx, id); err != nil {
+		t.Fatalf("expected a load shared with other callers to ignore cancellation, got %v", err)
+	}
+	if stats := cached.Stats(); stats.Entries != 1 {
+		t.Fatalf("expected the film to be cached, got %+v", stats)
+	}
+}
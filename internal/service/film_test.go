@@ -2,8 +2,11 @@ package service
 
 import (
     "context"
+    "errors"
     "testing"
 
+    "github.com/jackc/pgx/v5"
+
     "filmhub/internal/models"
 )
 
@@ -11,16 +14,18 @@ import (
 type stubFilmRepo struct {
     nextID int
     films  map[int]models.Film
+    revs   map[int][]models.FilmRevision
 }
 
 func newStubFilmRepo() *stubFilmRepo {
     return &stubFilmRepo{
         nextID: 1,
         films:  make(map[int]models.Film),
+        revs:   make(map[int][]models.FilmRevision),
     }
 }
 
-func (s *stubFilmRepo) CreateFilm(_ context.Context, req *models.FilmRequest) (int, error) {
+func (s *stubFilmRepo) CreateFilm(_ context.Context, req *models.FilmRequest, createdBy int) (int, error) {
     id := s.nextID
     s.nextID++
     s.films[id] = models.Film{
@@ -28,14 +33,106 @@ func (s *stubFilmRepo) CreateFilm(_ context.Context, req *models.FilmRequest) (i
         Title:       req.Title,
         Description: req.Description,
         ReleaseDate: req.ReleaseDate,
+        CreatedBy:   &createdBy,
+        Version:     1,
     }
+    s.addRevision(id, createdBy, models.RevisionCreate, nil)
     return id, nil
 }
 
+func (s *stubFilmRepo) UpdateFilm(_ context.Context, id int, req *models.FilmRequest, updatedBy, version int) error {
+    f, ok := s.films[id]
+    if !ok || f.Version != version {
+        return pgx.ErrNoRows
+    }
+    f.Version++
+    f.Title, f.Description, f.ReleaseDate = req.Title, req.Description, req.ReleaseDate
+    f.UpdatedBy = &updatedBy
+    s.films[id] = f
+    s.addRevision(id, updatedBy, models.RevisionUpdate, nil)
+    return nil
+}
+
+func (s *stubFilmRepo) SetGenres(_ context.Context, id int, genres []string) error {
+    f, ok := s.films[id]
+    if !ok {
+        return pgx.ErrNoRows
+    }
+    f.Genres = append([]string(nil), genres...)
+    s.films[id] = f
+    return nil
+}
+
+func (s *stubFilmRepo) SetTags(_ context.Context, id int, tags []string) error {
+    f, ok := s.films[id]
+    if !ok {
+        return pgx.ErrNoRows
+    }
+    f.Tags = append([]string(nil), tags...)
+    s.films[id] = f
+    return nil
+}
+
+func (s *stubFilmRepo) SetCredits(_ context.Context, id int, _ []models.FilmCredit) error {
+    if _, ok := s.films[id]; !ok {
+        return pgx.ErrNoRows
+    }
+    return nil
+}
+
+func (s *stubFilmRepo) DeleteFilm(_ context.Context, id, _ int) error {
+    if _, ok := s.films[id]; !ok {
+        return pgx.ErrNoRows
+    }
+    delete(s.films, id)
+    return nil
+}
+
+func (s *stubFilmRepo) RollbackFilm(ctx context.Context, id, revision, updatedBy, version int) error {
+    rv, err := s.GetRevision(ctx, id, revision)
+    if err != nil {
+        return err
+    }
+    f := s.films[id]
+    if f.Version != version {
+        return pgx.ErrNoRows
+    }
+    f.Title, f.Description, f.ReleaseDate = rv.Title, rv.Description, rv.ReleaseDate
+    f.UpdatedBy = &updatedBy
+    f.Version++
+    s.films[id] = f
+    s.addRevision(id, updatedBy, models.RevisionRollback, &revision)
+    return nil
+}
+
+func (s *stubFilmRepo) addRevision(id, authorID int, action string, restoredFrom *int) {
+    f := s.films[id]
+    s.revs[id] = append(s.revs[id], models.FilmRevision{
+        FilmID: id, Revision: len(s.revs[id]) + 1, Title: f.Title, Description: f.Description,
+        ReleaseDate: f.ReleaseDate, AuthorID: &authorID, Action: action, RestoredFrom: restoredFrom,
+    })
+}
+
+func (s *stubFilmRepo) ListRevisions(_ context.Context, filmID int) ([]models.FilmRevision, error) {
+    var revisions []models.FilmRevision
+    for i := len(s.revs[filmID]) - 1; i >= 0; i-- {
+        revisions = append(revisions, s.revs[filmID][i])
+    }
+    return revisions, nil
+}
+
+func (s *stubFilmRepo) GetRevision(_ context.Context, filmID, revision int) (*models.FilmRevision, error) {
+    if revision < 1 || revision > len(s.revs[filmID]) {
+        return nil, pgx.ErrNoRows
+    }
+    rv := s.revs[filmID][revision-1]
+    return &rv, nil
+}
+
 func (s *stubFilmRepo) GetFilmByID(_ context.Context, id int) (*models.Film, error) {
     f, ok := s.films[id]
     if !ok {
-        return nil, context.Canceled // placeholder error
+        return nil, pgx.ErrNoRows
     }
     return &f, nil
 }
@@ -86,7 +183,7 @@ func TestFilmService_BasicFlow(t *testing.T) {
     ctx := context.Background()
 
     req := &models.FilmRequest{Title: "Matrix", Description: "Sci-fi",}
-    id, err := svc.CreateFilm(ctx, req)
+    id, err := svc.CreateFilm(ctx, req, 1)
     if err != nil {
         t.Fatalf("create film failed: %v", err)
     }
@@ -102,4 +199,47 @@ func TestFilmService_BasicFlow(t *testing.T) {
     if err != nil || len(res) == 0 {
         t.Fatalf("search failed: %v", err)
     }
-} 
\ No newline at end of file
+}
+
+func TestFilmService_RevisionsAndRollback(t *testing.T) {
+    svc := NewFilmService(newStubFilmRepo())
+    ctx := context.Background()
+
+    id, _ := svc.CreateFilm(ctx, &models.FilmRequest{Title: "Matrix", Description: "Sci-fi"}, 1)
+    if _, err := svc.UpdateFilm(ctx, id, &models.FilmRequest{Title: "Matrix", Description: "Vandalised"}, 2, 1); err != nil {
+        t.Fatalf("update film: %v", err)
+    }
+
+    diff, err := svc.DiffRevisions(ctx, id, 1, 2)
+    if err != nil {
+        t.Fatalf("diff: %v", err)
+    }
+    if len(diff.Changes) != 1 || diff.Changes[0].Field != "description" || diff.Changes[0].To != "Vandalised" {
+        t.Fatalf("unexpected diff %+v", diff.Changes)
+    }
+
+    if _, err := svc.Rollback(ctx, id, 1, 3, 1); !errors.Is(err, ErrVersionMismatch) {
+        t.Fatalf("expected ErrVersionMismatch for a stale version, got %v", err)
+    }
+    film, err := svc.Rollback(ctx, id, 1, 3, 2)
+    if err != nil {
+        t.Fatalf("rollback: %v", err)
+    }
+    if film.Description != "Sci-fi" {
+        t.Fatalf("expected the original description back, got %q", film.Description)
+    }
+    if _, err := svc.Rollback(ctx, id, 1, 3, AnyVersion); !errors.Is(err, ErrNothingToRollback) {
+        t.Fatalf("expected ErrNothingToRollback, got %v", err)
+    }
+    if _, err := svc.Rollback(ctx, id, 9, 3, AnyVersion); !errors.Is(err, ErrRevisionNotFound) {
+        t.Fatalf("expected ErrRevisionNotFound, got %v", err)
+    }
+
+    revisions, err := svc.Revisions(ctx, id)
+    if err != nil {
+        t.Fatalf("revisions: %v", err)
+    }
+    if len(revisions) != 3 || revisions[0].Action != models.RevisionRollback || *revisions[0].RestoredFrom != 1 {
+        t.Fatalf("expected the rollback on top of an intact history, got %+v", revisions)
+    }
+}
@@ -0,0 +1,430 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+)
+
+// maxListEntries is the number of films a list can hold.
+const maxListEntries = 500
+
+var (
+	// ErrListNotFound is returned for a list that does not exist or that
+	// the user may not see.
+	ErrListNotFound = errors.New("list not found")
+	// ErrNotInList is returned for a film that is not in the list.
+	ErrNotInList = errors.New("film is not in the list")
+	// ErrAlreadyInList is returned when adding a film the list has.
+	ErrAlreadyInList = errors.New("film is already in the list")
+	// ErrListFull is returned when adding to a list of maxListEntries films.
+	ErrListFull = errors.New("list is full")
+	// ErrListOrder is returned for an order that does not name every film
+	// of the list exactly once.
+	ErrListOrder = errors.New("order must name every film of the list once")
+	// ErrOwnerCollaborator is returned when adding the owner of a list as
+	// its collaborator.
+	ErrOwnerCollaborator = errors.New("the owner cannot be a collaborator")
+	// ErrNotCollaborator is returned when removing a user who is not a
+	// collaborator of the list.
+	ErrNotCollaborator = errors.New("user is not a collaborator")
+)
+
+// ListRepo describes repository dependencies for film lists. Methods taking
+// viewerID fill in whether that user (0 when anonymous) likes the list.
+type ListRepo interface {
+	CreateList(ctx context.Context, ownerID int, req *models.FilmListRequest) (int, error)
+	// GetList returns the list with its collaborators but without entries.
+	GetList(ctx context.Context, id, viewerID int) (*models.FilmList, error)
+	UpdateList(ctx context.Context, id int, req *models.FilmListRequest) error
+	DeleteList(ctx context.Context, id int) error
+	// PublicLists returns public lists, those with filmID only unless it is
+	// 0, most liked first.
+	PublicLists(ctx context.Context, filmID, viewerID, limit, offset int) ([]models.FilmList, int, error)
+	// UserLists returns the lists userID owns or collaborates on, latest
+	// changed first.
+	UserLists(ctx context.Context, userID, limit, offset int) ([]models.FilmList, int, error)
+
+	// ListEntries returns the films of the list not in the trash, numbered
+	// 1..n.
+	ListEntries(ctx context.Context, listID int) ([]models.FilmListEntry, error)
+	// AddEntry appends a film to the list; it returns false if the film is
+	// in the list already.
+	AddEntry(ctx context.Context, listID, filmID, addedBy int, note string) (bool, error)
+	// UpdateEntry changes the note of an entry when note is not nil and
+	// moves it to position, as numbered by ListEntries, when that is not 0.
+	// It returns pgx.ErrNoRows when the film is not in the list.
+	UpdateEntry(ctx context.Context, listID, filmID int, note *string, position int) error
+	// RemoveEntry returns pgx.ErrNoRows when the film is not in the list.
+	RemoveEntry(ctx context.Context, listID, filmID int) error
+	// ReorderEntries returns false, changing nothing, unless filmIDs names
+	// every film ListEntries returns exactly once.
+	ReorderEntries(ctx context.Context, listID int, filmIDs []int) (bool, error)
+
+	// AddCollaborator is a no-op for an existing collaborator.
+	AddCollaborator(ctx context.Context, listID, userID int) error
+	// RemoveCollaborator returns pgx.ErrNoRows for a user who is not a
+	// collaborator.
+	RemoveCollaborator(ctx context.Context, listID, userID int) error
+	// Like and Unlike are no-ops when nothing changes.
+	Like(ctx context.Context, listID, userID int) error
+	Unlike(ctx context.Context, listID, userID int) error
+}
+
+// ListService manages user-curated lists of films. The owner edits a
+// list's details and collaborators; the owner and collaborators edit its
+// films. Private lists are hidden from everyone else as if they did not
+// exist; unlisted lists are seen by anyone with the link but are not
+// discoverable.
+type ListService struct {
+	repo  ListRepo
+	films FilmLookup
+	users UserLookup
+	audit Auditor
+	tx    Transactor
+}
+
+func NewListService(repo ListRepo, films FilmLookup, users UserLookup) *ListService {
+	return &ListService{repo: repo, films: films, users: users}
+}
+
+// WithAudit records changes to list details with a.
+func (s *ListService) WithAudit(a Auditor) *ListService {
+	s.audit = a
+	return s
+}
+
+// WithTransactions makes every change to a list one unit of work with t,
+// together with its audit entry.
+func (s *ListService) WithTransactions(t Transactor) *ListService {
+	s.tx = t
+	return s
+}
+
+// Create adds an empty list owned by ownerID; it is public unless req says
+// otherwise.
+func (s *ListService) Create(ctx context.Context, ownerID int, req *models.FilmListRequest) (int, error) {
+	if req.Visibility == "" {
+		req.Visibility = models.ListPublic
+	}
+	var id int
+	err := inTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		if id, err = s.repo.CreateList(ctx, ownerID, req); err != nil {
+			return fmt.Errorf("create list: %w", err)
+		}
+		return record(ctx, s.audit, models.AuditCreate, models.EntityList, id, nil, newListState(req))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Get returns the list with its films as seen by viewerID (0 when
+// anonymous).
+func (s *ListService) Get(ctx context.Context, id, viewerID int) (*models.FilmList, error) {
+	list, err := s.viewable(ctx, id, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if list.Entries, err = s.repo.ListEntries(ctx, id); err != nil {
+		return nil, fmt.Errorf("list entries: %w", err)
+	}
+	if list.Entries == nil {
+		list.Entries = []models.FilmListEntry{}
+	}
+	return list, nil
+}
+
+// Update replaces the title, description and visibility of the owner's
+// list.
+func (s *ListService) Update(ctx context.Context, id, userID int, req *models.FilmListRequest) (*models.FilmList, error) {
+	list, err := s.owned(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Visibility == "" {
+		req.Visibility = list.Visibility
+	}
+	before := newListState(&models.FilmListRequest{Title: list.Title, Description: list.Description, Visibility: list.Visibility})
+	err = inTx(ctx, s.tx, func(ctx context.Context) error {
+		if err := s.repo.UpdateList(ctx, id, req); err != nil {
+			return fmt.Errorf("update list: %w", err)
+		}
+		return record(ctx, s.audit, models.AuditUpdate, models.EntityList, id, before, newListState(req))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, id, userID)
+}
+
+// Delete removes the owner's list.
+func (s *ListService) Delete(ctx context.Context, id, userID int) error {
+	list, err := s.owned(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	before := newListState(&models.FilmListRequest{Title: list.Title, Description: list.Description, Visibility: list.Visibility})
+	return inTx(ctx, s.tx, func(ctx context.Context) error {
+		if err := s.repo.DeleteList(ctx, id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrListNotFound
+			}
+			return fmt.Errorf("delete list: %w", err)
+		}
+		return record(ctx, s.audit, models.AuditDelete, models.EntityList, id, before, nil)
+	})
+}
+
+// Discover returns page (1-based) of public lists, only those containing
+// filmID unless it is 0, most liked first.
+func (s *ListService) Discover(ctx context.Context, filmID, viewerID, page, limit int) (*models.FilmListPage, error) {
+	page, limit = pageBounds(page, limit)
+	items, total, err := s.repo.PublicLists(ctx, filmID, viewerID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, fmt.Errorf("list lists: %w", err)
+	}
+	if items == nil {
+		items = []models.FilmList{}
+	}
+	return &models.FilmListPage{Items: items, Total: total, Page: page, Limit: limit}, nil
+}
+
+// Mine returns page (1-based) of the lists the user owns or collaborates
+// on, whatever their visibility.
+func (s *ListService) Mine(ctx context.Context, userID, page, limit int) (*models.FilmListPage, error) {
+	page, limit = pageBounds(page, limit)
+	items, total, err := s.repo.UserLists(ctx, userID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, fmt.Errorf("list lists: %w", err)
+	}
+	if items == nil {
+		items = []models.FilmList{}
+	}
+	for i := range items {
+		items[i].CanEdit = true
+	}
+	return &models.FilmListPage{Items: items, Total: total, Page: page, Limit: limit}, nil
+}
+
+// AddEntry appends a film to a list the user may edit and returns the new
+// entry.
+func (s *ListService) AddEntry(ctx context.Context, listID, userID int, req *models.FilmListEntryRequest) (*models.FilmListEntry, error) {
+	list, err := s.editable(ctx, listID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if list.EntryCount >= maxListEntries {
+		return nil, ErrListFull
+	}
+	if _, err := s.films.GetFilmByID(ctx, req.FilmID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrFilmNotFound
+		}
+		return nil, fmt.Errorf("get film: %w", err)
+	}
+	var added bool
+	err = inTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		if added, err = s.repo.AddEntry(ctx, listID, req.FilmID, userID, req.Note); err != nil {
+			return fmt.Errorf("add list entry: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		return nil, ErrAlreadyInList
+	}
+	return s.entry(ctx, listID, req.FilmID)
+}
+
+// UpdateEntry changes the note of a film in a list the user may edit, moves
+// it, or both, and returns the entry.
+func (s *ListService) UpdateEntry(ctx context.Context, listID, filmID, userID int, req *models.FilmListEntryUpdateRequest) (*models.FilmListEntry, error) {
+	if _, err := s.editable(ctx, listID, userID); err != nil {
+		return nil, err
+	}
+	position := 0
+	if req.Position != nil {
+		position = *req.Position
+	}
+	err := inTx(ctx, s.tx, func(ctx context.Context) error {
+		if err := s.repo.UpdateEntry(ctx, listID, filmID, req.Note, position); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotInList
+			}
+			return fmt.Errorf("update list entry: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.entry(ctx, listID, filmID)
+}
+
+// RemoveEntry takes a film out of a list the user may edit; the films
+// after it move up.
+func (s *ListService) RemoveEntry(ctx context.Context, listID, filmID, userID int) error {
+	if _, err := s.editable(ctx, listID, userID); err != nil {
+		return err
+	}
+	return inTx(ctx, s.tx, func(ctx context.Context) error {
+		if err := s.repo.RemoveEntry(ctx, listID, filmID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotInList
+			}
+			return fmt.Errorf("remove list entry: %w", err)
+		}
+		return nil
+	})
+}
+
+// Reorder puts the films of a list the user may edit in the order of
+// filmIDs, which must name each of them once.
+func (s *ListService) Reorder(ctx context.Context, listID, userID int, filmIDs []int) error {
+	if _, err := s.editable(ctx, listID, userID); err != nil {
+		return err
+	}
+	return inTx(ctx, s.tx, func(ctx context.Context) error {
+		// The order is checked against the list under its lock, so films
+		// added meanwhile are not left out.
+		ok, err := s.repo.ReorderEntries(ctx, listID, filmIDs)
+		if err != nil {
+			return fmt.Errorf("reorder list: %w", err)
+		}
+		if !ok {
+			return ErrListOrder
+		}
+		return nil
+	})
+}
+
+// AddCollaborator lets another user edit the films of the owner's list.
+func (s *ListService) AddCollaborator(ctx context.Context, listID, ownerID, userID int) error {
+	if _, err := s.owned(ctx, listID, ownerID); err != nil {
+		return err
+	}
+	if userID == ownerID {
+		return ErrOwnerCollaborator
+	}
+	if _, err := s.users.FindByID(ctx, userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("find user: %w", err)
+	}
+	if err := s.repo.AddCollaborator(ctx, listID, userID); err != nil {
+		return fmt.Errorf("add collaborator: %w", err)
+	}
+	return nil
+}
+
+// RemoveCollaborator takes a collaborator off a list. The owner may remove
+// anyone; collaborators may remove themselves.
+func (s *ListService) RemoveCollaborator(ctx context.Context, listID, actorID, userID int) error {
+	list, err := s.viewable(ctx, listID, actorID)
+	if err != nil {
+		return err
+	}
+	if list.OwnerID != actorID && userID != actorID {
+		return ErrForbidden
+	}
+	if err := s.repo.RemoveCollaborator(ctx, listID, userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotCollaborator
+		}
+		return fmt.Errorf("remove collaborator: %w", err)
+	}
+	return nil
+}
+
+// Like adds or, with liked false, removes the user's like of a list they
+// can see.
+func (s *ListService) Like(ctx context.Context, listID, userID int, liked bool) error {
+	if _, err := s.viewable(ctx, listID, userID); err != nil {
+		return err
+	}
+	like := s.repo.Unlike
+	if liked {
+		like = s.repo.Like
+	}
+	if err := like(ctx, listID, userID); err != nil {
+		return fmt.Errorf("like list: %w", err)
+	}
+	return nil
+}
+
+// viewable returns the list if viewerID may see it.
+func (s *ListService) viewable(ctx context.Context, id, viewerID int) (*models.FilmList, error) {
+	list, err := s.repo.GetList(ctx, id, viewerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrListNotFound
+		}
+		return nil, fmt.Errorf("get list: %w", err)
+	}
+	list.CanEdit = viewerID != 0 && (list.OwnerID == viewerID || slices.ContainsFunc(list.Collaborators,
+		func(c models.ListCollaborator) bool { return c.UserID == viewerID }))
+	if list.Visibility == models.ListPrivate && !list.CanEdit {
+		return nil, ErrListNotFound
+	}
+	return list, nil
+}
+
+// editable returns the list if userID may change its films.
+func (s *ListService) editable(ctx context.Context, id, userID int) (*models.FilmList, error) {
+	list, err := s.viewable(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !list.CanEdit {
+		return nil, ErrForbidden
+	}
+	return list, nil
+}
+
+// owned returns the list if userID owns it.
+func (s *ListService) owned(ctx context.Context, id, userID int) (*models.FilmList, error) {
+	list, err := s.viewable(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if list.OwnerID != userID {
+		return nil, ErrForbidden
+	}
+	return list, nil
+}
+
+func (s *ListService) entry(ctx context.Context, listID, filmID int) (*models.FilmListEntry, error) {
+	entries, err := s.repo.ListEntries(ctx, listID)
+	if err != nil {
+		return nil, fmt.Errorf("list entries: %w", err)
+	}
+	for i := range entries {
+		if entries[i].FilmID == filmID {
+			return &entries[i], nil
+		}
+	}
+	return nil, ErrNotInList
+}
+
+// listState is the audited part of a list.
+type listState struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Visibility  string `json:"visibility"`
+}
+
+func newListState(req *models.FilmListRequest) *listState {
+	return &listState{Title: req.Title, Description: req.Description, Visibility: req.Visibility}
+}
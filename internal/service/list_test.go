@@ -0,0 +1,469 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+)
+
+// stubListRepo keeps lists in memory; entries hold film IDs in list order,
+// including films in the trash, which are left out of what it returns.
+type stubListRepo struct {
+	lists         map[int]*models.FilmList
+	entries       map[int][]int
+	trashed       map[int]bool
+	notes         map[[2]int]string // {listID, filmID}
+	collaborators map[int][]int
+	likes         map[[2]int]bool // {listID, userID}
+}
+
+func newStubListRepo() *stubListRepo {
+	return &stubListRepo{
+		lists:         map[int]*models.FilmList{},
+		entries:       map[int][]int{},
+		trashed:       map[int]bool{},
+		notes:         map[[2]int]string{},
+		collaborators: map[int][]int{},
+		likes:         map[[2]int]bool{},
+	}
+}
+
+func (s *stubListRepo) CreateList(_ context.Context, ownerID int, req *models.FilmListRequest) (int, error) {
+	id := len(s.lists) + 1
+	s.lists[id] = &models.FilmList{ID: id, OwnerID: ownerID, Title: req.Title, Description: req.Description, Visibility: req.Visibility}
+	return id, nil
+}
+
+func (s *stubListRepo) GetList(_ context.Context, id, viewerID int) (*models.FilmList, error) {
+	l, ok := s.lists[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	list := *l
+	list.EntryCount = len(s.visible(id))
+	list.Liked = s.likes[[2]int{id, viewerID}]
+	for key := range s.likes {
+		if key[0] == id {
+			list.LikeCount++
+		}
+	}
+	for _, userID := range s.collaborators[id] {
+		list.Collaborators = append(list.Collaborators, models.ListCollaborator{UserID: userID})
+	}
+	return &list, nil
+}
+
+func (s *stubListRepo) UpdateList(_ context.Context, id int, req *models.FilmListRequest) error {
+	l := s.lists[id]
+	l.Title, l.Description, l.Visibility = req.Title, req.Description, req.Visibility
+	return nil
+}
+
+func (s *stubListRepo) DeleteList(_ context.Context, id int) error {
+	delete(s.lists, id)
+	return nil
+}
+
+func (s *stubListRepo) PublicLists(ctx context.Context, filmID, viewerID, limit, offset int) ([]models.FilmList, int, error) {
+	var out []models.FilmList
+	for _, id := range slices.Sorted(maps.Keys(s.lists)) {
+		l := s.lists[id]
+		if l.Visibility != models.ListPublic || filmID != 0 && !slices.Contains(s.visible(id), filmID) {
+			continue
+		}
+		list, _ := s.GetList(ctx, id, viewerID)
+		out = append(out, *list)
+	}
+	total := len(out)
+	return out[min(offset, total):min(offset+limit, total)], total, nil
+}
+
+func (s *stubListRepo) UserLists(ctx context.Context, userID, limit, offset int) ([]models.FilmList, int, error) {
+	var out []models.FilmList
+	for _, id := range slices.Sorted(maps.Keys(s.lists)) {
+		l := s.lists[id]
+		if l.OwnerID != userID && !slices.Contains(s.collaborators[id], userID) {
+			continue
+		}
+		list, _ := s.GetList(ctx, id, userID)
+		out = append(out, *list)
+	}
+	total := len(out)
+	return out[min(offset, total):min(offset+limit, total)], total, nil
+}
+
+// visible returns the films of a list that are not in the trash.
+func (s *stubListRepo) visible(listID int) []int {
+	var films []int
+	for _, filmID := range s.entries[listID] {
+		if !s.trashed[filmID] {
+			films = append(films, filmID)
+		}
+	}
+	return films
+}
+
+func (s *stubListRepo) ListEntries(_ context.Context, listID int) ([]models.FilmListEntry, error) {
+	var out []models.FilmListEntry
+	for i, filmID := range s.visible(listID) {
+		out = append(out, models.FilmListEntry{Position: i + 1, FilmID: filmID, Note: s.notes[[2]int{listID, filmID}]})
+	}
+	return out, nil
+}
+
+func (s *stubListRepo) AddEntry(_ context.Context, listID, filmID, _ int, note string) (bool, error) {
+	if slices.Contains(s.entries[listID], filmID) {
+		return false, nil
+	}
+	s.entries[listID] = append(s.entries[listID], filmID)
+	s.notes[[2]int{listID, filmID}] = note
+	return true, nil
+}
+
+func (s *stubListRepo) UpdateEntry(_ context.Context, listID, filmID int, note *string, position int) error {
+	visible := s.visible(listID)
+	if !slices.Contains(visible, filmID) {
+		return pgx.ErrNoRows
+	}
+	if note != nil {
+		s.notes[[2]int{listID, filmID}] = *note
+	}
+	if position > 0 {
+		// Take the place of the film shown at position, as the repository
+		// does.
+		from := slices.Index(s.entries[listID], filmID)
+		to := slices.Index(s.entries[listID], visible[min(position, len(visible))-1])
+		films := slices.Delete(s.entries[listID], from, from+1)
+		s.entries[listID] = slices.Insert(films, to, filmID)
+	}
+	return nil
+}
+
+func (s *stubListRepo) RemoveEntry(_ context.Context, listID, filmID int) error {
+	i := slices.Index(s.entries[listID], filmID)
+	if i < 0 {
+		return pgx.ErrNoRows
+	}
+	s.entries[listID] = slices.Delete(s.entries[listID], i, i+1)
+	return nil
+}
+
+func (s *stubListRepo) ReorderEntries(_ context.Context, listID int, filmIDs []int) (bool, error) {
+	current, wanted := s.visible(listID), slices.Clone(filmIDs)
+	slices.Sort(current)
+	slices.Sort(wanted)
+	if !slices.Equal(current, wanted) {
+		return false, nil
+	}
+	order := slices.Clone(filmIDs)
+	for _, filmID := range s.entries[listID] {
+		if s.trashed[filmID] {
+			order = append(order, filmID)
+		}
+	}
+	s.entries[listID] = order
+	return true, nil
+}
+
+func (s *stubListRepo) AddCollaborator(_ context.Context, listID, userID int) error {
+	if !slices.Contains(s.collaborators[listID], userID) {
+		s.collaborators[listID] = append(s.collaborators[listID], userID)
+	}
+	return nil
+}
+
+func (s *stubListRepo) RemoveCollaborator(_ context.Context, listID, userID int) error {
+	i := slices.Index(s.collaborators[listID], userID)
+	if i < 0 {
+		return pgx.ErrNoRows
+	}
+	s.collaborators[listID] = slices.Delete(s.collaborators[listID], i, i+1)
+	return nil
+}
+
+func (s *stubListRepo) Like(_ context.Context, listID, userID int) error {
+	s.likes[[2]int{listID, userID}] = true
+	return nil
+}
+
+func (s *stubListRepo) Unlike(_ context.Context, listID, userID int) error {
+	delete(s.likes, [2]int{listID, userID})
+	return nil
+}
+
+// newTestListService returns a ListService with films 1 to 4 and users 1
+// (owner), 2 (collaborator) and 3 (anyone else).
+func newTestListService(t *testing.T) (*ListService, *stubListRepo) {
+	t.Helper()
+	films := newStubFilmRepo()
+	for id := 1; id <= 4; id++ {
+		films.films[id] = models.Film{ID: id}
+	}
+	users := newStubUserRepo()
+	for _, email := range []string{"owner@example.com", "collab@example.com", "other@example.com"} {
+		if err := users.Create(context.Background(), &models.User{Email: email}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo := newStubListRepo()
+	return NewListService(repo, films, users), repo
+}
+
+func listOrder(t *testing.T, svc *ListService, listID int) []int {
+	t.Helper()
+	list, err := svc.Get(context.Background(), listID, 1)
+	if err != nil {
+		t.Fatalf("get list: %v", err)
+	}
+	var order []int
+	for i, e := range list.Entries {
+		if e.Position != i+1 {
+			t.Fatalf("entry %d has position %d", i, e.Position)
+		}
+		order = append(order, e.FilmID)
+	}
+	return order
+}
+
+func TestListService_Visibility(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestListService(t)
+	public, _ := svc.Create(ctx, 1, &models.FilmListRequest{Title: "Public"})
+	unlisted, _ := svc.Create(ctx, 1, &models.FilmListRequest{Title: "Unlisted", Visibility: models.ListUnlisted})
+	private, _ := svc.Create(ctx, 1, &models.FilmListRequest{Title: "Private", Visibility: models.ListPrivate})
+	if err := svc.AddCollaborator(ctx, private, 1, 2); err != nil {
+		t.Fatalf("add collaborator: %v", err)
+	}
+
+	for _, viewer := range []int{0, 3} {
+		if _, err := svc.Get(ctx, unlisted, viewer); err != nil {
+			t.Errorf("viewer %d: unlisted list should be visible by link: %v", viewer, err)
+		}
+		if _, err := svc.Get(ctx, private, viewer); !errors.Is(err, ErrListNotFound) {
+			t.Errorf("viewer %d: expected ErrListNotFound for private list, got %v", viewer, err)
+		}
+	}
+	for _, viewer := range []int{1, 2} {
+		list, err := svc.Get(ctx, private, viewer)
+		if err != nil || !list.CanEdit {
+			t.Errorf("viewer %d: private list should be editable, got %+v, %v", viewer, list, err)
+		}
+	}
+
+	page, err := svc.Discover(ctx, 0, 0, 1, 0)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if page.Total != 1 || page.Items[0].ID != public {
+		t.Errorf("only the public list should be discoverable, got %+v", page.Items)
+	}
+	mine, err := svc.Mine(ctx, 2, 1, 0)
+	if err != nil {
+		t.Fatalf("mine: %v", err)
+	}
+	if mine.Total != 1 || mine.Items[0].ID != private {
+		t.Errorf("collaborator should see the shared list, got %+v", mine.Items)
+	}
+}
+
+func TestListService_Permissions(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestListService(t)
+	id, _ := svc.Create(ctx, 1, &models.FilmListRequest{Title: "Heists"})
+	if err := svc.AddCollaborator(ctx, id, 1, 2); err != nil {
+		t.Fatalf("add collaborator: %v", err)
+	}
+
+	if _, err := svc.AddEntry(ctx, id, 2, &models.FilmListEntryRequest{FilmID: 1}); err != nil {
+		t.Errorf("collaborator should add films: %v", err)
+	}
+	if _, err := svc.AddEntry(ctx, id, 3, &models.FilmListEntryRequest{FilmID: 2}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden for a stranger, got %v", err)
+	}
+	if _, err := svc.Update(ctx, id, 2, &models.FilmListRequest{Title: "Mine now"}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("collaborator must not rename the list, got %v", err)
+	}
+	if err := svc.AddCollaborator(ctx, id, 2, 3); !errors.Is(err, ErrForbidden) {
+		t.Errorf("collaborator must not add collaborators, got %v", err)
+	}
+	if err := svc.AddCollaborator(ctx, id, 1, 1); !errors.Is(err, ErrOwnerCollaborator) {
+		t.Errorf("expected ErrOwnerCollaborator, got %v", err)
+	}
+	if err := svc.AddCollaborator(ctx, id, 1, 99); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("expected ErrUserNotFound, got %v", err)
+	}
+	if err := svc.Delete(ctx, id, 2); !errors.Is(err, ErrForbidden) {
+		t.Errorf("collaborator must not delete the list, got %v", err)
+	}
+
+	// A collaborator may leave, but not remove others.
+	if err := svc.RemoveCollaborator(ctx, id, 3, 2); !errors.Is(err, ErrForbidden) {
+		t.Errorf("stranger must not remove collaborators, got %v", err)
+	}
+	if err := svc.RemoveCollaborator(ctx, id, 2, 2); err != nil {
+		t.Errorf("collaborator should leave: %v", err)
+	}
+	if err := svc.RemoveCollaborator(ctx, id, 1, 2); !errors.Is(err, ErrNotCollaborator) {
+		t.Errorf("expected ErrNotCollaborator, got %v", err)
+	}
+
+	list, err := svc.Update(ctx, id, 1, &models.FilmListRequest{Title: "Heist films"})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if list.Title != "Heist films" || list.Visibility != models.ListPublic {
+		t.Errorf("update should keep the visibility, got %+v", list)
+	}
+	if err := svc.Delete(ctx, id, 1); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := svc.Get(ctx, id, 1); !errors.Is(err, ErrListNotFound) {
+		t.Errorf("expected ErrListNotFound after delete, got %v", err)
+	}
+}
+
+func TestListService_Entries(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestListService(t)
+	id, _ := svc.Create(ctx, 1, &models.FilmListRequest{Title: "Sci-fi"})
+	for filmID := 1; filmID <= 4; filmID++ {
+		entry, err := svc.AddEntry(ctx, id, 1, &models.FilmListEntryRequest{FilmID: filmID})
+		if err != nil {
+			t.Fatalf("add film %d: %v", filmID, err)
+		}
+		if entry.Position != filmID {
+			t.Errorf("film %d added at position %d", filmID, entry.Position)
+		}
+	}
+	if _, err := svc.AddEntry(ctx, id, 1, &models.FilmListEntryRequest{FilmID: 2}); !errors.Is(err, ErrAlreadyInList) {
+		t.Errorf("expected ErrAlreadyInList, got %v", err)
+	}
+	if _, err := svc.AddEntry(ctx, id, 1, &models.FilmListEntryRequest{FilmID: 9}); !errors.Is(err, ErrFilmNotFound) {
+		t.Errorf("expected ErrFilmNotFound, got %v", err)
+	}
+
+	note := "Watch first"
+	position := 1
+	entry, err := svc.UpdateEntry(ctx, id, 3, 1, &models.FilmListEntryUpdateRequest{Note: &note, Position: &position})
+	if err != nil {
+		t.Fatalf("update entry: %v", err)
+	}
+	if entry.Position != 1 || entry.Note != note {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if got := listOrder(t, svc, id); !slices.Equal(got, []int{3, 1, 2, 4}) {
+		t.Errorf("after move: %v", got)
+	}
+
+	if err := svc.RemoveEntry(ctx, id, 1, 1); err != nil {
+		t.Fatalf("remove entry: %v", err)
+	}
+	if err := svc.RemoveEntry(ctx, id, 1, 1); !errors.Is(err, ErrNotInList) {
+		t.Errorf("expected ErrNotInList, got %v", err)
+	}
+	if got := listOrder(t, svc, id); !slices.Equal(got, []int{3, 2, 4}) {
+		t.Errorf("after remove: %v", got)
+	}
+
+	if err := svc.Reorder(ctx, id, 1, []int{4, 3}); !errors.Is(err, ErrListOrder) {
+		t.Errorf("expected ErrListOrder for a partial order, got %v", err)
+	}
+	if err := svc.Reorder(ctx, id, 1, []int{4, 3, 3}); !errors.Is(err, ErrListOrder) {
+		t.Errorf("expected ErrListOrder for a repeated film, got %v", err)
+	}
+	if err := svc.Reorder(ctx, id, 1, []int{4, 2, 3}); err != nil {
+		t.Fatalf("reorder: %v", err)
+	}
+	if got := listOrder(t, svc, id); !slices.Equal(got, []int{4, 2, 3}) {
+		t.Errorf("after reorder: %v", got)
+	}
+}
+
+func TestListService_TrashedFilms(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newTestListService(t)
+	tx := &stubTx{}
+	svc.WithTransactions(tx)
+	id, _ := svc.Create(ctx, 1, &models.FilmListRequest{Title: "Heists"})
+	for filmID := 1; filmID <= 4; filmID++ {
+		if _, err := svc.AddEntry(ctx, id, 1, &models.FilmListEntryRequest{FilmID: filmID}); err != nil {
+			t.Fatalf("add film %d: %v", filmID, err)
+		}
+	}
+	repo.trashed[2] = true
+
+	if err := svc.Reorder(ctx, id, 1, []int{4, 3, 2, 1}); !errors.Is(err, ErrListOrder) {
+		t.Errorf("expected ErrListOrder naming a film in the trash, got %v", err)
+	}
+	units := tx.units
+	if err := svc.Reorder(ctx, id, 1, []int{4, 3, 1}); err != nil {
+		t.Fatalf("reorder: %v", err)
+	}
+	if tx.units != units+1 {
+		t.Error("expected the reorder to be a unit of work")
+	}
+	if !slices.Equal(repo.entries[id], []int{4, 3, 1, 2}) {
+		t.Errorf("expected the film in the trash to follow the order, got %v", repo.entries[id])
+	}
+
+	// Positions past the end count only the films shown.
+	last := 99
+	if entry, err := svc.UpdateEntry(ctx, id, 4, 1, &models.FilmListEntryUpdateRequest{Position: &last}); err != nil || entry.Position != 3 {
+		t.Fatalf("expected film 4 to move last, got %+v, %v", entry, err)
+	}
+	if got := listOrder(t, svc, id); !slices.Equal(got, []int{3, 1, 4}) {
+		t.Errorf("after move: %v", got)
+	}
+	if _, err := svc.UpdateEntry(ctx, id, 2, 1, &models.FilmListEntryUpdateRequest{Position: &last}); !errors.Is(err, ErrNotInList) {
+		t.Errorf("expected a film in the trash not to be in the list, got %v", err)
+	}
+
+	if page, err := svc.Discover(ctx, 2, 0, 1, 10); err != nil || page.Total != 0 {
+		t.Errorf("expected no lists with a film in the trash, got %+v, %v", page, err)
+	}
+}
+
+func TestListService_Full(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newTestListService(t)
+	id, _ := svc.Create(ctx, 1, &models.FilmListRequest{Title: "Everything"})
+	for filmID := 100; len(repo.entries[id]) < maxListEntries; filmID++ {
+		repo.entries[id] = append(repo.entries[id], filmID)
+	}
+	if _, err := svc.AddEntry(ctx, id, 1, &models.FilmListEntryRequest{FilmID: 1}); !errors.Is(err, ErrListFull) {
+		t.Errorf("expected ErrListFull, got %v", err)
+	}
+}
+
+func TestListService_Likes(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestListService(t)
+	id, _ := svc.Create(ctx, 1, &models.FilmListRequest{Title: "Classics"})
+	private, _ := svc.Create(ctx, 1, &models.FilmListRequest{Title: "Drafts", Visibility: models.ListPrivate})
+
+	for range 2 {
+		if err := svc.Like(ctx, id, 3, true); err != nil {
+			t.Fatalf("like: %v", err)
+		}
+	}
+	list, _ := svc.Get(ctx, id, 3)
+	if list.LikeCount != 1 || !list.Liked {
+		t.Errorf("expected one like by the viewer, got %d, %v", list.LikeCount, list.Liked)
+	}
+	if err := svc.Like(ctx, id, 3, false); err != nil {
+		t.Fatalf("unlike: %v", err)
+	}
+	list, _ = svc.Get(ctx, id, 3)
+	if list.LikeCount != 0 || list.Liked {
+		t.Errorf("expected no likes, got %d, %v", list.LikeCount, list.Liked)
+	}
+	if err := svc.Like(ctx, private, 3, true); !errors.Is(err, ErrListNotFound) {
+		t.Errorf("expected ErrListNotFound liking a private list, got %v", err)
+	}
+}
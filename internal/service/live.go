@@ -0,0 +1,26 @@
+package service
+
+import "strconv"
+
+// ModerationTopic carries live updates only moderators see, such as held
+// reviews. Subscribers to every topic get them too.
+const ModerationTopic = "moderation"
+
+// FilmTopic is the live update topic of a film page.
+func FilmTopic(id int) string {
+	return "film:" + strconv.Itoa(id)
+}
+
+// Broadcaster pushes live updates to connected clients; see pubsub.Hub.
+// Services take one through their WithLiveUpdates setter and broadcast
+// after the change has been committed. Delivery is best effort.
+type Broadcaster interface {
+	Publish(topic, event string, data any)
+}
+
+// broadcast publishes with b, if any.
+func broadcast(b Broadcaster, topic, event string, data any) {
+	if b != nil {
+		b.Publish(topic, event, data)
+	}
+}
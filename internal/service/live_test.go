@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"filmhub/internal/models"
+	"filmhub/pkg/contentfilter"
+)
+
+// stubBroadcaster records "topic event" pairs and the last data.
+type stubBroadcaster struct {
+	sent []string
+	last any
+}
+
+func (b *stubBroadcaster) Publish(topic, event string, data any) {
+	b.sent = append(b.sent, topic+" "+event)
+	b.last = data
+}
+
+func TestReviewService_LiveUpdates(t *testing.T) {
+	ctx := context.Background()
+	live := &stubBroadcaster{}
+	filter := contentfilter.NewPipeline(1, 3, contentfilter.NewBannedWords([]string{"идиот"}, contentfilter.DefaultBannedWordWeight))
+	svc := NewReviewService(&stubReviewRepo{}).WithContentFilter(filter).WithLiveUpdates(live)
+
+	id, err := svc.CreateReview(ctx, &models.Review{FilmID: 4, UserID: 1, Rating: 9, Comment: "Отличный фильм"})
+	if err != nil {
+		t.Fatalf("create review: %v", err)
+	}
+	if _, err := svc.CreateReview(ctx, &models.Review{FilmID: 4, UserID: 2, Rating: 2, Comment: "Режиссёр идиот"}); err != nil {
+		t.Fatalf("held review: %v", err)
+	}
+	if err := svc.DeleteReview(ctx, id, 1, models.RoleUser); err != nil {
+		t.Fatalf("delete review: %v", err)
+	}
+
+	want := []string{
+		"film:4 review.created", "film:4 film.rating",
+		"moderation review.held",
+		"film:4 review.removed", "film:4 film.rating",
+	}
+	if len(live.sent) != len(want) {
+		t.Fatalf("expected %v, got %v", want, live.sent)
+	}
+	for i := range want {
+		if live.sent[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, live.sent)
+		}
+	}
+	if r := live.last.(models.FilmRating); r.FilmID != 4 || r.Rating != 0 {
+		t.Fatalf("expected the rating to drop to 0 with no published reviews, got %+v", r)
+	}
+}
+
+func TestModerationService_LiveUpdates(t *testing.T) {
+	ctx := context.Background()
+	reviews := &stubReviewRepo{}
+	id, _ := reviews.CreateReview(ctx, &models.Review{FilmID: 4, UserID: 2, Rating: 8, Comment: "Спойлер в первой строке"})
+	repo := &reviewModerationRepo{reviews: reviews, reports: []models.Report{
+		{ID: 1, TargetType: models.TargetReview, TargetID: id, Reason: "spoiler", Status: models.ReportOpen},
+	}}
+	live := &stubBroadcaster{}
+	svc := NewModerationService(repo).WithReviews(reviews).WithLiveUpdates(live)
+
+	if _, err := svc.Resolve(ctx, 1, 5, models.ModerationHide, ""); err != nil {
+		t.Fatalf("hide: %v", err)
+	}
+	if r := live.last.(models.FilmRating); r.FilmID != 4 || r.Rating != 0 {
+		t.Fatalf("expected the rating to drop to 0 once hidden, got %+v", r)
+	}
+	if _, err := svc.Resolve(ctx, 1, 5, models.ModerationRestore, ""); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+
+	want := []string{
+		"film:4 review.removed", "film:4 film.rating",
+		"film:4 review.created", "film:4 film.rating",
+	}
+	if len(live.sent) != len(want) {
+		t.Fatalf("expected %v, got %v", want, live.sent)
+	}
+	for i := range want {
+		if live.sent[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, live.sent)
+		}
+	}
+	if r := live.last.(models.FilmRating); r.Rating != 8 {
+		t.Fatalf("expected the restored review to count again, got %+v", r)
+	}
+}
+
+func TestTrashService_LiveUpdates(t *testing.T) {
+	ctx := context.Background()
+	reviews := &stubReviewRepo{}
+	id, _ := reviews.CreateReview(ctx, &models.Review{FilmID: 1, UserID: 2, Rating: 7})
+	repo := &stubTrashRepo{items: []models.TrashItem{{Type: models.EntityReview, ID: id}}}
+	live := &stubBroadcaster{}
+	svc := NewTrashService(repo, time.Hour).WithReviews(reviews).WithLiveUpdates(live)
+
+	if err := svc.Restore(ctx, models.EntityReview, id); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if len(live.sent) != 2 || live.sent[0] != "film:1 review.created" || live.sent[1] != "film:1 film.rating" {
+		t.Fatalf("expected the restored review and the new rating, got %v", live.sent)
+	}
+	if r := live.last.(models.FilmRating); r.FilmID != 1 || r.Rating != 7 {
+		t.Fatalf("unexpected rating %+v", r)
+	}
+}
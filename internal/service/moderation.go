@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+)
+
+var (
+	// ErrReportNotFound is returned when the report can't be located in storage.
+	ErrReportNotFound = errors.New("report not found")
+	// ErrAlreadyReported is returned when the user already has an open report
+	// on the same content.
+	ErrAlreadyReported = errors.New("content already reported")
+	// ErrInvalidStatus is returned for an unknown report status filter.
+	ErrInvalidStatus = errors.New("invalid report status")
+	// ErrReportClosed is returned when dismissing a report that is no longer open.
+	ErrReportClosed = errors.New("report is already closed")
+	// ErrNotHidden is returned when restoring content that is not hidden.
+	ErrNotHidden = errors.New("content is not hidden")
+)
+
+// ModerationRepo describes repository dependencies for moderation.
+type ModerationRepo interface {
+	GetTarget(ctx context.Context, targetType string, id int) (authorID int, hidden bool, err error)
+	CreateReport(ctx context.Context, report *models.Report) (bool, error)
+	GetReport(ctx context.Context, id int) (*models.Report, error)
+	ListReports(ctx context.Context, status string, limit, offset int) ([]models.Report, int, error)
+	Apply(ctx context.Context, action *models.ModerationAction) (*models.FilmRating, error)
+	ListActions(ctx context.Context, limit, offset int) ([]models.ModerationAction, int, error)
+}
+
+// ModerationService handles user reports and the moderator queue. Every
+// moderator decision is recorded in the audit trail.
+type ModerationService struct {
+	repo    ModerationRepo
+	audit   Auditor
+	tx      Transactor
+	films   FilmInvalidator
+	reviews ReviewLookup
+	events  EventPublisher
+	live    Broadcaster
+}
+
+func NewModerationService(repo ModerationRepo) *ModerationService {
+	return &ModerationService{repo: repo}
+}
+
+// WithAudit records reports and moderator decisions with a, in addition to
+// the moderation trail.
+func (s *ModerationService) WithAudit(a Auditor) *ModerationService {
+	s.audit = a
+	return s
+}
+
+// WithFilmCache invalidates in c the films whose rating changes because one
+// of their reviews is hidden or restored.
+func (s *ModerationService) WithFilmCache(c FilmInvalidator) *ModerationService {
+	s.films = c
+	return s
+}
+
+// WithReviews reads the reviews moderators act on from r; events and live
+// updates about restored reviews need it.
+func (s *ModerationService) WithReviews(r ReviewLookup) *ModerationService {
+	s.reviews = r
+	return s
+}
+
+// WithEvents publishes review.created with p when a review held by the
+// content filter when it was posted is restored, as it is published only
+// then.
+func (s *ModerationService) WithEvents(p EventPublisher) *ModerationService {
+	s.events = p
+	return s
+}
+
+// WithLiveUpdates broadcasts reviews hidden and restored by moderators, and
+// the ratings this changes, with b on the film's topic.
+func (s *ModerationService) WithLiveUpdates(b Broadcaster) *ModerationService {
+	s.live = b
+	return s
+}
+
+// WithTransactions stores reports and moderator decisions with their audit
+// entries in one unit of work with t.
+func (s *ModerationService) WithTransactions(t Transactor) *ModerationService {
+	s.tx = t
+	return s
+}
+
+// Report files a report on a review or comment. Content hidden from the
+// reporter counts as missing and is reported with ErrReviewNotFound or
+// ErrCommentNotFound.
+func (s *ModerationService) Report(ctx context.Context, report *models.Report) (int, error) {
+	authorID, hidden, err := s.repo.GetTarget(ctx, report.TargetType, report.TargetID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && hidden && (report.ReporterID == nil || authorID != *report.ReporterID)) {
+		return 0, targetNotFound(report.TargetType)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get report target: %w", err)
+	}
+	err = inTx(ctx, s.tx, func(ctx context.Context) error {
+		created, err := s.repo.CreateReport(ctx, report)
+		if err != nil {
+			return fmt.Errorf("create report: %w", err)
+		}
+		if !created {
+			return ErrAlreadyReported
+		}
+		return record(ctx, s.audit, models.AuditReport, report.TargetType, report.TargetID, nil, report)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return report.ID, nil
+}
+
+// Queue returns a page of reports with the given status; empty means open.
+func (s *ModerationService) Queue(ctx context.Context, status string, page, limit int) (*models.ReportPage, error) {
+	switch status {
+	case "":
+		status = models.ReportOpen
+	case models.ReportOpen, models.ReportActioned, models.ReportDismissed:
+	default:
+		return nil, ErrInvalidStatus
+	}
+	page, limit = pageBounds(page, limit)
+	items, total, err := s.repo.ListReports(ctx, status, limit, (page-1)*limit)
+	if err != nil {
+		return nil, fmt.Errorf("list reports: %w", err)
+	}
+	if items == nil {
+		items = []models.Report{}
+	}
+	return &models.ReportPage{Items: items, Total: total, Page: page, Limit: limit}, nil
+}
+
+// Resolve applies a moderator action to the content of a report:
+//   - hide hides the content and closes its open reports as actioned;
+//   - restore makes hidden content public again and dismisses its open
+//     reports;
+//   - dismiss closes the open reports on the content without changing it.
+func (s *ModerationService) Resolve(ctx context.Context, reportID, moderatorID int, action, note string) (*models.ModerationAction, error) {
+	report, err := s.repo.GetReport(ctx, reportID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReportNotFound
+		}
+		return nil, fmt.Errorf("get report: %w", err)
+	}
+	_, hidden, err := s.repo.GetTarget(ctx, report.TargetType, report.TargetID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, targetNotFound(report.TargetType)
+		}
+		return nil, fmt.Errorf("get report target: %w", err)
+	}
+	switch {
+	case action == models.ModerationDismiss && report.Status != models.ReportOpen:
+		return nil, ErrReportClosed
+	case action == models.ModerationRestore && !hidden:
+		return nil, ErrNotHidden
+	}
+
+	entry := &models.ModerationAction{
+		ModeratorID: moderatorID,
+		Action:      action,
+		TargetType:  report.TargetType,
+		TargetID:    report.TargetID,
+		ReportID:    &report.ID,
+		Note:        note,
+	}
+	after := hidden
+	switch action {
+	case models.ModerationHide:
+		after = true
+	case models.ModerationRestore:
+		after = false
+	}
+	var rating *models.FilmRating
+	var restored *models.Review
+	err = inTx(ctx, s.tx, func(ctx context.Context) error {
+		if rating, err = s.repo.Apply(ctx, entry); err != nil {
+			return fmt.Errorf("apply %s: %w", action, err)
+		}
+		if err := record(ctx, s.audit, action, report.TargetType, report.TargetID,
+			moderationState{Hidden: hidden}, moderationState{Hidden: after, ReportID: report.ID, Note: note}); err != nil {
+			return err
+		}
+		if action != models.ModerationRestore || report.TargetType != models.TargetReview || s.reviews == nil {
+			return nil
+		}
+		review, err := s.reviews.GetReviewByID(ctx, report.TargetID)
+		if err != nil {
+			return fmt.Errorf("get review: %w", err)
+		}
+		restored = review
+		if !report.HeldOnCreate {
+			return nil
+		}
+		return publish(ctx, s.events, models.EventReviewCreated, review.ID, models.ReviewEvent{
+			ID: review.ID, FilmID: review.FilmID, UserID: review.UserID, Rating: review.Rating, Comment: review.Comment,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rating == nil {
+		return entry, nil
+	}
+	if s.films != nil {
+		s.films.InvalidateFilm(rating.FilmID)
+	}
+	topic := FilmTopic(rating.FilmID)
+	switch {
+	case action == models.ModerationHide && !hidden:
+		broadcast(s.live, topic, models.LiveReviewRemoved, models.ReviewRef{ID: report.TargetID, FilmID: rating.FilmID})
+	case restored != nil:
+		broadcast(s.live, topic, models.LiveReviewCreated, restored)
+	}
+	broadcast(s.live, topic, models.LiveFilmRating, *rating)
+	return entry, nil
+}
+
+// AuditLog returns a page of moderator actions, newest first.
+func (s *ModerationService) AuditLog(ctx context.Context, page, limit int) (*models.ModerationLogPage, error) {
+	page, limit = pageBounds(page, limit)
+	items, total, err := s.repo.ListActions(ctx, limit, (page-1)*limit)
+	if err != nil {
+		return nil, fmt.Errorf("list moderation log: %w", err)
+	}
+	if items == nil {
+		items = []models.ModerationAction{}
+	}
+	return &models.ModerationLogPage{Items: items, Total: total, Page: page, Limit: limit}, nil
+}
+
+// moderationState is the audited state of moderated content; the report
+// and note describe the decision that led to it.
+type moderationState struct {
+	Hidden   bool   `json:"hidden"`
+	ReportID int    `json:"report_id,omitempty"`
+	Note     string `json:"note,omitempty"`
+}
+
+func targetNotFound(targetType string) error {
+	if targetType == models.TargetComment {
+		return ErrCommentNotFound
+	}
+	return ErrReviewNotFound
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+)
+
+// stubModerationRepo moderates the comments of a stubCommentRepo.
+type stubModerationRepo struct {
+	comments *stubCommentRepo
+	reports  []models.Report
+	log      []models.ModerationAction
+}
+
+func (s *stubModerationRepo) GetTarget(ctx context.Context, _ string, id int) (int, bool, error) {
+	c, err := s.comments.GetCommentByID(ctx, id)
+	if err != nil {
+		return 0, false, err
+	}
+	return c.UserID, c.HiddenAt != nil, nil
+}
+
+func (s *stubModerationRepo) CreateReport(_ context.Context, report *models.Report) (bool, error) {
+	for _, r := range s.reports {
+		if r.TargetID == report.TargetID && *r.ReporterID == *report.ReporterID && r.Status == models.ReportOpen {
+			return false, nil
+		}
+	}
+	report.ID, report.Status = len(s.reports)+1, models.ReportOpen
+	s.reports = append(s.reports, *report)
+	return true, nil
+}
+
+func (s *stubModerationRepo) GetReport(_ context.Context, id int) (*models.Report, error) {
+	if id < 1 || id > len(s.reports) {
+		return nil, pgx.ErrNoRows
+	}
+	r := s.reports[id-1]
+	return &r, nil
+}
+
+func (s *stubModerationRepo) ListReports(_ context.Context, status string, _, _ int) ([]models.Report, int, error) {
+	var out []models.Report
+	for _, r := range s.reports {
+		if r.Status == status {
+			out = append(out, r)
+		}
+	}
+	return out, len(out), nil
+}
+
+func (s *stubModerationRepo) Apply(ctx context.Context, action *models.ModerationAction) (*models.FilmRating, error) {
+	c, err := s.comments.GetCommentByID(ctx, action.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	status := ""
+	switch action.Action {
+	case models.ModerationHide:
+		now := time.Now()
+		c.HiddenAt, status = &now, models.ReportActioned
+	case models.ModerationRestore:
+		c.HiddenAt, status = nil, models.ReportDismissed
+	case models.ModerationDismiss:
+		status = models.ReportDismissed
+	}
+	for i := range s.reports {
+		if status != "" && s.reports[i].TargetID == action.TargetID && s.reports[i].Status == models.ReportOpen {
+			s.reports[i].Status = status
+		}
+	}
+	action.ID = len(s.log) + 1
+	s.log = append([]models.ModerationAction{*action}, s.log...)
+	return nil, nil
+}
+
+func (s *stubModerationRepo) ListActions(_ context.Context, _, _ int) ([]models.ModerationAction, int, error) {
+	return s.log, len(s.log), nil
+}
+
+func TestModerationService_HideRestoreDismiss(t *testing.T) {
+	comments, _ := newCommentFixture()
+	ctx := context.Background()
+	id, _ := comments.CreateComment(ctx, &models.Comment{ReviewID: 1, UserID: 2, Body: "buy cheap tickets"})
+	repo := &stubModerationRepo{comments: comments.repo.(*stubCommentRepo)}
+	svc := NewModerationService(repo)
+
+	report := func(reporterID int) error {
+		_, err := svc.Report(ctx, &models.Report{TargetType: models.TargetComment, TargetID: id, ReporterID: &reporterID, Reason: "spam"})
+		return err
+	}
+	if err := report(1); err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	if err := report(1); !errors.Is(err, ErrAlreadyReported) {
+		t.Fatalf("expected ErrAlreadyReported, got %v", err)
+	}
+	_ = report(3)
+	if _, err := svc.Report(ctx, &models.Report{TargetType: models.TargetComment, TargetID: 99, ReporterID: new(int)}); !errors.Is(err, ErrCommentNotFound) {
+		t.Fatalf("expected ErrCommentNotFound, got %v", err)
+	}
+
+	if _, err := svc.Resolve(ctx, 1, 5, models.ModerationRestore, ""); !errors.Is(err, ErrNotHidden) {
+		t.Fatalf("expected ErrNotHidden, got %v", err)
+	}
+	if _, err := svc.Resolve(ctx, 1, 5, models.ModerationHide, "spam"); err != nil {
+		t.Fatalf("hide: %v", err)
+	}
+	queue, _ := svc.Queue(ctx, "", 1, 20)
+	if queue.Total != 0 || len(queue.Items) != 0 {
+		t.Fatalf("expected both reports closed by hiding, got %+v", queue)
+	}
+
+	// Hidden content is gone for everyone but its author.
+	for viewer, want := range map[int]int{0: 0, 1: 0, 2: 1} {
+		page, err := comments.ListComments(ctx, 1, viewer, 1, 20)
+		if err != nil || len(page.Items) != want {
+			t.Fatalf("viewer %d: expected %d comments, got %+v (%v)", viewer, want, page, err)
+		}
+	}
+	if err := report(4); !errors.Is(err, ErrCommentNotFound) {
+		t.Fatalf("expected hidden comment to be unreportable, got %v", err)
+	}
+
+	if _, err := svc.Resolve(ctx, 2, 5, models.ModerationDismiss, ""); !errors.Is(err, ErrReportClosed) {
+		t.Fatalf("expected ErrReportClosed, got %v", err)
+	}
+	if _, err := svc.Resolve(ctx, 1, 5, models.ModerationRestore, "false positive"); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if page, _ := comments.ListComments(ctx, 1, 0, 1, 20); len(page.Items) != 1 {
+		t.Fatalf("expected restored comment to be public, got %+v", page)
+	}
+
+	log, _ := svc.AuditLog(ctx, 1, 20)
+	if log.Total != 2 || log.Items[0].Action != models.ModerationRestore || log.Items[1].Action != models.ModerationHide ||
+		log.Items[1].ModeratorID != 5 || *log.Items[1].ReportID != 1 || log.Items[1].Note != "spam" {
+		t.Fatalf("unexpected audit trail %+v", log.Items)
+	}
+}
+
+// reviewModerationRepo moderates the reviews of a stubReviewRepo; the
+// ratings it returns average the visible reviews of a film. It only closes
+// reports on restore.
+type reviewModerationRepo struct {
+	reviews *stubReviewRepo
+	reports []models.Report
+}
+
+func (s *reviewModerationRepo) GetTarget(ctx context.Context, _ string, id int) (int, bool, error) {
+	r, err := s.reviews.GetReviewByID(ctx, id)
+	if err != nil {
+		return 0, false, err
+	}
+	return r.UserID, r.HiddenAt != nil, nil
+}
+
+func (s *reviewModerationRepo) CreateReport(_ context.Context, report *models.Report) (bool, error) {
+	report.ID, report.Status = len(s.reports)+1, models.ReportOpen
+	s.reports = append(s.reports, *report)
+	return true, nil
+}
+
+func (s *reviewModerationRepo) GetReport(_ context.Context, id int) (*models.Report, error) {
+	if id < 1 || id > len(s.reports) {
+		return nil, pgx.ErrNoRows
+	}
+	r := s.reports[id-1]
+	return &r, nil
+}
+
+func (s *reviewModerationRepo) ListReports(_ context.Context, _ string, _, _ int) ([]models.Report, int, error) {
+	return nil, 0, nil
+}
+
+func (s *reviewModerationRepo) Apply(ctx context.Context, action *models.ModerationAction) (*models.FilmRating, error) {
+	r, err := s.reviews.GetReviewByID(ctx, action.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	switch action.Action {
+	case models.ModerationHide:
+		now := time.Now()
+		r.HiddenAt = &now
+	case models.ModerationRestore:
+		r.HiddenAt = nil
+		for i := range s.reports {
+			if s.reports[i].TargetID == r.ID && s.reports[i].Status == models.ReportOpen {
+				s.reports[i].Status = models.ReportDismissed
+			}
+		}
+	default:
+		return nil, nil
+	}
+	var sum, n int
+	for _, other := range s.reviews.reviews {
+		if other.FilmID == r.FilmID && other.HiddenAt == nil {
+			sum, n = sum+other.Rating, n+1
+		}
+	}
+	rating := &models.FilmRating{FilmID: r.FilmID}
+	if n > 0 {
+		rating.Rating = float32(sum) / float32(n)
+	}
+	return rating, nil
+}
+
+func (s *reviewModerationRepo) ListActions(_ context.Context, _, _ int) ([]models.ModerationAction, int, error) {
+	return nil, 0, nil
+}
+
+func TestModerationService_RestoringHeldReviewPublishesIt(t *testing.T) {
+	ctx := context.Background()
+	posted := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	reviews := &stubReviewRepo{}
+	heldID, _ := reviews.HoldReview(ctx, &models.Review{FilmID: 4, UserID: 2, Rating: 8, Comment: "held", CreatedAt: posted}, "")
+	editedID, _ := reviews.HoldReview(ctx, &models.Review{FilmID: 4, UserID: 3, Rating: 6, Comment: "edited", CreatedAt: posted}, "")
+	repo := &reviewModerationRepo{reviews: reviews, reports: []models.Report{
+		{ID: 1, TargetType: models.TargetReview, TargetID: heldID, Reason: models.ReasonAutoFilter, HeldOnCreate: true, CreatedAt: posted.Add(time.Hour)},
+		{ID: 2, TargetType: models.TargetReview, TargetID: editedID, Reason: models.ReasonAutoFilter, CreatedAt: posted},
+	}}
+	events := &stubPublisher{}
+	invalidated := &invalidatedFilms{}
+	svc := NewModerationService(repo).WithTransactions(&stubTx{}).WithReviews(reviews).WithEvents(events).WithFilmCache(invalidated)
+
+	for _, report := range []int{1, 2} {
+		if _, err := svc.Resolve(ctx, report, 5, models.ModerationRestore, ""); err != nil {
+			t.Fatalf("restore report %d: %v", report, err)
+		}
+	}
+	if len(events.events) != 1 || events.events[0].eventType != models.EventReviewCreated || events.events[0].aggregateID != heldID {
+		t.Fatalf("expected review.created only for the review held when posted, got %+v", events.events)
+	}
+	if p := events.events[0].payload.(models.ReviewEvent); p.FilmID != 4 || p.UserID != 2 || p.Comment != "held" {
+		t.Fatalf("unexpected review.created payload %+v", p)
+	}
+	if len(*invalidated) != 2 || (*invalidated)[0] != 4 {
+		t.Fatalf("expected the film to be invalidated on each restore, got %v", *invalidated)
+	}
+}
+
+func TestModerationService_RestoreClosesOpenReports(t *testing.T) {
+	ctx := context.Background()
+	reviews := &stubReviewRepo{}
+	id, _ := reviews.HoldReview(ctx, &models.Review{FilmID: 4, UserID: 2, Rating: 8, Comment: "held"}, "")
+	repo := &reviewModerationRepo{reviews: reviews, reports: []models.Report{
+		{ID: 1, TargetType: models.TargetReview, TargetID: id, Reason: models.ReasonAutoFilter, Status: models.ReportOpen},
+	}}
+	svc := NewModerationService(repo)
+
+	if _, err := svc.Resolve(ctx, 1, 5, models.ModerationRestore, "fine"); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if status := repo.reports[0].Status; status != models.ReportDismissed {
+		t.Fatalf("expected the approved review's report to leave the queue, got %q", status)
+	}
+	if _, err := svc.Resolve(ctx, 1, 5, models.ModerationDismiss, ""); !errors.Is(err, ErrReportClosed) {
+		t.Fatalf("expected the report to be closed, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+)
+
+// ErrNotificationNotFound is returned for an unknown notification, or one
+// of another user.
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// NotificationRepo describes repository dependencies for notifications.
+type NotificationRepo interface {
+	// CreateNotification stores n unless its user has switched its type
+	// off.
+	CreateNotification(ctx context.Context, n *models.Notification) error
+	// NotifyWatchers stores a copy of n for every user with n.FilmID on
+	// their watchlist who has not switched its type off.
+	NotifyWatchers(ctx context.Context, n *models.Notification) error
+	// ListNotifications returns a page of the user's notifications, newest
+	// first, with the number matching and the number unread.
+	ListNotifications(ctx context.Context, userID int, unreadOnly bool, limit, offset int) ([]models.Notification, int, int, error)
+	// MarkRead returns pgx.ErrNoRows when the user has no such
+	// notification.
+	MarkRead(ctx context.Context, userID int, id int64) error
+	MarkAllRead(ctx context.Context, userID int) error
+	DisabledTypes(ctx context.Context, userID int) ([]string, error)
+	SetPreference(ctx context.Context, userID int, notificationType string, enabled bool) error
+}
+
+// Notifier creates in-app notifications; see NotificationService. Services
+// take one through their WithNotifications setter and notify nobody without
+// it.
+type Notifier interface {
+	Notify(ctx context.Context, n *models.Notification) error
+	NotifyWatchers(ctx context.Context, n *models.Notification) error
+}
+
+// notify creates n with nt, if any.
+func notify(ctx context.Context, nt Notifier, n *models.Notification) error {
+	if nt == nil {
+		return nil
+	}
+	if err := nt.Notify(ctx, n); err != nil {
+		return fmt.Errorf("notify %s: %w", n.Type, err)
+	}
+	return nil
+}
+
+// NotificationService stores in-app notifications and the types each user
+// receives.
+type NotificationService struct {
+	repo NotificationRepo
+}
+
+func NewNotificationService(repo NotificationRepo) *NotificationService {
+	return &NotificationService{repo: repo}
+}
+
+// Notify notifies n.UserID unless they caused it themselves or have
+// switched its type off.
+func (s *NotificationService) Notify(ctx context.Context, n *models.Notification) error {
+	if n.ActorID != nil && *n.ActorID == n.UserID {
+		return nil
+	}
+	return s.repo.CreateNotification(ctx, n)
+}
+
+// NotifyWatchers notifies everyone watching n.FilmID; n.UserID is ignored.
+func (s *NotificationService) NotifyWatchers(ctx context.Context, n *models.Notification) error {
+	return s.repo.NotifyWatchers(ctx, n)
+}
+
+// List returns page (1-based) of the user's notifications, only unread ones
+// when unreadOnly is set, along with the unread count.
+func (s *NotificationService) List(ctx context.Context, userID int, unreadOnly bool, page, limit int) (*models.NotificationPage, error) {
+	page, limit = pageBounds(page, limit)
+	items, total, unread, err := s.repo.ListNotifications(ctx, userID, unreadOnly, limit, (page-1)*limit)
+	if err != nil {
+		return nil, fmt.Errorf("list notifications: %w", err)
+	}
+	if items == nil {
+		items = []models.Notification{}
+	}
+	return &models.NotificationPage{Items: items, Total: total, Unread: unread, Page: page, Limit: limit}, nil
+}
+
+// MarkRead marks one of the user's notifications read. Marking it again
+// keeps the first read time.
+func (s *NotificationService) MarkRead(ctx context.Context, userID int, id int64) error {
+	if err := s.repo.MarkRead(ctx, userID, id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotificationNotFound
+		}
+		return fmt.Errorf("mark notification read: %w", err)
+	}
+	return nil
+}
+
+// MarkAllRead marks every notification of the user read.
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID int) error {
+	if err := s.repo.MarkAllRead(ctx, userID); err != nil {
+		return fmt.Errorf("mark notifications read: %w", err)
+	}
+	return nil
+}
+
+// Preferences returns the notification types the user receives.
+func (s *NotificationService) Preferences(ctx context.Context, userID int) (*models.NotificationPreferences, error) {
+	disabled, err := s.repo.DisabledTypes(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get notification preferences: %w", err)
+	}
+	prefs := &models.NotificationPreferences{Reply: true, Like: true, ReleaseDate: true}
+	for _, t := range disabled {
+		switch t {
+		case models.NotificationReply:
+			prefs.Reply = false
+		case models.NotificationLike:
+			prefs.Like = false
+		case models.NotificationReleaseDate:
+			prefs.ReleaseDate = false
+		}
+	}
+	return prefs, nil
+}
+
+// SetPreferences switches the types given in req on or off and returns
+// the resulting preferences.
+func (s *NotificationService) SetPreferences(ctx context.Context, userID int, req *models.NotificationPreferencesRequest) (*models.NotificationPreferences, error) {
+	for t, enabled := range map[string]*bool{
+		models.NotificationReply:       req.Reply,
+		models.NotificationLike:        req.Like,
+		models.NotificationReleaseDate: req.ReleaseDate,
+	} {
+		if enabled == nil {
+			continue
+		}
+		if err := s.repo.SetPreference(ctx, userID, t, *enabled); err != nil {
+			return nil, fmt.Errorf("set notification preference: %w", err)
+		}
+	}
+	return s.Preferences(ctx, userID)
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+)
+
+// stubNotificationRepo keeps notifications in memory; watchers maps a film
+// to the users watching it.
+type stubNotificationRepo struct {
+	items    []models.Notification
+	watchers map[int][]int
+	disabled map[[2]any]bool // {userID, type}
+}
+
+func (s *stubNotificationRepo) CreateNotification(_ context.Context, n *models.Notification) error {
+	if s.disabled[[2]any{n.UserID, n.Type}] {
+		return nil
+	}
+	n.ID = int64(len(s.items) + 1)
+	s.items = append(s.items, *n)
+	return nil
+}
+
+func (s *stubNotificationRepo) NotifyWatchers(ctx context.Context, n *models.Notification) error {
+	for _, userID := range s.watchers[*n.FilmID] {
+		copied := *n
+		copied.UserID = userID
+		if err := s.CreateNotification(ctx, &copied); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *stubNotificationRepo) ListNotifications(_ context.Context, userID int, unreadOnly bool, limit, offset int) ([]models.Notification, int, int, error) {
+	var out []models.Notification
+	unread := 0
+	for i := len(s.items) - 1; i >= 0; i-- {
+		n := s.items[i]
+		if n.UserID != userID {
+			continue
+		}
+		if n.ReadAt == nil {
+			unread++
+		}
+		if !unreadOnly || n.ReadAt == nil {
+			out = append(out, n)
+		}
+	}
+	total := len(out)
+	if offset >= total {
+		return nil, total, unread, nil
+	}
+	return out[offset:min(offset+limit, total)], total, unread, nil
+}
+
+func (s *stubNotificationRepo) MarkRead(_ context.Context, userID int, id int64) error {
+	for i := range s.items {
+		if s.items[i].ID == id && s.items[i].UserID == userID {
+			if s.items[i].ReadAt == nil {
+				now := time.Now()
+				s.items[i].ReadAt = &now
+			}
+			return nil
+		}
+	}
+	return pgx.ErrNoRows
+}
+
+func (s *stubNotificationRepo) MarkAllRead(ctx context.Context, userID int) error {
+	for _, n := range s.items {
+		if n.UserID == userID {
+			_ = s.MarkRead(ctx, userID, n.ID)
+		}
+	}
+	return nil
+}
+
+func (s *stubNotificationRepo) DisabledTypes(_ context.Context, userID int) ([]string, error) {
+	var out []string
+	for key, off := range s.disabled {
+		if off && key[0] == userID {
+			out = append(out, key[1].(string))
+		}
+	}
+	return out, nil
+}
+
+func (s *stubNotificationRepo) SetPreference(_ context.Context, userID int, notificationType string, enabled bool) error {
+	if s.disabled == nil {
+		s.disabled = map[[2]any]bool{}
+	}
+	s.disabled[[2]any{userID, notificationType}] = !enabled
+	return nil
+}
+
+func TestNotifications_RepliesAndLikes(t *testing.T) {
+	ctx := context.Background()
+	repo := &stubNotificationRepo{}
+	notes := NewNotificationService(repo)
+	reviews := &stubReviewRepo{reviews: []models.Review{{ID: 1, FilmID: 3, UserID: 1}}}
+	comments := NewCommentService(&stubCommentRepo{}, reviews).WithNotifications(notes)
+	votes := NewReviewService(reviews).WithNotifications(notes)
+
+	// User 2 comments on the review of user 1, who replies.
+	top, err := comments.CreateComment(ctx, &models.Comment{ReviewID: 1, UserID: 2, Body: "top"})
+	if err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+	if _, err := comments.CreateComment(ctx, &models.Comment{ReviewID: 1, ParentID: &top, UserID: 1, Body: "reply"}); err != nil {
+		t.Fatalf("reply: %v", err)
+	}
+	// Commenting on your own review notifies nobody.
+	if _, err := comments.CreateComment(ctx, &models.Comment{ReviewID: 1, UserID: 1, Body: "own"}); err != nil {
+		t.Fatalf("own comment: %v", err)
+	}
+	if len(repo.items) != 2 || repo.items[0].UserID != 1 || *repo.items[0].CommentID != top || repo.items[1].UserID != 2 {
+		t.Fatalf("expected replies to notify the review and comment authors, got %+v", repo.items)
+	}
+
+	// Only helpful votes notify, and only while the author receives likes.
+	if _, err := votes.Vote(ctx, 1, 2, false); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+	if _, err := votes.Vote(ctx, 1, 2, true); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+	if _, err := notes.SetPreferences(ctx, 1, &models.NotificationPreferencesRequest{Like: new(bool)}); err != nil {
+		t.Fatalf("set preferences: %v", err)
+	}
+	if _, err := votes.Vote(ctx, 1, 3, true); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+	page, err := notes.List(ctx, 1, false, 1, 10)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if page.Total != 2 || page.Unread != 2 || page.Items[0].Type != models.NotificationLike || *page.Items[0].ActorID != 2 {
+		t.Fatalf("expected the reply and one like, got %+v", page)
+	}
+	prefs, err := notes.Preferences(ctx, 1)
+	if err != nil || prefs.Like || !prefs.Reply || !prefs.ReleaseDate {
+		t.Fatalf("expected only likes off, got %+v, %v", prefs, err)
+	}
+
+	if err := notes.MarkRead(ctx, 2, page.Items[0].ID); !errors.Is(err, ErrNotificationNotFound) {
+		t.Fatalf("expected another user's notification to be not found, got %v", err)
+	}
+	if err := notes.MarkRead(ctx, 1, page.Items[0].ID); err != nil {
+		t.Fatalf("mark read: %v", err)
+	}
+	if page, _ = notes.List(ctx, 1, true, 1, 10); page.Total != 1 || page.Unread != 1 {
+		t.Fatalf("expected one unread notification, got %+v", page)
+	}
+	if err := notes.MarkAllRead(ctx, 1); err != nil {
+		t.Fatalf("mark all read: %v", err)
+	}
+	if page, _ = notes.List(ctx, 1, true, 1, 10); page.Total != 0 || page.Unread != 0 || page.Items == nil {
+		t.Fatalf("expected no unread notifications, got %+v", page)
+	}
+}
+
+func TestNotifications_ReleaseDate(t *testing.T) {
+	ctx := context.Background()
+	repo := &stubNotificationRepo{watchers: map[int][]int{1: {4, 5}}}
+	svc := NewFilmService(newStubFilmRepo()).WithNotifications(NewNotificationService(repo))
+
+	id, err := svc.CreateFilm(ctx, &models.FilmRequest{Title: "Dune: Part Three", Description: "Sci-fi"}, 1)
+	if err != nil {
+		t.Fatalf("create film: %v", err)
+	}
+	if _, err := svc.UpdateFilm(ctx, id, &models.FilmRequest{Title: "Dune: Messiah", Description: "Sci-fi"}, 1, 1); err != nil {
+		t.Fatalf("update film: %v", err)
+	}
+	if len(repo.items) != 0 {
+		t.Fatalf("expected no notifications without a release date, got %+v", repo.items)
+	}
+	release := time.Date(2026, 12, 18, 0, 0, 0, 0, time.UTC)
+	if _, err := svc.UpdateFilm(ctx, id, &models.FilmRequest{Title: "Dune: Messiah", Description: "Sci-fi", ReleaseDate: release}, 1, 2); err != nil {
+		t.Fatalf("update film: %v", err)
+	}
+	if len(repo.items) != 2 || repo.items[0].UserID != 4 || repo.items[1].Type != models.NotificationReleaseDate {
+		t.Fatalf("expected both watchers notified, got %+v", repo.items)
+	}
+	// Other edits keep the date and notify nobody.
+	if _, err := svc.UpdateFilm(ctx, id, &models.FilmRequest{Title: "Dune: Messiah", Description: "Epic", ReleaseDate: release}, 1, 3); err != nil {
+		t.Fatalf("update film: %v", err)
+	}
+	if len(repo.items) != 2 {
+		t.Fatalf("expected no new notifications, got %+v", repo.items)
+	}
+}
+
+// txNotifier records notifications, and fails them outside a unit of work.
+type txNotifier struct {
+	items []models.Notification
+}
+
+func (n *txNotifier) Notify(ctx context.Context, note *models.Notification) error {
+	if ctx.Value(txKey{}) == nil {
+		return errors.New("notified outside a transaction")
+	}
+	n.items = append(n.items, *note)
+	return nil
+}
+
+func (n *txNotifier) NotifyWatchers(ctx context.Context, note *models.Notification) error {
+	return n.Notify(ctx, note)
+}
+
+func TestNotifications_WrittenWithTheChange(t *testing.T) {
+	ctx := context.Background()
+	notes := &txNotifier{}
+	reviews := &stubReviewRepo{reviews: []models.Review{{ID: 1, FilmID: 3, UserID: 1}}}
+	comments := NewCommentService(&stubCommentRepo{}, reviews).WithTransactions(&stubTx{}).WithNotifications(notes)
+	votes := NewReviewService(reviews).WithTransactions(&stubTx{}).WithNotifications(notes)
+
+	if _, err := comments.CreateComment(ctx, &models.Comment{ReviewID: 1, UserID: 2, Body: "top"}); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+	if _, err := votes.Vote(ctx, 1, 2, true); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+	if len(notes.items) != 2 || notes.items[0].Type != models.NotificationReply || notes.items[1].Type != models.NotificationLike {
+		t.Fatalf("expected the reply and like notified in their unit of work, got %+v", notes.items)
+	}
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/bcrypt"
+
+	"filmhub/internal/models"
+	"filmhub/internal/repository"
+	"filmhub/pkg/login"
+	"filmhub/pkg/oidc"
+	"filmhub/pkg/signedtoken"
+)
+
+// PurposeOIDCLogin signs the login state kept in a cookie between the
+// redirect to the provider and the callback.
+const PurposeOIDCLogin = "oidc_login"
+
+const oidcLoginTTL = 10 * time.Minute
+
+var (
+	// ErrInvalidState is returned when the callback does not match the login
+	// started by this browser or the login has expired.
+	ErrInvalidState = errors.New("invalid or expired login state")
+	// ErrIdentityLinked is returned when the external identity already
+	// belongs to another user.
+	ErrIdentityLinked = errors.New("identity is linked to another account")
+	// ErrProviderLinked is returned when linking an identity to a user who
+	// has another one at the same provider; that one must be unlinked first.
+	ErrProviderLinked = errors.New("another identity of this provider is already linked")
+	// ErrAccountExists is returned when a local account uses the provider's
+	// email but either side has not verified it, so linking would be unsafe:
+	// whoever registered the address first could take over the other account.
+	ErrAccountExists = errors.New("an account with this email already exists; sign in and link the identity")
+	// ErrLoginRejected wraps code exchange and ID token verification failures.
+	ErrLoginRejected = errors.New("identity provider login rejected")
+)
+
+// SocialAuthService signs users in with an external OpenID provider and
+// links external identities to local accounts.
+//
+// The state, PKCE verifier and OIDC nonce are all derived from one signed
+// token, so nothing has to be stored server-side between the redirect and the
+// callback: the token travels in a cookie, the provider echoes the state.
+type SocialAuthService struct {
+	name       string
+	provider   *oidc.Provider
+	users      repository.UserRepository
+	identities repository.IdentityRepository
+	signer     *signedtoken.Signer
+	tx         Transactor
+	events     EventPublisher
+}
+
+func NewSocialAuthService(
+	name string,
+	provider *oidc.Provider,
+	users repository.UserRepository,
+	identities repository.IdentityRepository,
+	signer *signedtoken.Signer,
+) *SocialAuthService {
+	return &SocialAuthService{name: name, provider: provider, users: users, identities: identities, signer: signer}
+}
+
+// WithTransactions stores accounts created at sign-in with their identity
+// and user.registered event in one unit of work with t.
+func (s *SocialAuthService) WithTransactions(t Transactor) *SocialAuthService {
+	s.tx = t
+	return s
+}
+
+// WithEvents publishes user.registered for accounts created at sign-in
+// with p.
+func (s *SocialAuthService) WithEvents(p EventPublisher) *SocialAuthService {
+	s.events = p
+	return s
+}
+
+// Provider returns the name identities of this provider are stored under.
+func (s *SocialAuthService) Provider() string {
+	return s.name
+}
+
+// Begin starts a login. linkUserID is the signed-in user asking to link the
+// identity, or 0 for a plain login. The returned state token must be handed
+// back to Callback unchanged.
+func (s *SocialAuthService) Begin(linkUserID int) (authURL, stateToken string, err error) {
+	stateToken, p, err := s.signer.Issue(PurposeOIDCLogin, linkUserID, oidcLoginTTL)
+	if err != nil {
+		return "", "", fmt.Errorf("issue state: %w", err)
+	}
+	verifier := s.signer.Derive("pkce", p.Nonce)
+	url := s.provider.AuthCodeURL(p.Nonce, s.signer.Derive("nonce", p.Nonce), oidc.Challenge(verifier))
+	return url, stateToken, nil
+}
+
+// Callback completes a login and returns a FilmHub JWT. The identity is
+// resolved in order: an existing link; the account that started a link
+// request; an account with the same email if both the provider and the
+// account have verified it; a new account.
+func (s *SocialAuthService) Callback(ctx context.Context, stateToken, state, code string) (string, error) {
+	p, err := s.signer.Verify(stateToken, PurposeOIDCLogin)
+	if err != nil || state != p.Nonce {
+		return "", ErrInvalidState
+	}
+	tok, err := s.provider.Exchange(ctx, code, s.signer.Derive("pkce", p.Nonce))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrLoginRejected, err)
+	}
+	claims, err := s.provider.VerifyIDToken(ctx, tok.IDToken, s.signer.Derive("nonce", p.Nonce))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrLoginRejected, err)
+	}
+
+	user, err := s.resolve(ctx, p.UserID, claims)
+	if err != nil {
+		return "", err
+	}
+	return login.GenerateToken(user.ID, string(user.Role))
+}
+
+func (s *SocialAuthService) resolve(ctx context.Context, linkUserID int, claims *oidc.IDClaims) (*models.User, error) {
+	userID, err := s.identities.FindUserID(ctx, s.name, claims.Subject)
+	switch {
+	case err == nil:
+		if linkUserID != 0 && linkUserID != userID {
+			return nil, ErrIdentityLinked
+		}
+		return s.users.FindByID(ctx, userID)
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("find identity: %w", err)
+	}
+
+	if linkUserID != 0 {
+		user, err := s.users.FindByID(ctx, linkUserID)
+		if err != nil {
+			return nil, fmt.Errorf("find user: %w", err)
+		}
+		return user, s.link(ctx, user, claims)
+	}
+
+	if claims.Email != "" {
+		user, err := s.users.FindByEmail(ctx, claims.Email)
+		switch {
+		case err == nil:
+			if !claims.EmailVerified || !user.EmailVerified {
+				return nil, ErrAccountExists
+			}
+			return user, s.link(ctx, user, claims)
+		case !errors.Is(err, pgx.ErrNoRows):
+			return nil, fmt.Errorf("find user: %w", err)
+		}
+	}
+
+	var user *models.User
+	err = inTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		if user, err = s.createUser(ctx, claims); err != nil {
+			return err
+		}
+		return s.link(ctx, user, claims)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *SocialAuthService) createUser(ctx context.Context, claims *oidc.IDClaims) (*models.User, error) {
+	if claims.Email == "" {
+		return nil, errors.New("provider did not return an email")
+	}
+	// The account has no usable password until the owner sets one through
+	// the password reset flow.
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(secret)), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	user := &models.User{
+		Username: usernameFromClaims(claims),
+		Email:    claims.Email,
+		Password: string(hash),
+		Role:     models.RoleUser,
+	}
+	if err := s.users.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("create user: %w", err)
+	}
+	if claims.EmailVerified {
+		if err := s.users.MarkEmailVerified(ctx, user.ID); err != nil {
+			return nil, fmt.Errorf("mark email verified: %w", err)
+		}
+		user.EmailVerified = true
+	}
+	return user, publish(ctx, s.events, models.EventUserRegistered, user.ID, models.UserEvent{ID: user.ID, Username: user.Username})
+}
+
+func (s *SocialAuthService) link(ctx context.Context, user *models.User, claims *oidc.IDClaims) error {
+	if err := s.identities.Link(ctx, &models.Identity{
+		Provider: s.name,
+		Subject:  claims.Subject,
+		UserID:   user.ID,
+		Email:    claims.Email,
+	}); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrProviderLinked):
+			return ErrProviderLinked
+		case errors.Is(err, repository.ErrSubjectLinked):
+			// Linked by a concurrent callback since it was looked up.
+			return ErrIdentityLinked
+		}
+		return fmt.Errorf("link identity: %w", err)
+	}
+	return nil
+}
+
+// Identities lists the external identities linked to a user.
+func (s *SocialAuthService) Identities(ctx context.Context, userID int) ([]models.Identity, error) {
+	return s.identities.ListByUser(ctx, userID)
+}
+
+// Unlink removes the user's identity at provider. It returns pgx.ErrNoRows
+// when none is linked.
+func (s *SocialAuthService) Unlink(ctx context.Context, userID int, provider string) error {
+	return s.identities.Unlink(ctx, userID, provider)
+}
+
+func usernameFromClaims(claims *oidc.IDClaims) string {
+	for _, name := range []string{claims.PreferredUsername, claims.Name, strings.SplitN(claims.Email, "@", 2)[0]} {
+		if name = strings.TrimSpace(name); name != "" {
+			if r := []rune(name); len(r) > 64 {
+				name = string(r[:64])
+			}
+			return name
+		}
+	}
+	return "user"
+}
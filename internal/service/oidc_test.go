@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+	"filmhub/internal/repository"
+	"filmhub/pkg/login"
+	"filmhub/pkg/oidc"
+	"filmhub/pkg/oidc/oidctest"
+	"filmhub/pkg/signedtoken"
+)
+
+// stubIdentityRepo keeps linked identities in memory.
+type stubIdentityRepo struct {
+	identities []models.Identity
+}
+
+func (s *stubIdentityRepo) FindUserID(_ context.Context, provider, subject string) (int, error) {
+	for _, i := range s.identities {
+		if i.Provider == provider && i.Subject == subject {
+			return i.UserID, nil
+		}
+	}
+	return 0, pgx.ErrNoRows
+}
+
+func (s *stubIdentityRepo) Link(_ context.Context, identity *models.Identity) error {
+	for _, i := range s.identities {
+		switch {
+		case i.Provider == identity.Provider && i.Subject == identity.Subject:
+			return repository.ErrSubjectLinked
+		case i.Provider == identity.Provider && i.UserID == identity.UserID:
+			return repository.ErrProviderLinked
+		}
+	}
+	identity.CreatedAt = time.Now()
+	s.identities = append(s.identities, *identity)
+	return nil
+}
+
+func (s *stubIdentityRepo) ListByUser(_ context.Context, userID int) ([]models.Identity, error) {
+	var out []models.Identity
+	for _, i := range s.identities {
+		if i.UserID == userID {
+			out = append(out, i)
+		}
+	}
+	return out, nil
+}
+
+func (s *stubIdentityRepo) Unlink(_ context.Context, userID int, provider string) error {
+	for n, i := range s.identities {
+		if i.UserID == userID && i.Provider == provider {
+			s.identities = append(s.identities[:n], s.identities[n+1:]...)
+			return nil
+		}
+	}
+	return pgx.ErrNoRows
+}
+
+type socialFixture struct {
+	svc        *SocialAuthService
+	idp        *oidctest.Provider
+	users      *stubUserRepo
+	identities *stubIdentityRepo
+}
+
+func newSocialFixture(t *testing.T) *socialFixture {
+	t.Helper()
+	initTestKeys()
+	idp := oidctest.NewProvider("filmhub", "client-secret")
+	t.Cleanup(idp.Close)
+
+	provider, err := oidc.Discover(context.Background(), idp.Config("http://localhost:8080/auth/oidc/callback"), idp.Client())
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	signer, _ := signedtoken.New("testsecret")
+	f := &socialFixture{idp: idp, users: newStubUserRepo(), identities: &stubIdentityRepo{}}
+	f.svc = NewSocialAuthService("mock", provider, f.users, f.identities, signer)
+	return f
+}
+
+// login runs the whole browser round trip and returns the user the issued
+// JWT belongs to.
+func (f *socialFixture) login(t *testing.T, linkUserID int) (int, error) {
+	t.Helper()
+	authURL, stateToken, err := f.svc.Begin(linkUserID)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	code, state, err := f.idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	token, err := f.svc.Callback(context.Background(), stateToken, state, code)
+	if err != nil {
+		return 0, err
+	}
+	claims, err := login.ParseToken(token)
+	if err != nil {
+		t.Fatalf("parse issued token: %v", err)
+	}
+	return claims.UserID, nil
+}
+
+func TestSocialAuth_CreatesUserAndReusesIdentity(t *testing.T) {
+	f := newSocialFixture(t)
+	f.idp.SetUser(oidctest.User{Subject: "s-1", Email: "new@example.com", EmailVerified: true, Name: "New User"})
+
+	first, err := f.login(t, 0)
+	if err != nil {
+		t.Fatalf("first login: %v", err)
+	}
+	user, err := f.users.FindByID(context.Background(), first)
+	if err != nil {
+		t.Fatalf("user not created: %v", err)
+	}
+	if user.Email != "new@example.com" || user.Username != "New User" || !user.EmailVerified || user.Role != models.RoleUser {
+		t.Fatalf("unexpected user %+v", user)
+	}
+
+	second, err := f.login(t, 0)
+	if err != nil {
+		t.Fatalf("second login: %v", err)
+	}
+	if second != first || len(f.users.users) != 1 || len(f.identities.identities) != 1 {
+		t.Fatalf("expected the existing identity to be reused")
+	}
+}
+
+func TestSocialAuth_LinksByVerifiedEmailOnly(t *testing.T) {
+	f := newSocialFixture(t)
+	existing := &models.User{Username: "john", Email: "john@example.com", Role: models.RoleUser, EmailVerified: true}
+	_ = f.users.Create(context.Background(), existing)
+
+	f.idp.SetUser(oidctest.User{Subject: "s-1", Email: "john@example.com", EmailVerified: false})
+	if _, err := f.login(t, 0); !errors.Is(err, ErrAccountExists) {
+		t.Fatalf("expected ErrAccountExists for unverified email, got %v", err)
+	}
+
+	f.idp.SetUser(oidctest.User{Subject: "s-1", Email: "john@example.com", EmailVerified: true})
+	id, err := f.login(t, 0)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if id != existing.ID {
+		t.Fatalf("expected link to user %d, got user %d", existing.ID, id)
+	}
+}
+
+func TestSocialAuth_DoesNotLinkUnverifiedAccount(t *testing.T) {
+	f := newSocialFixture(t)
+	// Someone registered the victim's address first and never verified it.
+	squatter := &models.User{Username: "mallory", Email: "victim@example.com", Role: models.RoleUser}
+	_ = f.users.Create(context.Background(), squatter)
+
+	f.idp.SetUser(oidctest.User{Subject: "s-1", Email: "victim@example.com", EmailVerified: true})
+	if _, err := f.login(t, 0); !errors.Is(err, ErrAccountExists) {
+		t.Fatalf("expected ErrAccountExists for an unverified local account, got %v", err)
+	}
+	if len(f.identities.identities) != 0 || squatter.EmailVerified {
+		t.Fatalf("identity must not be linked to the unverified account")
+	}
+}
+
+func TestSocialAuth_ExplicitLink(t *testing.T) {
+	f := newSocialFixture(t)
+	ctx := context.Background()
+	alice := &models.User{Username: "alice", Email: "alice@example.com", Role: models.RoleUser}
+	bob := &models.User{Username: "bob", Email: "bob@example.com", Role: models.RoleUser}
+	_ = f.users.Create(ctx, alice)
+	_ = f.users.Create(ctx, bob)
+
+	// The provider email differs from the local one; only the explicit link
+	// from a signed-in session can connect them.
+	f.idp.SetUser(oidctest.User{Subject: "s-1", Email: "alice@work.example", EmailVerified: true})
+	id, err := f.login(t, alice.ID)
+	if err != nil {
+		t.Fatalf("link: %v", err)
+	}
+	if id != alice.ID {
+		t.Fatalf("expected identity linked to alice, got user %d", id)
+	}
+	identities, _ := f.svc.Identities(ctx, alice.ID)
+	if len(identities) != 1 || identities[0].Provider != "mock" || identities[0].Subject != "s-1" {
+		t.Fatalf("unexpected identities %+v", identities)
+	}
+
+	if _, err := f.login(t, bob.ID); !errors.Is(err, ErrIdentityLinked) {
+		t.Fatalf("expected ErrIdentityLinked, got %v", err)
+	}
+
+	// A second account at the same provider is refused, not a server error.
+	f.idp.SetUser(oidctest.User{Subject: "s-2", Email: "alice@home.example", EmailVerified: true})
+	if _, err := f.login(t, alice.ID); !errors.Is(err, ErrProviderLinked) {
+		t.Fatalf("expected ErrProviderLinked, got %v", err)
+	}
+
+	if err := f.svc.Unlink(ctx, alice.ID, "mock"); err != nil {
+		t.Fatalf("unlink: %v", err)
+	}
+	if err := f.svc.Unlink(ctx, alice.ID, "mock"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("expected ErrNoRows on second unlink, got %v", err)
+	}
+}
+
+func TestSocialAuth_RejectsForeignState(t *testing.T) {
+	f := newSocialFixture(t)
+	authURL, _, _ := f.svc.Begin(0)
+	_, otherState, _ := f.svc.Begin(0)
+	code, state, err := f.idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	// A callback arriving with another browser's cookie must not complete.
+	if _, err := f.svc.Callback(context.Background(), otherState, state, code); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected ErrInvalidState, got %v", err)
+	}
+	if _, err := f.svc.Callback(context.Background(), "garbage", state, code); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected ErrInvalidState for garbage state, got %v", err)
+	}
+}
+
+func TestSocialAuth_PublishesUserRegistered(t *testing.T) {
+	f := newSocialFixture(t)
+	events := &stubPublisher{}
+	f.svc.WithTransactions(&stubTx{}).WithEvents(events)
+	f.idp.SetUser(oidctest.User{Subject: "s-1", Email: "new@example.com", EmailVerified: true, Name: "New User"})
+
+	id, err := f.login(t, 0)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if _, err := f.login(t, 0); err != nil {
+		t.Fatalf("second login: %v", err)
+	}
+	if len(events.events) != 1 || events.events[0].eventType != models.EventUserRegistered || events.events[0].aggregateID != id {
+		t.Fatalf("expected one user.registered for the new account, got %v", events.types())
+	}
+	if p := events.events[0].payload.(models.UserEvent); p.Username != "New User" {
+		t.Fatalf("unexpected user.registered payload %+v", p)
+	}
+}
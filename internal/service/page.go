@@ -0,0 +1,18 @@
+package service
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// pageBounds normalises 1-based paging parameters: pages below 1 become the
+// first page and a limit outside 1..100 falls back to the default of 20.
+func pageBounds(page, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > maxPageLimit {
+		limit = defaultPageLimit
+	}
+	return page, limit
+}
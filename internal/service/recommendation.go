@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"filmhub/internal/models"
+	"filmhub/pkg/recommend"
+)
+
+// likedRating separates liking from disliking: ratings above it count for
+// similar films, ratings below it against them.
+const likedRating = 6
+
+// maxReasons is the number of rated films a recommendation names.
+const maxReasons = 3
+
+// RecommendationRepo describes repository dependencies for
+// recommendations.
+type RecommendationRepo interface {
+	Ratings(ctx context.Context) ([]recommend.Rating, error)
+	ReplaceSimilarities(ctx context.Context, sims []recommend.Similarity) error
+	ComputedAt(ctx context.Context) (*time.Time, error)
+	UserRatings(ctx context.Context, userID int) ([]models.RatedFilm, error)
+	Neighbors(ctx context.Context, rated []int) ([]models.FilmNeighbor, error)
+	PopularFilms(ctx context.Context, genres []string, exclude []int, limit int) ([]models.PopularFilm, error)
+}
+
+// RecommendationService recommends films by item-item collaborative
+// filtering: Refresh computes how alike films are rated and stores the
+// result, and Recommend scores the films similar to those a user rated.
+// Users with too few ratings get popular films of the genres they like, or
+// popular films overall.
+type RecommendationService struct {
+	repo RecommendationRepo
+	opts recommend.Options
+}
+
+func NewRecommendationService(repo RecommendationRepo, opts recommend.Options) *RecommendationService {
+	return &RecommendationService{repo: repo, opts: opts}
+}
+
+// Refresh recomputes the film similarities from the current review
+// ratings.
+func (s *RecommendationService) Refresh(ctx context.Context) error {
+	ratings, err := s.repo.Ratings(ctx)
+	if err != nil {
+		return fmt.Errorf("load ratings: %w", err)
+	}
+	if err := s.repo.ReplaceSimilarities(ctx, recommend.ItemSimilarities(ratings, s.opts)); err != nil {
+		return fmt.Errorf("store film similarities: %w", err)
+	}
+	return nil
+}
+
+// Recommend returns up to limit films for the user, never one they have
+// reviewed. A limit outside 1..100 falls back to 20.
+func (s *RecommendationService) Recommend(ctx context.Context, userID, limit int) (*models.RecommendationList, error) {
+	_, limit = pageBounds(1, limit)
+	rated, err := s.repo.UserRatings(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user ratings: %w", err)
+	}
+	computedAt, err := s.repo.ComputedAt(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get film similarities: %w", err)
+	}
+
+	items := []models.Recommendation{}
+	exclude := make([]int, 0, len(rated)+limit)
+	for _, f := range rated {
+		exclude = append(exclude, f.FilmID)
+	}
+	if len(rated) > 0 {
+		neighbors, err := s.repo.Neighbors(ctx, exclude)
+		if err != nil {
+			return nil, fmt.Errorf("get similar films: %w", err)
+		}
+		items = similarFilms(rated, neighbors)
+		items = items[:min(len(items), limit)]
+	}
+	for _, item := range items {
+		exclude = append(exclude, item.FilmID)
+	}
+
+	// Cold start: popular films of the genres the user likes, then popular
+	// films overall.
+	if genres := likedGenres(rated); len(items) < limit && len(genres) > 0 {
+		popular, err := s.repo.PopularFilms(ctx, genres, exclude, limit-len(items))
+		if err != nil {
+			return nil, fmt.Errorf("get popular films: %w", err)
+		}
+		for _, p := range popular {
+			items = append(items, models.Recommendation{
+				FilmID: p.FilmID, Title: p.Title, Rating: p.Rating, Score: float64(p.Reviews), Reason: models.RecommendGenre,
+				Genre: p.Genre, Explanation: "Популярен в жанре «" + p.Genre + "», который вам нравится",
+			})
+			exclude = append(exclude, p.FilmID)
+		}
+	}
+	if len(items) < limit {
+		popular, err := s.repo.PopularFilms(ctx, nil, exclude, limit-len(items))
+		if err != nil {
+			return nil, fmt.Errorf("get popular films: %w", err)
+		}
+		for _, p := range popular {
+			items = append(items, models.Recommendation{
+				FilmID: p.FilmID, Title: p.Title, Rating: p.Rating, Score: float64(p.Reviews), Reason: models.RecommendPopular,
+				Explanation: "Популярен у зрителей",
+			})
+		}
+	}
+	return &models.RecommendationList{Items: items, ComputedAt: computedAt}, nil
+}
+
+// similarFilms scores each neighbour by the similarities to the rated
+// films weighted by how much the user liked them, and keeps those with a
+// positive score, best first.
+func similarFilms(rated []models.RatedFilm, neighbors []models.FilmNeighbor) []models.Recommendation {
+	byID := make(map[int]models.RatedFilm, len(rated))
+	for _, f := range rated {
+		byID[f.FilmID] = f
+	}
+	type contribution struct {
+		source models.RatedFilm
+		weight float64
+	}
+	scores := map[int]*models.Recommendation{}
+	reasons := map[int][]contribution{}
+	for _, n := range neighbors {
+		source, ok := byID[n.RatedID]
+		if !ok {
+			continue
+		}
+		weight := n.Score * float64(source.Rating-likedRating)
+		rec := scores[n.FilmID]
+		if rec == nil {
+			rec = &models.Recommendation{FilmID: n.FilmID, Title: n.Title, Rating: n.Rating, Reason: models.RecommendSimilar}
+			scores[n.FilmID] = rec
+		}
+		rec.Score += weight
+		if weight > 0 {
+			reasons[n.FilmID] = append(reasons[n.FilmID], contribution{source, weight})
+		}
+	}
+
+	var out []models.Recommendation
+	for id, rec := range scores {
+		if rec.Score <= 0 {
+			continue
+		}
+		cs := reasons[id]
+		sort.Slice(cs, func(i, j int) bool { return cs[i].weight > cs[j].weight })
+		titles := make([]string, 0, maxReasons)
+		for _, c := range cs[:min(len(cs), maxReasons)] {
+			rec.BasedOn = append(rec.BasedOn, models.RecommendationSource{FilmID: c.source.FilmID, Title: c.source.Title, Rating: c.source.Rating})
+			titles = append(titles, "«"+c.source.Title+"»")
+		}
+		rec.Explanation = "Похож на фильмы, которые вы оценили высоко: " + strings.Join(titles, ", ")
+		out = append(out, *rec)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].FilmID < out[j].FilmID
+	})
+	return out
+}
+
+// likedGenres returns the genres of the films the user liked, most liked
+// first.
+func likedGenres(rated []models.RatedFilm) []string {
+	counts := map[string]int{}
+	for _, f := range rated {
+		if f.Rating <= likedRating {
+			continue
+		}
+		for _, g := range f.Genres {
+			counts[g]++
+		}
+	}
+	genres := make([]string, 0, len(counts))
+	for g := range counts {
+		genres = append(genres, g)
+	}
+	sort.Slice(genres, func(i, j int) bool {
+		if counts[genres[i]] != counts[genres[j]] {
+			return counts[genres[i]] > counts[genres[j]]
+		}
+		return genres[i] < genres[j]
+	})
+	return genres
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"filmhub/internal/models"
+	"filmhub/pkg/recommend"
+)
+
+// stubRecommendationRepo serves films and ratings from memory and keeps the
+// similarities Refresh stores.
+type stubRecommendationRepo struct {
+	films   map[int]models.PopularFilm // Genre holds the film's only genre
+	ratings []recommend.Rating
+	sims    []recommend.Similarity
+	at      *time.Time
+}
+
+func (s *stubRecommendationRepo) Ratings(_ context.Context) ([]recommend.Rating, error) {
+	return s.ratings, nil
+}
+
+func (s *stubRecommendationRepo) ReplaceSimilarities(_ context.Context, sims []recommend.Similarity) error {
+	now := time.Now()
+	s.sims, s.at = sims, &now
+	return nil
+}
+
+func (s *stubRecommendationRepo) ComputedAt(_ context.Context) (*time.Time, error) {
+	return s.at, nil
+}
+
+func (s *stubRecommendationRepo) UserRatings(_ context.Context, userID int) ([]models.RatedFilm, error) {
+	var out []models.RatedFilm
+	for _, r := range s.ratings {
+		if r.UserID == userID {
+			f := s.films[r.ItemID]
+			out = append(out, models.RatedFilm{FilmID: f.FilmID, Title: f.Title, Rating: int(r.Value), Genres: []string{f.Genre}})
+		}
+	}
+	return out, nil
+}
+
+func (s *stubRecommendationRepo) Neighbors(_ context.Context, rated []int) ([]models.FilmNeighbor, error) {
+	var out []models.FilmNeighbor
+	for _, sim := range s.sims {
+		if slices.Contains(rated, sim.ItemID) && !slices.Contains(rated, sim.OtherID) {
+			f := s.films[sim.OtherID]
+			out = append(out, models.FilmNeighbor{RatedID: sim.ItemID, FilmID: f.FilmID, Title: f.Title, Rating: f.Rating, Score: sim.Score})
+		}
+	}
+	return out, nil
+}
+
+func (s *stubRecommendationRepo) PopularFilms(_ context.Context, genres []string, exclude []int, limit int) ([]models.PopularFilm, error) {
+	var out []models.PopularFilm
+	for _, f := range s.films {
+		if slices.Contains(exclude, f.FilmID) || (len(genres) > 0 && !slices.Contains(genres, f.Genre)) {
+			continue
+		}
+		if len(genres) == 0 {
+			f.Genre = ""
+		}
+		out = append(out, f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Reviews > out[j].Reviews })
+	return out[:min(len(out), limit)], nil
+}
+
+func newRecommendationFixture() *stubRecommendationRepo {
+	return &stubRecommendationRepo{
+		films: map[int]models.PopularFilm{
+			1: {FilmID: 1, Title: "Heat", Reviews: 4, Genre: "crime"},
+			2: {FilmID: 2, Title: "Ronin", Reviews: 3, Genre: "crime"},
+			3: {FilmID: 3, Title: "Amélie", Reviews: 3, Genre: "comedy"},
+			4: {FilmID: 4, Title: "Collateral", Reviews: 1, Genre: "crime"},
+			5: {FilmID: 5, Title: "Titanic", Reviews: 9, Genre: "drama"},
+		},
+		// Users 1 and 2 like Heat and Ronin and not Amélie; user 3 has only
+		// rated Heat.
+		ratings: []recommend.Rating{
+			{UserID: 1, ItemID: 1, Value: 9}, {UserID: 1, ItemID: 2, Value: 9}, {UserID: 1, ItemID: 3, Value: 2},
+			{UserID: 2, ItemID: 1, Value: 8}, {UserID: 2, ItemID: 2, Value: 9}, {UserID: 2, ItemID: 3, Value: 3},
+			{UserID: 3, ItemID: 1, Value: 10},
+		},
+	}
+}
+
+func TestRecommendationService_SimilarThenGenreThenPopular(t *testing.T) {
+	ctx := context.Background()
+	repo := newRecommendationFixture()
+	svc := NewRecommendationService(repo, recommend.Options{})
+	if err := svc.Refresh(ctx); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	list, err := svc.Recommend(ctx, 3, 3)
+	if err != nil {
+		t.Fatalf("recommend: %v", err)
+	}
+	if list.ComputedAt == nil || len(list.Items) != 3 {
+		t.Fatalf("expected three recommendations after a refresh, got %+v", list)
+	}
+	similar, genre, popular := list.Items[0], list.Items[1], list.Items[2]
+	if similar.FilmID != 2 || similar.Reason != models.RecommendSimilar || len(similar.BasedOn) != 1 ||
+		similar.BasedOn[0].FilmID != 1 || similar.BasedOn[0].Rating != 10 || similar.Explanation == "" {
+		t.Fatalf("expected Ronin because of Heat first, got %+v", similar)
+	}
+	if genre.FilmID != 4 || genre.Reason != models.RecommendGenre || genre.Genre != "crime" {
+		t.Fatalf("expected another crime film next, got %+v", genre)
+	}
+	if popular.FilmID != 5 || popular.Reason != models.RecommendPopular {
+		t.Fatalf("expected the most reviewed film last, got %+v", popular)
+	}
+	for _, item := range list.Items {
+		if item.FilmID == 1 {
+			t.Fatal("expected reviewed films to be left out")
+		}
+	}
+}
+
+func TestRecommendationService_ColdStart(t *testing.T) {
+	ctx := context.Background()
+	svc := NewRecommendationService(newRecommendationFixture(), recommend.Options{})
+
+	// Before the first refresh and without ratings, popular films are all
+	// there is.
+	list, err := svc.Recommend(ctx, 9, 2)
+	if err != nil {
+		t.Fatalf("recommend: %v", err)
+	}
+	if list.ComputedAt != nil || len(list.Items) != 2 || list.Items[0].FilmID != 5 || list.Items[0].Reason != models.RecommendPopular {
+		t.Fatalf("expected popular films, got %+v", list)
+	}
+}
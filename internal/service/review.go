@@ -2,37 +2,408 @@ package service
 
 import (
     "context"
+    "errors"
     "fmt"
+    "strings"
+
+    "github.com/jackc/pgx/v5"
 
     "filmhub/internal/models"
+    "filmhub/pkg/contentfilter"
+)
+
+var (
+    // ErrReviewNotFound is returned when the review can't be located in storage.
+    ErrReviewNotFound = errors.New("review not found")
+    // ErrInvalidSort is returned for an unknown listing order.
+    ErrInvalidSort = errors.New("invalid sort order")
+    // ErrOwnReview is returned when users vote on their own review.
+    ErrOwnReview = errors.New("cannot vote on your own review")
 )
 
+// ContentRejectedError is returned when the content filter refuses a review.
+type ContentRejectedError struct {
+    Reasons []string
+}
+
+func (e *ContentRejectedError) Error() string {
+    return "review rejected by content filter: " + strings.Join(e.Reasons, ", ")
+}
+
 // ReviewRepo describes repository dependencies for reviews.
 type ReviewRepo interface {
     CreateReview(ctx context.Context, review *models.Review) (int, error)
-    ListReviewsByFilm(ctx context.Context, filmID int) ([]models.Review, error)
+    HoldReview(ctx context.Context, review *models.Review, details string) (int, error)
+    GetReviewByID(ctx context.Context, id int) (*models.Review, error)
+    ListReviewsByFilm(ctx context.Context, filmID int, sort string, viewerID int) ([]models.Review, error)
+    Vote(ctx context.Context, reviewID, userID, value int) (*models.ReviewVotes, error)
+    UpdateReview(ctx context.Context, review *models.Review, version int, holdDetails string) error
+    DeleteReview(ctx context.Context, id, deletedBy int) error
+    RefreshFilmRating(ctx context.Context, filmID int) (float32, error)
+}
+
+// UserLookup is the subset of the user repository ReviewService needs.
+type UserLookup interface {
+    FindByID(ctx context.Context, id int) (*models.User, error)
+}
+
+// ContentFilter scores review text before it is stored; see
+// contentfilter.Pipeline.
+type ContentFilter interface {
+    Evaluate(text string) contentfilter.Verdict
 }
 
 type ReviewService struct {
-    repo ReviewRepo
+    repo     ReviewRepo
+    users    UserLookup    // set when reviews require a verified email
+    filter   ContentFilter // set when review text is checked automatically
+    audit    Auditor
+    films    FilmInvalidator // set when film reads are cached
+    tx       Transactor
+    events   EventPublisher
+    live     Broadcaster
+    notifier Notifier
 }
 
 func NewReviewService(r ReviewRepo) *ReviewService {
     return &ReviewService{repo: r}
 }
 
+// RequireVerifiedEmail makes CreateReview reject authors whose email is not
+// verified yet.
+func (s *ReviewService) RequireVerifiedEmail(users UserLookup) *ReviewService {
+    s.users = users
+    return s
+}
+
+// WithContentFilter checks the text of new reviews with f. Held reviews are
+// stored hidden and queued for moderation; rejected ones are refused with a
+// *ContentRejectedError.
+func (s *ReviewService) WithContentFilter(f ContentFilter) *ReviewService {
+    s.filter = f
+    return s
+}
+
+// WithAudit records new reviews with a.
+func (s *ReviewService) WithAudit(a Auditor) *ReviewService {
+    s.audit = a
+    return s
+}
+
+// WithTransactions makes review changes, the film rating they affect and
+// the notifications they cause one unit of work with t.
+func (s *ReviewService) WithTransactions(t Transactor) *ReviewService {
+    s.tx = t
+    return s
+}
+
+// WithFilmCache invalidates the reviewed film in c whenever its reviews
+// change.
+func (s *ReviewService) WithFilmCache(c FilmInvalidator) *ReviewService {
+    s.films = c
+    return s
+}
+
+// WithEvents publishes review.created events with p. Held reviews are not
+// published.
+func (s *ReviewService) WithEvents(p EventPublisher) *ReviewService {
+    s.events = p
+    return s
+}
+
+// WithLiveUpdates broadcasts review and rating changes with b, on the
+// film's topic; held reviews go to ModerationTopic.
+func (s *ReviewService) WithLiveUpdates(b Broadcaster) *ReviewService {
+    s.live = b
+    return s
+}
+
+// WithNotifications notifies authors with n when their review is marked
+// helpful.
+func (s *ReviewService) WithNotifications(n Notifier) *ReviewService {
+    s.notifier = n
+    return s
+}
+
+// invalidateFilm drops the cached film after a change to its reviews.
+func (s *ReviewService) invalidateFilm(filmID int) {
+    if s.films != nil {
+        s.films.InvalidateFilm(filmID)
+    }
+}
+
+// CreateReview stores a review. When the content filter holds it, the review
+// is stored hidden and review.HiddenAt and review.HeldReasons are set.
 func (s *ReviewService) CreateReview(ctx context.Context, review *models.Review) (int, error) {
-    id, err := s.repo.CreateReview(ctx, review)
+    if s.users != nil {
+        author, err := s.users.FindByID(ctx, review.UserID)
+        if err != nil {
+            return 0, fmt.Errorf("find author: %w", err)
+        }
+        if !author.EmailVerified {
+            return 0, ErrEmailNotVerified
+        }
+    }
+    reasons, details, err := s.screen(review.Comment)
     if err != nil {
-        return 0, fmt.Errorf("create review: %w", err)
+        return 0, err
+    }
+    var id int
+    var rating float32
+    err = inTx(ctx, s.tx, func(ctx context.Context) error {
+        if details != "" {
+            // Held reviews are hidden and don't count towards the rating.
+            review.HeldReasons = reasons
+            id, err = s.repo.HoldReview(ctx, review, details)
+            if err != nil {
+                return fmt.Errorf("hold review: %w", err)
+            }
+            return record(ctx, s.audit, models.AuditHold, models.EntityReview, id, nil, newReviewState(review))
+        }
+        id, err = s.repo.CreateReview(ctx, review)
+        if err != nil {
+            return fmt.Errorf("create review: %w", err)
+        }
+        if rating, err = s.refreshRating(ctx, review.FilmID); err != nil {
+            return err
+        }
+        if err := record(ctx, s.audit, models.AuditCreate, models.EntityReview, id, nil, newReviewState(review)); err != nil {
+            return err
+        }
+        return publish(ctx, s.events, models.EventReviewCreated, id, models.ReviewEvent{
+            ID: id, FilmID: review.FilmID, UserID: review.UserID, Rating: review.Rating, Comment: review.Comment,
+        })
+    })
+    if err != nil {
+        return 0, err
+    }
+    s.invalidateFilm(review.FilmID)
+    created := *review
+    created.ID = id
+    if details != "" {
+        broadcast(s.live, ModerationTopic, models.LiveReviewHeld, &created)
+    } else {
+        broadcast(s.live, FilmTopic(review.FilmID), models.LiveReviewCreated, &created)
+        s.broadcastRating(review.FilmID, rating)
     }
     return id, nil
 }
 
-func (s *ReviewService) ListReviews(ctx context.Context, filmID int) ([]models.Review, error) {
-    reviews, err := s.repo.ListReviewsByFilm(ctx, filmID)
+func (s *ReviewService) refreshRating(ctx context.Context, filmID int) (float32, error) {
+    rating, err := s.repo.RefreshFilmRating(ctx, filmID)
+    if err != nil {
+        return 0, fmt.Errorf("refresh film rating: %w", err)
+    }
+    return rating, nil
+}
+
+func (s *ReviewService) broadcastRating(filmID int, rating float32) {
+    broadcast(s.live, FilmTopic(filmID), models.LiveFilmRating, models.FilmRating{FilmID: filmID, Rating: rating})
+}
+
+// screen runs text through the content filter. A rejection is returned as
+// *ContentRejectedError; for held text the reasons and the details of the
+// moderation report are returned, details is empty otherwise.
+func (s *ReviewService) screen(text string) ([]string, string, error) {
+    if s.filter == nil || text == "" {
+        return nil, "", nil
+    }
+    verdict := s.filter.Evaluate(text)
+    switch verdict.Decision {
+    case contentfilter.Reject:
+        return nil, "", &ContentRejectedError{Reasons: verdict.Reasons}
+    case contentfilter.Hold:
+        return verdict.Reasons, fmt.Sprintf("score %.1f: %s", verdict.Score, strings.Join(verdict.Reasons, ", ")), nil
+    }
+    return nil, "", nil
+}
+
+// GetReview returns a review as seen by viewerID (0 when anonymous); hidden
+// reviews are only visible to their author.
+func (s *ReviewService) GetReview(ctx context.Context, id, viewerID int) (*models.Review, error) {
+    review, err := s.repo.GetReviewByID(ctx, id)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            return nil, ErrReviewNotFound
+        }
+        return nil, fmt.Errorf("get review: %w", err)
+    }
+    if !visible(review.HiddenAt, review.UserID, viewerID) {
+        return nil, ErrReviewNotFound
+    }
+    return review, nil
+}
+
+// UpdateReview changes the rating and text of the user's own review. version
+// is the version the edit is based on, or AnyVersion; ErrVersionMismatch is
+// returned when the review has changed since. The new text goes through the
+// content filter like a new review: when held, the review is hidden and
+// HeldReasons is set.
+func (s *ReviewService) UpdateReview(ctx context.Context, id, userID, version int, req *models.ReviewRequest) (*models.Review, error) {
+    before, err := s.GetReview(ctx, id, userID)
+    if err != nil {
+        return nil, err
+    }
+    if before.UserID != userID {
+        return nil, ErrForbidden
+    }
+    if version == AnyVersion {
+        version = before.Version
+    }
+    if before.Version != version {
+        return nil, ErrVersionMismatch
+    }
+    reasons, details, err := s.screen(req.Comment)
+    if err != nil {
+        return nil, err
+    }
+    edit := *before
+    edit.Rating, edit.Comment = req.Rating, req.Comment
+    var after *models.Review
+    var rating float32
+    err = inTx(ctx, s.tx, func(ctx context.Context) error {
+        if err := s.repo.UpdateReview(ctx, &edit, version, details); err != nil {
+            if errors.Is(err, pgx.ErrNoRows) {
+                if _, err := s.GetReview(ctx, id, userID); err != nil {
+                    return err
+                }
+                return ErrVersionMismatch
+            }
+            return fmt.Errorf("update review: %w", err)
+        }
+        if rating, err = s.refreshRating(ctx, before.FilmID); err != nil {
+            return err
+        }
+        if after, err = s.GetReview(ctx, id, userID); err != nil {
+            return err
+        }
+        action := models.AuditUpdate
+        if details != "" {
+            action = models.AuditHold
+        }
+        return record(ctx, s.audit, action, models.EntityReview, id, newReviewState(before), newReviewState(after))
+    })
+    if err != nil {
+        return nil, err
+    }
+    s.invalidateFilm(before.FilmID)
+    after.HeldReasons = reasons
+    if details != "" {
+        broadcast(s.live, FilmTopic(before.FilmID), models.LiveReviewRemoved, models.ReviewRef{ID: id, FilmID: before.FilmID})
+        broadcast(s.live, ModerationTopic, models.LiveReviewHeld, after)
+    } else {
+        broadcast(s.live, FilmTopic(before.FilmID), models.LiveReviewUpdated, after)
+    }
+    s.broadcastRating(before.FilmID, rating)
+    return after, nil
+}
+
+// ListReviews returns the film's reviews ordered by sort, one of the
+// models.ReviewSort* values; empty means newest first. Reviews hidden by a
+// moderator are only listed for their author, viewerID (0 when anonymous).
+func (s *ReviewService) ListReviews(ctx context.Context, filmID int, sort string, viewerID int) ([]models.Review, error) {
+    switch sort {
+    case "":
+        sort = models.ReviewSortNewest
+    case models.ReviewSortNewest, models.ReviewSortHelpful, models.ReviewSortRating:
+    default:
+        return nil, ErrInvalidSort
+    }
+    reviews, err := s.repo.ListReviewsByFilm(ctx, filmID, sort, viewerID)
     if err != nil {
         return nil, fmt.Errorf("list reviews: %w", err)
     }
     return reviews, nil
-} 
\ No newline at end of file
+}
+
+// Vote marks a review helpful or unhelpful for the user. Casting the same
+// vote again withdraws it; the opposite vote replaces it.
+func (s *ReviewService) Vote(ctx context.Context, reviewID, userID int, helpful bool) (*models.ReviewVotes, error) {
+    review, err := s.repo.GetReviewByID(ctx, reviewID)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            return nil, ErrReviewNotFound
+        }
+        return nil, fmt.Errorf("get review: %w", err)
+    }
+    if review.UserID == userID {
+        return nil, ErrOwnReview
+    }
+    if review.HiddenAt != nil {
+        return nil, ErrReviewNotFound
+    }
+    value := -1
+    if helpful {
+        value = 1
+    }
+    var votes *models.ReviewVotes
+    err = inTx(ctx, s.tx, func(ctx context.Context) error {
+        if votes, err = s.repo.Vote(ctx, reviewID, userID, value); err != nil {
+            if errors.Is(err, pgx.ErrNoRows) {
+                return ErrReviewNotFound
+            }
+            return fmt.Errorf("vote: %w", err)
+        }
+        if votes.MyVote != 1 {
+            return nil
+        }
+        filmID := review.FilmID
+        return notify(ctx, s.notifier, &models.Notification{
+            UserID: review.UserID, Type: models.NotificationLike, ActorID: &userID, FilmID: &filmID, ReviewID: &reviewID,
+        })
+    })
+    if err != nil {
+        return nil, err
+    }
+    return votes, nil
+}
+
+// DeleteReview moves a review to the trash. Authors may delete their own
+// reviews; moderators and admins may delete any.
+func (s *ReviewService) DeleteReview(ctx context.Context, id, userID int, role models.UserRole) error {
+    review, err := s.repo.GetReviewByID(ctx, id)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            return ErrReviewNotFound
+        }
+        return fmt.Errorf("get review: %w", err)
+    }
+    if !visible(review.HiddenAt, review.UserID, userID) && !role.CanModerate() {
+        return ErrReviewNotFound
+    }
+    if review.UserID != userID && !role.CanModerate() {
+        return ErrForbidden
+    }
+    var rating float32
+    err = inTx(ctx, s.tx, func(ctx context.Context) error {
+        if err := s.repo.DeleteReview(ctx, id, userID); err != nil {
+            if errors.Is(err, pgx.ErrNoRows) {
+                return ErrReviewNotFound
+            }
+            return fmt.Errorf("delete review: %w", err)
+        }
+        if rating, err = s.refreshRating(ctx, review.FilmID); err != nil {
+            return err
+        }
+        return record(ctx, s.audit, models.AuditDelete, models.EntityReview, id, newReviewState(review), nil)
+    })
+    if err != nil {
+        return err
+    }
+    s.invalidateFilm(review.FilmID)
+    broadcast(s.live, FilmTopic(review.FilmID), models.LiveReviewRemoved, models.ReviewRef{ID: id, FilmID: review.FilmID})
+    s.broadcastRating(review.FilmID, rating)
+    return nil
+}
+
+// reviewState is the audited part of a review.
+type reviewState struct {
+    FilmID  int    `json:"film_id"`
+    UserID  int    `json:"user_id"`
+    Rating  int    `json:"rating"`
+    Comment string `json:"comment"`
+}
+
+func newReviewState(r *models.Review) reviewState {
+    return reviewState{FilmID: r.FilmID, UserID: r.UserID, Rating: r.Rating, Comment: r.Comment}
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+	"filmhub/pkg/contentfilter"
+)
+
+type stubReviewRepo struct {
+	reviews   []models.Review
+	votes     map[[2]int]int // {reviewID, userID} -> value
+	refreshed []int          // films whose rating was recomputed
+	ratingErr error
+}
+
+func (s *stubReviewRepo) CreateReview(_ context.Context, review *models.Review) (int, error) {
+	review.ID = len(s.reviews) + 1
+	review.Version = 1
+	s.reviews = append(s.reviews, *review)
+	return review.ID, nil
+}
+
+func (s *stubReviewRepo) HoldReview(ctx context.Context, review *models.Review, _ string) (int, error) {
+	now := time.Now()
+	review.HiddenAt = &now
+	return s.CreateReview(ctx, review)
+}
+
+func (s *stubReviewRepo) GetReviewByID(_ context.Context, id int) (*models.Review, error) {
+	for i := range s.reviews {
+		if s.reviews[i].ID == id {
+			return &s.reviews[i], nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (s *stubReviewRepo) ListReviewsByFilm(_ context.Context, filmID int, order string, viewerID int) ([]models.Review, error) {
+	var out []models.Review
+	for _, r := range s.reviews {
+		if r.FilmID == filmID && visible(r.HiddenAt, r.UserID, viewerID) {
+			out = append(out, r)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		switch order {
+		case models.ReviewSortHelpful:
+			return out[i].HelpfulCount-out[i].UnhelpfulCount > out[j].HelpfulCount-out[j].UnhelpfulCount
+		case models.ReviewSortRating:
+			return out[i].Rating > out[j].Rating
+		}
+		return out[i].ID > out[j].ID
+	})
+	return out, nil
+}
+
+func (s *stubReviewRepo) UpdateReview(ctx context.Context, review *models.Review, version int, holdDetails string) error {
+	stored, err := s.GetReviewByID(ctx, review.ID)
+	if err != nil || stored.Version != version {
+		return pgx.ErrNoRows
+	}
+	stored.Rating, stored.Comment = review.Rating, review.Comment
+	stored.Version++
+	if holdDetails != "" && stored.HiddenAt == nil {
+		now := time.Now()
+		stored.HiddenAt = &now
+	}
+	return nil
+}
+
+func (s *stubReviewRepo) RefreshFilmRating(_ context.Context, filmID int) (float32, error) {
+	if s.ratingErr != nil {
+		return 0, s.ratingErr
+	}
+	s.refreshed = append(s.refreshed, filmID)
+	var sum, n int
+	for _, r := range s.reviews {
+		if r.FilmID == filmID && r.HiddenAt == nil {
+			sum, n = sum+r.Rating, n+1
+		}
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	return float32(sum) / float32(n), nil
+}
+
+func (s *stubReviewRepo) DeleteReview(_ context.Context, id, _ int) error {
+	for i := range s.reviews {
+		if s.reviews[i].ID == id {
+			s.reviews = append(s.reviews[:i], s.reviews[i+1:]...)
+			return nil
+		}
+	}
+	return pgx.ErrNoRows
+}
+
+func (s *stubReviewRepo) Vote(ctx context.Context, reviewID, userID, value int) (*models.ReviewVotes, error) {
+	review, err := s.GetReviewByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if s.votes == nil {
+		s.votes = map[[2]int]int{}
+	}
+	key := [2]int{reviewID, userID}
+	votes := &models.ReviewVotes{ReviewID: reviewID}
+	if s.votes[key] == value {
+		delete(s.votes, key)
+	} else {
+		s.votes[key] = value
+		votes.MyVote = value
+	}
+	review.HelpfulCount, review.UnhelpfulCount = 0, 0
+	for k, v := range s.votes {
+		if k[0] != reviewID {
+			continue
+		}
+		if v == 1 {
+			review.HelpfulCount++
+		} else {
+			review.UnhelpfulCount++
+		}
+	}
+	votes.HelpfulCount, votes.UnhelpfulCount = review.HelpfulCount, review.UnhelpfulCount
+	return votes, nil
+}
+
+func TestReviewService_RequireVerifiedEmail(t *testing.T) {
+	ctx := context.Background()
+	users := newStubUserRepo()
+	author := &models.User{Username: "john", Email: "john@example.com"}
+	if err := users.Create(ctx, author); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	svc := NewReviewService(&stubReviewRepo{}).RequireVerifiedEmail(users)
+
+	review := &models.Review{FilmID: 1, UserID: author.ID, Rating: 8}
+	if _, err := svc.CreateReview(ctx, review); !errors.Is(err, ErrEmailNotVerified) {
+		t.Fatalf("expected ErrEmailNotVerified, got %v", err)
+	}
+
+	author.EmailVerified = true
+	if _, err := svc.CreateReview(ctx, review); err != nil {
+		t.Fatalf("verified author must be able to post: %v", err)
+	}
+}
+
+func TestReviewService_VotesAndSorting(t *testing.T) {
+	ctx := context.Background()
+	repo := &stubReviewRepo{}
+	svc := NewReviewService(repo)
+	older, _ := svc.CreateReview(ctx, &models.Review{FilmID: 1, UserID: 1, Rating: 9})
+	newer, _ := svc.CreateReview(ctx, &models.Review{FilmID: 1, UserID: 2, Rating: 5})
+
+	if _, err := svc.Vote(ctx, older, 1, true); !errors.Is(err, ErrOwnReview) {
+		t.Fatalf("expected ErrOwnReview, got %v", err)
+	}
+	if _, err := svc.Vote(ctx, 99, 1, true); !errors.Is(err, ErrReviewNotFound) {
+		t.Fatalf("expected ErrReviewNotFound, got %v", err)
+	}
+
+	votes, err := svc.Vote(ctx, older, 3, true)
+	if err != nil || votes.HelpfulCount != 1 || votes.MyVote != 1 {
+		t.Fatalf("unexpected vote result %+v, %v", votes, err)
+	}
+	// Switching replaces the vote, repeating it withdraws it.
+	votes, _ = svc.Vote(ctx, older, 3, false)
+	if votes.HelpfulCount != 0 || votes.UnhelpfulCount != 1 || votes.MyVote != -1 {
+		t.Fatalf("expected switched vote, got %+v", votes)
+	}
+	votes, _ = svc.Vote(ctx, older, 3, false)
+	if votes.UnhelpfulCount != 0 || votes.MyVote != 0 {
+		t.Fatalf("expected withdrawn vote, got %+v", votes)
+	}
+	_, _ = svc.Vote(ctx, older, 3, true)
+	_, _ = svc.Vote(ctx, older, 4, true)
+
+	for order, first := range map[string]int{"": newer, "newest": newer, "helpful": older, "rating": older} {
+		reviews, err := svc.ListReviews(ctx, 1, order, 0)
+		if err != nil {
+			t.Fatalf("list %q: %v", order, err)
+		}
+		if reviews[0].ID != first {
+			t.Errorf("sort %q: expected review %d first, got %d", order, first, reviews[0].ID)
+		}
+	}
+	if _, err := svc.ListReviews(ctx, 1, "random", 0); !errors.Is(err, ErrInvalidSort) {
+		t.Fatalf("expected ErrInvalidSort, got %v", err)
+	}
+}
+
+func TestReviewService_ContentFilter(t *testing.T) {
+	ctx := context.Background()
+	repo := &stubReviewRepo{}
+	filter := contentfilter.NewPipeline(1, 3,
+		contentfilter.NewBannedWords([]string{"идиот"}, contentfilter.DefaultBannedWordWeight),
+		contentfilter.NewShoutingFilter())
+	svc := NewReviewService(repo).WithContentFilter(filter)
+
+	if _, err := svc.CreateReview(ctx, &models.Review{FilmID: 1, UserID: 1, Rating: 9, Comment: "Отличный фильм"}); err != nil {
+		t.Fatalf("clean review: %v", err)
+	}
+
+	held := &models.Review{FilmID: 1, UserID: 2, Rating: 2, Comment: "Режиссёр идиот"}
+	if _, err := svc.CreateReview(ctx, held); err != nil {
+		t.Fatalf("held review: %v", err)
+	}
+	if held.HiddenAt == nil || len(held.HeldReasons) != 1 || held.HeldReasons[0] != contentfilter.ReasonBannedWords {
+		t.Fatalf("expected the review to be held for banned words, got %+v", held)
+	}
+	if reviews, _ := svc.ListReviews(ctx, 1, "", 0); len(reviews) != 1 {
+		t.Fatalf("expected the held review to stay out of public listings, got %d reviews", len(reviews))
+	}
+	if reviews, _ := svc.ListReviews(ctx, 1, "", 2); len(reviews) != 2 {
+		t.Fatalf("expected the author to see the held review, got %d reviews", len(reviews))
+	}
+
+	_, err := svc.CreateReview(ctx, &models.Review{FilmID: 1, UserID: 3, Rating: 1, Comment: "РЕЖИССЁР ИДИОТ!!!!"})
+	var rejected *ContentRejectedError
+	if !errors.As(err, &rejected) || len(rejected.Reasons) != 3 {
+		t.Fatalf("expected rejection with three reasons, got %v", err)
+	}
+	if len(repo.reviews) != 2 {
+		t.Fatalf("rejected review must not be stored")
+	}
+}
+
+func TestReviewService_DeleteReview(t *testing.T) {
+	ctx := context.Background()
+	repo := &stubReviewRepo{}
+	svc := NewReviewService(repo)
+	own, _ := svc.CreateReview(ctx, &models.Review{FilmID: 1, UserID: 1, Rating: 7})
+	other, _ := svc.CreateReview(ctx, &models.Review{FilmID: 1, UserID: 2, Rating: 3})
+
+	if err := svc.DeleteReview(ctx, other, 1, models.RoleUser); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+	if err := svc.DeleteReview(ctx, own, 1, models.RoleUser); err != nil {
+		t.Fatalf("delete own review: %v", err)
+	}
+	if err := svc.DeleteReview(ctx, own, 1, models.RoleUser); !errors.Is(err, ErrReviewNotFound) {
+		t.Fatalf("expected ErrReviewNotFound for a deleted review, got %v", err)
+	}
+	if err := svc.DeleteReview(ctx, other, 5, models.RoleModerator); err != nil {
+		t.Fatalf("moderator delete: %v", err)
+	}
+}
+
+func TestReviewService_UpdateReview(t *testing.T) {
+	ctx := context.Background()
+	repo := &stubReviewRepo{}
+	filter := contentfilter.NewPipeline(1, 3, contentfilter.NewBannedWords([]string{"идиот"}, contentfilter.DefaultBannedWordWeight))
+	svc := NewReviewService(repo).WithContentFilter(filter)
+	id, _ := svc.CreateReview(ctx, &models.Review{FilmID: 1, UserID: 1, Rating: 5, Comment: "Так себе"})
+
+	if _, err := svc.UpdateReview(ctx, id, 2, 1, &models.ReviewRequest{Rating: 1}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for another user, got %v", err)
+	}
+	updated, err := svc.UpdateReview(ctx, id, 1, 1, &models.ReviewRequest{Rating: 8, Comment: "Пересмотрел — хорошо"})
+	if err != nil {
+		t.Fatalf("update review: %v", err)
+	}
+	if updated.Version != 2 || updated.Rating != 8 || updated.HiddenAt != nil {
+		t.Fatalf("unexpected updated review %+v", updated)
+	}
+	// A second editor still holding version 1 must not overwrite the edit.
+	if _, err := svc.UpdateReview(ctx, id, 1, 1, &models.ReviewRequest{Rating: 3}); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("expected ErrVersionMismatch, got %v", err)
+	}
+
+	held, err := svc.UpdateReview(ctx, id, 1, 2, &models.ReviewRequest{Rating: 2, Comment: "Режиссёр идиот"})
+	if err != nil {
+		t.Fatalf("held update: %v", err)
+	}
+	if held.HiddenAt == nil || len(held.HeldReasons) != 1 || held.Version != 3 {
+		t.Fatalf("expected the edited review to be held, got %+v", held)
+	}
+	if _, err := svc.UpdateReview(ctx, 99, 1, 1, &models.ReviewRequest{Rating: 3}); !errors.Is(err, ErrReviewNotFound) {
+		t.Fatalf("expected ErrReviewNotFound, got %v", err)
+	}
+}
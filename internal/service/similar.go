@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+	"filmhub/pkg/cache"
+	"filmhub/pkg/recommend"
+	"filmhub/pkg/textsim"
+)
+
+const (
+	similarKeyPrefix = "similar:"
+	// similarIndexKey caches the catalog every film is compared against.
+	similarIndexKey = similarKeyPrefix + "index"
+)
+
+// maxShared is the number of genres, tags, people or words a signal names.
+const maxShared = 5
+
+// SimilarRepo describes repository dependencies for similar films.
+type SimilarRepo interface {
+	FilmFeatures(ctx context.Context) ([]models.FilmFeatures, error)
+	CoRatings(ctx context.Context, id int) ([]recommend.Similarity, error)
+}
+
+// SimilarWeights weigh the signals of film similarity against each other.
+// Only their ratios matter; a zero weight turns a signal off.
+type SimilarWeights struct {
+	Genres  float64
+	Tags    float64
+	People  float64
+	Ratings float64
+	Text    float64
+}
+
+// SimilarService finds the films most like a film by a weighted mix of
+// shared genres and tags, shared cast and crew, how alike users rate them
+// (see RecommendationService) and how alike their descriptions read.
+// Results are cached per film, and so is the catalog they are computed
+// from, so changes show up once they expire.
+type SimilarService struct {
+	repo    SimilarRepo
+	films   FilmLookup
+	weights SimilarWeights
+	cache   cache.Cache
+	group   cache.Group
+}
+
+// NewSimilarService returns a SimilarService caching results in c; a nil c
+// computes them on every call.
+func NewSimilarService(repo SimilarRepo, films FilmLookup, weights SimilarWeights, c cache.Cache) *SimilarService {
+	return &SimilarService{repo: repo, films: films, weights: weights, cache: c}
+}
+
+// Similar returns up to limit films most similar to film id, best first,
+// each with the signals it is similar by. A limit outside 1..100 falls back
+// to 20.
+func (s *SimilarService) Similar(ctx context.Context, id, limit int) ([]models.SimilarFilm, error) {
+	_, limit = pageBounds(1, limit)
+	key := similarKeyPrefix + strconv.Itoa(id)
+	if s.cache != nil {
+		if v, ok := s.cache.Get(key); ok {
+			films := v.([]models.SimilarFilm)
+			return films[:min(len(films), limit)], nil
+		}
+	}
+	// Unknown films are not cached, so they are turned away before anything
+	// is computed.
+	if _, err := s.films.GetFilmByID(ctx, id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrFilmNotFound
+		}
+		return nil, fmt.Errorf("get film: %w", err)
+	}
+	v, err, _ := s.group.Do(key, func() (any, error) {
+		// The result is shared, so it must not fail with the caller that
+		// happened to start it.
+		films, err := s.compute(context.WithoutCancel(ctx), id)
+		if err != nil {
+			return nil, err
+		}
+		if s.cache != nil {
+			s.cache.Set(key, films)
+		}
+		return films, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	films := v.([]models.SimilarFilm)
+	return films[:min(len(films), limit)], nil
+}
+
+// similarIndex is the catalog films are compared against: the features of
+// every film and, when text is weighed, an index of their descriptions.
+type similarIndex struct {
+	features []models.FilmFeatures
+	byID     map[int]int
+	text     *textsim.Index
+}
+
+func newSimilarIndex(features []models.FilmFeatures, text bool) *similarIndex {
+	idx := &similarIndex{features: features, byID: make(map[int]int, len(features))}
+	for i, f := range features {
+		idx.byID[f.FilmID] = i
+	}
+	if text {
+		docs := make(map[int]string, len(features))
+		for _, f := range features {
+			docs[f.FilmID] = f.Description
+		}
+		idx.text = textsim.New(docs)
+	}
+	return idx
+}
+
+// index returns the catalog including film id. A cached catalog is reused
+// unless the film was added after it was built.
+func (s *SimilarService) index(ctx context.Context, id int) (*similarIndex, error) {
+	if s.cache != nil {
+		if v, ok := s.cache.Get(similarIndexKey); ok {
+			idx := v.(*similarIndex)
+			if _, ok := idx.byID[id]; ok {
+				return idx, nil
+			}
+		}
+	}
+	v, err, _ := s.group.Do(similarIndexKey, func() (any, error) {
+		features, err := s.repo.FilmFeatures(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("get film features: %w", err)
+		}
+		idx := newSimilarIndex(features, s.weights.Text > 0)
+		if s.cache != nil {
+			s.cache.Set(similarIndexKey, idx)
+		}
+		return idx, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*similarIndex), nil
+}
+
+// compute scores every other film against film id and keeps the best
+// maxPageLimit.
+func (s *SimilarService) compute(ctx context.Context, id int) ([]models.SimilarFilm, error) {
+	idx, err := s.index(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	i, ok := idx.byID[id]
+	if !ok {
+		return nil, ErrFilmNotFound
+	}
+	target := &idx.features[i]
+
+	w := s.weights
+	total := max(w.Genres, 0) + max(w.Tags, 0) + max(w.People, 0) + max(w.Ratings, 0) + max(w.Text, 0)
+	if total == 0 {
+		return []models.SimilarFilm{}, nil
+	}
+	coRatings := map[int]recommend.Similarity{}
+	if w.Ratings > 0 {
+		sims, err := s.repo.CoRatings(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("get rating similarities: %w", err)
+		}
+		for _, sim := range sims {
+			coRatings[sim.OtherID] = sim
+		}
+	}
+
+	films := []models.SimilarFilm{}
+	for _, f := range idx.features {
+		if f.FilmID == id {
+			continue
+		}
+		var signals []models.SimilaritySignal
+		add := func(weight, similarity float64, signal models.SimilaritySignal) {
+			if weight <= 0 || similarity <= 0 {
+				return
+			}
+			signal.Similarity = similarity
+			signal.Contribution = weight * similarity / total
+			signals = append(signals, signal)
+		}
+		if shared, sim := overlap(target.Genres, f.Genres); len(shared) > 0 {
+			add(w.Genres, sim, models.SimilaritySignal{
+				Signal: models.SignalGenres, Shared: shared, Explanation: "Общие жанры: " + strings.Join(shared, ", "),
+			})
+		}
+		if shared, sim := overlap(target.Tags, f.Tags); len(shared) > 0 {
+			add(w.Tags, sim, models.SimilaritySignal{
+				Signal: models.SignalTags, Shared: shared, Explanation: "Общие теги: " + strings.Join(shared, ", "),
+			})
+		}
+		if shared, sim := overlap(target.People, f.People); len(shared) > 0 {
+			add(w.People, sim, models.SimilaritySignal{
+				Signal: models.SignalPeople, Shared: shared, Explanation: "Общие актёры и создатели: " + strings.Join(shared, ", "),
+			})
+		}
+		if co, ok := coRatings[f.FilmID]; ok {
+			add(w.Ratings, co.Score, models.SimilaritySignal{
+				Signal: models.SignalRatings, CoRaters: co.Support,
+				Explanation: fmt.Sprintf("Зрители, оценившие оба фильма (%d), оценивают их похоже", co.Support),
+			})
+		}
+		if idx.text != nil {
+			if sim, words := idx.text.Similarity(id, f.FilmID, maxShared); len(words) > 0 {
+				add(w.Text, sim, models.SimilaritySignal{
+					Signal: models.SignalText, Shared: words, Explanation: "Похожие описания: " + strings.Join(words, ", "),
+				})
+			}
+		}
+		if len(signals) == 0 {
+			continue
+		}
+
+		film := models.SimilarFilm{FilmID: f.FilmID, Title: f.Title, Rating: f.Rating}
+		sort.SliceStable(signals, func(i, j int) bool { return signals[i].Contribution > signals[j].Contribution })
+		for _, signal := range signals {
+			film.Score += signal.Contribution
+		}
+		film.Signals = signals
+		films = append(films, film)
+	}
+	sort.Slice(films, func(i, j int) bool {
+		if films[i].Score != films[j].Score {
+			return films[i].Score > films[j].Score
+		}
+		return films[i].FilmID < films[j].FilmID
+	})
+	return films[:min(len(films), maxPageLimit)], nil
+}
+
+// overlap returns the first maxShared values a and b have in common, in the
+// order of a, and the Jaccard index of the two sets.
+func overlap(a, b []string) ([]string, float64) {
+	in := make(map[string]bool, len(b))
+	for _, v := range b {
+		in[v] = true
+	}
+	var shared []string
+	common := 0
+	for _, v := range a {
+		if in[v] {
+			common++
+			if len(shared) < maxShared {
+				shared = append(shared, v)
+			}
+		}
+	}
+	if common == 0 {
+		return nil, 0
+	}
+	return shared, float64(common) / float64(len(a)+len(b)-common)
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+	"filmhub/pkg/cache"
+	"filmhub/pkg/recommend"
+)
+
+// stubSimilarRepo serves film features and rating similarities from memory
+// and counts feature loads. It also looks up the films it knows.
+type stubSimilarRepo struct {
+	films []models.FilmFeatures
+	sims  []recommend.Similarity
+	loads int
+}
+
+func (s *stubSimilarRepo) FilmFeatures(ctx context.Context) ([]models.FilmFeatures, error) {
+	s.loads++
+	return s.films, ctx.Err()
+}
+
+func (s *stubSimilarRepo) GetFilmByID(_ context.Context, id int) (*models.Film, error) {
+	for _, f := range s.films {
+		if f.FilmID == id {
+			return &models.Film{ID: id, Title: f.Title}, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (s *stubSimilarRepo) CoRatings(_ context.Context, id int) ([]recommend.Similarity, error) {
+	var out []recommend.Similarity
+	for _, sim := range s.sims {
+		if sim.ItemID == id {
+			out = append(out, sim)
+		}
+	}
+	return out, nil
+}
+
+func newSimilarFixture() *stubSimilarRepo {
+	return &stubSimilarRepo{
+		films: []models.FilmFeatures{
+			{FilmID: 1, Title: "Heat", Description: "A detective hunts a crew of bank robbers in Los Angeles.",
+				Genres: []string{"crime", "thriller"}, Tags: []string{"heist"}, People: []string{"Al Pacino", "Michael Mann", "Robert De Niro"}},
+			{FilmID: 2, Title: "Collateral", Description: "A cab driver is forced to drive a hitman around Los Angeles.",
+				Genres: []string{"crime", "thriller"}, People: []string{"Michael Mann", "Tom Cruise"}},
+			{FilmID: 3, Title: "Ronin", Description: "Mercenaries chase a briefcase across France.",
+				Genres: []string{"action", "crime"}, Tags: []string{"car chase", "heist"}, People: []string{"Robert De Niro"}},
+			{FilmID: 4, Title: "Amélie", Description: "A shy waitress in Paris.", Genres: []string{"comedy"}},
+		},
+		sims: []recommend.Similarity{{ItemID: 1, OtherID: 3, Score: 0.5, Support: 12}},
+	}
+}
+
+func TestSimilarService_WeighsAndExplainsSignals(t *testing.T) {
+	fixture := newSimilarFixture()
+	svc := NewSimilarService(fixture, fixture, SimilarWeights{Genres: 1, People: 1, Ratings: 1, Text: 1}, nil)
+
+	films, err := svc.Similar(context.Background(), 1, 10)
+	if err != nil {
+		t.Fatalf("similar: %v", err)
+	}
+	if len(films) != 2 || films[0].FilmID != 2 || films[1].FilmID != 3 {
+		t.Fatalf("expected Collateral, then Ronin, and not Amélie, got %+v", films)
+	}
+	collateral := films[0]
+	signals := map[string]models.SimilaritySignal{}
+	var sum float64
+	for _, s := range collateral.Signals {
+		signals[s.Signal] = s
+		sum += s.Contribution
+	}
+	if genres := signals[models.SignalGenres]; genres.Similarity != 1 || genres.Contribution != 0.25 || len(genres.Shared) != 2 {
+		t.Fatalf("expected identical genres to contribute a quarter, got %+v", genres)
+	}
+	if people := signals[models.SignalPeople]; len(people.Shared) != 1 || people.Shared[0] != "Michael Mann" || people.Explanation == "" {
+		t.Fatalf("expected the shared director, got %+v", people)
+	}
+	if _, ok := signals[models.SignalText]; !ok {
+		t.Fatalf("expected the descriptions to match on Los Angeles, got %+v", collateral.Signals)
+	}
+	if _, ok := signals[models.SignalRatings]; ok || sum != collateral.Score {
+		t.Fatalf("expected the score to add up the signals without ratings, got %+v", collateral)
+	}
+	if collateral.Signals[0].Signal != models.SignalGenres {
+		t.Fatalf("expected the strongest signal first, got %+v", collateral.Signals)
+	}
+	if ratings := films[1].Signals[0]; ratings.Signal != models.SignalRatings || ratings.CoRaters != 12 {
+		t.Fatalf("expected Ronin to be similar by ratings first, got %+v", films[1].Signals)
+	}
+
+	// Signals weighted 0 are left out.
+	genresOnly := NewSimilarService(fixture, fixture, SimilarWeights{Genres: 1}, nil)
+	if films, _ := genresOnly.Similar(context.Background(), 1, 10); len(films) != 2 || len(films[1].Signals) != 1 || films[1].Score != 1.0/3 {
+		t.Fatalf("expected only genre signals, got %+v", films)
+	}
+
+	tagsOnly := NewSimilarService(fixture, fixture, SimilarWeights{Tags: 1}, nil)
+	films, err = tagsOnly.Similar(context.Background(), 1, 10)
+	if err != nil || len(films) != 1 || films[0].FilmID != 3 || films[0].Signals[0].Signal != models.SignalTags || films[0].Score != 0.5 {
+		t.Fatalf("expected Ronin to share the heist tag, got %+v, %v", films, err)
+	}
+
+	loads := fixture.loads
+	if _, err := svc.Similar(context.Background(), 9, 10); !errors.Is(err, ErrFilmNotFound) {
+		t.Fatalf("expected an unknown film to be not found, got %v", err)
+	}
+	if fixture.loads != loads {
+		t.Fatal("expected an unknown film to be turned away before the catalog is loaded")
+	}
+}
+
+func TestSimilarService_Cache(t *testing.T) {
+	repo := newSimilarFixture()
+	svc := NewSimilarService(repo, repo, SimilarWeights{Genres: 1, People: 1}, cache.NewLRU(10, time.Minute))
+
+	for _, limit := range []int{10, 1} {
+		films, err := svc.Similar(context.Background(), 1, limit)
+		if err != nil {
+			t.Fatalf("similar: %v", err)
+		}
+		if len(films) != min(limit, 2) {
+			t.Fatalf("expected %d films, got %+v", min(limit, 2), films)
+		}
+	}
+	if repo.loads != 1 {
+		t.Fatalf("expected one load for a cached film, got %d", repo.loads)
+	}
+
+	// Other films are compared against the catalog already loaded, until a
+	// film newer than it is asked for.
+	if _, err := svc.Similar(context.Background(), 2, 10); err != nil || repo.loads != 1 {
+		t.Fatalf("expected the catalog to be reused, got %d loads, %v", repo.loads, err)
+	}
+	repo.films = append(repo.films, models.FilmFeatures{FilmID: 5, Title: "Thief", Genres: []string{"crime"}})
+	films, err := svc.Similar(context.Background(), 5, 10)
+	if err != nil || repo.loads != 2 || len(films) != 3 {
+		t.Fatalf("expected a new film to reload the catalog, got %+v after %d loads, %v", films, repo.loads, err)
+	}
+}
+
+func TestSimilarService_OutlivesCaller(t *testing.T) {
+	repo := newSimilarFixture()
+	svc := NewSimilarService(repo, repo, SimilarWeights{Genres: 1}, nil)
+
+	// The computation is shared with other callers, so a caller going away
+	// must not fail it.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if films, err := svc.Similar(ctx, 1, 10); err != nil || len(films) != 2 {
+		t.Fatalf("expected a cancelled caller to get the result, got %+v, %v", films, err)
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+)
+
+var (
+	// ErrInvalidTrashType is returned for a trash item type other than film
+	// or review.
+	ErrInvalidTrashType = errors.New("invalid trash type")
+	// ErrNotInTrash is returned when restoring an item that is not deleted.
+	ErrNotInTrash = errors.New("item is not in the trash")
+)
+
+// TrashRepo describes repository dependencies for the trash.
+type TrashRepo interface {
+	List(ctx context.Context, itemType string, limit, offset int) ([]models.TrashItem, int, error)
+	Restore(ctx context.Context, itemType string, id int) (*models.FilmRating, error)
+	Purge(ctx context.Context, cutoff time.Time) (films, reviews int64, err error)
+}
+
+// TrashService manages soft-deleted films and reviews: admins list and
+// restore them, and Run purges those older than the retention period.
+type TrashService struct {
+	repo      TrashRepo
+	retention time.Duration
+	audit     Auditor
+	films     FilmInvalidator
+	reviews   ReviewLookup
+	live      Broadcaster
+	tx        Transactor
+	now       func() time.Time
+}
+
+func NewTrashService(repo TrashRepo, retention time.Duration) *TrashService {
+	return &TrashService{repo: repo, retention: retention, now: time.Now}
+}
+
+// WithAudit records restores with a.
+func (s *TrashService) WithAudit(a Auditor) *TrashService {
+	s.audit = a
+	return s
+}
+
+// WithTransactions stores restores and their audit entries in one unit of
+// work with t.
+func (s *TrashService) WithTransactions(t Transactor) *TrashService {
+	s.tx = t
+	return s
+}
+
+// WithFilmCache invalidates restored films, and the films of restored
+// reviews, in c.
+func (s *TrashService) WithFilmCache(c FilmInvalidator) *TrashService {
+	s.films = c
+	return s
+}
+
+// WithReviews reads restored reviews from r for live updates.
+func (s *TrashService) WithReviews(r ReviewLookup) *TrashService {
+	s.reviews = r
+	return s
+}
+
+// WithLiveUpdates broadcasts restored reviews, and the ratings this
+// changes, with b on the film's topic.
+func (s *TrashService) WithLiveUpdates(b Broadcaster) *TrashService {
+	s.live = b
+	return s
+}
+
+// List returns a page of deleted items of itemType, "film" or "review";
+// empty lists both.
+func (s *TrashService) List(ctx context.Context, itemType string, page, limit int) (*models.TrashPage, error) {
+	if itemType != "" && !trashType(itemType) {
+		return nil, ErrInvalidTrashType
+	}
+	page, limit = pageBounds(page, limit)
+	items, total, err := s.repo.List(ctx, itemType, limit, (page-1)*limit)
+	if err != nil {
+		return nil, fmt.Errorf("list trash: %w", err)
+	}
+	if items == nil {
+		items = []models.TrashItem{}
+	}
+	for i := range items {
+		items[i].PurgeAt = items[i].DeletedAt.Add(s.retention)
+	}
+	return &models.TrashPage{Items: items, Total: total, Page: page, Limit: limit}, nil
+}
+
+// Restore takes a film or review out of the trash.
+func (s *TrashService) Restore(ctx context.Context, itemType string, id int) error {
+	if !trashType(itemType) {
+		return ErrInvalidTrashType
+	}
+	var rating *models.FilmRating
+	var restored *models.Review
+	err := inTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		if rating, err = s.repo.Restore(ctx, itemType, id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotInTrash
+			}
+			return fmt.Errorf("restore %s: %w", itemType, err)
+		}
+		if rating != nil && s.reviews != nil {
+			if restored, err = s.reviews.GetReviewByID(ctx, id); err != nil {
+				return fmt.Errorf("get review: %w", err)
+			}
+		}
+		return record(ctx, s.audit, models.AuditRestore, itemType, id, deletedState{Deleted: true}, deletedState{Deleted: false})
+	})
+	if err != nil {
+		return err
+	}
+	if s.films != nil {
+		switch {
+		case itemType == models.EntityFilm:
+			s.films.InvalidateFilm(id)
+		case rating != nil:
+			s.films.InvalidateFilm(rating.FilmID)
+		}
+	}
+	if rating != nil {
+		topic := FilmTopic(rating.FilmID)
+		// A review held by moderation stays off the film page.
+		if restored != nil && restored.HiddenAt == nil {
+			broadcast(s.live, topic, models.LiveReviewCreated, restored)
+		}
+		broadcast(s.live, topic, models.LiveFilmRating, *rating)
+	}
+	return nil
+}
+
+// Purge permanently deletes items that have been in the trash longer than
+// the retention period.
+func (s *TrashService) Purge(ctx context.Context) (films, reviews int64, err error) {
+	films, reviews, err = s.repo.Purge(ctx, s.now().Add(-s.retention))
+	if err != nil {
+		return 0, 0, fmt.Errorf("purge trash: %w", err)
+	}
+	return films, reviews, nil
+}
+
+func trashType(t string) bool {
+	return t == models.EntityFilm || t == models.EntityReview
+}
+
+// deletedState is the audited state of a trashed item.
+type deletedState struct {
+	Deleted bool `json:"deleted"`
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+)
+
+type stubTrashRepo struct {
+	items  []models.TrashItem
+	cutoff time.Time
+}
+
+func (s *stubTrashRepo) List(_ context.Context, itemType string, _, _ int) ([]models.TrashItem, int, error) {
+	var out []models.TrashItem
+	for _, it := range s.items {
+		if itemType == "" || it.Type == itemType {
+			out = append(out, it)
+		}
+	}
+	return out, len(out), nil
+}
+
+// Restore treats every review as one of film 1.
+func (s *stubTrashRepo) Restore(_ context.Context, itemType string, id int) (*models.FilmRating, error) {
+	for i, it := range s.items {
+		if it.Type == itemType && it.ID == id {
+			s.items = append(s.items[:i], s.items[i+1:]...)
+			if itemType == models.EntityReview {
+				return &models.FilmRating{FilmID: 1, Rating: 7}, nil
+			}
+			return nil, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+// invalidatedFilms is a FilmInvalidator recording the films it is told about.
+type invalidatedFilms []int
+
+func (f *invalidatedFilms) InvalidateFilm(id int) { *f = append(*f, id) }
+
+func (s *stubTrashRepo) Purge(_ context.Context, cutoff time.Time) (int64, int64, error) {
+	s.cutoff = cutoff
+	var films, reviews int64
+	kept := s.items[:0]
+	for _, it := range s.items {
+		switch {
+		case !it.DeletedAt.Before(cutoff):
+			kept = append(kept, it)
+		case it.Type == models.EntityFilm:
+			films++
+		default:
+			reviews++
+		}
+	}
+	s.items = kept
+	return films, reviews, nil
+}
+
+func TestTrashService(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := &stubTrashRepo{items: []models.TrashItem{
+		{Type: models.EntityFilm, ID: 1, DeletedAt: now.Add(-40 * 24 * time.Hour)},
+		{Type: models.EntityReview, ID: 2, DeletedAt: now.Add(-time.Hour)},
+		{Type: models.EntityReview, ID: 3, DeletedAt: now.Add(-2 * time.Hour)},
+	}}
+	var invalidated invalidatedFilms
+	svc := NewTrashService(repo, 30*24*time.Hour).WithFilmCache(&invalidated)
+	svc.now = func() time.Time { return now }
+
+	if _, err := svc.List(ctx, "user", 1, 20); !errors.Is(err, ErrInvalidTrashType) {
+		t.Fatalf("expected ErrInvalidTrashType, got %v", err)
+	}
+	page, err := svc.List(ctx, models.EntityReview, 1, 20)
+	if err != nil || page.Total != 2 {
+		t.Fatalf("list reviews: %v %+v", err, page)
+	}
+	if want := page.Items[0].DeletedAt.Add(30 * 24 * time.Hour); !page.Items[0].PurgeAt.Equal(want) {
+		t.Fatalf("expected purge at %v, got %v", want, page.Items[0].PurgeAt)
+	}
+
+	if err := svc.Restore(ctx, models.EntityReview, 3); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if len(invalidated) != 1 || invalidated[0] != 1 {
+		t.Fatalf("expected the review's film to be invalidated, got %v", invalidated)
+	}
+	if err := svc.Restore(ctx, models.EntityReview, 3); !errors.Is(err, ErrNotInTrash) {
+		t.Fatalf("expected ErrNotInTrash, got %v", err)
+	}
+
+	films, reviews, err := svc.Purge(ctx)
+	if err != nil || films != 1 || reviews != 0 {
+		t.Fatalf("expected only the old film purged, got %d films %d reviews (%v)", films, reviews, err)
+	}
+	if !repo.cutoff.Equal(now.Add(-30 * 24 * time.Hour)) {
+		t.Fatalf("unexpected purge cutoff %v", repo.cutoff)
+	}
+}
@@ -0,0 +1,19 @@
+package service
+
+import "context"
+
+// Transactor runs fn as one unit of work: repository calls made with the
+// context passed to fn share a transaction that commits when fn returns
+// nil. fn may be retried, so it must only change the database. See
+// database.TxManager.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// inTx runs fn with t, or directly when no Transactor is configured.
+func inTx(ctx context.Context, t Transactor, fn func(ctx context.Context) error) error {
+	if t == nil {
+		return fn(ctx)
+	}
+	return t.WithinTx(ctx, fn)
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"filmhub/internal/models"
+)
+
+type txKey struct{}
+
+// stubTx marks the context of a unit of work and counts units run.
+type stubTx struct {
+	units int
+}
+
+func (t *stubTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.units++
+	return fn(context.WithValue(ctx, txKey{}, true))
+}
+
+// txFilmRepo fails SetGenres outside a unit of work, or for failGenre.
+type txFilmRepo struct {
+	*stubFilmRepo
+	failGenre string
+}
+
+func (r *txFilmRepo) SetGenres(ctx context.Context, id int, genres []string) error {
+	if ctx.Value(txKey{}) == nil {
+		return errors.New("not in a transaction")
+	}
+	for _, g := range genres {
+		if g == r.failGenre {
+			return errors.New("genre rejected")
+		}
+	}
+	return r.stubFilmRepo.SetGenres(ctx, id, genres)
+}
+
+func TestFilmService_ImportFilms(t *testing.T) {
+	ctx := context.Background()
+	tx := &stubTx{}
+	repo := &txFilmRepo{stubFilmRepo: newStubFilmRepo(), failGenre: "bad"}
+	svc := NewFilmService(repo).WithTransactions(tx)
+
+	ids, err := svc.ImportFilms(ctx, []models.FilmImport{
+		{Title: "The Matrix", Description: "Sci-fi", Genres: []string{"sci-fi", "action"}},
+		{Title: "Heat", Description: "Crime drama"},
+	}, 5)
+	if err != nil {
+		t.Fatalf("import films: %v", err)
+	}
+	if len(ids) != 2 || tx.units != 1 {
+		t.Fatalf("expected two films in one unit of work, got %v in %d", ids, tx.units)
+	}
+	film, _ := svc.GetFilm(ctx, ids[0])
+	if len(film.Genres) != 2 || *film.CreatedBy != 5 {
+		t.Fatalf("unexpected imported film %+v", film)
+	}
+
+	_, err = svc.ImportFilms(ctx, []models.FilmImport{
+		{Title: "Alien", Description: "Horror"},
+		{Title: "Broken", Description: "x", Genres: []string{"bad"}},
+	}, 5)
+	if err == nil {
+		t.Fatal("expected the failing film to fail the import")
+	}
+}
+
+func TestReviewService_RatingInSameUnitOfWork(t *testing.T) {
+	ctx := context.Background()
+	tx := &stubTx{}
+	repo := &stubReviewRepo{}
+	svc := NewReviewService(repo).WithTransactions(tx)
+
+	id, err := svc.CreateReview(ctx, &models.Review{FilmID: 3, UserID: 1, Rating: 8})
+	if err != nil {
+		t.Fatalf("create review: %v", err)
+	}
+	if _, err := svc.UpdateReview(ctx, id, 1, 1, &models.ReviewRequest{Rating: 6}); err != nil {
+		t.Fatalf("update review: %v", err)
+	}
+	if err := svc.DeleteReview(ctx, id, 1, models.RoleUser); err != nil {
+		t.Fatalf("delete review: %v", err)
+	}
+	if tx.units != 3 || len(repo.refreshed) != 3 || repo.refreshed[0] != 3 {
+		t.Fatalf("expected each change to refresh film 3 in its own unit of work, got %d units, %v", tx.units, repo.refreshed)
+	}
+
+	// A failed rating update fails the whole change, so it rolls back.
+	repo.ratingErr = errors.New("serialization failure")
+	if _, err := svc.CreateReview(ctx, &models.Review{FilmID: 3, UserID: 2, Rating: 1}); !errors.Is(err, repo.ratingErr) {
+		t.Fatalf("expected the rating error, got %v", err)
+	}
+}
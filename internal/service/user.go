@@ -11,13 +11,28 @@ import (
 )
 
 type AuthService struct {
-	repo repository.UserRepository
+	repo   repository.UserRepository
+	tx     Transactor
+	events EventPublisher
 }
 
 func NewAuthService(repo repository.UserRepository) *AuthService {
 	return &AuthService{repo: repo}
 }
 
+// WithTransactions stores new users and their user.registered event in one
+// unit of work with t.
+func (s *AuthService) WithTransactions(t Transactor) *AuthService {
+	s.tx = t
+	return s
+}
+
+// WithEvents publishes user.registered events with p.
+func (s *AuthService) WithEvents(p EventPublisher) *AuthService {
+	s.events = p
+	return s
+}
+
 func (s *AuthService) Register(ctx context.Context, user *models.User) error {
 	hash, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -25,7 +40,12 @@ func (s *AuthService) Register(ctx context.Context, user *models.User) error {
 	}
 	user.Password = string(hash)
 	user.Role = models.RoleUser
-	return s.repo.Create(ctx, user)
+	return inTx(ctx, s.tx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, user); err != nil {
+			return err
+		}
+		return publish(ctx, s.events, models.EventUserRegistered, user.ID, models.UserEvent{ID: user.ID, Username: user.Username})
+	})
 }
 
 func (s *AuthService) Login(ctx context.Context, email, password string) (string, error) {
@@ -6,6 +6,8 @@ import (
 
     "filmhub/internal/models"
     "filmhub/pkg/login"
+
+    "github.com/jackc/pgx/v5"
 )
 
 // stubUserRepo is an in-memory implementation of repository.UserRepository
@@ -20,6 +22,7 @@ func newStubUserRepo() *stubUserRepo {
 }
 
 func (s *stubUserRepo) Create(_ context.Context, user *models.User) error {
+    user.ID = len(s.users) + 1
     s.users[user.Email] = user
     return nil
 }
@@ -28,7 +31,52 @@ func (s *stubUserRepo) FindByEmail(_ context.Context, email string) (*models.Use
     if u, ok := s.users[email]; ok {
         return u, nil
     }
-    return nil, context.Canceled // use any non-nil error to indicate not found
+    return nil, pgx.ErrNoRows
+}
+
+func (s *stubUserRepo) FindByID(_ context.Context, id int) (*models.User, error) {
+    for _, u := range s.users {
+        if u.ID == id {
+            return u, nil
+        }
+    }
+    return nil, pgx.ErrNoRows
+}
+
+func (s *stubUserRepo) MarkEmailVerified(ctx context.Context, id int) error {
+    u, err := s.FindByID(ctx, id)
+    if err != nil {
+        return err
+    }
+    u.EmailVerified = true
+    return nil
+}
+
+func (s *stubUserRepo) UpdatePassword(ctx context.Context, id int, hash string) error {
+    u, err := s.FindByID(ctx, id)
+    if err != nil {
+        return err
+    }
+    u.Password = hash
+    return nil
+}
+
+func (s *stubUserRepo) UpdateRole(ctx context.Context, id int, role models.UserRole) error {
+    u, err := s.FindByID(ctx, id)
+    if err != nil {
+        return err
+    }
+    u.Role = role
+    return nil
+}
+
+// initTestKeys installs a fresh EdDSA keyring for token signing.
+func initTestKeys() {
+    keys, err := login.NewKeyring(login.KeyringConfig{Algorithm: login.EdDSA})
+    if err != nil {
+        panic(err)
+    }
+    login.Init(keys, login.Config{})
 }
 
 func TestAuthService_Register_And_Login(t *testing.T) {
@@ -42,7 +90,7 @@ func TestAuthService_Register_And_Login(t *testing.T) {
         Password: "s3cr3tPwd",
     }
 
-    login.Init("testsecret")
+    initTestKeys()
     // Register should hash password and store the user.
     if err := svc.Register(ctx, user); err != nil {
         t.Fatalf("register failed: %v", err)
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+)
+
+// ErrNotOnWatchlist is returned when removing a film the user does not
+// watch.
+var ErrNotOnWatchlist = errors.New("film is not on the watchlist")
+
+// WatchlistRepo describes repository dependencies for watchlists.
+type WatchlistRepo interface {
+	// AddToWatchlist is a no-op for a film already on the watchlist.
+	AddToWatchlist(ctx context.Context, userID, filmID int) error
+	// RemoveFromWatchlist returns pgx.ErrNoRows when the film is not on
+	// the watchlist.
+	RemoveFromWatchlist(ctx context.Context, userID, filmID int) error
+	ListWatchlist(ctx context.Context, userID, limit, offset int) ([]models.WatchlistItem, int, error)
+}
+
+// FilmLookup is the subset of the film repository other services need.
+type FilmLookup interface {
+	GetFilmByID(ctx context.Context, id int) (*models.Film, error)
+}
+
+type WatchlistService struct {
+	repo  WatchlistRepo
+	films FilmLookup
+}
+
+func NewWatchlistService(repo WatchlistRepo, films FilmLookup) *WatchlistService {
+	return &WatchlistService{repo: repo, films: films}
+}
+
+// Add puts a film on the user's watchlist.
+func (s *WatchlistService) Add(ctx context.Context, userID, filmID int) error {
+	if _, err := s.films.GetFilmByID(ctx, filmID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrFilmNotFound
+		}
+		return fmt.Errorf("get film: %w", err)
+	}
+	if err := s.repo.AddToWatchlist(ctx, userID, filmID); err != nil {
+		return fmt.Errorf("add to watchlist: %w", err)
+	}
+	return nil
+}
+
+// Remove takes a film off the user's watchlist.
+func (s *WatchlistService) Remove(ctx context.Context, userID, filmID int) error {
+	if err := s.repo.RemoveFromWatchlist(ctx, userID, filmID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotOnWatchlist
+		}
+		return fmt.Errorf("remove from watchlist: %w", err)
+	}
+	return nil
+}
+
+// List returns page (1-based) of the user's watchlist, latest additions
+// first.
+func (s *WatchlistService) List(ctx context.Context, userID, page, limit int) (*models.WatchlistPage, error) {
+	page, limit = pageBounds(page, limit)
+	items, total, err := s.repo.ListWatchlist(ctx, userID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, fmt.Errorf("list watchlist: %w", err)
+	}
+	if items == nil {
+		items = []models.WatchlistItem{}
+	}
+	return &models.WatchlistPage{Items: items, Total: total, Page: page, Limit: limit}, nil
+}
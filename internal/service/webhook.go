@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+	"filmhub/pkg/outbox"
+)
+
+var (
+	// ErrWebhookNotFound is returned for an unknown subscription.
+	ErrWebhookNotFound = errors.New("webhook subscription not found")
+	// ErrDeliveryNotFound is returned for an unknown delivery, or one of
+	// another subscription.
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+	// ErrInvalidDeliveryStatus is returned when filtering deliveries by an
+	// unknown status.
+	ErrInvalidDeliveryStatus = errors.New("invalid delivery status")
+)
+
+// WebhookRepo describes repository dependencies for webhooks.
+type WebhookRepo interface {
+	CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) (int, error)
+	ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	// DeleteSubscription removes a subscription and returns it.
+	DeleteSubscription(ctx context.Context, id int) (*models.WebhookSubscription, error)
+	// Enqueue adds a delivery of an event for every subscription to its
+	// type, once per subscription and event.
+	Enqueue(ctx context.Context, eventID int64, eventType string, body []byte) error
+	// ListDeliveries returns pgx.ErrNoRows for an unknown subscription.
+	ListDeliveries(ctx context.Context, subscriptionID int, status string, limit, offset int) ([]models.WebhookDelivery, int, error)
+	// Redeliver makes a delivery of the subscription pending again with a
+	// fresh attempt count.
+	Redeliver(ctx context.Context, subscriptionID int, id int64) error
+}
+
+// WebhookService manages webhook subscriptions and their delivery log. It
+// is also an outbox.Sink queueing each event for the subscriptions to its
+// type; webhook.Sender sends the queued deliveries.
+type WebhookService struct {
+	repo  WebhookRepo
+	audit Auditor
+	tx    Transactor
+}
+
+func NewWebhookService(repo WebhookRepo) *WebhookService {
+	return &WebhookService{repo: repo}
+}
+
+// WithAudit records subscription changes and redeliveries with a.
+func (s *WebhookService) WithAudit(a Auditor) *WebhookService {
+	s.audit = a
+	return s
+}
+
+// WithTransactions stores subscription changes and redeliveries with their
+// audit entries in one unit of work with t.
+func (s *WebhookService) WithTransactions(t Transactor) *WebhookService {
+	s.tx = t
+	return s
+}
+
+// Create subscribes a URL to event types. The returned subscription carries
+// the signing secret, generated unless req has one; it is not shown again.
+func (s *WebhookService) Create(ctx context.Context, req *models.WebhookRequest, createdBy int) (*models.WebhookSubscription, error) {
+	secret := req.Secret
+	if secret == "" {
+		b := make([]byte, 24)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("generate webhook secret: %w", err)
+		}
+		secret = "whsec_" + hex.EncodeToString(b)
+	}
+	sub := &models.WebhookSubscription{URL: req.URL, EventTypes: req.EventTypes, Secret: secret, CreatedBy: &createdBy}
+	err := inTx(ctx, s.tx, func(ctx context.Context) error {
+		id, err := s.repo.CreateSubscription(ctx, sub)
+		if err != nil {
+			return fmt.Errorf("create webhook: %w", err)
+		}
+		sub.ID = id
+		return record(ctx, s.audit, models.AuditCreate, models.EntityWebhook, id, nil, webhookState{URL: sub.URL, EventTypes: sub.EventTypes})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// List returns every subscription, without secrets.
+func (s *WebhookService) List(ctx context.Context) ([]models.WebhookSubscription, error) {
+	subs, err := s.repo.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list webhooks: %w", err)
+	}
+	if subs == nil {
+		subs = []models.WebhookSubscription{}
+	}
+	return subs, nil
+}
+
+// Delete removes a subscription and its delivery log.
+func (s *WebhookService) Delete(ctx context.Context, id int) error {
+	return inTx(ctx, s.tx, func(ctx context.Context) error {
+		sub, err := s.repo.DeleteSubscription(ctx, id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrWebhookNotFound
+			}
+			return fmt.Errorf("delete webhook: %w", err)
+		}
+		return record(ctx, s.audit, models.AuditDelete, models.EntityWebhook, id, webhookState{URL: sub.URL, EventTypes: sub.EventTypes}, nil)
+	})
+}
+
+// Deliveries returns a page of the subscription's deliveries with status,
+// or all of them when status is empty.
+func (s *WebhookService) Deliveries(ctx context.Context, subscriptionID int, status string, page, limit int) (*models.WebhookDeliveryPage, error) {
+	switch status {
+	case "", models.DeliveryPending, models.DeliveryDelivered, models.DeliveryDead:
+	default:
+		return nil, ErrInvalidDeliveryStatus
+	}
+	page, limit = pageBounds(page, limit)
+	items, total, err := s.repo.ListDeliveries(ctx, subscriptionID, status, limit, (page-1)*limit)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("list webhook deliveries: %w", err)
+	}
+	if items == nil {
+		items = []models.WebhookDelivery{}
+	}
+	return &models.WebhookDeliveryPage{Items: items, Total: total, Page: page, Limit: limit}, nil
+}
+
+// Redeliver queues a delivery of the subscription again, whatever its
+// status: dead-lettered deliveries get a full set of attempts, delivered
+// ones are sent once more.
+func (s *WebhookService) Redeliver(ctx context.Context, subscriptionID int, id int64) error {
+	return inTx(ctx, s.tx, func(ctx context.Context) error {
+		if err := s.repo.Redeliver(ctx, subscriptionID, id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrDeliveryNotFound
+			}
+			return fmt.Errorf("redeliver webhook: %w", err)
+		}
+		return record(ctx, s.audit, models.AuditRedeliver, models.EntityWebhook, subscriptionID, nil, redeliveryState{DeliveryID: id})
+	})
+}
+
+func (s *WebhookService) Name() string { return "webhooks" }
+
+// Deliver queues e for the subscriptions to its type. The request body is
+// the event as JSON, the same for every subscription and attempt.
+func (s *WebhookService) Deliver(ctx context.Context, e outbox.Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return s.repo.Enqueue(ctx, e.ID, e.Type, body)
+}
+
+type webhookState struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+}
+
+type redeliveryState struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"filmhub/internal/models"
+	"filmhub/pkg/outbox"
+	"filmhub/pkg/webhook"
+)
+
+// memWebhookRepo is both the service's repository and the sender's queue.
+// Retry times are ignored: every pending delivery is due.
+type memWebhookRepo struct {
+	subs       []models.WebhookSubscription
+	secrets    map[int]string
+	deliveries []models.WebhookDelivery
+	bodies     map[int64][]byte
+}
+
+func newMemWebhookRepo() *memWebhookRepo {
+	return &memWebhookRepo{secrets: map[int]string{}, bodies: map[int64][]byte{}}
+}
+
+func (r *memWebhookRepo) CreateSubscription(_ context.Context, sub *models.WebhookSubscription) (int, error) {
+	id := len(r.subs) + 1
+	stored := *sub
+	stored.ID, stored.Secret = id, ""
+	r.subs = append(r.subs, stored)
+	r.secrets[id] = sub.Secret
+	return id, nil
+}
+
+func (r *memWebhookRepo) ListSubscriptions(_ context.Context) ([]models.WebhookSubscription, error) {
+	return r.subs, nil
+}
+
+func (r *memWebhookRepo) DeleteSubscription(_ context.Context, id int) (*models.WebhookSubscription, error) {
+	for i, s := range r.subs {
+		if s.ID == id {
+			r.subs = slices.Delete(r.subs, i, i+1)
+			return &s, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (r *memWebhookRepo) Enqueue(_ context.Context, eventID int64, eventType string, body []byte) error {
+	for _, s := range r.subs {
+		if !slices.Contains(s.EventTypes, eventType) || slices.ContainsFunc(r.deliveries, func(d models.WebhookDelivery) bool {
+			return d.SubscriptionID == s.ID && d.EventID == eventID
+		}) {
+			continue
+		}
+		id := int64(len(r.deliveries) + 1)
+		r.deliveries = append(r.deliveries, models.WebhookDelivery{
+			ID: id, SubscriptionID: s.ID, EventID: eventID, EventType: eventType, Status: models.DeliveryPending,
+		})
+		r.bodies[id] = body
+	}
+	return nil
+}
+
+func (r *memWebhookRepo) ListDeliveries(_ context.Context, subscriptionID int, status string, _, _ int) ([]models.WebhookDelivery, int, error) {
+	var out []models.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.SubscriptionID == subscriptionID && (status == "" || d.Status == status) {
+			out = append(out, d)
+		}
+	}
+	return out, len(out), nil
+}
+
+func (r *memWebhookRepo) Redeliver(_ context.Context, subscriptionID int, id int64) error {
+	d := r.delivery(id)
+	if d == nil || d.SubscriptionID != subscriptionID {
+		return pgx.ErrNoRows
+	}
+	d.Status, d.Attempts = models.DeliveryPending, 0
+	return nil
+}
+
+func (r *memWebhookRepo) Claim(_ context.Context, limit int, _ time.Duration) ([]webhook.Delivery, error) {
+	var out []webhook.Delivery
+	for _, d := range r.deliveries {
+		if d.Status != models.DeliveryPending || len(out) == limit {
+			continue
+		}
+		var url string
+		for _, s := range r.subs {
+			if s.ID == d.SubscriptionID {
+				url = s.URL
+			}
+		}
+		out = append(out, webhook.Delivery{
+			ID: d.ID, URL: url, Secret: r.secrets[d.SubscriptionID], EventType: d.EventType, Body: r.bodies[d.ID], Attempts: d.Attempts,
+		})
+	}
+	return out, nil
+}
+
+func (r *memWebhookRepo) MarkDelivered(_ context.Context, id int64, a webhook.Attempt) error {
+	d := r.delivery(id)
+	d.Status, d.LastStatusCode = models.DeliveryDelivered, &a.StatusCode
+	return nil
+}
+
+func (r *memWebhookRepo) MarkFailed(_ context.Context, id int64, a webhook.Attempt, retryAt *time.Time) error {
+	d := r.delivery(id)
+	d.Attempts++
+	d.LastError = &a.Error
+	if retryAt == nil {
+		d.Status = models.DeliveryDead
+	}
+	return nil
+}
+
+func (r *memWebhookRepo) delivery(id int64) *models.WebhookDelivery {
+	for i := range r.deliveries {
+		if r.deliveries[i].ID == id {
+			return &r.deliveries[i]
+		}
+	}
+	return nil
+}
+
+func TestWebhookService_EndToEnd(t *testing.T) {
+	ctx := context.Background()
+	var down atomic.Bool
+	var received []outbox.Event
+	partner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := webhook.Verify("whsec_partner_secret", r.Header.Get(webhook.SignatureHeader), body, time.Minute, time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if down.Load() {
+			http.Error(w, "maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		var e outbox.Event
+		_ = json.Unmarshal(body, &e)
+		received = append(received, e)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer partner.Close()
+
+	repo := newMemWebhookRepo()
+	svc := NewWebhookService(repo)
+	sub, err := svc.Create(ctx, &models.WebhookRequest{
+		URL: partner.URL, EventTypes: []string{models.EventFilmCreated}, Secret: "whsec_partner_secret",
+	}, 1)
+	if err != nil || sub.Secret != "whsec_partner_secret" {
+		t.Fatalf("create webhook: %+v, %v", sub, err)
+	}
+	generated, _ := svc.Create(ctx, &models.WebhookRequest{URL: partner.URL + "/other", EventTypes: []string{models.EventUserRegistered}}, 1)
+	if !strings.HasPrefix(generated.Secret, "whsec_") || len(generated.Secret) != 54 {
+		t.Fatalf("expected a generated secret, got %q", generated.Secret)
+	}
+
+	// The outbox hands events to the service as a sink, possibly twice.
+	film := outbox.Event{ID: 7, Type: models.EventFilmCreated, AggregateID: 3, Payload: json.RawMessage(`{"id":3}`)}
+	for _, e := range []outbox.Event{film, film, {ID: 8, Type: models.EventReviewCreated, AggregateID: 1}} {
+		if err := svc.Deliver(ctx, e); err != nil {
+			t.Fatalf("deliver %d: %v", e.ID, err)
+		}
+	}
+	if len(repo.deliveries) != 1 {
+		t.Fatalf("expected one delivery for the subscribed event, got %+v", repo.deliveries)
+	}
+
+	sender := webhook.NewSender(repo, webhook.Options{MaxAttempts: 2, Client: partner.Client()})
+	down.Store(true)
+	sender.SendOnce(ctx)
+	sender.SendOnce(ctx)
+	log, err := svc.Deliveries(ctx, sub.ID, models.DeliveryDead, 1, 20)
+	if err != nil || log.Total != 1 || log.Items[0].Attempts != 2 {
+		t.Fatalf("expected the delivery dead-lettered after two attempts, got %+v, %v", log, err)
+	}
+
+	down.Store(false)
+	if err := svc.Redeliver(ctx, sub.ID, log.Items[0].ID); err != nil {
+		t.Fatalf("redeliver: %v", err)
+	}
+	if n, _ := sender.SendOnce(ctx); n != 1 {
+		t.Fatalf("expected the redelivery to be sent, got %d deliveries", n)
+	}
+	if len(received) != 1 || received[0].ID != 7 || string(received[0].Payload) != `{"id":3}` {
+		t.Fatalf("unexpected deliveries at the partner: %+v", received)
+	}
+	if log, _ := svc.Deliveries(ctx, sub.ID, models.DeliveryDelivered, 1, 20); log.Total != 1 {
+		t.Fatalf("expected the delivery logged as delivered, got %+v", log)
+	}
+
+	if err := svc.Redeliver(ctx, generated.ID, log.Items[0].ID); !errors.Is(err, ErrDeliveryNotFound) {
+		t.Fatalf("expected another subscription's delivery to be refused, got %v", err)
+	}
+	if _, err := svc.Deliveries(ctx, sub.ID, "lost", 1, 20); !errors.Is(err, ErrInvalidDeliveryStatus) {
+		t.Fatalf("expected an invalid status error, got %v", err)
+	}
+	if err := svc.Delete(ctx, 9); !errors.Is(err, ErrWebhookNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+-- Every change to a film's editable fields is kept as a numbered revision.
+-- Rollbacks add a new revision that copies an older one, so history is
+-- never rewritten.
+CREATE TABLE IF NOT EXISTS film_revisions (
+    id SERIAL PRIMARY KEY,
+    film_id INT NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    revision INT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    release_date DATE,
+    author_id INT REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(16) NOT NULL,
+    restored_from INT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (film_id, revision)
+);
+
+-- Films created before revisions existed start their history at revision 1.
+INSERT INTO film_revisions (film_id, revision, title, description, release_date, author_id, action, created_at)
+SELECT id, 1, title, description, release_date, COALESCE(updated_by, created_by), 'create', COALESCE(updated_at, created_at, now())
+FROM films
+ON CONFLICT (film_id, revision) DO NOTHING;
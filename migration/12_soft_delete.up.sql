@@ -0,0 +1,9 @@
+-- Deleted films and reviews go to the trash first; a background job purges
+-- them after the retention period.
+ALTER TABLE films ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE films ADD COLUMN IF NOT EXISTS deleted_by INT REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS deleted_by INT REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS films_deleted_idx ON films (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS reviews_deleted_idx ON reviews (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,5 @@
+-- Row versions for optimistic concurrency: every edit bumps the version and
+-- is only applied when the client saw the current one (If-Match).
+ALTER TABLE films ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ;
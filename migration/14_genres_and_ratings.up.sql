@@ -0,0 +1,19 @@
+-- Film genres, set when films are imported.
+CREATE TABLE IF NOT EXISTS genres (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(64) UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS film_genres (
+    film_id INT NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    genre_id INT NOT NULL REFERENCES genres(id),
+    PRIMARY KEY (film_id, genre_id)
+);
+
+-- films.rating is the average rating of the film's visible reviews; it is
+-- kept up to date together with the reviews.
+UPDATE films SET rating = COALESCE((
+    SELECT avg(r.rating) FROM reviews r
+    WHERE r.film_id = films.id AND r.deleted_at IS NULL AND r.hidden_at IS NULL), 0);
+ALTER TABLE films ALTER COLUMN rating SET DEFAULT 0;
+ALTER TABLE films ALTER COLUMN rating SET NOT NULL;
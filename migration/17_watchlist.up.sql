@@ -0,0 +1,10 @@
+-- Films users want to watch; the watchers of a film are notified when it
+-- gets a release date.
+CREATE TABLE IF NOT EXISTS watchlist (
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    film_id INT NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, film_id)
+);
+
+CREATE INDEX IF NOT EXISTS watchlist_film_idx ON watchlist (film_id);
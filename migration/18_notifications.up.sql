@@ -0,0 +1,26 @@
+-- In-app notifications. Each row points at what it is about; the actor is
+-- the user who caused it, if any.
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL,
+    actor_id INT REFERENCES users(id) ON DELETE SET NULL,
+    film_id INT REFERENCES films(id) ON DELETE CASCADE,
+    review_id INT REFERENCES reviews(id) ON DELETE CASCADE,
+    comment_id INT REFERENCES review_comments(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    read_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_idx ON notifications (user_id, id DESC);
+CREATE INDEX IF NOT EXISTS notifications_unread_idx ON notifications (user_id) WHERE read_at IS NULL;
+-- Toggling a helpful vote must not notify the author again.
+CREATE UNIQUE INDEX IF NOT EXISTS notifications_like_once ON notifications (user_id, actor_id, review_id) WHERE type = 'like';
+
+-- Types a user has switched off; every type is on by default.
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    PRIMARY KEY (user_id, type)
+);
@@ -0,0 +1,17 @@
+-- Item-item similarities of films computed from review ratings. The
+-- recommendation job replaces the whole table on every run. Each film keeps
+-- only its most similar films, so a pair may be stored in one direction.
+CREATE TABLE IF NOT EXISTS film_similarities (
+    film_id INT NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    similar_film_id INT NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    score REAL NOT NULL,
+    support INT NOT NULL,
+    PRIMARY KEY (film_id, similar_film_id)
+);
+
+-- When the similarities were last computed; a single row.
+CREATE TABLE IF NOT EXISTS film_similarity_runs (
+    id BOOLEAN PRIMARY KEY DEFAULT true CHECK (id),
+    computed_at TIMESTAMPTZ NOT NULL,
+    pairs INT NOT NULL
+);
@@ -0,0 +1,14 @@
+-- Cast and crew of films, set when films are imported.
+CREATE TABLE IF NOT EXISTS people (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS film_credits (
+    film_id INT NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    person_id INT NOT NULL REFERENCES people(id),
+    role VARCHAR(32) NOT NULL,
+    PRIMARY KEY (film_id, person_id, role)
+);
+
+CREATE INDEX IF NOT EXISTS film_credits_person_id_idx ON film_credits (person_id);
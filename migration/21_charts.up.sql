@@ -0,0 +1,17 @@
+-- Film charts (trending, top rated, new releases, most watchlisted). The
+-- chart job replaces a chart's entries on every run and keeps each film's
+-- rank from the run before.
+CREATE TABLE IF NOT EXISTS chart_entries (
+    chart VARCHAR(32) NOT NULL,
+    rank INT NOT NULL,
+    film_id INT NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    previous_rank INT,
+    PRIMARY KEY (chart, rank)
+);
+
+-- When each chart was last computed.
+CREATE TABLE IF NOT EXISTS chart_runs (
+    chart VARCHAR(32) PRIMARY KEY,
+    computed_at TIMESTAMPTZ NOT NULL
+);
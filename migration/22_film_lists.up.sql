@@ -0,0 +1,42 @@
+-- User-curated lists of films. Entries are kept numbered 1..n by position;
+-- changes to a list's entries lock its row.
+CREATE TABLE IF NOT EXISTS film_lists (
+    id SERIAL PRIMARY KEY,
+    owner_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    visibility VARCHAR(16) NOT NULL DEFAULT 'public' CHECK (visibility IN ('public', 'unlisted', 'private')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS film_lists_owner_idx ON film_lists (owner_id);
+
+CREATE TABLE IF NOT EXISTS film_list_entries (
+    list_id INT NOT NULL REFERENCES film_lists(id) ON DELETE CASCADE,
+    film_id INT NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    added_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (list_id, film_id)
+);
+
+CREATE INDEX IF NOT EXISTS film_list_entries_film_idx ON film_list_entries (film_id);
+
+-- Users other than the owner who may edit a list's entries.
+CREATE TABLE IF NOT EXISTS film_list_collaborators (
+    list_id INT NOT NULL REFERENCES film_lists(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (list_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS film_list_collaborators_user_idx ON film_list_collaborators (user_id);
+
+CREATE TABLE IF NOT EXISTS film_list_likes (
+    list_id INT NOT NULL REFERENCES film_lists(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (list_id, user_id)
+);
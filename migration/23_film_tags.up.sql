@@ -0,0 +1,11 @@
+-- Free-form film tags, set when films are imported.
+CREATE TABLE IF NOT EXISTS tags (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(64) UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS film_tags (
+    film_id INT NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    tag_id INT NOT NULL REFERENCES tags(id),
+    PRIMARY KEY (film_id, tag_id)
+);
@@ -0,0 +1,3 @@
+-- Marks the content filter reports that held a review when it was posted,
+-- as opposed to on a later edit.
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS held_on_create BOOLEAN NOT NULL DEFAULT false;
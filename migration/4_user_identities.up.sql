@@ -0,0 +1,10 @@
+-- External OIDC identities linked to local accounts.
+CREATE TABLE IF NOT EXISTS user_identities (
+    provider VARCHAR(64) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255),
+    created_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (provider, subject),
+    UNIQUE (user_id, provider)
+);
@@ -0,0 +1,14 @@
+-- One helpful/unhelpful vote per user and review; the counters on reviews are
+-- kept in sync by the repository so listings can sort by them.
+CREATE TABLE IF NOT EXISTS review_votes (
+    review_id INT NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    value SMALLINT NOT NULL CHECK (value IN (-1, 1)),
+    created_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (review_id, user_id)
+);
+
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS helpful_count INT NOT NULL DEFAULT 0;
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS unhelpful_count INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS reviews_film_helpful_idx ON reviews (film_id, (helpful_count - unhelpful_count) DESC, created_at DESC);
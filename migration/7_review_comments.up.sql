@@ -0,0 +1,13 @@
+-- Comments on reviews with a single level of replies.
+CREATE TABLE IF NOT EXISTS review_comments (
+    id SERIAL PRIMARY KEY,
+    review_id INT NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    parent_id INT REFERENCES review_comments(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS review_comments_review_idx ON review_comments (review_id, created_at) WHERE parent_id IS NULL;
+CREATE INDEX IF NOT EXISTS review_comments_parent_idx ON review_comments (parent_id, created_at);
@@ -0,0 +1,36 @@
+-- Hidden content stays in place for its author but is left out of public
+-- listings.
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMPTZ;
+ALTER TABLE review_comments ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMPTZ;
+
+-- User reports on reviews and comments. A user has at most one open report
+-- per target.
+CREATE TABLE IF NOT EXISTS reports (
+    id SERIAL PRIMARY KEY,
+    target_type VARCHAR(16) NOT NULL CHECK (target_type IN ('review', 'comment')),
+    target_id INT NOT NULL,
+    reporter_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(32) NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'open',
+    created_at TIMESTAMPTZ DEFAULT now(),
+    resolved_at TIMESTAMPTZ,
+    resolved_by INT REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS reports_open_uniq ON reports (target_type, target_id, reporter_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS reports_status_idx ON reports (status, created_at);
+
+-- Audit trail of moderator actions.
+CREATE TABLE IF NOT EXISTS moderation_log (
+    id SERIAL PRIMARY KEY,
+    moderator_id INT REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(16) NOT NULL,
+    target_type VARCHAR(16) NOT NULL,
+    target_id INT NOT NULL,
+    report_id INT REFERENCES reports(id) ON DELETE SET NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS moderation_log_target_idx ON moderation_log (target_type, target_id);
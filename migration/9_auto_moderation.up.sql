@@ -0,0 +1,3 @@
+-- Reviews held by the automatic content filter are queued as reports
+-- without a reporter.
+ALTER TABLE reports ALTER COLUMN reporter_id DROP NOT NULL;
@@ -0,0 +1,93 @@
+// Package audit carries who is behind a request through the context and
+// computes the before/after diff stored with each audit log entry.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Actor describes the caller of the current request. UserID is 0 for
+// anonymous requests.
+type Actor struct {
+	UserID    int
+	Role      string
+	IP        string
+	RequestID string
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx carrying a.
+func WithActor(ctx context.Context, a Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, a)
+}
+
+// ActorFrom returns the actor stored in ctx, or the zero Actor.
+func ActorFrom(ctx context.Context) Actor {
+	a, _ := ctx.Value(actorKey{}).(Actor)
+	return a
+}
+
+// Middleware stores the Actor in the request context. It reads "user_id" and
+// "role" set by login.AuthMiddleware and "request_id" set by
+// middleware.RequestID, so it must run after both. The IP comes from
+// X-Forwarded-For only when the peer is one of the engine's trusted proxies.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(WithActor(c.Request.Context(), Actor{
+			UserID:    c.GetInt("user_id"),
+			Role:      c.GetString("role"),
+			IP:        c.ClientIP(),
+			RequestID: c.GetString("request_id"),
+		}))
+		c.Next()
+	}
+}
+
+// Diff marshals before and after to JSON objects and returns only the keys
+// whose values differ. A nil side, as for creations, yields a nil map while
+// the other side keeps every key.
+func Diff(before, after any) (map[string]any, map[string]any, error) {
+	b, err := toMap(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := toMap(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	if b == nil || a == nil {
+		return b, a, nil
+	}
+	changedBefore, changedAfter := map[string]any{}, map[string]any{}
+	for k, v := range b {
+		if w, ok := a[k]; !ok || !reflect.DeepEqual(v, w) {
+			changedBefore[k] = v
+		}
+	}
+	for k, w := range a {
+		if v, ok := b[k]; !ok || !reflect.DeepEqual(v, w) {
+			changedAfter[k] = w
+		}
+	}
+	return changedBefore, changedAfter, nil
+}
+
+func toMap(v any) (map[string]any, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil()) {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type film struct {
+	Title string `json:"title"`
+	Year  int    `json:"year"`
+	Notes string `json:"-"`
+}
+
+func TestDiff(t *testing.T) {
+	before, after, err := Diff(&film{Title: "Matrix", Year: 1999, Notes: "a"}, &film{Title: "The Matrix", Year: 1999, Notes: "b"})
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	if len(before) != 1 || before["title"] != "Matrix" || len(after) != 1 || after["title"] != "The Matrix" {
+		t.Fatalf("expected only the title to differ, got %v -> %v", before, after)
+	}
+
+	var none *film
+	before, after, _ = Diff(none, film{Title: "Alien", Year: 1979})
+	if before != nil || len(after) != 2 {
+		t.Fatalf("expected a creation to keep every field, got %v -> %v", before, after)
+	}
+}
+
+func TestActorContext(t *testing.T) {
+	if a := ActorFrom(context.Background()); a != (Actor{}) {
+		t.Fatalf("expected zero actor, got %+v", a)
+	}
+	ctx := WithActor(context.Background(), Actor{UserID: 3, IP: "10.0.0.1", RequestID: "abc"})
+	if a := ActorFrom(ctx); a.UserID != 3 || a.RequestID != "abc" {
+		t.Fatalf("unexpected actor %+v", a)
+	}
+}
+
+func TestMiddleware_ForwardedForOnlyFromTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, tc := range []struct {
+		name    string
+		trusted []string
+		want    string
+	}{
+		{"no proxies trusted", nil, "203.0.113.9"},
+		{"peer is a trusted proxy", []string{"203.0.113.0/24"}, "198.51.100.7"},
+		{"peer is another proxy", []string{"10.0.0.0/8"}, "203.0.113.9"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := gin.New()
+			if err := r.SetTrustedProxies(tc.trusted); err != nil {
+				t.Fatalf("set trusted proxies: %v", err)
+			}
+			var got Actor
+			r.GET("/", Middleware(), func(c *gin.Context) { got = ActorFrom(c.Request.Context()) })
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "203.0.113.9:4242"
+			req.Header.Set("X-Forwarded-For", "198.51.100.7")
+			r.ServeHTTP(httptest.NewRecorder(), req)
+			if got.IP != tc.want {
+				t.Fatalf("expected ip %s, got %s", tc.want, got.IP)
+			}
+		})
+	}
+}
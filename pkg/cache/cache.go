@@ -0,0 +1,123 @@
+// Package cache provides the Cache interface read-through caches are built
+// on, an in-process LRU implementation with expiry, and Group, which
+// collapses concurrent loads of the same key.
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores values by key. Implementations must be safe for concurrent
+// use; a shared cache such as Redis can be plugged in behind it.
+type Cache interface {
+	// Get returns the value stored under key, if any and not expired.
+	Get(key string) (any, bool)
+	// Set stores value under key.
+	Set(key string, value any)
+	// Delete removes keys.
+	Delete(keys ...string)
+	// DeletePrefix removes every key starting with prefix.
+	DeletePrefix(prefix string)
+	// Len returns the number of stored entries, expired ones included until
+	// they are evicted.
+	Len() int
+}
+
+type entry struct {
+	key     string
+	value   any
+	expires time.Time
+}
+
+// LRU is an in-process Cache holding at most capacity entries, each for at
+// most ttl. When full, the least recently used entry is evicted.
+type LRU struct {
+	capacity int
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu    sync.Mutex
+	order *list.List // front is the most recently used
+	items map[string]*list.Element
+}
+
+// NewLRU returns an LRU holding up to capacity entries for ttl each; a ttl of
+// 0 keeps entries until they are evicted.
+func NewLRU(capacity int, ttl time.Duration) *LRU {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LRU{
+		capacity: capacity,
+		ttl:      ttl,
+		now:      time.Now,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *LRU) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*entry)
+	if c.ttl > 0 && !c.now().Before(e.expires) {
+		c.remove(el)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return e.value, true
+}
+
+func (c *LRU) Set(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expires := c.now().Add(c.ttl)
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry)
+		e.value, e.expires = value, expires
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&entry{key: key, value: value, expires: expires})
+	if c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *LRU) Delete(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		if el, ok := c.items[key]; ok {
+			c.remove(el)
+		}
+	}
+}
+
+func (c *LRU) DeletePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, el := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.remove(el)
+		}
+	}
+}
+
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*entry).key)
+}
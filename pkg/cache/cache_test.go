@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewLRU(2, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	// b is now the least recently used entry.
+	c.Set("c", 3)
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	if v, ok := c.Get("c"); !ok || v != 3 {
+		t.Fatalf("expected c=3, got %v, %v", v, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected a to expire")
+	}
+	if c.Len() != 1 {
+		t.Fatalf("expected the expired entry to be dropped, got %d entries", c.Len())
+	}
+
+	c.Set("films:1", 1)
+	c.Set("films:2", 2)
+	c.DeletePrefix("films:")
+	c.Delete("c")
+	if c.Len() != 0 {
+		t.Fatalf("expected an empty cache, got %d entries", c.Len())
+	}
+}
+
+func TestGroupCollapsesConcurrentCalls(t *testing.T) {
+	var g Group
+	var calls atomic.Int32
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	var wg sync.WaitGroup
+	results := make([]any, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _, _ = g.Do("film:1", func() (any, error) {
+				calls.Add(1)
+				close(started)
+				<-release
+				return "matrix", nil
+			})
+		}()
+		if i == 0 {
+			<-started
+		}
+	}
+	// Give the other callers time to join the call in flight.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected one call, got %d", calls.Load())
+	}
+	for i, v := range results {
+		if v != "matrix" {
+			t.Fatalf("caller %d got %v", i, v)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package cache
+
+import "sync"
+
+type call struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+// Group collapses concurrent calls for the same key into one: while a call
+// for key is in flight, later callers wait for it and share its result.
+// The zero Group is ready to use.
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// Do runs fn for key unless a call for key is already in flight, in which
+// case it waits for that call. shared reports whether the result came from
+// another caller's call.
+func (g *Group) Do(key string, fn func() (any, error)) (value any, err error, shared bool) {
+	g.mu.Lock()
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-c.done
+		return c.value, c.err, true
+	}
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	c := &call{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+		close(c.done)
+	}()
+	c.value, c.err = fn()
+	return c.value, c.err, false
+}
+
+// Forget makes the next Do for key start a new call even if one is in
+// flight, so that a load racing with an invalidation is not handed to
+// later callers.
+func (g *Group) Forget(key string) {
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+}
@@ -0,0 +1,99 @@
+// Package charts scores and ranks items for charts such as trending or top
+// rated.
+package charts
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Score is an item and the value it is ranked by, higher first.
+type Score struct {
+	ItemID int
+	Value  float64
+}
+
+// Activity is one event that makes an item trend, such as a review.
+type Activity struct {
+	ItemID int
+	At     time.Time
+}
+
+// RatingSummary is how many ratings an item has and their mean.
+type RatingSummary struct {
+	ItemID int
+	Count  int
+	Mean   float64
+}
+
+// Entry is an item's place in a chart. PreviousRank is its rank in the
+// previous computation of the chart, or 0 if it was not in it.
+type Entry struct {
+	ItemID       int
+	Rank         int
+	Score        float64
+	PreviousRank int
+}
+
+// Trending scores items by their activity since now-window, each event
+// weighted by its age so that it counts half as much every halfLife.
+// Recent bursts of activity therefore outrank steady older activity.
+func Trending(events []Activity, now time.Time, window, halfLife time.Duration) []Score {
+	since := now.Add(-window)
+	sums := map[int]float64{}
+	for _, e := range events {
+		if e.At.Before(since) || e.At.After(now) {
+			continue
+		}
+		sums[e.ItemID] += math.Exp2(-float64(now.Sub(e.At)) / float64(halfLife))
+	}
+	out := make([]Score, 0, len(sums))
+	for id, v := range sums {
+		out = append(out, Score{ItemID: id, Value: v})
+	}
+	return out
+}
+
+// TopRated scores the items with at least minCount ratings by their
+// Bayesian average: the mean is pulled towards the mean of all ratings as if
+// every item had minCount more ratings of that value, so that a few high
+// ratings do not outrank many good ones.
+func TopRated(items []RatingSummary, minCount int) []Score {
+	var total, count float64
+	for _, it := range items {
+		total += it.Mean * float64(it.Count)
+		count += float64(it.Count)
+	}
+	if count == 0 {
+		return nil
+	}
+	prior, m := total/count, float64(max(minCount, 0))
+	var out []Score
+	for _, it := range items {
+		if it.Count < minCount || it.Count == 0 {
+			continue
+		}
+		v := float64(it.Count)
+		out = append(out, Score{ItemID: it.ItemID, Value: (v*it.Mean + m*prior) / (v + m)})
+	}
+	return out
+}
+
+// Rank orders scores from highest to lowest, ties by item ID, and returns
+// the first limit as entries numbered from 1, with the ranks previous held
+// before.
+func Rank(scores []Score, limit int, previous map[int]int) []Entry {
+	sorted := append([]Score(nil), scores...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Value != sorted[j].Value {
+			return sorted[i].Value > sorted[j].Value
+		}
+		return sorted[i].ItemID < sorted[j].ItemID
+	})
+	out := make([]Entry, 0, min(len(sorted), limit))
+	for i, s := range sorted[:min(len(sorted), limit)] {
+		out = append(out, Entry{ItemID: s.ItemID, Rank: i + 1, Score: s.Value, PreviousRank: previous[s.ItemID]})
+	}
+	return out
+}
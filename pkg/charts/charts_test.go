@@ -0,0 +1,69 @@
+package charts
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTrending(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	events := []Activity{
+		// Item 1: three reviews a week ago.
+		{1, now.Add(-6 * day)}, {1, now.Add(-6 * day)}, {1, now.Add(-6 * day)},
+		// Item 2: two reviews today.
+		{2, now.Add(-time.Hour)}, {2, now},
+		// Item 3: many reviews, but outside the window.
+		{3, now.Add(-30 * day)}, {3, now.Add(-31 * day)},
+	}
+	scores := map[int]float64{}
+	for _, s := range Trending(events, now, 7*day, 2*day) {
+		scores[s.ItemID] = s.Value
+	}
+	if _, ok := scores[3]; ok || len(scores) != 2 {
+		t.Fatalf("expected activity outside the window to be ignored, got %v", scores)
+	}
+	if math.Abs(scores[1]-3.0/8) > 1e-9 {
+		t.Fatalf("expected three reviews three half-lives old to score 3/8, got %v", scores[1])
+	}
+	if scores[2] <= scores[1] {
+		t.Fatalf("expected the recent burst to outrank older activity, got %v", scores)
+	}
+}
+
+func TestTopRated(t *testing.T) {
+	// The mean of all 40 ratings is 7.125.
+	items := []RatingSummary{
+		{ItemID: 1, Count: 2, Mean: 10},
+		{ItemID: 2, Count: 30, Mean: 7.4},
+		{ItemID: 3, Count: 5, Mean: 8},
+		{ItemID: 4, Count: 3, Mean: 1},
+	}
+	scores := map[int]float64{}
+	for _, s := range TopRated(items, 5) {
+		scores[s.ItemID] = s.Value
+	}
+	if len(scores) != 2 {
+		t.Fatalf("expected items below the minimum count to be left out, got %v", scores)
+	}
+	if math.Abs(scores[3]-(5*8+5*7.125)/10) > 1e-9 || math.Abs(scores[2]-(30*7.4+5*7.125)/35) > 1e-9 {
+		t.Fatalf("unexpected Bayesian averages %v", scores)
+	}
+	if TopRated(nil, 5) != nil {
+		t.Fatal("expected no scores without ratings")
+	}
+}
+
+func TestRank(t *testing.T) {
+	got := Rank([]Score{{3, 1}, {1, 5}, {2, 5}, {4, 0.5}}, 3, map[int]int{2: 1, 3: 1, 9: 2})
+	want := []Entry{{1, 1, 5, 0}, {2, 2, 5, 1}, {3, 3, 1, 1}}
+	if len(got) != len(want) {
+		t.Fatalf("Rank = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Rank = %+v, want %+v", got, want)
+		}
+	}
+}
@@ -1,36 +1,420 @@
 package config
 
-import "os"
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Config holds application configuration loaded from environment variables.
 type Config struct {
-    AppEnv     string
-    DBHost     string
-    DBPort     string
-    DBUser     string
-    DBPassword string
-    DBName     string
-    JWTSecret  string
-    SentryDSN  string
+	AppEnv     string
+	DBHost     string
+	DBPort     string
+	DBUser     string
+	DBPassword string
+	DBName     string
+	JWTSecret  string
+	SentryDSN  string
+
+	// DBTxAttempts is how many times a unit of work is tried when it hits
+	// a serialization failure or a deadlock.
+	DBTxAttempts int
+
+	// JWT signing
+	JWTAlgorithm   string
+	JWTKeysDir     string
+	JWTRotateEvery time.Duration
+	JWTKeyRetain   time.Duration
+	JWTTTL         time.Duration
+	JWTIssuer      string
+	JWTAudience    string
+
+	// HTTP listener
+	HTTPAddr     string
+	TLSCertFile  string
+	TLSKeyFile   string
+	MaxBodyBytes int64
+	// TrustedProxies are the addresses or CIDRs whose X-Forwarded-For header
+	// is believed when resolving the client IP; empty trusts none.
+	TrustedProxies []string
+
+	// CORS and security headers
+	CORSAllowedOrigins    []string
+	CORSAllowCredentials  bool
+	ContentSecurityPolicy string
+
+	// Email
+	AppBaseURL   string
+	TokenSecret  string
+	MailDriver   string
+	MailFrom     string
+	MailFile     string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+
+	// RequireVerifiedEmail blocks unverified users from posting reviews.
+	RequireVerifiedEmail bool
+
+	// OpenID Connect login; disabled while OIDCIssuer is empty.
+	OIDCProviderName string
+	OIDCIssuer       string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCRedirectURL  string
+	OIDCScopes       []string
+
+	// Automatic review filter; see pkg/contentfilter.
+	ContentFilterEnabled     bool
+	ContentFilterWords       []string
+	ContentFilterWordsFile   string
+	ContentFilterMaxLinks    int
+	ContentFilterHoldScore   float64
+	ContentFilterRejectScore float64
+
+	// Soft-deleted films and reviews are purged for good once they have
+	// been in the trash for TrashRetention; TrashPurgeEvery 0 disables it.
+	TrashRetention  time.Duration
+	TrashPurgeEvery time.Duration
+
+	// Domain events are delivered from the outbox to these sinks: log,
+	// webhook (OutboxWebhookURL) and notify (Postgres NOTIFY).
+	OutboxSinks         []string
+	OutboxWebhookURL    string
+	OutboxNotifyChannel string
+	OutboxPollInterval  time.Duration
+	OutboxMaxAttempts   int
+
+	// Outgoing webhooks to admin-managed subscriptions: attempts before a
+	// delivery is dead-lettered, the delay after the first failure and the
+	// timeout of one request.
+	WebhookMaxAttempts int
+	WebhookBackoff     time.Duration
+	WebhookTimeout     time.Duration
+
+	// In-process cache of film reads; FilmCacheSize 0 disables it.
+	FilmCacheSize int
+	FilmCacheTTL  time.Duration
+
+	// Live updates over SSE: how many recent events are kept for clients
+	// resuming with Last-Event-ID, and how often idle streams get a
+	// heartbeat.
+	SSEHistory   int
+	SSEHeartbeat time.Duration
+
+	// Recommendations: how often film similarities are recomputed from
+	// review ratings (0 disables it) and how many users must have rated
+	// both films of a pair.
+	RecommendRefreshEvery time.Duration
+	RecommendMinSupport   int
+
+	// Similar films: the relative weights of shared genres, shared tags,
+	// shared people, rating similarity and description similarity, and how
+	// many films' results are cached for how long (SimilarCacheSize 0
+	// disables it).
+	SimilarWeightGenres  float64
+	SimilarWeightTags    float64
+	SimilarWeightPeople  float64
+	SimilarWeightRatings float64
+	SimilarWeightText    float64
+	SimilarCacheSize     int
+	SimilarCacheTTL      time.Duration
+
+	// Charts: how often they are recomputed (0 disables it), how many films
+	// each keeps, the trending window and half-life of a review's weight,
+	// the reviews a film needs to be top rated and how long a release
+	// counts as new.
+	ChartsRefreshEvery       time.Duration
+	ChartsSize               int
+	ChartsTrendingWindow     time.Duration
+	ChartsTrendingHalfLife   time.Duration
+	ChartsTopRatedMinReviews int
+	ChartsNewReleasesWindow  time.Duration
 }
 
 func Load() (*Config, error) {
-    cfg := &Config{
-        AppEnv:     getenv("APP_ENV", "dev"),
-        DBHost:     getenv("DB_HOST", "localhost"),
-        DBPort:     getenv("DB_PORT", "5432"),
-        DBUser:     getenv("DB_USER", "postgres"),
-        DBPassword: getenv("DB_PASSWORD", "postgres"),
-        DBName:     getenv("DB_NAME", "filmhub"),
-        JWTSecret:  getenv("JWT_SECRET", "supersecretkey"),
-        SentryDSN:  getenv("SENTRY_DSN", ""),
-    }
-    return cfg, nil
+	cfg := &Config{
+		AppEnv:     getenv("APP_ENV", "dev"),
+		DBHost:     getenv("DB_HOST", "localhost"),
+		DBPort:     getenv("DB_PORT", "5432"),
+		DBUser:     getenv("DB_USER", "postgres"),
+		DBPassword: getenv("DB_PASSWORD", "postgres"),
+		DBName:     getenv("DB_NAME", "filmhub"),
+		JWTSecret:  getenv("JWT_SECRET", "supersecretkey"),
+		SentryDSN:  getenv("SENTRY_DSN", ""),
+
+		JWTAlgorithm: getenv("JWT_ALGORITHM", "RS256"),
+		JWTKeysDir:   getenv("JWT_KEYS_DIR", ""),
+		JWTIssuer:    getenv("JWT_ISSUER", "filmhub"),
+		JWTAudience:  getenv("JWT_AUDIENCE", "filmhub-api"),
+
+		HTTPAddr:    getenv("HTTP_ADDR", ":8080"),
+		TLSCertFile: getenv("TLS_CERT_FILE", ""),
+		TLSKeyFile:  getenv("TLS_KEY_FILE", ""),
+
+		TrustedProxies: getenvList("TRUSTED_PROXIES", nil),
+
+		CORSAllowedOrigins:    getenvList("CORS_ALLOWED_ORIGINS", []string{"http://localhost:3000"}),
+		ContentSecurityPolicy: getenv("CONTENT_SECURITY_POLICY", "default-src 'self'; frame-ancestors 'none'"),
+
+		AppBaseURL:   getenv("APP_BASE_URL", "http://localhost:3000"),
+		MailDriver:   getenv("MAIL_DRIVER", "log"),
+		MailFrom:     getenv("MAIL_FROM", "FilmHub <no-reply@filmhub.local>"),
+		MailFile:     getenv("MAIL_FILE", "mail.log"),
+		SMTPHost:     getenv("SMTP_HOST", "localhost"),
+		SMTPPort:     getenv("SMTP_PORT", "587"),
+		SMTPUsername: getenv("SMTP_USERNAME", ""),
+		SMTPPassword: getenv("SMTP_PASSWORD", ""),
+
+		OIDCProviderName: getenv("OIDC_PROVIDER_NAME", "oidc"),
+		OIDCIssuer:       getenv("OIDC_ISSUER", ""),
+		OIDCClientID:     getenv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret: getenv("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:  getenv("OIDC_REDIRECT_URL", "http://localhost:8080/auth/oidc/callback"),
+		OIDCScopes:       getenvList("OIDC_SCOPES", []string{"openid", "email", "profile"}),
+
+		ContentFilterWords:     getenvList("CONTENT_FILTER_WORDS", nil),
+		ContentFilterWordsFile: getenv("CONTENT_FILTER_WORDS_FILE", ""),
+
+		OutboxSinks:         getenvList("OUTBOX_SINKS", []string{"log"}),
+		OutboxWebhookURL:    getenv("OUTBOX_WEBHOOK_URL", ""),
+		OutboxNotifyChannel: getenv("OUTBOX_NOTIFY_CHANNEL", "filmhub_events"),
+	}
+	cfg.TokenSecret = getenv("TOKEN_SECRET", cfg.JWTSecret)
+
+	var err error
+	if cfg.MaxBodyBytes, err = getenvInt64("MAX_BODY_BYTES", 1<<20); err != nil {
+		return nil, err
+	}
+	if cfg.JWTTTL, err = getenvDuration("JWT_TTL", 24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.JWTRotateEvery, err = getenvDuration("JWT_ROTATE_EVERY", 30*24*time.Hour); err != nil {
+		return nil, err
+	}
+	// Keep replaced keys at least as long as the tokens they signed live.
+	if cfg.JWTKeyRetain, err = getenvDuration("JWT_KEY_RETAIN", 2*cfg.JWTTTL); err != nil {
+		return nil, err
+	}
+	if cfg.JWTKeyRetain < cfg.JWTTTL {
+		return nil, errors.New("JWT_KEY_RETAIN must not be shorter than JWT_TTL")
+	}
+	if cfg.CORSAllowCredentials, err = getenvBool("CORS_ALLOW_CREDENTIALS", false); err != nil {
+		return nil, err
+	}
+	if cfg.RequireVerifiedEmail, err = getenvBool("REQUIRE_VERIFIED_EMAIL", false); err != nil {
+		return nil, err
+	}
+	if cfg.ContentFilterEnabled, err = getenvBool("CONTENT_FILTER_ENABLED", true); err != nil {
+		return nil, err
+	}
+	maxLinks, err := getenvInt64("CONTENT_FILTER_MAX_LINKS", 1)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ContentFilterMaxLinks = int(maxLinks)
+	if cfg.ContentFilterHoldScore, err = getenvFloat("CONTENT_FILTER_HOLD_SCORE", 1); err != nil {
+		return nil, err
+	}
+	if cfg.ContentFilterRejectScore, err = getenvFloat("CONTENT_FILTER_REJECT_SCORE", 3); err != nil {
+		return nil, err
+	}
+	if cfg.ContentFilterRejectScore < cfg.ContentFilterHoldScore {
+		return nil, errors.New("CONTENT_FILTER_REJECT_SCORE must not be lower than CONTENT_FILTER_HOLD_SCORE")
+	}
+	if cfg.TrashRetention, err = getenvDuration("TRASH_RETENTION", 30*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.TrashPurgeEvery, err = getenvDuration("TRASH_PURGE_EVERY", time.Hour); err != nil {
+		return nil, err
+	}
+	txAttempts, err := getenvInt64("DB_TX_ATTEMPTS", 3)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DBTxAttempts = int(txAttempts)
+	if cfg.OutboxPollInterval, err = getenvDuration("OUTBOX_POLL_INTERVAL", time.Second); err != nil {
+		return nil, err
+	}
+	outboxAttempts, err := getenvInt64("OUTBOX_MAX_ATTEMPTS", 10)
+	if err != nil {
+		return nil, err
+	}
+	cfg.OutboxMaxAttempts = int(outboxAttempts)
+	webhookAttempts, err := getenvInt64("WEBHOOK_MAX_ATTEMPTS", 8)
+	if err != nil {
+		return nil, err
+	}
+	cfg.WebhookMaxAttempts = int(webhookAttempts)
+	if cfg.WebhookBackoff, err = getenvDuration("WEBHOOK_BACKOFF", 10*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.WebhookTimeout, err = getenvDuration("WEBHOOK_TIMEOUT", 10*time.Second); err != nil {
+		return nil, err
+	}
+	cacheSize, err := getenvInt64("FILM_CACHE_SIZE", 1000)
+	if err != nil {
+		return nil, err
+	}
+	cfg.FilmCacheSize = int(cacheSize)
+	if cfg.FilmCacheTTL, err = getenvDuration("FILM_CACHE_TTL", time.Minute); err != nil {
+		return nil, err
+	}
+	sseHistory, err := getenvInt64("SSE_HISTORY", 1000)
+	if err != nil {
+		return nil, err
+	}
+	cfg.SSEHistory = int(sseHistory)
+	if cfg.SSEHeartbeat, err = getenvDuration("SSE_HEARTBEAT", 15*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.RecommendRefreshEvery, err = getenvDuration("RECOMMEND_REFRESH_EVERY", time.Hour); err != nil {
+		return nil, err
+	}
+	minSupport, err := getenvInt64("RECOMMEND_MIN_SUPPORT", 2)
+	if err != nil {
+		return nil, err
+	}
+	cfg.RecommendMinSupport = int(minSupport)
+	if cfg.SimilarWeightGenres, err = getenvFloat("SIMILAR_WEIGHT_GENRES", 0.3); err != nil {
+		return nil, err
+	}
+	if cfg.SimilarWeightTags, err = getenvFloat("SIMILAR_WEIGHT_TAGS", 0.1); err != nil {
+		return nil, err
+	}
+	if cfg.SimilarWeightPeople, err = getenvFloat("SIMILAR_WEIGHT_PEOPLE", 0.3); err != nil {
+		return nil, err
+	}
+	if cfg.SimilarWeightRatings, err = getenvFloat("SIMILAR_WEIGHT_RATINGS", 0.25); err != nil {
+		return nil, err
+	}
+	if cfg.SimilarWeightText, err = getenvFloat("SIMILAR_WEIGHT_TEXT", 0.15); err != nil {
+		return nil, err
+	}
+	if min(cfg.SimilarWeightGenres, cfg.SimilarWeightTags, cfg.SimilarWeightPeople, cfg.SimilarWeightRatings, cfg.SimilarWeightText) < 0 {
+		return nil, errors.New("SIMILAR_WEIGHT_* must not be negative")
+	}
+	similarCacheSize, err := getenvInt64("SIMILAR_CACHE_SIZE", 1000)
+	if err != nil {
+		return nil, err
+	}
+	cfg.SimilarCacheSize = int(similarCacheSize)
+	if cfg.SimilarCacheTTL, err = getenvDuration("SIMILAR_CACHE_TTL", 10*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.ChartsRefreshEvery, err = getenvDuration("CHARTS_REFRESH_EVERY", 15*time.Minute); err != nil {
+		return nil, err
+	}
+	chartsSize, err := getenvInt64("CHARTS_SIZE", 100)
+	if err != nil {
+		return nil, err
+	}
+	if chartsSize <= 0 {
+		return nil, errors.New("CHARTS_SIZE must be positive")
+	}
+	cfg.ChartsSize = int(chartsSize)
+	if cfg.ChartsTrendingWindow, err = getenvDuration("CHARTS_TRENDING_WINDOW", 7*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.ChartsTrendingHalfLife, err = getenvDuration("CHARTS_TRENDING_HALF_LIFE", 48*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.ChartsTrendingHalfLife <= 0 {
+		return nil, errors.New("CHARTS_TRENDING_HALF_LIFE must be positive")
+	}
+	topRatedMin, err := getenvInt64("CHARTS_TOP_RATED_MIN_REVIEWS", 5)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ChartsTopRatedMinReviews = int(topRatedMin)
+	if cfg.ChartsNewReleasesWindow, err = getenvDuration("CHARTS_NEW_RELEASES_WINDOW", 90*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.OIDCIssuer != "" && cfg.OIDCClientID == "" {
+		return nil, errors.New("OIDC_CLIENT_ID must be set when OIDC_ISSUER is set")
+	}
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return nil, errors.New("both TLS_CERT_FILE and TLS_KEY_FILE must be set to enable TLS")
+	}
+	return cfg, nil
+}
+
+// TLSEnabled reports whether the server should listen over HTTPS.
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
 }
 
 func getenv(key, fallback string) string {
-    if v := os.Getenv(key); v != "" {
-        return v
-    }
-    return fallback
-} 
\ No newline at end of file
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func getenvInt64(key string, fallback int64) (int64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", key, err)
+	}
+	return n, nil
+}
+
+func getenvBool(key string, fallback bool) (bool, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("parse %s: %w", key, err)
+	}
+	return b, nil
+}
+
+func getenvFloat(key string, fallback float64) (float64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", key, err)
+	}
+	return f, nil
+}
+
+func getenvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", key, err)
+	}
+	return d, nil
+}
+
+// getenvList parses a comma-separated variable, dropping empty items.
+func getenvList(key string, fallback []string) []string {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
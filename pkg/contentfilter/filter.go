@@ -0,0 +1,124 @@
+// Package contentfilter scores user-written text before it is published.
+// Each Filter adds to a score; a Pipeline sums the scores of its filters and
+// turns the total into a Decision using two thresholds.
+package contentfilter
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"filmhub/pkg/config"
+)
+
+// Decision is what happens to the checked text.
+type Decision string
+
+const (
+	Accept Decision = "accept"
+	Hold   Decision = "hold"   // publish only after a moderator approves
+	Reject Decision = "reject" // refuse outright
+)
+
+// Reason codes reported by the built-in filters.
+const (
+	ReasonBannedWords   = "banned_words"
+	ReasonTooManyLinks  = "too_many_links"
+	ReasonLinkShortener = "link_shortener"
+	ReasonContactInfo   = "contact_info"
+	ReasonExcessiveCaps = "excessive_caps"
+	ReasonRepetition    = "repetition"
+)
+
+// Result is the contribution of one filter. A zero Score means nothing was
+// found.
+type Result struct {
+	Score   float64
+	Reasons []string
+}
+
+// Filter scores a text.
+type Filter interface {
+	Check(text string) Result
+}
+
+// Verdict is the combined outcome of a Pipeline.
+type Verdict struct {
+	Decision Decision
+	Score    float64
+	Reasons  []string
+}
+
+// Pipeline runs filters and decides by the total score: at least holdAt
+// holds the text, at least rejectAt rejects it.
+type Pipeline struct {
+	filters  []Filter
+	holdAt   float64
+	rejectAt float64
+}
+
+func NewPipeline(holdAt, rejectAt float64, filters ...Filter) *Pipeline {
+	return &Pipeline{filters: filters, holdAt: holdAt, rejectAt: rejectAt}
+}
+
+// New builds the pipeline configured by the CONTENT_FILTER_* settings: the
+// banned-word list, link heuristics and the caps/repetition score.
+func New(cfg *config.Config) (*Pipeline, error) {
+	words := append([]string(nil), cfg.ContentFilterWords...)
+	if cfg.ContentFilterWordsFile != "" {
+		fromFile, err := readWordList(cfg.ContentFilterWordsFile)
+		if err != nil {
+			return nil, err
+		}
+		words = append(words, fromFile...)
+	}
+	return NewPipeline(cfg.ContentFilterHoldScore, cfg.ContentFilterRejectScore,
+		NewBannedWords(words, DefaultBannedWordWeight),
+		NewLinkFilter(cfg.ContentFilterMaxLinks),
+		NewShoutingFilter(),
+	), nil
+}
+
+// Evaluate checks text with every filter.
+func (p *Pipeline) Evaluate(text string) Verdict {
+	var v Verdict
+	for _, f := range p.filters {
+		r := f.Check(text)
+		v.Score += r.Score
+		v.Reasons = append(v.Reasons, r.Reasons...)
+	}
+	switch {
+	case v.Score >= p.rejectAt:
+		v.Decision = Reject
+	case v.Score >= p.holdAt:
+		v.Decision = Hold
+	default:
+		v.Decision = Accept
+	}
+	return v
+}
+
+// readWordList reads one word per line; blank lines and lines starting with
+// # are skipped.
+func readWordList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open banned word list: %w", err)
+	}
+	defer f.Close()
+
+	var words []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read banned word list: %w", err)
+	}
+	return words, nil
+}
@@ -0,0 +1,79 @@
+package contentfilter
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBannedWords_MatchesInflectedAndObfuscatedForms(t *testing.T) {
+	f := NewBannedWords([]string{"идиот", "гадость", "spam", "scammer"}, 2)
+	cases := []struct {
+		text  string
+		score float64
+	}{
+		{"Режиссёр — идиот", 2},
+		{"Сценарий писали идиоты", 2},
+		{"Для идиотов", 2},
+		{"Какая гааадость", 2},
+		{"ИДИOТСКИЙ фильм", 2}, // Latin O and a derived form
+		{"Stop spamming, scammers", 4},
+		{"Spam spam spam", 2}, // one distinct word
+		{"Идеальный фильм, никакой гадости", 2},
+		{"Идея отличная", 0},
+		{"Spanish cinema", 0},
+	}
+	for _, tc := range cases {
+		if got := f.Check(tc.text).Score; got != tc.score {
+			t.Errorf("%q: expected score %v, got %v", tc.text, tc.score, got)
+		}
+	}
+}
+
+func TestLinkFilter(t *testing.T) {
+	f := NewLinkFilter(1)
+	cases := []struct {
+		text    string
+		reasons []string
+	}{
+		{"Обзор на https://example.com/review", nil},
+		{"Смотрите https://a.example и www.b.example", []string{ReasonTooManyLinks}},
+		{"Подробнее: bit.ly/xyz", []string{ReasonLinkShortener}},
+		{"Пишите на promo@example.com или +7 (999) 123-45-67", []string{ReasonContactInfo}},
+		{"Скидки в телеграме @cheap_movies", []string{ReasonContactInfo}},
+	}
+	for _, tc := range cases {
+		if got := f.Check(tc.text).Reasons; !slices.Equal(got, tc.reasons) {
+			t.Errorf("%q: expected reasons %v, got %v", tc.text, tc.reasons, got)
+		}
+	}
+}
+
+func TestShoutingFilter(t *testing.T) {
+	f := NewShoutingFilter()
+	if r := f.Check("Отличный фильм, NASA и IMAX в деле"); r.Score != 0 {
+		t.Fatalf("expected calm text to pass, got %+v", r)
+	}
+	if r := f.Check("ЭТО ЛУЧШИЙ ФИЛЬМ ГОДА"); r.Score != capsMaxScore || !slices.Equal(r.Reasons, []string{ReasonExcessiveCaps}) {
+		t.Fatalf("expected full caps score, got %+v", r)
+	}
+	if r := f.Check("круто круто круто!!!!"); r.Score != 2*repeatScore || !slices.Equal(r.Reasons, []string{ReasonRepetition}) {
+		t.Fatalf("expected two repetitions, got %+v", r)
+	}
+}
+
+func TestPipeline_Decisions(t *testing.T) {
+	p := NewPipeline(1, 3, NewBannedWords([]string{"идиот"}, DefaultBannedWordWeight), NewLinkFilter(1), NewShoutingFilter())
+	cases := []struct {
+		text string
+		want Decision
+	}{
+		{"Отличный фильм, рекомендую!", Accept},
+		{"Режиссёр идиот", Hold},
+		{"РЕЖИССЁР ИДИОТ, СМОТРИТЕ ЛУЧШЕ bit.ly/free", Reject},
+	}
+	for _, tc := range cases {
+		if v := p.Evaluate(tc.text); v.Decision != tc.want {
+			t.Errorf("%q: expected %s, got %+v", tc.text, tc.want, v)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package contentfilter
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	linkPattern    = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s]+|\b[a-z0-9-]+\.(?:com|net|org|ru|рф|info|biz|xyz|top|io|me|ly|cc|su)\b`)
+	emailPattern   = regexp.MustCompile(`(?i)\b[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}\b`)
+	phonePattern   = regexp.MustCompile(`(?:\+7|\b8)[\s(-]*\d{3}[\s)-]*\d{3}[\s-]*\d{2}[\s-]*\d{2}\b`)
+	handlePattern  = regexp.MustCompile(`(?i)(?:\bt\.me/|\btelegram\b|\bтелеграм\w*|\bwhats?app\b|(?:^|\s)@[a-z0-9_]{4,})`)
+	linkShorteners = []string{"bit.ly", "goo.gl", "tinyurl.com", "t.co", "clck.ru", "cutt.ly", "is.gd", "ow.ly"}
+)
+
+// LinkFilter flags link spam: more links than allowed, URL shorteners and
+// contact details (emails, phone numbers, messenger handles), which reviews
+// have no use for.
+type LinkFilter struct {
+	maxLinks int
+}
+
+func NewLinkFilter(maxLinks int) *LinkFilter {
+	return &LinkFilter{maxLinks: maxLinks}
+}
+
+func (f *LinkFilter) Check(text string) Result {
+	var r Result
+	contacts := len(emailPattern.FindAllString(text, -1))
+	// Emails would otherwise also count as links to their domain.
+	text = emailPattern.ReplaceAllString(text, " ")
+	contacts += len(phonePattern.FindAllString(text, -1)) + len(handlePattern.FindAllString(text, -1))
+
+	links := linkPattern.FindAllString(text, -1)
+	if extra := len(links) - f.maxLinks; extra > 0 {
+		r.Score += float64(extra)
+		r.Reasons = append(r.Reasons, ReasonTooManyLinks)
+	}
+	shortened := 0
+	for _, l := range links {
+		l = strings.ToLower(l)
+		for _, s := range linkShorteners {
+			if strings.Contains(l, s) {
+				shortened++
+				break
+			}
+		}
+	}
+	if shortened > 0 {
+		r.Score += float64(shortened)
+		r.Reasons = append(r.Reasons, ReasonLinkShortener)
+	}
+	if contacts > 0 {
+		r.Score += float64(contacts)
+		r.Reasons = append(r.Reasons, ReasonContactInfo)
+	}
+	return r
+}
+
+// Shouting thresholds.
+const (
+	capsMinLetters = 12  // shorter texts are not judged for caps
+	capsThreshold  = 0.6 // share of upper-case letters considered shouting
+	capsMaxScore   = 1.5 // score of a text written entirely in capitals
+	repeatRun      = 4   // same character this many times in a row
+	repeatWords    = 3   // same word this many times in a row
+	repeatScore    = 0.5 // per repetition found
+	repeatMaxScore = 1.5
+)
+
+// ShoutingFilter scores excessive capitals and repetition such as
+// "ОТЛИЧНЫЙ ФИЛЬМ!!!!!" or "круто круто круто".
+type ShoutingFilter struct{}
+
+func NewShoutingFilter() *ShoutingFilter {
+	return &ShoutingFilter{}
+}
+
+func (ShoutingFilter) Check(text string) Result {
+	var r Result
+	if s := capsScore(text); s > 0 {
+		r.Score += s
+		r.Reasons = append(r.Reasons, ReasonExcessiveCaps)
+	}
+	if n := repetitions(text); n > 0 {
+		r.Score += math.Min(repeatScore*float64(n), repeatMaxScore)
+		r.Reasons = append(r.Reasons, ReasonRepetition)
+	}
+	return r
+}
+
+// capsScore grows linearly from 0 at capsThreshold to capsMaxScore when
+// every letter is upper case.
+func capsScore(text string) float64 {
+	var letters, upper int
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	if letters < capsMinLetters {
+		return 0
+	}
+	ratio := float64(upper) / float64(letters)
+	if ratio <= capsThreshold {
+		return 0
+	}
+	return (ratio - capsThreshold) / (1 - capsThreshold) * capsMaxScore
+}
+
+// repetitions counts runs of repeatRun identical characters (ignoring
+// spaces) and runs of repeatWords identical words.
+func repetitions(text string) int {
+	n := 0
+	var prev rune = -1
+	run := 0
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			prev, run = -1, 0
+			continue
+		}
+		if r == prev {
+			run++
+			if run == repeatRun {
+				n++
+			}
+		} else {
+			prev, run = r, 1
+		}
+	}
+
+	words := tokenize(strings.ToLower(text))
+	same := 1
+	for i := 1; i < len(words); i++ {
+		if words[i] == words[i-1] {
+			same++
+			if same == repeatWords {
+				n++
+			}
+		} else {
+			same = 1
+		}
+	}
+	return n
+}
@@ -0,0 +1,154 @@
+package contentfilter
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultBannedWordWeight holds a text with one banned word and rejects it
+// with two under the default thresholds.
+const DefaultBannedWordWeight = 2
+
+// minStem is the shortest stem, in runes, left after stripping an ending.
+const minStem = 3
+
+// minPrefixStem is the shortest banned stem that also matches as a prefix,
+// catching compounds and derivations the suffix lists miss. Shorter stems
+// must match exactly to avoid hits inside unrelated words.
+const minPrefixStem = 5
+
+// Inflectional endings, longest first. Stripping one is a rough stand-in for
+// a real stemmer but is enough to match "идиоты" and "идиотами" against
+// "идиот", or "spamming" against "spam".
+var (
+	russianEndings = sortedByLength(
+		"иями", "ями", "ами", "ого", "его", "ому", "ему", "ыми", "ими", "ией", "ешь", "ишь", "ться", "тся",
+		"ий", "ый", "ой", "ей", "ая", "яя", "ое", "ее", "ые", "ие", "ую", "юю", "ов", "ев", "ах", "ях",
+		"ом", "ем", "ам", "ям", "ть", "ет", "ют", "ут", "ит", "ят", "ал", "ил", "ла", "ли", "ло",
+		"а", "я", "о", "е", "ы", "и", "у", "ю", "ь", "й",
+	)
+	englishEndings = sortedByLength(
+		"ings", "ing", "edly", "ed", "ies", "es", "s", "ers", "er", "est", "ly", "ness",
+	)
+)
+
+// latinToCyrillic maps Latin letters that look like Cyrillic ones. It is
+// applied to mixed-script words such as "идиoт" with a Latin "o".
+var latinToCyrillic = map[rune]rune{
+	'a': 'а', 'b': 'в', 'c': 'с', 'e': 'е', 'h': 'н', 'k': 'к', 'm': 'м',
+	'o': 'о', 'p': 'р', 't': 'т', 'x': 'х', 'y': 'у',
+}
+
+// BannedWords scores every distinct banned word found in a text. Words are
+// compared by stem after lowercasing, folding ё to е, undoing Latin
+// look-alikes in Cyrillic words and squeezing repeated letters, so
+// inflected and lightly obfuscated forms still match.
+type BannedWords struct {
+	stems  map[string]string // stem -> word as configured
+	weight float64
+}
+
+func NewBannedWords(words []string, weight float64) *BannedWords {
+	b := &BannedWords{stems: make(map[string]string, len(words)), weight: weight}
+	for _, w := range words {
+		if w = strings.TrimSpace(w); w != "" {
+			b.stems[stem(normalizeWord(w))] = w
+		}
+	}
+	return b
+}
+
+func (b *BannedWords) Check(text string) Result {
+	if len(b.stems) == 0 {
+		return Result{}
+	}
+	found := map[string]bool{}
+	for _, token := range tokenize(text) {
+		if word, ok := b.match(normalizeWord(token)); ok {
+			found[word] = true
+		}
+	}
+	if len(found) == 0 {
+		return Result{}
+	}
+	return Result{Score: b.weight * float64(len(found)), Reasons: []string{ReasonBannedWords}}
+}
+
+func (b *BannedWords) match(token string) (string, bool) {
+	if word, ok := b.stems[stem(token)]; ok {
+		return word, true
+	}
+	for s, word := range b.stems {
+		if utf8.RuneCountInString(s) >= minPrefixStem && strings.HasPrefix(token, s) {
+			return word, true
+		}
+	}
+	return "", false
+}
+
+// tokenize splits text into runs of letters and digits.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func normalizeWord(w string) string {
+	w = strings.ToLower(w)
+	w = strings.ReplaceAll(w, "ё", "е")
+	if hasScript(w, unicode.Cyrillic) && hasScript(w, unicode.Latin) {
+		w = strings.Map(func(r rune) rune {
+			if c, ok := latinToCyrillic[r]; ok {
+				return c
+			}
+			return r
+		}, w)
+	}
+	return squeeze(w)
+}
+
+// stem strips the longest known ending that leaves at least minStem runes.
+func stem(w string) string {
+	endings := englishEndings
+	if hasScript(w, unicode.Cyrillic) {
+		endings = russianEndings
+	}
+	n := utf8.RuneCountInString(w)
+	for _, e := range endings {
+		if strings.HasSuffix(w, e) && n-utf8.RuneCountInString(e) >= minStem {
+			return strings.TrimSuffix(w, e)
+		}
+	}
+	return w
+}
+
+// squeeze collapses runs of the same rune: "гааадость" -> "гадость".
+func squeeze(w string) string {
+	var sb strings.Builder
+	var prev rune = -1
+	for _, r := range w {
+		if r != prev {
+			sb.WriteRune(r)
+		}
+		prev = r
+	}
+	return sb.String()
+}
+
+func hasScript(w string, script *unicode.RangeTable) bool {
+	for _, r := range w {
+		if unicode.Is(script, r) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedByLength(endings ...string) []string {
+	sort.SliceStable(endings, func(i, j int) bool {
+		return utf8.RuneCountInString(endings[i]) > utf8.RuneCountInString(endings[j])
+	})
+	return endings
+}
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Querier is what repositories run statements on: the pool, or the
+// transaction of the current unit of work. Begin on a transaction starts a
+// savepoint, so repositories may open their own transactions either way.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+type txKey struct{}
+
+type unitOfWork struct {
+	tx          pgx.Tx
+	afterCommit []func()
+}
+
+// Conn returns the transaction carried by ctx, if TxManager.WithinTx put one
+// there, and pool otherwise.
+func Conn(ctx context.Context, pool *pgxpool.Pool) Querier {
+	if uow, ok := ctx.Value(txKey{}).(*unitOfWork); ok {
+		return uow.tx
+	}
+	return pool
+}
+
+// InTx reports whether ctx carries the transaction of a unit of work.
+func InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*unitOfWork)
+	return ok
+}
+
+// AfterCommit runs fn once the transaction carried by ctx commits, or right
+// away when there is none. fn is dropped if the transaction rolls back.
+func AfterCommit(ctx context.Context, fn func()) {
+	if uow, ok := ctx.Value(txKey{}).(*unitOfWork); ok {
+		uow.afterCommit = append(uow.afterCommit, fn)
+		return
+	}
+	fn()
+}
+
+// TxManager runs units of work in serializable transactions and retries
+// them on serialization failures and deadlocks.
+type TxManager struct {
+	pool     *pgxpool.Pool
+	attempts int
+	backoff  time.Duration
+}
+
+// NewTxManager returns a TxManager making up to attempts tries per unit of
+// work.
+func NewTxManager(pool *pgxpool.Pool, attempts int) *TxManager {
+	if attempts < 1 {
+		attempts = 1
+	}
+	return &TxManager{pool: pool, attempts: attempts, backoff: 20 * time.Millisecond}
+}
+
+// WithinTx calls fn with a context carrying a transaction, which
+// repositories pick up through Conn, and commits it if fn returns nil. fn
+// may be called again when the transaction has to be retried, so it must
+// not have effects outside the database; use AfterCommit for those. Called
+// within a unit of work, WithinTx joins it.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if InTx(ctx) {
+		return fn(ctx)
+	}
+	for attempt := 1; ; attempt++ {
+		err := m.run(ctx, fn)
+		if err == nil || !Retryable(err) || attempt == m.attempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * m.backoff):
+		}
+	}
+}
+
+func (m *TxManager) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	uow := &unitOfWork{}
+	err := pgx.BeginTxFunc(ctx, m.pool, pgx.TxOptions{IsoLevel: pgx.Serializable}, func(tx pgx.Tx) error {
+		uow.tx = tx
+		return fn(context.WithValue(ctx, txKey{}, uow))
+	})
+	if err != nil {
+		return err
+	}
+	for _, f := range uow.afterCommit {
+		f()
+	}
+	return nil
+}
+
+// Retryable reports whether err is a serialization failure or a deadlock,
+// after which the whole transaction can be run again.
+func Retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
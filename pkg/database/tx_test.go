@@ -0,0 +1,30 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestRetryable(t *testing.T) {
+	for code, want := range map[string]bool{"40001": true, "40P01": true, "23505": false} {
+		err := fmt.Errorf("create review: %w", &pgconn.PgError{Code: code})
+		if got := Retryable(err); got != want {
+			t.Errorf("code %s: expected %v, got %v", code, want, got)
+		}
+	}
+	if Retryable(errors.New("connection refused")) {
+		t.Error("expected non-Postgres errors not to be retried")
+	}
+}
+
+func TestAfterCommitOutsideTx(t *testing.T) {
+	ran := false
+	AfterCommit(context.Background(), func() { ran = true })
+	if !ran {
+		t.Fatal("expected fn to run right away without a transaction")
+	}
+}
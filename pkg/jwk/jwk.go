@@ -0,0 +1,123 @@
+// Package jwk converts public keys to and from JSON Web Keys (RFC 7517).
+// RSA, EC (P-256/384/521) and Ed25519 keys are supported.
+package jwk
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Key is a public JSON Web Key.
+type Key struct {
+	Kty string `json:"kty" example:"RSA" description:"Тип ключа: RSA, EC или OKP"`
+	Kid string `json:"kid,omitempty" example:"20240101T000000-9f86d081884c7d65" description:"Идентификатор ключа"`
+	Use string `json:"use,omitempty" example:"sig"`
+	Alg string `json:"alg,omitempty" example:"RS256"`
+
+	// RSA
+	N string `json:"n,omitempty" description:"Модуль RSA (base64url)"`
+	E string `json:"e,omitempty" example:"AQAB" description:"Экспонента RSA (base64url)"`
+
+	// EC and OKP
+	Crv string `json:"crv,omitempty" example:"Ed25519"`
+	X   string `json:"x,omitempty" description:"Координата X / открытый ключ Ed25519 (base64url)"`
+	Y   string `json:"y,omitempty" description:"Координата Y (base64url)"`
+}
+
+// Set is a JWK Set document as served from a jwks_uri.
+type Set struct {
+	Keys []Key `json:"keys"`
+}
+
+// Find returns the key with the given kid.
+func (s Set) Find(kid string) (Key, bool) {
+	for _, k := range s.Keys {
+		if k.Kid == kid {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+var b64 = base64.RawURLEncoding
+
+// FromPublicKey builds a signing JWK for pub.
+func FromPublicKey(kid, alg string, pub crypto.PublicKey) (Key, error) {
+	k := Key{Kid: kid, Alg: alg, Use: "sig"}
+	switch p := pub.(type) {
+	case *rsa.PublicKey:
+		k.Kty = "RSA"
+		k.N = b64.EncodeToString(p.N.Bytes())
+		k.E = b64.EncodeToString(big.NewInt(int64(p.E)).Bytes())
+	case *ecdsa.PublicKey:
+		k.Kty = "EC"
+		k.Crv = p.Curve.Params().Name
+		size := (p.Curve.Params().BitSize + 7) / 8
+		k.X = b64.EncodeToString(p.X.FillBytes(make([]byte, size)))
+		k.Y = b64.EncodeToString(p.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		k.Kty = "OKP"
+		k.Crv = "Ed25519"
+		k.X = b64.EncodeToString(p)
+	default:
+		return Key{}, fmt.Errorf("jwk: unsupported key type %T", pub)
+	}
+	return k, nil
+}
+
+// PublicKey decodes the key material.
+func (k Key) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := b64.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwk: decode n: %w", err)
+		}
+		e, err := b64.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("jwk: decode e: %w", err)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("jwk: unsupported curve %q", k.Crv)
+		}
+		x, err := b64.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("jwk: decode x: %w", err)
+		}
+		y, err := b64.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("jwk: decode y: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("jwk: unsupported curve %q", k.Crv)
+		}
+		x, err := b64.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("jwk: decode x: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("jwk: bad Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("jwk: unsupported key type %q", k.Kty)
+}
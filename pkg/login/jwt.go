@@ -2,30 +2,50 @@ package login
 
 import (
 	"errors"
+	"strconv"
 	"sync"
 	"time"
 
 	jwt "github.com/golang-jwt/jwt/v5"
 )
 
+// Config holds the registered claims put into and required from tokens.
+type Config struct {
+	Issuer   string
+	Audience string
+	TTL      time.Duration
+}
+
 var (
-	secretOnce sync.Once
-	jwtKey     []byte
+	mu      sync.RWMutex
+	keyring *Keyring
+	config  Config
 )
 
-// Init sets the HMAC secret used for signing JWTs. It must be called once at
-// application startup. Re-initialization is ignored.
-func Init(secret string) {
-	secretOnce.Do(func() {
-		jwtKey = []byte(secret)
-	})
+// Init sets the keyring and claims used by GenerateToken and ParseToken. It
+// is called at application startup; tests may call it again to swap keys.
+func Init(kr *Keyring, cfg Config) {
+	if cfg.Issuer == "" {
+		cfg.Issuer = "filmhub"
+	}
+	if cfg.Audience == "" {
+		cfg.Audience = "filmhub-api"
+	}
+	if cfg.TTL == 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+	mu.Lock()
+	keyring, config = kr, cfg
+	mu.Unlock()
 }
 
-func prepareKey() error {
-	if len(jwtKey) == 0 {
-		return errors.New("jwt secret not initialized: call login.Init first")
+func current() (*Keyring, Config, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if keyring == nil {
+		return nil, Config{}, errors.New("jwt keyring not initialized: call login.Init first")
 	}
-	return nil
+	return keyring, config, nil
 }
 
 type Claims struct {
@@ -35,31 +55,55 @@ type Claims struct {
 }
 
 func GenerateToken(userID int, role string) (string, error) {
-	if err := prepareKey(); err != nil {
+	kr, cfg, err := current()
+	if err != nil {
+		return "", err
+	}
+	key, err := kr.signingKey()
+	if err != nil {
 		return "", err
 	}
-	expirationTime := time.Now().Add(24 * time.Hour)
+	now := time.Now()
 	claims := &Claims{
 		UserID: userID,
 		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			Issuer:    cfg.Issuer,
+			Subject:   strconv.Itoa(userID),
+			Audience:  jwt.ClaimStrings{cfg.Audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(cfg.TTL)),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtKey)
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(kr.Algorithm()), claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.private)
 }
 
+// ParseToken verifies the signature against the keyring and requires the
+// configured issuer and audience, an expiry, an issue time not in the future
+// and a subject matching user_id.
 func ParseToken(tokenStr string) (*Claims, error) {
-	if err := prepareKey(); err != nil {
+	kr, cfg, err := current()
+	if err != nil {
 		return nil, err
 	}
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenStr, claims, func(_ *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	})
-	if err != nil || !token.Valid {
+	_, err = jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return kr.publicKey(kid)
+	},
+		jwt.WithValidMethods([]string{kr.Algorithm()}),
+		jwt.WithIssuer(cfg.Issuer),
+		jwt.WithAudience(cfg.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
 		return nil, err
 	}
+	if claims.Subject != strconv.Itoa(claims.UserID) {
+		return nil, errors.New("token subject does not match user_id")
+	}
 	return claims, nil
 }
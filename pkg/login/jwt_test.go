@@ -1,18 +1,151 @@
 package login
 
-import "testing"
+import (
+    "path/filepath"
+    "testing"
+    "time"
+
+    jwt "github.com/golang-jwt/jwt/v5"
+)
 
 func TestGenerateAndParseToken(t *testing.T) {
-    Init("testsecret")
-    tokenStr, err := GenerateToken(42, "admin")
-    if err != nil {
-        t.Fatalf("generate token: %v", err)
+    for _, alg := range []string{RS256, EdDSA} {
+        kr, err := NewKeyring(KeyringConfig{Algorithm: alg})
+        if err != nil {
+            t.Fatalf("%s keyring: %v", alg, err)
+        }
+        Init(kr, Config{})
+        tokenStr, err := GenerateToken(42, "admin")
+        if err != nil {
+            t.Fatalf("generate token: %v", err)
+        }
+        claims, err := ParseToken(tokenStr)
+        if err != nil {
+            t.Fatalf("parse token: %v", err)
+        }
+        if claims.UserID != 42 || claims.Role != "admin" || claims.Subject != "42" ||
+            claims.Issuer != "filmhub" || claims.IssuedAt == nil {
+            t.Errorf("unexpected claims: %+v", claims)
+        }
+    }
+}
+
+func TestParseToken_RejectsForeignClaims(t *testing.T) {
+    kr, _ := NewKeyring(KeyringConfig{Algorithm: EdDSA})
+    key, _ := kr.signingKey()
+    sign := func(claims *Claims) string {
+        token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+        token.Header["kid"] = key.kid
+        s, _ := token.SignedString(key.private)
+        return s
+    }
+    Init(kr, Config{Issuer: "filmhub", Audience: "filmhub-api"})
+    valid := func() *Claims {
+        now := time.Now()
+        return &Claims{UserID: 1, Role: "user", RegisteredClaims: jwt.RegisteredClaims{
+            Issuer: "filmhub", Audience: jwt.ClaimStrings{"filmhub-api"}, Subject: "1",
+            IssuedAt: jwt.NewNumericDate(now), ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+        }}
+    }
+
+    if _, err := ParseToken(sign(valid())); err != nil {
+        t.Fatalf("valid token rejected: %v", err)
+    }
+    cases := map[string]func(c *Claims){
+        "issuer":    func(c *Claims) { c.Issuer = "other" },
+        "audience":  func(c *Claims) { c.Audience = jwt.ClaimStrings{"other-api"} },
+        "subject":   func(c *Claims) { c.Subject = "2" },
+        "issued at": func(c *Claims) { c.IssuedAt = jwt.NewNumericDate(time.Now().Add(time.Hour)) },
+        "expiry":    func(c *Claims) { c.ExpiresAt = nil },
+    }
+    for name, mutate := range cases {
+        c := valid()
+        mutate(c)
+        if _, err := ParseToken(sign(c)); err == nil {
+            t.Errorf("%s: expected token to be rejected", name)
+        }
     }
-    claims, err := ParseToken(tokenStr)
+}
+
+func TestKeyring_RotationKeepsOldKeysForVerification(t *testing.T) {
+    now := time.Now()
+    kr, _ := NewKeyring(KeyringConfig{Algorithm: EdDSA, Dir: t.TempDir(), Retain: time.Hour})
+    kr.now = func() time.Time { return now }
+    Init(kr, Config{})
+
+    old, _ := GenerateToken(1, "user")
+    if err := kr.Rotate(); err != nil {
+        t.Fatalf("rotate: %v", err)
+    }
+    fresh, _ := GenerateToken(1, "user")
+    if _, err := ParseToken(old); err != nil {
+        t.Fatalf("token of the previous key rejected: %v", err)
+    }
+    if got := len(kr.JWKS().Keys); got != 2 {
+        t.Fatalf("expected 2 keys in JWKS, got %d", got)
+    }
+
+    // Another instance sharing the directory sees both keys.
+    other, err := NewKeyring(KeyringConfig{Algorithm: EdDSA, Dir: kr.cfg.Dir, Retain: time.Hour})
     if err != nil {
-        t.Fatalf("parse token: %v", err)
+        t.Fatalf("load keyring: %v", err)
     }
-    if claims.UserID != 42 || claims.Role != "admin" {
-        t.Errorf("unexpected claims: %+v", claims)
+    if got := len(other.JWKS().Keys); got != 2 {
+        t.Fatalf("expected 2 persisted keys, got %d", got)
+    }
+
+    // Past the retention window the replaced key is dropped.
+    now = now.Add(2 * time.Hour)
+    if err := kr.Rotate(); err != nil {
+        t.Fatalf("rotate: %v", err)
+    }
+    if _, err := ParseToken(old); err == nil {
+        t.Fatal("token of a pruned key accepted")
+    }
+    if _, err := ParseToken(fresh); err != nil {
+        t.Fatalf("token of the retained key rejected: %v", err)
+    }
+    if err := other.Reload(); err != nil {
+        t.Fatalf("reload: %v", err)
+    }
+    if got := len(other.JWKS().Keys); got != 2 {
+        t.Fatalf("expected pruned key file to be removed, got %d keys", got)
+    }
+}
+
+func TestKeyring_UnknownKidReloadsAtMostOncePerCooldown(t *testing.T) {
+    now := time.Now()
+    dir := t.TempDir()
+    kr, _ := NewKeyring(KeyringConfig{Algorithm: EdDSA, Dir: dir, Retain: time.Hour})
+    kr.now = func() time.Time { return now }
+    other, _ := NewKeyring(KeyringConfig{Algorithm: EdDSA, Dir: dir, Retain: time.Hour})
+
+    // A key rotated by another instance is picked up on first sight.
+    if err := other.Rotate(); err != nil {
+        t.Fatalf("rotate: %v", err)
+    }
+    if _, err := kr.publicKey(other.keys[0].kid); err != nil {
+        t.Fatalf("key of the other instance rejected: %v", err)
+    }
+
+    // Within the cooldown unknown kids do not touch the directory again.
+    if err := other.Rotate(); err != nil {
+        t.Fatalf("rotate: %v", err)
+    }
+    if _, err := kr.publicKey("random-kid"); err == nil {
+        t.Fatal("unknown kid accepted")
+    }
+    if _, err := kr.publicKey(other.keys[0].kid); err == nil {
+        t.Fatal("expected no reload within the cooldown")
+    }
+
+    now = now.Add(reloadCooldown)
+    if _, err := kr.publicKey(other.keys[0].kid); err != nil {
+        t.Fatalf("key rejected after the cooldown: %v", err)
+    }
+
+    matches, _ := filepath.Glob(filepath.Join(dir, "*"))
+    if len(matches) != 3 {
+        t.Fatalf("expected only the 3 key files, got %v", matches)
     }
-} 
\ No newline at end of file
+}
@@ -0,0 +1,361 @@
+package login
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"filmhub/pkg/jwk"
+)
+
+// Supported signing algorithms.
+const (
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+// KeyringConfig configures a Keyring.
+type KeyringConfig struct {
+	// Algorithm is RS256 (default) or EdDSA.
+	Algorithm string
+	// Dir persists private keys as <kid>.pem. Instances sharing the
+	// directory share keys. Empty keeps keys in memory only, so a restart
+	// invalidates every issued token.
+	Dir string
+	// RotateEvery is the age after which Run replaces the signing key.
+	// Zero disables scheduled rotation.
+	RotateEvery time.Duration
+	// Retain is how long a replaced key stays valid for verification. It
+	// should be at least the token lifetime. Defaults to 48h.
+	Retain time.Duration
+}
+
+// reloadCooldown is the least time between reloads triggered by tokens with
+// an unknown kid. The kid is read before the signature is checked, so
+// without it anyone could make every request re-read the key directory.
+const reloadCooldown = 10 * time.Second
+
+type signingKey struct {
+	kid     string
+	private crypto.Signer
+	created time.Time
+}
+
+// Keyring holds the active signing key and the recently rotated keys that
+// are still accepted for verification.
+type Keyring struct {
+	cfg KeyringConfig
+	now func() time.Time
+
+	mu   sync.RWMutex
+	keys []signingKey // newest first; keys[0] signs
+
+	reloadMu   sync.Mutex
+	reloadedAt time.Time // of the last reload for an unknown kid
+}
+
+// NewKeyring loads keys from cfg.Dir, generating the first one if there is
+// none.
+func NewKeyring(cfg KeyringConfig) (*Keyring, error) {
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = RS256
+	}
+	if cfg.Algorithm != RS256 && cfg.Algorithm != EdDSA {
+		return nil, fmt.Errorf("login: unsupported signing algorithm %q", cfg.Algorithm)
+	}
+	if cfg.Retain == 0 {
+		cfg.Retain = 48 * time.Hour
+	}
+	k := &Keyring{cfg: cfg, now: time.Now}
+	if cfg.Dir != "" {
+		if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+			return nil, fmt.Errorf("login: key dir: %w", err)
+		}
+		if err := k.Reload(); err != nil {
+			return nil, err
+		}
+	}
+	if len(k.keys) == 0 {
+		if err := k.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// Algorithm returns the JWS algorithm of the keys.
+func (k *Keyring) Algorithm() string {
+	return k.cfg.Algorithm
+}
+
+// Rotate generates a new signing key. The previous keys keep verifying
+// tokens until they are older than Retain past their replacement.
+func (k *Keyring) Rotate() error {
+	key, err := k.generate()
+	if err != nil {
+		return err
+	}
+	if k.cfg.Dir != "" {
+		if err := writeKey(filepath.Join(k.cfg.Dir, key.kid+".pem"), key); err != nil {
+			return err
+		}
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys = append([]signingKey{key}, k.keys...)
+	k.prune()
+	return nil
+}
+
+// Reload re-reads cfg.Dir, picking up keys rotated by other instances.
+func (k *Keyring) Reload() error {
+	if k.cfg.Dir == "" {
+		return nil
+	}
+	paths, err := filepath.Glob(filepath.Join(k.cfg.Dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+	var keys []signingKey
+	for _, path := range paths {
+		key, err := readKey(path)
+		if err != nil {
+			return err
+		}
+		if keyAlgorithm(key.private) == k.cfg.Algorithm {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].created.After(keys[j].created) })
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys = keys
+	k.prune()
+	return nil
+}
+
+// Run rotates the signing key whenever it gets older than RotateEvery until
+// ctx is done. With a key directory it also reloads keys once a minute.
+func (k *Keyring) Run(ctx context.Context, onError func(error)) {
+	if k.cfg.RotateEvery <= 0 && k.cfg.Dir == "" {
+		return
+	}
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := k.Reload(); err != nil {
+			onError(err)
+			continue
+		}
+		if k.cfg.RotateEvery <= 0 {
+			continue
+		}
+		k.mu.RLock()
+		due := len(k.keys) == 0 || k.now().Sub(k.keys[0].created) >= k.cfg.RotateEvery
+		k.mu.RUnlock()
+		if due {
+			if err := k.Rotate(); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// JWKS returns the public keys that verify tokens, for /.well-known/jwks.json.
+func (k *Keyring) JWKS() jwk.Set {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	set := jwk.Set{Keys: make([]jwk.Key, 0, len(k.keys))}
+	for _, key := range k.keys {
+		pub, err := jwk.FromPublicKey(key.kid, k.cfg.Algorithm, key.private.Public())
+		if err == nil {
+			set.Keys = append(set.Keys, pub)
+		}
+	}
+	return set
+}
+
+func (k *Keyring) signingKey() (signingKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if len(k.keys) == 0 {
+		return signingKey{}, errors.New("login: keyring is empty")
+	}
+	return k.keys[0], nil
+}
+
+// publicKey returns the verification key for kid. An unknown kid triggers
+// a reload, at most once per reloadCooldown, so tokens signed by another
+// instance right after it rotated are accepted.
+func (k *Keyring) publicKey(kid string) (crypto.PublicKey, error) {
+	if pub, ok := k.lookup(kid); ok {
+		return pub, nil
+	}
+	reloaded, err := k.reloadThrottled()
+	if err != nil {
+		return nil, err
+	}
+	if reloaded {
+		if pub, ok := k.lookup(kid); ok {
+			return pub, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// reloadThrottled reloads cfg.Dir unless that was done less than
+// reloadCooldown ago, and reports whether it did.
+func (k *Keyring) reloadThrottled() (bool, error) {
+	if k.cfg.Dir == "" {
+		return false, nil
+	}
+	k.reloadMu.Lock()
+	defer k.reloadMu.Unlock()
+	now := k.now()
+	if !k.reloadedAt.IsZero() && now.Sub(k.reloadedAt) < reloadCooldown {
+		return false, nil
+	}
+	// A failed reload counts too, so a broken directory is not hammered.
+	k.reloadedAt = now
+	return true, k.Reload()
+}
+
+func (k *Keyring) lookup(kid string) (crypto.PublicKey, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	for _, key := range k.keys {
+		if key.kid == kid {
+			return key.private.Public(), true
+		}
+	}
+	return nil, false
+}
+
+// prune drops keys replaced more than Retain ago; keys[i] was replaced when
+// keys[i-1] was created. Files are removed too so other instances drop them.
+func (k *Keyring) prune() {
+	for i := 1; i < len(k.keys); i++ {
+		if k.now().Sub(k.keys[i-1].created) > k.cfg.Retain {
+			if k.cfg.Dir != "" {
+				for _, old := range k.keys[i:] {
+					_ = os.Remove(filepath.Join(k.cfg.Dir, old.kid+".pem"))
+				}
+			}
+			k.keys = k.keys[:i]
+			return
+		}
+	}
+}
+
+func (k *Keyring) generate() (signingKey, error) {
+	var private crypto.Signer
+	var err error
+	switch k.cfg.Algorithm {
+	case EdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	}
+	if err != nil {
+		return signingKey{}, fmt.Errorf("login: generate key: %w", err)
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return signingKey{}, err
+	}
+	// The timestamp prefix keeps kids sortable by creation time.
+	kid := k.now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(id)
+	return signingKey{kid: kid, private: private, created: k.now()}, nil
+}
+
+func keyAlgorithm(key crypto.Signer) string {
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		return EdDSA
+	}
+	return RS256
+}
+
+// writeKey stores key as PKCS#8 PEM. The file's modification time records
+// the creation time, which drives retention after a reload. The key is
+// written to a temporary file and renamed into place, so a crash or another
+// instance reloading never sees a truncated key.
+func writeKey(path string, key signingKey) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key.private)
+	if err != nil {
+		return fmt.Errorf("login: marshal key: %w", err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	// CreateTemp makes the file 0600 and its name does not match *.pem.
+	f, err := os.CreateTemp(filepath.Dir(path), ".key-*.tmp")
+	if err != nil {
+		return fmt.Errorf("login: write key: %w", err)
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chtimes(tmp, key.created, key.created)
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("login: write key: %w", err)
+	}
+	return nil
+}
+
+func readKey(path string) (signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return signingKey{}, fmt.Errorf("login: read key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return signingKey{}, fmt.Errorf("login: %s is not PEM", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return signingKey{}, fmt.Errorf("login: parse %s: %w", path, err)
+	}
+	private, ok := parsed.(crypto.Signer)
+	if !ok {
+		return signingKey{}, fmt.Errorf("login: %s holds an unsupported key", path)
+	}
+	if _, isRSA := private.(*rsa.PrivateKey); !isRSA {
+		if _, isEd := private.(ed25519.PrivateKey); !isEd {
+			return signingKey{}, fmt.Errorf("login: %s holds an unsupported key", path)
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return signingKey{}, err
+	}
+	kid := strings.TrimSuffix(filepath.Base(path), ".pem")
+	return signingKey{kid: kid, private: private, created: info.ModTime()}, nil
+}
@@ -1,27 +1,129 @@
 package login
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// APIKeyPrefix starts every personal API key, which lets a key be passed as
+// a Bearer credential next to JWTs.
+const APIKeyPrefix = "fh_"
+
+// Values stored under "auth_method" in the gin context.
+const (
+	MethodJWT    = "jwt"
+	MethodAPIKey = "api_key"
+)
+
+// ErrInvalidAPIKey is returned by an APIKeyAuthenticator for unknown,
+// revoked or expired keys. Any other error is treated as a failure to check
+// the key, not as a bad key.
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+// Principal is the caller behind a non-JWT credential.
+type Principal struct {
+	UserID int
+	Role   string
+	// ReadOnly limits the caller to GET and HEAD requests.
+	ReadOnly bool
+}
+
+// APIKeyAuthenticator resolves API keys. It returns ErrInvalidAPIKey for
+// unknown, revoked or expired keys.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*Principal, error)
+}
+
+// Option configures AuthMiddleware.
+type Option func(*authOptions)
+
+type authOptions struct {
+	apiKeys  APIKeyAuthenticator
+	optional bool
+}
+
+// WithAPIKeys accepts API keys from the X-API-Key header or as a Bearer
+// credential starting with APIKeyPrefix.
+func WithAPIKeys(a APIKeyAuthenticator) Option {
+	return func(o *authOptions) { o.apiKeys = a }
+}
+
+// Optional lets requests without credentials through anonymously, leaving
+// "user_id" unset. Invalid credentials are still rejected.
+func Optional() Option {
+	return func(o *authOptions) { o.optional = true }
+}
+
+// AuthMiddleware authenticates the request and stores "user_id" (int),
+// "role" (string) and "auth_method" in the context.
+func AuthMiddleware(opts ...Option) gin.HandlerFunc {
+	var o authOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
 	return func(c *gin.Context) {
 		header := c.GetHeader("Authorization")
-		if header == "" || !strings.HasPrefix(header, "Bearer ") {
+		apiKey := c.GetHeader("X-API-Key")
+		bearer, isBearer := strings.CutPrefix(header, "Bearer ")
+		if apiKey == "" && isBearer && strings.HasPrefix(bearer, APIKeyPrefix) {
+			apiKey = bearer
+		}
+
+		if apiKey != "" && o.apiKeys != nil {
+			p, err := o.apiKeys.AuthenticateAPIKey(c.Request.Context(), apiKey)
+			switch {
+			case errors.Is(err, ErrInvalidAPIKey):
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid api key"})
+				return
+			case err != nil:
+				// An outage must not look like a revoked key to clients.
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "could not check api key"})
+				return
+			}
+			if p.ReadOnly && c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "api key lacks write scope"})
+				return
+			}
+			c.Set("user_id", p.UserID)
+			c.Set("role", p.Role)
+			c.Set("auth_method", MethodAPIKey)
+			c.Next()
+			return
+		}
+
+		if o.optional && header == "" && apiKey == "" {
+			c.Next()
+			return
+		}
+		if !isBearer || bearer == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid token"})
 			return
 		}
-		tokenStr := strings.TrimPrefix(header, "Bearer ")
-		claims, err := ParseToken(tokenStr)
+		claims, err := ParseToken(bearer)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
 			return
 		}
 		c.Set("user_id", claims.UserID)
 		c.Set("role", claims.Role)
+		c.Set("auth_method", MethodJWT)
+		c.Next()
+	}
+}
+
+// SessionOnly refuses callers authenticated with an API key. It guards
+// routes through which a key could gain more than its scope, such as
+// managing keys or sign-in methods. Use it after AuthMiddleware.
+func SessionOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_method") == MethodAPIKey {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "this action requires signing in, not an api key"})
+			return
+		}
 		c.Next()
 	}
 }
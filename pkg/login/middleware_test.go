@@ -0,0 +1,135 @@
+package login
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+)
+
+type stubKeys map[string]*Principal
+
+func (s stubKeys) AuthenticateAPIKey(_ context.Context, key string) (*Principal, error) {
+    if key == "fh_outage" {
+        return nil, errors.New("connection refused")
+    }
+    if p, ok := s[key]; ok {
+        return p, nil
+    }
+    return nil, ErrInvalidAPIKey
+}
+
+func TestAuthMiddleware_AcceptsJWTAndAPIKeys(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+    kr, _ := NewKeyring(KeyringConfig{Algorithm: EdDSA})
+    Init(kr, Config{})
+    token, _ := GenerateToken(1, "admin")
+    keys := stubKeys{
+        "fh_rw": {UserID: 2, Role: "user"},
+        "fh_ro": {UserID: 3, Role: "user", ReadOnly: true},
+    }
+
+    r := gin.New()
+    r.Use(AuthMiddleware(WithAPIKeys(keys)))
+    whoami := func(c *gin.Context) {
+        c.JSON(http.StatusOK, gin.H{"user_id": c.GetInt("user_id"), "method": c.GetString("auth_method")})
+    }
+    r.GET("/me", whoami)
+    r.POST("/me", whoami)
+
+    cases := []struct {
+        name, method, header, value string
+        want                        int
+    }{
+        {"jwt", http.MethodPost, "Authorization", "Bearer " + token, http.StatusOK},
+        {"key as bearer", http.MethodPost, "Authorization", "Bearer fh_rw", http.StatusOK},
+        {"key header", http.MethodGet, "X-API-Key", "fh_ro", http.StatusOK},
+        {"read-only key writes", http.MethodPost, "X-API-Key", "fh_ro", http.StatusForbidden},
+        {"unknown key", http.MethodGet, "X-API-Key", "fh_nope", http.StatusUnauthorized},
+        {"key store down", http.MethodGet, "X-API-Key", "fh_outage", http.StatusInternalServerError},
+        {"garbage bearer", http.MethodGet, "Authorization", "Bearer nope", http.StatusUnauthorized},
+        {"no credentials", http.MethodGet, "", "", http.StatusUnauthorized},
+    }
+    for _, tc := range cases {
+        req := httptest.NewRequest(tc.method, "/me", nil)
+        if tc.header != "" {
+            req.Header.Set(tc.header, tc.value)
+        }
+        resp := httptest.NewRecorder()
+        r.ServeHTTP(resp, req)
+        if resp.Code != tc.want {
+            t.Errorf("%s: expected %d, got %d: %s", tc.name, tc.want, resp.Code, resp.Body.String())
+        }
+    }
+}
+
+func TestAuthMiddleware_Optional(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+    kr, _ := NewKeyring(KeyringConfig{Algorithm: EdDSA})
+    Init(kr, Config{})
+    token, _ := GenerateToken(4, "user")
+
+    r := gin.New()
+    r.Use(AuthMiddleware(Optional()))
+    r.GET("/reviews", func(c *gin.Context) {
+        c.JSON(http.StatusOK, gin.H{"user_id": c.GetInt("user_id")})
+    })
+
+    cases := []struct {
+        name, header string
+        want         int
+        body         string
+    }{
+        {"anonymous", "", http.StatusOK, `{"user_id":0}`},
+        {"signed in", "Bearer " + token, http.StatusOK, `{"user_id":4}`},
+        {"bad token", "Bearer nope", http.StatusUnauthorized, ""},
+    }
+    for _, tc := range cases {
+        req := httptest.NewRequest(http.MethodGet, "/reviews", nil)
+        if tc.header != "" {
+            req.Header.Set("Authorization", tc.header)
+        }
+        resp := httptest.NewRecorder()
+        r.ServeHTTP(resp, req)
+        if resp.Code != tc.want || (tc.body != "" && resp.Body.String() != tc.body) {
+            t.Errorf("%s: got %d %s", tc.name, resp.Code, resp.Body.String())
+        }
+    }
+}
+
+func TestSessionOnly_RefusesAPIKeys(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+    kr, _ := NewKeyring(KeyringConfig{Algorithm: EdDSA})
+    Init(kr, Config{})
+    token, _ := GenerateToken(1, "user")
+    keys := stubKeys{"fh_rw": {UserID: 1, Role: "user"}}
+
+    r := gin.New()
+    identities := r.Group("/me/identities", AuthMiddleware(WithAPIKeys(keys)), SessionOnly())
+    ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+    identities.POST("/link", ok)
+    identities.GET("", ok)
+    identities.DELETE("/:provider", ok)
+
+    cases := []struct {
+        method, path string
+    }{
+        {http.MethodPost, "/me/identities/link"},
+        {http.MethodGet, "/me/identities"},
+        {http.MethodDelete, "/me/identities/google"},
+    }
+    for _, tc := range cases {
+        for credential, want := range map[string]int{"Bearer fh_rw": http.StatusForbidden, "Bearer " + token: http.StatusNoContent} {
+            req := httptest.NewRequest(tc.method, tc.path, nil)
+            req.Header.Set("Authorization", credential)
+            resp := httptest.NewRecorder()
+            r.ServeHTTP(resp, req)
+            if resp.Code != want {
+                t.Errorf("%s %s with %.10s: expected %d, got %d", tc.method, tc.path, credential, want, resp.Code)
+            }
+        }
+    }
+}
@@ -0,0 +1,133 @@
+// Package mailer sends transactional email. SMTPMailer is used in
+// production; LogMailer and FileMailer let local setups read the messages
+// (and the links in them) without a mail server.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"filmhub/pkg/config"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New picks the implementation configured by MAIL_DRIVER.
+func New(cfg *config.Config, log *zap.SugaredLogger) (Mailer, error) {
+	switch cfg.MailDriver {
+	case "smtp":
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom), nil
+	case "file":
+		return NewFileMailer(cfg.MailFile, cfg.MailFrom), nil
+	case "log", "":
+		return NewLogMailer(log), nil
+	}
+	return nil, fmt.Errorf("unknown MAIL_DRIVER %q", cfg.MailDriver)
+}
+
+// SMTPMailer sends mail through an SMTP relay, using STARTTLS when offered.
+type SMTPMailer struct {
+	addr     string
+	auth     smtp.Auth
+	from     string // header value, may include a display name
+	envelope string // bare address for MAIL FROM
+}
+
+// NewSMTPMailer configures PLAIN auth when username is set.
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	m := &SMTPMailer{addr: net.JoinHostPort(host, port), from: from, envelope: from}
+	if addr, err := mail.ParseAddress(from); err == nil {
+		m.envelope = addr.Address
+	}
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(m.addr, m.auth, m.envelope, []string{msg.To}, render(m.from, msg))
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("smtp send: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// LogMailer writes messages to the application log.
+type LogMailer struct {
+	log *zap.SugaredLogger
+}
+
+func NewLogMailer(log *zap.SugaredLogger) *LogMailer {
+	return &LogMailer{log: log}
+}
+
+func (m *LogMailer) Send(_ context.Context, msg Message) error {
+	m.log.Infow("mail", "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+	return nil
+}
+
+// FileMailer appends RFC 822 messages to a file.
+type FileMailer struct {
+	path string
+	from string
+	mu   sync.Mutex
+}
+
+func NewFileMailer(path, from string) *FileMailer {
+	return &FileMailer{path: path, from: from}
+}
+
+func (m *FileMailer) Send(_ context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open mail file: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(render(m.from, msg), "\r\n\r\n"...)); err != nil {
+		return fmt.Errorf("write mail file: %w", err)
+	}
+	return nil
+}
+
+func render(from string, msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(msg.Body)
+	return []byte(b.String())
+}
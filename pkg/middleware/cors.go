@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSConfig describes which cross-origin requests the API accepts.
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowCredentials bool
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	MaxAge           int // seconds the preflight response may be cached
+}
+
+var (
+	defaultCORSMethods = []string{
+		http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions,
+	}
+	// If-Match and If-None-Match carry ETags back on conditional requests.
+	defaultCORSHeaders = []string{
+		"Authorization", "X-API-Key", "Content-Type", "Accept-Language", "If-Match", "If-None-Match", RequestIDHeader,
+	}
+)
+
+// CORS returns a middleware answering preflight requests and decorating
+// responses with Access-Control-* headers for allowed origins. "*" allows any
+// origin; with credentials enabled the request origin is echoed back instead,
+// as browsers reject a wildcard in that case.
+func CORS(cfg CORSConfig) gin.HandlerFunc {
+	if len(cfg.AllowedMethods) == 0 {
+		cfg.AllowedMethods = defaultCORSMethods
+	}
+	if len(cfg.AllowedHeaders) == 0 {
+		cfg.AllowedHeaders = defaultCORSHeaders
+	}
+	if cfg.MaxAge == 0 {
+		cfg.MaxAge = 600
+	}
+
+	allowAll := false
+	allowed := make(map[string]struct{}, len(cfg.AllowedOrigins))
+	for _, o := range cfg.AllowedOrigins {
+		if o == "*" {
+			allowAll = true
+			continue
+		}
+		allowed[strings.TrimSuffix(o, "/")] = struct{}{}
+	}
+
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	exposed := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(cfg.MaxAge)
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+		h.Add("Vary", "Origin")
+
+		_, ok := allowed[origin]
+		if !ok && !allowAll {
+			if c.Request.Method == http.MethodOptions {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if allowAll && !cfg.AllowCredentials {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+		if exposed != "" {
+			h.Set("Access-Control-Expose-Headers", exposed)
+		}
+
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			h.Set("Access-Control-Allow-Methods", methods)
+			h.Set("Access-Control-Allow-Headers", headers)
+			h.Set("Access-Control-Max-Age", maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newRouter(mw ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(mw...)
+	r.GET("/films", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+	r.POST("/films", func(c *gin.Context) {
+		if _, err := io.ReadAll(c.Request.Body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+	return r
+}
+
+func TestCORS_PreflightAllowedOrigin(t *testing.T) {
+	r := newRouter(CORS(CORSConfig{AllowedOrigins: []string{"http://localhost:3000"}, AllowCredentials: true}))
+
+	req := httptest.NewRequest(http.MethodOptions, "/films", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", resp.Code)
+	}
+	if got := resp.Header().Get("Access-Control-Allow-Origin"); got != "http://localhost:3000" {
+		t.Errorf("unexpected allow-origin %q", got)
+	}
+	if got := resp.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("expected credentials to be allowed, got %q", got)
+	}
+	if !strings.Contains(resp.Header().Get("Access-Control-Allow-Methods"), http.MethodPost) {
+		t.Errorf("POST missing from allowed methods")
+	}
+	for _, h := range []string{"If-Match", "If-None-Match"} {
+		if !strings.Contains(resp.Header().Get("Access-Control-Allow-Headers"), h) {
+			t.Errorf("%s missing from allowed headers", h)
+		}
+	}
+}
+
+func TestCORS_UnknownOrigin(t *testing.T) {
+	r := newRouter(CORS(CORSConfig{AllowedOrigins: []string{"http://localhost:3000"}}))
+
+	req := httptest.NewRequest(http.MethodGet, "/films", nil)
+	req.Header.Set("Origin", "http://evil.example")
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.Code)
+	}
+	if got := resp.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("expected no allow-origin header, got %q", got)
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	r := newRouter(SecurityHeaders(SecurityConfig{HSTS: true, ContentSecurityPolicy: "default-src 'self'"}))
+
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/films", nil))
+
+	if resp.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("missing nosniff header")
+	}
+	if resp.Header().Get("Strict-Transport-Security") == "" {
+		t.Errorf("missing HSTS header")
+	}
+	if resp.Header().Get("Content-Security-Policy") != "default-src 'self'" {
+		t.Errorf("unexpected CSP %q", resp.Header().Get("Content-Security-Policy"))
+	}
+}
+
+func TestBodyLimit(t *testing.T) {
+	r := newRouter(BodyLimit(8))
+
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/films", strings.NewReader(`{"title":"too long"}`)))
+	if resp.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", resp.Code)
+	}
+
+	resp = httptest.NewRecorder()
+	r.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/films", strings.NewReader(`{}`)))
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.Code)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	r := newRouter(RequestID())
+
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/films", nil))
+	generated := resp.Header().Get(RequestIDHeader)
+	if len(generated) != 32 {
+		t.Fatalf("expected a generated request id, got %q", generated)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/films", nil)
+	req.Header.Set(RequestIDHeader, "proxy-42")
+	resp = httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	if got := resp.Header().Get(RequestIDHeader); got != "proxy-42" {
+		t.Fatalf("expected the client request id to be kept, got %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/films", nil)
+	req.Header.Set(RequestIDHeader, "bad id\r\n")
+	resp = httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	if got := resp.Header().Get(RequestIDHeader); got == "bad id\r\n" || len(got) != 32 {
+		t.Fatalf("expected a malformed request id to be replaced, got %q", got)
+	}
+}
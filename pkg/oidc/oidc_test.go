@@ -0,0 +1,50 @@
+package oidc_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"filmhub/pkg/oidc"
+	"filmhub/pkg/oidc/oidctest"
+)
+
+func TestAuthorizationCodeFlowWithPKCE(t *testing.T) {
+	idp := oidctest.NewProvider("filmhub", "secret")
+	defer idp.Close()
+	ctx := context.Background()
+
+	p, err := oidc.Discover(ctx, idp.Config("http://localhost:8080/auth/oidc/callback"), idp.Client())
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+
+	verifier := strings.Repeat("v", 43)
+	code, state, err := idp.Authorize(p.AuthCodeURL("state-1", "nonce-1", oidc.Challenge(verifier)))
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	if state != "state-1" || code == "" {
+		t.Fatalf("unexpected callback state=%q code=%q", state, code)
+	}
+
+	if _, err := p.Exchange(ctx, code, "wrong-verifier"); err == nil {
+		t.Fatalf("exchange must fail with a wrong PKCE verifier")
+	}
+
+	code, _, _ = idp.Authorize(p.AuthCodeURL("state-2", "nonce-2", oidc.Challenge(verifier)))
+	tok, err := p.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	if _, err := p.VerifyIDToken(ctx, tok.IDToken, "other-nonce"); err == nil {
+		t.Fatalf("verification must fail on nonce mismatch")
+	}
+	claims, err := p.VerifyIDToken(ctx, tok.IDToken, "nonce-2")
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if claims.Subject != "user-1" || claims.Email != "oidc@example.com" || !claims.EmailVerified {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+}
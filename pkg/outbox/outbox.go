@@ -0,0 +1,158 @@
+// Package outbox delivers domain events stored in a transactional outbox.
+// Events are written in the transaction of the change they describe; a
+// Dispatcher later hands them to Sinks, at least once: an event is retried
+// until every sink accepted it, so sinks may see it more than once.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"filmhub/pkg/worker"
+)
+
+// Event is a domain event waiting in the outbox.
+type Event struct {
+	ID          int64           `json:"id"`
+	Type        string          `json:"type"`
+	AggregateID int             `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   time.Time       `json:"created_at"`
+	// Attempts counts earlier failed deliveries.
+	Attempts int `json:"-"`
+}
+
+// Sink receives events. Deliver must be safe to call again with an event it
+// has already seen.
+type Sink interface {
+	Name() string
+	Deliver(ctx context.Context, e Event) error
+}
+
+// Store is the outbox table.
+type Store interface {
+	// Claim returns up to limit pending events that are due and hides them
+	// from other claims for lease, so that several dispatchers can share
+	// the outbox.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Event, error)
+	MarkDelivered(ctx context.Context, id int64) error
+	// MarkFailed records a failed delivery. The event is retried at
+	// retryAt, or never again when retryAt is nil.
+	MarkFailed(ctx context.Context, id int64, reason string, retryAt *time.Time) error
+}
+
+// Options tune a Dispatcher; zero values pick the defaults.
+type Options struct {
+	PollInterval time.Duration // how often the outbox is checked; 1s
+	BatchSize    int           // events claimed at once; 50
+	Lease        time.Duration // how long claimed events stay hidden; 1m
+	MaxAttempts  int           // deliveries tried before giving up; 10
+	BaseBackoff  time.Duration // delay after the first failure, doubled each time; 5s
+	MaxBackoff   time.Duration // upper bound of the delay; 10m
+	// OnError is told about failed deliveries and storage errors.
+	OnError func(error)
+}
+
+// Dispatcher polls the outbox and delivers events to its sinks.
+type Dispatcher struct {
+	store Store
+	sinks []Sink
+	opts  Options
+	now   func() time.Time
+	loop  *worker.Loop
+}
+
+func NewDispatcher(store Store, sinks []Sink, opts Options) *Dispatcher {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 50
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = time.Minute
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 10
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = 5 * time.Second
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 10 * time.Minute
+	}
+	d := &Dispatcher{store: store, sinks: sinks, opts: opts, now: time.Now}
+	d.loop = worker.New(opts.PollInterval, opts.BatchSize, d.DispatchOnce, opts.OnError)
+	return d
+}
+
+// Start runs the dispatcher in a goroutine until Stop is called.
+func (d *Dispatcher) Start() {
+	d.loop.Start()
+}
+
+// Stop stops polling and waits for the batch in flight to be delivered, or
+// for ctx to be done, whichever comes first; in the latter case deliveries
+// still running are cancelled. Events of an unfinished batch are delivered
+// again once their lease expires.
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	return d.loop.Stop(ctx)
+}
+
+// DispatchOnce claims one batch and delivers it. It returns the number of
+// events claimed.
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	events, err := d.store.Claim(ctx, d.opts.BatchSize, d.opts.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("claim events: %w", err)
+	}
+	for _, e := range events {
+		if err := d.deliver(ctx, e); err != nil {
+			d.report(err)
+		}
+	}
+	return len(events), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, e Event) error {
+	var failures []error
+	for _, s := range d.sinks {
+		if err := s.Deliver(ctx, e); err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", s.Name(), err))
+		}
+	}
+	if len(failures) == 0 {
+		if err := d.store.MarkDelivered(ctx, e.ID); err != nil {
+			return fmt.Errorf("mark event %d delivered: %w", e.ID, err)
+		}
+		return nil
+	}
+
+	cause := errors.Join(failures...)
+	var retryAt *time.Time
+	if attempt := e.Attempts + 1; attempt < d.opts.MaxAttempts {
+		at := d.now().Add(d.backoff(attempt))
+		retryAt = &at
+	}
+	if err := d.store.MarkFailed(ctx, e.ID, cause.Error(), retryAt); err != nil {
+		return fmt.Errorf("mark event %d failed: %w", e.ID, err)
+	}
+	if retryAt == nil {
+		return fmt.Errorf("event %d (%s) dropped after %d attempts: %w", e.ID, e.Type, d.opts.MaxAttempts, cause)
+	}
+	return fmt.Errorf("deliver event %d (%s): %w", e.ID, e.Type, cause)
+}
+
+// backoff is the delay before retry number attempt.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	return worker.Backoff(d.opts.BaseBackoff, d.opts.MaxBackoff, attempt)
+}
+
+func (d *Dispatcher) report(err error) {
+	if d.opts.OnError != nil {
+		d.opts.OnError(err)
+	}
+}
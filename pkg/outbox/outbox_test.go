@@ -0,0 +1,255 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memStore is an in-memory Store. Events are due when their retry time has
+// come; leases are not modelled.
+type memStore struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	events    []Event
+	retryAt   map[int64]time.Time
+	delivered map[int64]bool
+	dropped   map[int64]string
+}
+
+func newMemStore(now func() time.Time, events ...Event) *memStore {
+	return &memStore{
+		now:       now,
+		events:    events,
+		retryAt:   make(map[int64]time.Time),
+		delivered: make(map[int64]bool),
+		dropped:   make(map[int64]string),
+	}
+}
+
+func (s *memStore) Claim(_ context.Context, limit int, _ time.Duration) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Event
+	for _, e := range s.events {
+		if len(out) == limit {
+			break
+		}
+		if s.delivered[e.ID] || s.dropped[e.ID] != "" || s.now().Before(s.retryAt[e.ID]) {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func (s *memStore) MarkDelivered(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delivered[id] = true
+	return nil
+}
+
+func (s *memStore) MarkFailed(_ context.Context, id int64, reason string, retryAt *time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.events {
+		if s.events[i].ID == id {
+			s.events[i].Attempts++
+		}
+	}
+	if retryAt == nil {
+		s.dropped[id] = reason
+		return nil
+	}
+	s.retryAt[id] = *retryAt
+	return nil
+}
+
+// flakySink fails the first failures deliveries of each event.
+type flakySink struct {
+	mu       sync.Mutex
+	failures int
+	seen     map[int64]int
+}
+
+func (s *flakySink) Name() string { return "flaky" }
+
+func (s *flakySink) Deliver(_ context.Context, e Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen == nil {
+		s.seen = make(map[int64]int)
+	}
+	s.seen[e.ID]++
+	if s.seen[e.ID] <= s.failures {
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+func TestDispatcherRetriesWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	store := newMemStore(clock, Event{ID: 1, Type: "film.created"}, Event{ID: 2, Type: "film.updated"})
+	sink := &flakySink{failures: 2}
+	var reported []error
+	d := NewDispatcher(store, []Sink{sink}, Options{
+		BaseBackoff: time.Second,
+		OnError:     func(err error) { reported = append(reported, err) },
+	})
+	d.now = clock
+
+	if n, err := d.DispatchOnce(ctx); err != nil || n != 2 {
+		t.Fatalf("expected two events claimed, got %d, %v", n, err)
+	}
+	if len(reported) != 2 {
+		t.Fatalf("expected both failures reported, got %v", reported)
+	}
+	// Not due yet.
+	if n, _ := d.DispatchOnce(ctx); n != 0 {
+		t.Fatalf("expected no events before the backoff, got %d", n)
+	}
+	now = now.Add(time.Second)
+	d.DispatchOnce(ctx)
+	// The second failure doubles the delay.
+	now = now.Add(time.Second)
+	if n, _ := d.DispatchOnce(ctx); n != 0 {
+		t.Fatalf("expected the backoff to double, got %d events", n)
+	}
+	now = now.Add(time.Second)
+	d.DispatchOnce(ctx)
+	if !store.delivered[1] || !store.delivered[2] {
+		t.Fatalf("expected both events delivered on the third attempt, got %v", store.delivered)
+	}
+	if sink.seen[1] != 3 {
+		t.Fatalf("expected three deliveries of event 1, got %d", sink.seen[1])
+	}
+}
+
+func TestDispatcherDropsAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	store := newMemStore(clock, Event{ID: 1, Type: "review.created"})
+	good := &flakySink{}
+	bad := &flakySink{failures: 100}
+	d := NewDispatcher(store, []Sink{good, bad}, Options{MaxAttempts: 3, BaseBackoff: time.Second})
+	d.now = clock
+
+	for range 5 {
+		d.DispatchOnce(ctx)
+		now = now.Add(time.Hour)
+	}
+	if store.dropped[1] == "" || store.delivered[1] {
+		t.Fatalf("expected event 1 dropped, got delivered=%v dropped=%q", store.delivered[1], store.dropped[1])
+	}
+	// Every attempt goes to every sink, so the good sink sees duplicates.
+	if bad.seen[1] != 3 || good.seen[1] != 3 {
+		t.Fatalf("expected three attempts per sink, got %d and %d", good.seen[1], bad.seen[1])
+	}
+}
+
+func TestDispatcherBackoffIsCapped(t *testing.T) {
+	d := NewDispatcher(nil, nil, Options{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second})
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 40: 5 * time.Second} {
+		if got := d.backoff(attempt); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
+
+func TestDispatcherStartStop(t *testing.T) {
+	store := newMemStore(time.Now, Event{ID: 1, Type: "user.registered"})
+	d := NewDispatcher(store, []Sink{&flakySink{}}, Options{PollInterval: time.Millisecond})
+	d.Start()
+	deadline := time.Now().Add(time.Second)
+	for {
+		store.mu.Lock()
+		done := store.delivered[1]
+		store.mu.Unlock()
+		if done {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("event was not delivered")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := d.Stop(ctx); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	// Stopping twice is fine.
+	if err := d.Stop(ctx); err != nil {
+		t.Fatalf("second stop: %v", err)
+	}
+}
+
+// blockingSink blocks every delivery until its context is done.
+type blockingSink struct {
+	started chan struct{}
+}
+
+func (s *blockingSink) Name() string { return "blocking" }
+
+func (s *blockingSink) Deliver(ctx context.Context, _ Event) error {
+	close(s.started)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestDispatcherStopCancelsDeliveryInFlight(t *testing.T) {
+	store := newMemStore(time.Now, Event{ID: 1, Type: "user.registered"})
+	sink := &blockingSink{started: make(chan struct{})}
+	d := NewDispatcher(store, []Sink{sink}, Options{PollInterval: time.Hour})
+	d.Start()
+	<-sink.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := d.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected stop to give up waiting, got %v", err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := d.Stop(ctx); err != nil {
+		t.Fatalf("expected the delivery in flight to be cancelled, got %v", err)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got Event
+	var header http.Header
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	sink := NewWebhookSink(srv.URL, srv.Client())
+	e := Event{ID: 7, Type: "film.created", AggregateID: 3, Payload: json.RawMessage(`{"id":3}`)}
+	if err := sink.Deliver(context.Background(), e); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if got.ID != 7 || got.AggregateID != 3 || string(got.Payload) != `{"id":3}` {
+		t.Fatalf("unexpected body %+v", got)
+	}
+	if header.Get("X-Event-ID") != "7" || header.Get("X-Event-Type") != "film.created" {
+		t.Fatalf("unexpected headers %v", header)
+	}
+
+	status = http.StatusBadGateway
+	if err := sink.Deliver(context.Background(), e); err == nil {
+		t.Fatal("expected a non-2xx response to fail the delivery")
+	}
+}
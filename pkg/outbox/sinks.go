@@ -0,0 +1,129 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+
+	"filmhub/pkg/config"
+)
+
+// NewSinks builds the sinks listed in OUTBOX_SINKS. db is used by the
+// notify sink.
+func NewSinks(cfg *config.Config, log *zap.SugaredLogger, db Execer) ([]Sink, error) {
+	var sinks []Sink
+	for _, name := range cfg.OutboxSinks {
+		switch name {
+		case "log":
+			sinks = append(sinks, NewLogSink(log))
+		case "webhook":
+			if cfg.OutboxWebhookURL == "" {
+				return nil, fmt.Errorf("OUTBOX_WEBHOOK_URL must be set for the webhook sink")
+			}
+			sinks = append(sinks, NewWebhookSink(cfg.OutboxWebhookURL, nil))
+		case "notify":
+			sinks = append(sinks, NewNotifySink(db, cfg.OutboxNotifyChannel))
+		default:
+			return nil, fmt.Errorf("unknown outbox sink %q", name)
+		}
+	}
+	return sinks, nil
+}
+
+// LogSink writes events to the application log.
+type LogSink struct {
+	log *zap.SugaredLogger
+}
+
+func NewLogSink(log *zap.SugaredLogger) *LogSink {
+	return &LogSink{log: log}
+}
+
+func (s *LogSink) Name() string { return "log" }
+
+func (s *LogSink) Deliver(_ context.Context, e Event) error {
+	s.log.Infow("domain event", "id", e.ID, "type", e.Type, "aggregate_id", e.AggregateID, "payload", string(e.Payload))
+	return nil
+}
+
+// WebhookSink POSTs each event as JSON to a fixed URL. Any 2xx response
+// counts as delivered. Receivers deduplicate by the event ID, also sent in
+// the X-Event-ID header.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink posts to url with client; nil uses a client with a 10s
+// timeout.
+func NewWebhookSink(url string, client *http.Client) *WebhookSink {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &WebhookSink{url: url, client: client}
+}
+
+func (s *WebhookSink) Name() string { return "webhook" }
+
+func (s *WebhookSink) Deliver(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", fmt.Sprint(e.ID))
+	req.Header.Set("X-Event-Type", e.Type)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// Execer runs a statement; *pgxpool.Pool satisfies it.
+type Execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// NotifySink publishes events with Postgres NOTIFY on a channel, for
+// listeners inside the database's network. The notification carries the
+// event without its payload, which can exceed NOTIFY's 8000 byte limit.
+type NotifySink struct {
+	db      Execer
+	channel string
+}
+
+func NewNotifySink(db Execer, channel string) *NotifySink {
+	return &NotifySink{db: db, channel: channel}
+}
+
+func (s *NotifySink) Name() string { return "notify" }
+
+func (s *NotifySink) Deliver(ctx context.Context, e Event) error {
+	msg, err := json.Marshal(struct {
+		ID          int64  `json:"id"`
+		Type        string `json:"type"`
+		AggregateID int    `json:"aggregate_id"`
+	}{e.ID, e.Type, e.AggregateID})
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(ctx, `SELECT pg_notify($1, $2)`, s.channel, string(msg))
+	return err
+}
@@ -0,0 +1,177 @@
+// Package pubsub is an in-process publish/subscribe hub for live updates.
+// Messages get increasing IDs and the most recent ones are kept, so that a
+// subscriber that reconnects can resume after the last message it saw.
+package pubsub
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Message is a published update.
+type Message struct {
+	ID    uint64
+	Topic string
+	Event string
+	Data  json.RawMessage
+}
+
+// Hub fans messages out to subscribers. Publishing never blocks: a
+// subscriber that falls more than its queue behind is dropped and has to
+// resubscribe, resuming from the history.
+type Hub struct {
+	queue int
+
+	mu      sync.Mutex
+	lastID  uint64
+	history []Message // ring buffer of the latest messages
+	next    int       // where the next message goes in history
+	full    bool
+	subs    map[*Subscription]struct{}
+	closed  bool
+}
+
+// New returns a Hub remembering the latest history messages for resuming,
+// with up to queue undelivered messages per subscriber.
+func New(history, queue int) *Hub {
+	if history < 1 {
+		history = 1
+	}
+	if queue < 1 {
+		queue = 1
+	}
+	return &Hub{queue: queue, history: make([]Message, history), subs: make(map[*Subscription]struct{})}
+}
+
+// Subscription receives the messages of a topic, or of every topic.
+type Subscription struct {
+	// C delivers messages in ID order. It is closed when the subscription
+	// is closed, or dropped for falling behind, or the hub shuts down.
+	C <-chan Message
+	// Gap is set when resuming could not replay every message after the
+	// requested ID, because it is no longer in the history or was issued
+	// before the hub started.
+	Gap bool
+
+	hub   *Hub
+	topic string
+	ch    chan Message
+}
+
+// Subscribe returns a subscription to topic; an empty topic receives every
+// message. With afterID > 0, the messages after it still in the history are
+// delivered first.
+func (h *Hub) Subscribe(topic string, afterID uint64) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := &Subscription{hub: h, topic: topic}
+	var replay []Message
+	if afterID > 0 {
+		replay, s.Gap = h.since(afterID)
+	}
+	s.ch = make(chan Message, h.queue+len(replay))
+	s.C = s.ch
+	for _, m := range replay {
+		if s.wants(m) {
+			s.ch <- m
+		}
+	}
+	if h.closed {
+		close(s.ch)
+		return s
+	}
+	h.subs[s] = struct{}{}
+	return s
+}
+
+// since returns the messages in the history after id, and whether some
+// were missed.
+func (h *Hub) since(id uint64) ([]Message, bool) {
+	if id > h.lastID {
+		// From before a restart.
+		return nil, true
+	}
+	var out []Message
+	n := h.next
+	if h.full {
+		n = len(h.history)
+	}
+	for i := 0; i < n; i++ {
+		m := h.history[(h.next-n+i+len(h.history))%len(h.history)]
+		if m.ID > id {
+			out = append(out, m)
+		}
+	}
+	oldest := h.lastID + 1
+	if n > 0 {
+		oldest = h.history[(h.next-n+len(h.history))%len(h.history)].ID
+	}
+	return out, id+1 < oldest
+}
+
+// Publish sends event with data marshalled to JSON to the subscribers of
+// topic and of every topic. Data that can't be marshalled is dropped.
+func (h *Hub) Publish(topic, event string, data any) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.lastID++
+	m := Message{ID: h.lastID, Topic: topic, Event: event, Data: raw}
+	h.history[h.next] = m
+	h.next = (h.next + 1) % len(h.history)
+	if h.next == 0 {
+		h.full = true
+	}
+	for s := range h.subs {
+		if !s.wants(m) {
+			continue
+		}
+		select {
+		case s.ch <- m:
+		default:
+			h.drop(s)
+		}
+	}
+}
+
+// Subscribers returns the number of open subscriptions.
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// Close ends every subscription; later ones are closed right away. Call it
+// on shutdown so that streaming responses finish.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for s := range h.subs {
+		h.drop(s)
+	}
+}
+
+func (h *Hub) drop(s *Subscription) {
+	delete(h.subs, s)
+	close(s.ch)
+}
+
+// Close unsubscribes. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	if _, ok := s.hub.subs[s]; ok {
+		s.hub.drop(s)
+	}
+}
+
+func (s *Subscription) wants(m Message) bool {
+	return s.topic == "" || s.topic == m.Topic
+}
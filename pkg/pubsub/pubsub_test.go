@@ -0,0 +1,93 @@
+package pubsub
+
+import "testing"
+
+func receive(t *testing.T, s *Subscription) []uint64 {
+	t.Helper()
+	var ids []uint64
+	for {
+		select {
+		case m, ok := <-s.C:
+			if !ok {
+				return ids
+			}
+			ids = append(ids, m.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestHubTopics(t *testing.T) {
+	h := New(10, 10)
+	film := h.Subscribe("film:1", 0)
+	all := h.Subscribe("", 0)
+
+	h.Publish("film:1", "review.created", map[string]int{"id": 1})
+	h.Publish("film:2", "review.created", map[string]int{"id": 2})
+
+	if got := receive(t, film); len(got) != 1 || got[0] != 1 {
+		t.Fatalf("expected only the film's message, got %v", got)
+	}
+	if got := receive(t, all); len(got) != 2 {
+		t.Fatalf("expected every message, got %v", got)
+	}
+
+	film.Close()
+	film.Close()
+	if h.Subscribers() != 1 {
+		t.Fatalf("expected one subscriber left, got %d", h.Subscribers())
+	}
+	if _, ok := <-film.C; ok {
+		t.Fatal("expected a closed channel after Close")
+	}
+}
+
+func TestHubResume(t *testing.T) {
+	h := New(3, 10)
+	for range 5 {
+		h.Publish("film:1", "film.rating", 1)
+	}
+
+	s := h.Subscribe("film:1", 3)
+	if got := receive(t, s); len(got) != 2 || got[0] != 4 || got[1] != 5 || s.Gap {
+		t.Fatalf("expected messages 4 and 5 without a gap, got %v, gap %v", got, s.Gap)
+	}
+	// Message 2 has left the history.
+	s = h.Subscribe("film:1", 1)
+	if got := receive(t, s); len(got) != 3 || !s.Gap {
+		t.Fatalf("expected messages 3 to 5 with a gap, got %v, gap %v", got, s.Gap)
+	}
+	// An ID from before a restart.
+	s = h.Subscribe("film:1", 99)
+	if got := receive(t, s); len(got) != 0 || !s.Gap {
+		t.Fatalf("expected nothing with a gap, got %v, gap %v", got, s.Gap)
+	}
+}
+
+func TestHubDropsSlowSubscribers(t *testing.T) {
+	h := New(10, 2)
+	slow := h.Subscribe("", 0)
+	for range 3 {
+		h.Publish("film:1", "film.rating", 1)
+	}
+	if got := receive(t, slow); len(got) != 2 {
+		t.Fatalf("expected the queued messages before the channel closes, got %v", got)
+	}
+	if h.Subscribers() != 0 {
+		t.Fatal("expected the slow subscriber to be dropped")
+	}
+	// It resumes from the history.
+	again := h.Subscribe("", 2)
+	if got := receive(t, again); len(got) != 1 || got[0] != 3 {
+		t.Fatalf("expected message 3 on resume, got %v", got)
+	}
+
+	h.Close()
+	if _, ok := <-again.C; ok {
+		t.Fatal("expected Close to end subscriptions")
+	}
+	if _, ok := <-h.Subscribe("", 0).C; ok {
+		t.Fatal("expected subscriptions after Close to be closed")
+	}
+}
@@ -0,0 +1,135 @@
+// Package recommend computes item-item similarities for collaborative
+// filtering from user ratings.
+package recommend
+
+import (
+	"math"
+	"sort"
+)
+
+// Rating is a user's rating of an item.
+type Rating struct {
+	UserID int
+	ItemID int
+	Value  float64
+}
+
+// Similarity is how alike users rate two items, from -1 to 1, and how many
+// users rated both.
+type Similarity struct {
+	ItemID  int
+	OtherID int
+	Score   float64
+	Support int
+}
+
+// Options tune ItemSimilarities.
+type Options struct {
+	// MinSupport is the number of users who must have rated both items
+	// (default 2).
+	MinSupport int
+	// Shrinkage damps scores backed by few users: a score is multiplied by
+	// support / (support + Shrinkage) (default 5; negative disables it).
+	Shrinkage float64
+	// Neighbors is the number of most similar items kept per item
+	// (default 50).
+	Neighbors int
+}
+
+func (o *Options) defaults() {
+	if o.MinSupport < 1 {
+		o.MinSupport = 2
+	}
+	if o.Shrinkage < 0 {
+		o.Shrinkage = 0
+	} else if o.Shrinkage == 0 {
+		o.Shrinkage = 5
+	}
+	if o.Neighbors < 1 {
+		o.Neighbors = 50
+	}
+}
+
+type pair struct{ a, b int }
+
+type sums struct {
+	dot, a2, b2 float64
+	support     int
+}
+
+// ItemSimilarities returns, for every item, its most similar items by
+// adjusted cosine similarity: ratings are centred on each user's mean, so
+// that generous and harsh raters compare fairly. Only positive scores are
+// returned, ordered by item and then by descending score. A pair is scored
+// the same either way round, but each item keeps only its opts.Neighbors
+// best, so B may be among A's neighbours without A being among B's.
+func ItemSimilarities(ratings []Rating, opts Options) []Similarity {
+	opts.defaults()
+
+	byUser := map[int][]Rating{}
+	for _, r := range ratings {
+		byUser[r.UserID] = append(byUser[r.UserID], r)
+	}
+	acc := map[pair]*sums{}
+	for _, rs := range byUser {
+		// A single rating says nothing about how items compare.
+		if len(rs) < 2 {
+			continue
+		}
+		var mean float64
+		for _, r := range rs {
+			mean += r.Value
+		}
+		mean /= float64(len(rs))
+		for i := range rs {
+			for j := range rs {
+				if rs[i].ItemID >= rs[j].ItemID {
+					continue
+				}
+				p := pair{rs[i].ItemID, rs[j].ItemID}
+				s := acc[p]
+				if s == nil {
+					s = &sums{}
+					acc[p] = s
+				}
+				da, db := rs[i].Value-mean, rs[j].Value-mean
+				s.dot += da * db
+				s.a2 += da * da
+				s.b2 += db * db
+				s.support++
+			}
+		}
+	}
+
+	neighbors := map[int][]Similarity{}
+	for p, s := range acc {
+		if s.support < opts.MinSupport || s.a2 == 0 || s.b2 == 0 {
+			continue
+		}
+		score := s.dot / math.Sqrt(s.a2*s.b2)
+		score *= float64(s.support) / (float64(s.support) + opts.Shrinkage)
+		if score <= 0 {
+			continue
+		}
+		neighbors[p.a] = append(neighbors[p.a], Similarity{ItemID: p.a, OtherID: p.b, Score: score, Support: s.support})
+		neighbors[p.b] = append(neighbors[p.b], Similarity{ItemID: p.b, OtherID: p.a, Score: score, Support: s.support})
+	}
+
+	items := make([]int, 0, len(neighbors))
+	for id := range neighbors {
+		items = append(items, id)
+	}
+	sort.Ints(items)
+	var out []Similarity
+	for _, id := range items {
+		ns := neighbors[id]
+		sort.Slice(ns, func(i, j int) bool {
+			if ns[i].Score != ns[j].Score {
+				return ns[i].Score > ns[j].Score
+			}
+			return ns[i].OtherID < ns[j].OtherID
+		})
+		out = append(out, ns[:min(len(ns), opts.Neighbors)]...)
+	}
+	return out
+}
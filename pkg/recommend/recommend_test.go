@@ -0,0 +1,52 @@
+package recommend
+
+import (
+	"math"
+	"testing"
+)
+
+func TestItemSimilarities(t *testing.T) {
+	// Users 1–3 like items 1 and 2 together and dislike item 3; user 4 only
+	// rated item 4.
+	ratings := []Rating{
+		{1, 1, 9}, {1, 2, 8}, {1, 3, 2},
+		{2, 1, 10}, {2, 2, 9}, {2, 3, 3},
+		{3, 1, 7}, {3, 2, 8}, {3, 3, 1},
+		{4, 4, 10},
+	}
+	got := ItemSimilarities(ratings, Options{Shrinkage: -1})
+
+	if len(got) != 2 {
+		t.Fatalf("expected only items 1 and 2 to be similar, got %+v", got)
+	}
+	if got[0].ItemID != 1 || got[0].OtherID != 2 || got[1].ItemID != 2 || got[1].OtherID != 1 {
+		t.Fatalf("expected the pair in both directions, got %+v", got)
+	}
+	if got[0].Support != 3 || got[0].Score < 0.9 || got[0].Score > 1 || got[0].Score != got[1].Score {
+		t.Fatalf("expected a strong symmetric score backed by 3 users, got %+v", got)
+	}
+}
+
+func TestItemSimilaritiesOptions(t *testing.T) {
+	ratings := []Rating{
+		{1, 1, 9}, {1, 2, 9}, {1, 3, 1},
+		{2, 1, 8}, {2, 2, 9}, {2, 3, 2},
+	}
+	raw := ItemSimilarities(ratings, Options{Shrinkage: -1})
+	shrunk := ItemSimilarities(ratings, Options{})
+	if math.Abs(shrunk[0].Score-raw[0].Score*2/7) > 1e-9 {
+		t.Fatalf("expected the default shrinkage of 5 with 2 raters, got %v from %v", shrunk[0].Score, raw[0].Score)
+	}
+	if got := ItemSimilarities(ratings, Options{MinSupport: 3}); len(got) != 0 {
+		t.Fatalf("expected no pairs below the minimum support, got %+v", got)
+	}
+
+	// Item 1 is most like item 2, then item 4.
+	ratings = append(ratings, Rating{1, 4, 7}, Rating{2, 4, 8})
+	got := ItemSimilarities(ratings, Options{Neighbors: 1})
+	for _, s := range got {
+		if s.ItemID == 1 && s.OtherID != 2 {
+			t.Fatalf("expected only the closest neighbour of item 1, got %+v", got)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package signedtoken
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIssueAndVerify(t *testing.T) {
+	s, err := New("testsecret")
+	if err != nil {
+		t.Fatalf("new signer: %v", err)
+	}
+	token, issued, err := s.Issue("verify_email", 42, time.Hour)
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+
+	p, err := s.Verify(token, "verify_email")
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if p != issued || p.UserID != 42 {
+		t.Errorf("unexpected payload %+v", p)
+	}
+
+	if _, err := s.Verify(token, "reset_password"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid for wrong purpose, got %v", err)
+	}
+	if _, err := s.Verify(token+"x", "verify_email"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid for tampered token, got %v", err)
+	}
+
+	s.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if _, err := s.Verify(token, "verify_email"); !errors.Is(err, ErrExpired) {
+		t.Errorf("expected ErrExpired, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+// Package textsim compares short texts, such as film descriptions, by the
+// cosine similarity of their TF-IDF vectors.
+package textsim
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minTerm is the shortest word, in runes, that counts as a term.
+const minTerm = 3
+
+// stemLength is the number of runes a term is cut to. Comparing prefixes is
+// a rough stand-in for a stemmer but makes "гангстер" and "гангстеры", or
+// "gangster" and "gangsters", the same term.
+const stemLength = 6
+
+// stopWords are frequent words that say nothing about what a text is about.
+var stopWords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`
+		the and for with from into that this his her their they them are was were has have had
+		its not but who what when where which while about after before over under than then
+		там это как так его она они оно что чтобы для при над под без про после перед или
+		но же ещё уже был была были было есть её их них ним нее него который которая
+		которые когда где чем тем все всё весь своей свой свою своих между через`) {
+		stopWords[w] = true
+	}
+}
+
+// Index holds the TF-IDF vectors of a set of documents. It is read-only
+// once built and safe for concurrent use.
+type Index struct {
+	vectors map[int]vector
+}
+
+type vector struct {
+	weights map[string]float64 // unit length
+	words   map[string]string  // term -> first word it was seen as
+}
+
+// New indexes docs by ID. Terms are weighted by their count in a document
+// times the log of how rare they are across docs.
+func New(docs map[int]string) *Index {
+	type doc struct {
+		counts map[string]int
+		words  map[string]string
+	}
+	parsed := make(map[int]doc, len(docs))
+	df := map[string]int{}
+	for id, text := range docs {
+		d := doc{counts: map[string]int{}, words: map[string]string{}}
+		for _, word := range words(text) {
+			term := stem(word)
+			if d.counts[term] == 0 {
+				df[term]++
+				d.words[term] = word
+			}
+			d.counts[term]++
+		}
+		parsed[id] = d
+	}
+
+	n := float64(len(docs))
+	idx := &Index{vectors: make(map[int]vector, len(docs))}
+	for id, d := range parsed {
+		v := vector{weights: make(map[string]float64, len(d.counts)), words: d.words}
+		var norm float64
+		for term, count := range d.counts {
+			w := (1 + math.Log(float64(count))) * math.Log(1+n/float64(df[term]))
+			v.weights[term] = w
+			norm += w * w
+		}
+		norm = math.Sqrt(norm)
+		for term := range v.weights {
+			v.weights[term] /= norm
+		}
+		idx.vectors[id] = v
+	}
+	return idx
+}
+
+// Similarity returns the cosine similarity of documents a and b, from 0 to
+// 1, and up to maxTerms of the shared terms that contribute most to it, as
+// they appear in a. Unknown documents have no similarity.
+func (idx *Index) Similarity(a, b, maxTerms int) (float64, []string) {
+	va, vb := idx.vectors[a], idx.vectors[b]
+	if len(va.weights) > len(vb.weights) {
+		// Iterate over the shorter vector; terms are still reported from a.
+		return idx.similarity(vb, va, va, maxTerms)
+	}
+	return idx.similarity(va, vb, va, maxTerms)
+}
+
+func (idx *Index) similarity(short, long, from vector, maxTerms int) (float64, []string) {
+	type shared struct {
+		term   string
+		weight float64
+	}
+	var score float64
+	var terms []shared
+	for term, w := range short.weights {
+		if other, ok := long.weights[term]; ok {
+			score += w * other
+			terms = append(terms, shared{term, w * other})
+		}
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if terms[i].weight != terms[j].weight {
+			return terms[i].weight > terms[j].weight
+		}
+		return terms[i].term < terms[j].term
+	})
+	out := make([]string, 0, min(len(terms), maxTerms))
+	for _, t := range terms[:min(len(terms), maxTerms)] {
+		out = append(out, from.words[t.term])
+	}
+	return min(score, 1), out
+}
+
+// words splits text into lowercase words of letters and digits, leaving out
+// short words and stop words.
+func words(text string) []string {
+	var out []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		w = strings.ReplaceAll(w, "ё", "е")
+		if utf8.RuneCountInString(w) >= minTerm && !stopWords[w] {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+func stem(word string) string {
+	if utf8.RuneCountInString(word) <= stemLength {
+		return word
+	}
+	return string([]rune(word)[:stemLength])
+}
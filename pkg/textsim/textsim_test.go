@@ -0,0 +1,44 @@
+package textsim
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestIndex_Similarity(t *testing.T) {
+	idx := New(map[int]string{
+		1: "A gangster plans one last heist in Los Angeles.",
+		2: "Gangsters plan a heist that goes wrong.",
+		3: "A shy waitress changes the lives of those around her in Paris.",
+		4: "",
+	})
+
+	heist, terms := idx.Similarity(1, 2, 2)
+	if heist <= 0 || len(terms) != 2 || !slices.Contains(terms, "heist") || !slices.Contains(terms, "gangster") {
+		t.Fatalf("expected the heist films to share gangster and heist, got %v %v", heist, terms)
+	}
+	if back, _ := idx.Similarity(2, 1, 2); math.Abs(back-heist) > 1e-9 {
+		t.Fatalf("expected a symmetric score, got %v and %v", heist, back)
+	}
+	if self, _ := idx.Similarity(1, 1, 0); self < 0.999 || self > 1 {
+		t.Fatalf("expected a text to match itself, got %v", self)
+	}
+	if other, terms := idx.Similarity(1, 3, 5); other != 0 || len(terms) != 0 {
+		t.Fatalf("expected unrelated texts not to match, got %v %v", other, terms)
+	}
+	if empty, _ := idx.Similarity(1, 4, 5); empty != 0 {
+		t.Fatalf("expected an empty text not to match, got %v", empty)
+	}
+	if unknown, _ := idx.Similarity(1, 9, 5); unknown != 0 {
+		t.Fatalf("expected an unknown document not to match, got %v", unknown)
+	}
+}
+
+func TestWords(t *testing.T) {
+	got := words("Ёжик и его друзья — в тумане, 1975!")
+	want := []string{"ежик", "друзья", "тумане", "1975"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("words = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,106 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/text/language"
+)
+
+// Supported languages. Russian is the default, matching the API docs.
+const (
+	LangRU = "ru"
+	LangEN = "en"
+)
+
+var matcher = language.NewMatcher([]language.Tag{language.Russian, language.English})
+
+// Language picks the response language from an Accept-Language header.
+func Language(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return LangRU
+	}
+	_, idx, conf := matcher.Match(tags...)
+	if conf == language.No {
+		return LangRU
+	}
+	if idx == 1 {
+		return LangEN
+	}
+	return LangRU
+}
+
+// Messages keyed by language, then by validation tag. "{param}" is replaced
+// with the rule parameter. Length rules have separate wording for strings.
+var messages = map[string]map[string]string{
+	LangRU: {
+		"required":     "обязательное поле",
+		"email":        "некорректный email",
+		"url":          "некорректный URL",
+		"min":          "значение должно быть не меньше {param}",
+		"min_string":   "должно содержать не менее {param} символов",
+		"max":          "значение должно быть не больше {param}",
+		"max_string":   "должно содержать не более {param} символов",
+		"len_string":   "должно содержать ровно {param} символов",
+		"gte":          "значение должно быть не меньше {param}",
+		"lte":          "значение должно быть не больше {param}",
+		"oneof":        "допустимые значения: {param}",
+		"username":     "может содержать только латинские буквы, цифры и символы _ . - (от 3 до 32 символов)",
+		"comment":      "текст слишком длинный",
+		"notfarfuture": "дата слишком далеко в будущем",
+		"default":      "некорректное значение",
+
+		"invalid_body":   "некорректное тело запроса",
+		"validation":     "ошибка валидации",
+		"body_too_large": "тело запроса слишком большое",
+	},
+	LangEN: {
+		"required":     "is required",
+		"email":        "must be a valid email",
+		"url":          "must be a valid URL",
+		"min":          "must be at least {param}",
+		"min_string":   "must be at least {param} characters long",
+		"max":          "must be at most {param}",
+		"max_string":   "must be at most {param} characters long",
+		"len_string":   "must be exactly {param} characters long",
+		"gte":          "must be at least {param}",
+		"lte":          "must be at most {param}",
+		"oneof":        "must be one of: {param}",
+		"username":     "may contain only latin letters, digits, _ . - (3 to 32 characters)",
+		"comment":      "text is too long",
+		"notfarfuture": "date is too far in the future",
+		"default":      "is invalid",
+
+		"invalid_body":   "malformed request body",
+		"validation":     "validation failed",
+		"body_too_large": "request body too large",
+	},
+}
+
+// Message returns a localized general message, e.g. Message("validation", "en").
+func Message(key, lang string) string {
+	if m, ok := messages[lang][key]; ok {
+		return m
+	}
+	return messages[LangRU][key]
+}
+
+func message(fe validator.FieldError, lang string) string {
+	table, ok := messages[lang]
+	if !ok {
+		table = messages[LangRU]
+	}
+	key := fe.Tag()
+	if fe.Kind() == reflect.String {
+		if _, ok := table[key+"_string"]; ok {
+			key += "_string"
+		}
+	}
+	tmpl, ok := table[key]
+	if !ok {
+		tmpl = table["default"]
+	}
+	return strings.ReplaceAll(tmpl, "{param}", fe.Param())
+}
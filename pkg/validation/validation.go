@@ -0,0 +1,161 @@
+// Package validation enforces `validate` struct tags on request DTOs and
+// renders failures as per-field messages in Russian or English.
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// MaxCommentLength is the longest review/comment text accepted by the
+// "comment" rule, in characters.
+const MaxCommentLength = 2000
+
+// defaultFutureYears is used by "notfarfuture" when no parameter is given.
+const defaultFutureYears = 5
+
+var usernameRe = regexp.MustCompile(`^[A-Za-z0-9_.-]{3,32}$`)
+
+// Validator validates structs by their `validate` tags. It satisfies gin's
+// binding.StructValidator, so it can also be installed as binding.Validator.
+type Validator struct {
+	validate *validator.Validate
+}
+
+// New builds a Validator with the project's custom rules registered:
+//
+//	username        latin letters, digits, "_", "." and "-", 3–32 characters
+//	comment         at most MaxCommentLength characters after trimming spaces
+//	notfarfuture=N  a time at most N years (default 5) from now
+func New() *Validator {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.SetTagName("validate")
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+	mustRegister(v, "username", func(fl validator.FieldLevel) bool {
+		return usernameRe.MatchString(fl.Field().String())
+	})
+	mustRegister(v, "comment", func(fl validator.FieldLevel) bool {
+		return utf8.RuneCountInString(strings.TrimSpace(fl.Field().String())) <= MaxCommentLength
+	})
+	mustRegister(v, "notfarfuture", notFarFuture)
+	return &Validator{validate: v}
+}
+
+func mustRegister(v *validator.Validate, tag string, fn validator.Func) {
+	if err := v.RegisterValidation(tag, fn); err != nil {
+		panic(err)
+	}
+}
+
+func notFarFuture(fl validator.FieldLevel) bool {
+	t, ok := fl.Field().Interface().(time.Time)
+	if !ok {
+		return false
+	}
+	if t.IsZero() {
+		return true
+	}
+	years := defaultFutureYears
+	if p := fl.Param(); p != "" {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return false
+		}
+		years = n
+	}
+	return !t.After(time.Now().AddDate(years, 0, 0))
+}
+
+// ElementError reports a failure of one element of a validated slice.
+type ElementError struct {
+	Index int
+	Err   error
+}
+
+func (e *ElementError) Error() string {
+	return "[" + strconv.Itoa(e.Index) + "]: " + e.Err.Error()
+}
+
+func (e *ElementError) Unwrap() error { return e.Err }
+
+// ValidateStruct validates a struct, a pointer to one, or every element of a
+// slice of them. Other values are accepted as is.
+func (v *Validator) ValidateStruct(obj any) error {
+	if obj == nil {
+		return nil
+	}
+	value := reflect.ValueOf(obj)
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return nil
+		}
+		if value.Elem().Kind() != reflect.Struct {
+			return v.ValidateStruct(value.Elem().Interface())
+		}
+		return v.validate.Struct(obj)
+	case reflect.Struct:
+		return v.validate.Struct(obj)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := v.ValidateStruct(value.Index(i).Interface()); err != nil {
+				return &ElementError{Index: i, Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+// Engine exposes the underlying go-playground validator.
+func (v *Validator) Engine() any {
+	return v.validate
+}
+
+// FieldErrors converts a validation error into field -> message pairs in the
+// given language. Keys use JSON names; nested fields are dotted and slice
+// fields indexed ("credits[0].name"), and elements of a validated slice are
+// prefixed with "[i]." ("[1].credits[0].name"). It returns nil when err is
+// not a validation error.
+func FieldErrors(err error, lang string) map[string]string {
+	prefix := ""
+	var elemErr *ElementError
+	for errors.As(err, &elemErr) {
+		prefix += "[" + strconv.Itoa(elemErr.Index) + "]."
+		err = elemErr.Err
+	}
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return nil
+	}
+	out := make(map[string]string, len(verrs))
+	for _, fe := range verrs {
+		out[prefix+fieldPath(fe)] = message(fe, lang)
+	}
+	return out
+}
+
+// fieldPath strips the root struct name from the namespace.
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if _, rest, ok := strings.Cut(ns, "."); ok {
+		return rest
+	}
+	return fe.Field()
+}
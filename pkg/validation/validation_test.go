@@ -0,0 +1,84 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type signup struct {
+	Username string    `json:"username" validate:"required,username"`
+	Password string    `json:"password" validate:"required,min=6"`
+	Comment  string    `json:"comment" validate:"comment"`
+	Release  time.Time `json:"release_date" validate:"notfarfuture"`
+}
+
+func TestValidator_CustomRules(t *testing.T) {
+	v := New()
+
+	valid := signup{Username: "john_doe", Password: "secret1", Comment: "ok", Release: time.Now()}
+	if err := v.ValidateStruct(&valid); err != nil {
+		t.Fatalf("expected valid struct, got %v", err)
+	}
+
+	invalid := signup{
+		Username: "джон",
+		Password: "123",
+		Comment:  strings.Repeat("я", MaxCommentLength+1),
+		Release:  time.Now().AddDate(50, 0, 0),
+	}
+	fields := FieldErrors(v.ValidateStruct(&invalid), LangEN)
+	for _, name := range []string{"username", "password", "comment", "release_date"} {
+		if _, ok := fields[name]; !ok {
+			t.Errorf("expected error for %q, got %v", name, fields)
+		}
+	}
+	if fields["password"] != "must be at least 6 characters long" {
+		t.Errorf("unexpected password message %q", fields["password"])
+	}
+}
+
+func TestFieldErrors_Localized(t *testing.T) {
+	v := New()
+	err := v.ValidateStruct([]signup{{Username: "john_doe", Password: "secret1"}, {}})
+
+	ru := FieldErrors(err, LangRU)
+	if ru["[1].username"] != "обязательное поле" {
+		t.Errorf("unexpected russian message: %v", ru)
+	}
+	en := FieldErrors(err, LangEN)
+	if en["[1].username"] != "is required" {
+		t.Errorf("unexpected english message: %v", en)
+	}
+}
+
+type credit struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type credited struct {
+	Credits []credit `json:"credits" validate:"dive"`
+}
+
+func TestFieldErrors_NestedPaths(t *testing.T) {
+	v := New()
+	fields := FieldErrors(v.ValidateStruct([]credited{{}, {Credits: []credit{{Name: "Al Pacino"}, {}}}}), LangEN)
+	if _, ok := fields["[1].credits[1].name"]; !ok || len(fields) != 1 {
+		t.Errorf("unexpected field paths: %v", fields)
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	cases := map[string]string{
+		"":                       LangRU,
+		"en-US,en;q=0.9":         LangEN,
+		"ru-RU,ru;q=0.9,en;q=.8": LangRU,
+		"de-DE":                  LangRU,
+		"de-DE,en;q=0.5":         LangEN,
+	}
+	for header, want := range cases {
+		if got := Language(header); got != want {
+			t.Errorf("Language(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Request headers of a delivery.
+const (
+	// SignatureHeader carries "t=<unix seconds>,v1=<hex HMAC-SHA256>". The
+	// HMAC is keyed with the subscription secret and covers
+	// "<t>.<body>", so a captured request can't be replayed later with a
+	// new timestamp.
+	SignatureHeader = "X-FilmHub-Signature"
+	EventHeader     = "X-FilmHub-Event"
+	DeliveryHeader  = "X-FilmHub-Delivery"
+)
+
+var (
+	// ErrBadSignature is returned by Verify for a malformed header or a
+	// signature that doesn't match.
+	ErrBadSignature = errors.New("webhook: invalid signature")
+	// ErrStaleSignature is returned by Verify for a timestamp outside the
+	// tolerance.
+	ErrStaleSignature = errors.New("webhook: signature timestamp out of tolerance")
+)
+
+// Sign returns the SignatureHeader value for body sent at t.
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac(secret, ts, body))
+}
+
+// Verify checks a SignatureHeader value against body, as receivers should.
+// Signatures made more than tolerance away from now are refused.
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(part, "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sig = v
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrBadSignature
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, mac(secret, ts, body)) {
+		return ErrBadSignature
+	}
+	if d := now.Sub(time.Unix(unix, 0)); d > tolerance || d < -tolerance {
+		return ErrStaleSignature
+	}
+	return nil
+}
+
+func mac(secret, ts string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
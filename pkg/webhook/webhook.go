@@ -0,0 +1,190 @@
+// Package webhook sends queued deliveries to subscribers' endpoints. Each
+// request is signed with the subscription secret (see Sign); failed
+// deliveries are retried with exponential backoff and jitter and
+// dead-lettered after a number of attempts.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"filmhub/pkg/worker"
+)
+
+// Delivery is one event queued for one subscription.
+type Delivery struct {
+	ID        int64
+	URL       string
+	Secret    string
+	EventType string
+	Body      []byte
+	// Attempts counts earlier failed attempts.
+	Attempts int
+}
+
+// Attempt is the outcome of sending a delivery once.
+type Attempt struct {
+	StatusCode int // 0 when no response was received
+	Error      string
+	Duration   time.Duration
+}
+
+// Store is the delivery queue.
+type Store interface {
+	// Claim returns up to limit pending deliveries that are due and hides
+	// them from other claims for lease.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error)
+	MarkDelivered(ctx context.Context, id int64, a Attempt) error
+	// MarkFailed records a failed attempt. The delivery is retried at
+	// retryAt, or dead-lettered when retryAt is nil.
+	MarkFailed(ctx context.Context, id int64, a Attempt, retryAt *time.Time) error
+}
+
+// Options tune a Sender; zero values pick the defaults.
+type Options struct {
+	PollInterval time.Duration // how often the queue is checked; 1s
+	BatchSize    int           // deliveries claimed at once; 20
+	Lease        time.Duration // how long claimed deliveries stay hidden; 1m
+	MaxAttempts  int           // attempts before dead-lettering; 8
+	BaseBackoff  time.Duration // delay after the first failure, doubled each time; 10s
+	MaxBackoff   time.Duration // upper bound of the delay; 1h
+	Client       *http.Client  // defaults to NewClient(10 * time.Second)
+	// OnError is told about failed attempts and storage errors.
+	OnError func(error)
+}
+
+// Sender polls the delivery queue and POSTs due deliveries.
+type Sender struct {
+	store Store
+	opts  Options
+	now   func() time.Time
+	// jitter picks the actual delay for a backoff of d.
+	jitter func(d time.Duration) time.Duration
+	loop   *worker.Loop
+}
+
+func NewSender(store Store, opts Options) *Sender {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 20
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = time.Minute
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 8
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = 10 * time.Second
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = time.Hour
+	}
+	if opts.Client == nil {
+		opts.Client = NewClient(10 * time.Second)
+	}
+	s := &Sender{store: store, opts: opts, now: time.Now, jitter: equalJitter}
+	s.loop = worker.New(opts.PollInterval, opts.BatchSize, s.SendOnce, opts.OnError)
+	return s
+}
+
+// equalJitter spreads retries of deliveries that failed together over
+// [d/2, d].
+func equalJitter(d time.Duration) time.Duration {
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+// Start runs the sender in a goroutine until Stop is called.
+func (s *Sender) Start() {
+	s.loop.Start()
+}
+
+// Stop stops polling and waits for the batch in flight, or for ctx to be
+// done, in which case requests still running are cancelled. Unfinished
+// deliveries are sent again once their lease expires.
+func (s *Sender) Stop(ctx context.Context) error {
+	return s.loop.Stop(ctx)
+}
+
+// SendOnce claims one batch and sends it. It returns the number of
+// deliveries claimed.
+func (s *Sender) SendOnce(ctx context.Context) (int, error) {
+	deliveries, err := s.store.Claim(ctx, s.opts.BatchSize, s.opts.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("claim deliveries: %w", err)
+	}
+	for _, d := range deliveries {
+		if err := s.send(ctx, d); err != nil {
+			s.report(err)
+		}
+	}
+	return len(deliveries), nil
+}
+
+func (s *Sender) send(ctx context.Context, d Delivery) error {
+	a := s.post(ctx, d)
+	if a.Error == "" {
+		if err := s.store.MarkDelivered(ctx, d.ID, a); err != nil {
+			return fmt.Errorf("mark delivery %d delivered: %w", d.ID, err)
+		}
+		return nil
+	}
+
+	var retryAt *time.Time
+	if attempt := d.Attempts + 1; attempt < s.opts.MaxAttempts {
+		at := s.now().Add(s.jitter(s.backoff(attempt)))
+		retryAt = &at
+	}
+	if err := s.store.MarkFailed(ctx, d.ID, a, retryAt); err != nil {
+		return fmt.Errorf("mark delivery %d failed: %w", d.ID, err)
+	}
+	if retryAt == nil {
+		return fmt.Errorf("delivery %d to %s dead-lettered after %d attempts: %s", d.ID, d.URL, s.opts.MaxAttempts, a.Error)
+	}
+	return fmt.Errorf("delivery %d to %s: %s", d.ID, d.URL, a.Error)
+}
+
+// post sends d once. Any 2xx response counts as delivered.
+func (s *Sender) post(ctx context.Context, d Delivery) Attempt {
+	start := s.now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Body))
+	if err != nil {
+		return Attempt{Error: err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "FilmHub-Webhooks/1.0")
+	req.Header.Set(EventHeader, d.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(SignatureHeader, Sign(d.Secret, start, d.Body))
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return Attempt{Error: err.Error(), Duration: s.now().Sub(start)}
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	a := Attempt{StatusCode: resp.StatusCode, Duration: s.now().Sub(start)}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		a.Error = "endpoint responded " + resp.Status
+	}
+	return a
+}
+
+// backoff is the delay before retry number attempt, before jitter.
+func (s *Sender) backoff(attempt int) time.Duration {
+	return worker.Backoff(s.opts.BaseBackoff, s.opts.MaxBackoff, attempt)
+}
+
+func (s *Sender) report(err error) {
+	if s.opts.OnError != nil {
+		s.opts.OnError(err)
+	}
+}
@@ -0,0 +1,173 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"id":1}`)
+	header := Sign("whsec_test", now, body)
+
+	if err := Verify("whsec_test", header, body, 5*time.Minute, now.Add(time.Minute)); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if err := Verify("whsec_other", header, body, 5*time.Minute, now); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("expected a wrong secret to fail, got %v", err)
+	}
+	if err := Verify("whsec_test", header, []byte(`{"id":2}`), 5*time.Minute, now); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("expected a changed body to fail, got %v", err)
+	}
+	if err := Verify("whsec_test", header, body, 5*time.Minute, now.Add(time.Hour)); !errors.Is(err, ErrStaleSignature) {
+		t.Fatalf("expected an old signature to fail, got %v", err)
+	}
+	if err := Verify("whsec_test", "v1=abc", body, 5*time.Minute, now); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("expected a malformed header to fail, got %v", err)
+	}
+}
+
+// memStore is an in-memory Store; leases are not modelled.
+type memStore struct {
+	now        func() time.Time
+	deliveries []Delivery
+	retryAt    map[int64]time.Time
+	status     map[int64]string
+	attempts   map[int64][]Attempt
+}
+
+func newMemStore(now func() time.Time, deliveries ...Delivery) *memStore {
+	s := &memStore{
+		now:        now,
+		deliveries: deliveries,
+		retryAt:    make(map[int64]time.Time),
+		status:     make(map[int64]string),
+		attempts:   make(map[int64][]Attempt),
+	}
+	for _, d := range deliveries {
+		s.status[d.ID] = "pending"
+	}
+	return s
+}
+
+func (s *memStore) Claim(_ context.Context, limit int, _ time.Duration) ([]Delivery, error) {
+	var out []Delivery
+	for _, d := range s.deliveries {
+		if len(out) < limit && s.status[d.ID] == "pending" && !s.now().Before(s.retryAt[d.ID]) {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func (s *memStore) MarkDelivered(_ context.Context, id int64, a Attempt) error {
+	s.status[id] = "delivered"
+	s.attempts[id] = append(s.attempts[id], a)
+	return nil
+}
+
+func (s *memStore) MarkFailed(_ context.Context, id int64, a Attempt, retryAt *time.Time) error {
+	s.attempts[id] = append(s.attempts[id], a)
+	for i := range s.deliveries {
+		if s.deliveries[i].ID == id {
+			s.deliveries[i].Attempts++
+		}
+	}
+	if retryAt == nil {
+		s.status[id] = "dead"
+		return nil
+	}
+	s.retryAt[id] = *retryAt
+	return nil
+}
+
+func TestSenderRetriesSignedDeliveries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := Verify("whsec_test", r.Header.Get(SignatureHeader), body, time.Hour, time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(EventHeader) != "film.created" || r.Header.Get(DeliveryHeader) != "1" {
+			http.Error(w, "missing headers", http.StatusBadRequest)
+			return
+		}
+		if calls.Add(1) <= 2 {
+			http.Error(w, "try later", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	clock := func() time.Time { return now }
+	store := newMemStore(clock, Delivery{ID: 1, URL: srv.URL, Secret: "whsec_test", EventType: "film.created", Body: []byte(`{"id":1}`)})
+	s := NewSender(store, Options{BaseBackoff: time.Second, Client: srv.Client()})
+	s.now = clock
+	var delays []time.Duration
+	s.jitter = func(d time.Duration) time.Duration { delays = append(delays, d); return d }
+
+	for range 3 {
+		if n, err := s.SendOnce(ctx); err != nil || n != 1 {
+			t.Fatalf("expected the delivery to be due, got %d, %v", n, err)
+		}
+		now = now.Add(10 * time.Second)
+	}
+	if store.status[1] != "delivered" {
+		t.Fatalf("expected delivery on the third attempt, got %s", store.status[1])
+	}
+	if len(delays) != 2 || delays[0] != time.Second || delays[1] != 2*time.Second {
+		t.Fatalf("expected exponential backoff, got %v", delays)
+	}
+	attempts := store.attempts[1]
+	if attempts[0].StatusCode != http.StatusServiceUnavailable || attempts[0].Error == "" || attempts[2].StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected attempts %+v", attempts)
+	}
+}
+
+func TestSenderDeadLetters(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	clock := func() time.Time { return now }
+	store := newMemStore(clock, Delivery{ID: 1, URL: srv.URL, Secret: "s", EventType: "film.updated", Body: []byte(`{}`)})
+	var reported []error
+	s := NewSender(store, Options{MaxAttempts: 3, Client: srv.Client(), OnError: func(err error) { reported = append(reported, err) }})
+	s.now = clock
+
+	for range 5 {
+		s.SendOnce(ctx)
+		now = now.Add(24 * time.Hour)
+	}
+	if store.status[1] != "dead" || len(store.attempts[1]) != 3 {
+		t.Fatalf("expected dead-lettering after 3 attempts, got %s after %d", store.status[1], len(store.attempts[1]))
+	}
+	if len(reported) != 3 {
+		t.Fatalf("expected every failure reported, got %v", reported)
+	}
+}
+
+func TestEqualJitter(t *testing.T) {
+	for range 100 {
+		if d := equalJitter(10 * time.Second); d < 5*time.Second || d > 10*time.Second {
+			t.Fatalf("jitter %v outside [5s, 10s]", d)
+		}
+	}
+	s := NewSender(nil, Options{BaseBackoff: time.Second, MaxBackoff: 3 * time.Second})
+	if d := s.backoff(5); d != 3*time.Second {
+		t.Fatalf("expected the backoff to be capped, got %v", d)
+	}
+}
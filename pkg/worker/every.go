@@ -0,0 +1,24 @@
+package worker
+
+import (
+	"context"
+	"time"
+)
+
+// Every calls job right away and then every interval until ctx is done.
+// Errors are passed to onError, which may be nil, unless they come from ctx
+// being done; a failed job is simply run again on the next tick.
+func Every(ctx context.Context, every time.Duration, job func(context.Context) error, onError func(error)) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		if err := job(ctx); err != nil && ctx.Err() == nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Package worker runs the polling loop shared by the background queues: a
+// batch is processed every poll interval, and again right away while full
+// batches show there is a backlog. Stop waits for the batch in flight and
+// cancels it when the caller stops waiting. Every runs the periodic jobs
+// that have no queue to drain.
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Batch processes one batch and returns how many items it claimed.
+type Batch func(ctx context.Context) (int, error)
+
+// Loop calls a Batch in a goroutine between Start and Stop.
+type Loop struct {
+	every   time.Duration
+	size    int
+	batch   Batch
+	onError func(error)
+
+	// ctx is what batches run with; cancel aborts them when Stop gives up
+	// waiting.
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// New returns a Loop running batch every interval, and again immediately
+// while it claims size items. Errors of batch are passed to onError, which
+// may be nil.
+func New(every time.Duration, size int, batch Batch, onError func(error)) *Loop {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Loop{
+		every:   every,
+		size:    size,
+		batch:   batch,
+		onError: onError,
+		ctx:     ctx,
+		cancel:  cancel,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start runs the loop in a goroutine until Stop is called.
+func (l *Loop) Start() {
+	go l.run()
+}
+
+// Stop stops polling and waits for the batch in flight to finish, or for
+// ctx to be done, whichever comes first; in the latter case the batch is
+// cancelled.
+func (l *Loop) Stop(ctx context.Context) error {
+	l.once.Do(func() { close(l.stop) })
+	defer l.cancel()
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *Loop) run() {
+	defer close(l.done)
+	ticker := time.NewTicker(l.every)
+	defer ticker.Stop()
+	for {
+		// Keep going while there is a backlog, then wait for the next tick.
+		for {
+			n, err := l.batch(l.ctx)
+			if err != nil && l.onError != nil {
+				l.onError(err)
+			}
+			if err != nil || n < l.size || l.stopped() {
+				break
+			}
+		}
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (l *Loop) stopped() bool {
+	select {
+	case <-l.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// Backoff is the delay before retry number attempt: base, doubled for every
+// earlier retry, and capped at limit.
+func Backoff(base, limit time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoopDrainsBacklogBeforeWaiting(t *testing.T) {
+	var calls atomic.Int32
+	drained := make(chan struct{})
+	l := New(time.Hour, 10, func(context.Context) (int, error) {
+		// Two full batches, then a partial one ends the backlog.
+		switch calls.Add(1) {
+		case 1, 2:
+			return 10, nil
+		case 3:
+			close(drained)
+		}
+		return 3, nil
+	}, nil)
+	l.Start()
+	select {
+	case <-drained:
+	case <-time.After(time.Second):
+		t.Fatal("expected full batches to be followed right away")
+	}
+	if err := l.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("expected the loop to wait for the next tick after a partial batch, got %d batches", n)
+	}
+}
+
+func TestLoopReportsErrors(t *testing.T) {
+	reported := make(chan error, 1)
+	l := New(time.Hour, 10, func(context.Context) (int, error) {
+		return 0, errors.New("claim failed")
+	}, func(err error) { reported <- err })
+	l.Start()
+	defer l.Stop(context.Background())
+	select {
+	case err := <-reported:
+		if err.Error() != "claim failed" {
+			t.Fatalf("unexpected error %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the error to be reported")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 40: 5 * time.Second} {
+		if got := Backoff(time.Second, 5*time.Second, attempt); got != want {
+			t.Errorf("Backoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
+
+func TestEveryRunsRightAwayAndReportsErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	reported := make(chan error, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Every(ctx, time.Hour, func(context.Context) error {
+			return errors.New("boom")
+		}, func(err error) { reported <- err })
+	}()
+	select {
+	case err := <-reported:
+		if err.Error() != "boom" {
+			t.Fatalf("expected the job error, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the job to run before the first tick")
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected Every to return once ctx is done")
+	}
+}
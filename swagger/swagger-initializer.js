@@ -0,0 +1,8 @@
+window.onload = function () {
+  window.ui = SwaggerUIBundle({
+    url: "doc.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    persistAuthorization: true
+  });
+};
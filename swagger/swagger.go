@@ -0,0 +1,25 @@
+// Package swagger embeds the generated OpenAPI spec and the Swagger UI page.
+//
+// swagger.json is produced by cmd/swaggen from the handler annotations; run
+// `go generate ./swagger` after changing them instead of editing it by hand.
+package swagger
+
+import _ "embed"
+
+//go:generate go run ../cmd/swaggen -root .. -o swagger/swagger.json
+
+// Spec is the OpenAPI document served at /swagger/doc.json.
+//
+//go:embed swagger.json
+var Spec []byte
+
+// IndexHTML is the Swagger UI page; assets are loaded from a CDN.
+//
+//go:embed index.html
+var IndexHTML []byte
+
+// InitializerJS boots Swagger UI; it lives in a separate file so the page
+// works under a CSP without 'unsafe-inline' scripts.
+//
+//go:embed swagger-initializer.js
+var InitializerJS []byte